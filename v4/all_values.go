@@ -0,0 +1,205 @@
+package configcat
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sort"
+	"sync"
+)
+
+// GetAllValues evaluates all the settings for the given user and returns the values by their keys.
+// Optional user argument can be passed to identify the caller. The settings which fail to evaluate are
+// left out, unless they have a default registered with RegisterDefault. When AllValuesCacheSize is set,
+// the result is reused for the same user while the configuration doesn't change.
+func (client *Client) GetAllValues(user *User) (map[string]interface{}, error) {
+	json, err := client.getConfiguration()
+	if err != nil {
+		client.reportError(err)
+		json = client.servedConfiguration()
+	}
+
+	config, err := client.parser.load(json)
+	if err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+
+	var userKey [sha256.Size]byte
+	if client.allValues != nil {
+		userKey = userCacheKey(user)
+		if values, ok := client.allValues.get(config, userKey); ok {
+			return copyValues(values), nil
+		}
+	}
+
+	values := make(map[string]interface{}, len(config.settings))
+	for key := range config.settings {
+		details := client.evaluateDetails(json, key, client.defaults.resolve(key, nil), user)
+		if details.Value != nil {
+			values[key] = details.Value
+		}
+	}
+
+	if client.allValues != nil {
+		client.allValues.put(config, userKey, values)
+		return copyValues(values), nil
+	}
+	return values, nil
+}
+
+// GetValues evaluates the settings of the given keys for the user and returns the values by their keys.
+// The settings are evaluated against the same configuration, so related flags read together never mix
+// the values of two configuration versions even when the configuration changes meanwhile. The keys which fail
+// to evaluate are left out, unless they have a default registered with RegisterDefault.
+func (client *Client) GetValues(keys []string, user *User) (map[string]interface{}, error) {
+	json, err := client.getConfiguration()
+	if err != nil {
+		client.reportError(err)
+		json = client.servedConfiguration()
+	}
+
+	if _, err := client.parser.load(json); err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		client.evaluatedKeys.add(key)
+		details := client.evaluateDetails(json, key, client.defaults.resolve(key, nil), user)
+		if details.Value != nil {
+			values[key] = details.Value
+		}
+	}
+	return values, nil
+}
+
+// allValuesCache is an LRU cache of the GetAllValues results of a configuration by the users.
+type allValuesCache struct {
+	capacity int
+	budget   *memoBudget
+	// the configuration of the cached results, the cache is emptied when it changes
+	config  *parsedConfig
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+	sync.Mutex
+}
+
+type allValuesEntry struct {
+	userKey [sha256.Size]byte
+	values  map[string]interface{}
+	memo    *memoEntry
+}
+
+func newAllValuesCache(capacity int, budget *memoBudget) *allValuesCache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &allValuesCache{capacity: capacity, budget: budget,
+		entries: map[[sha256.Size]byte]*list.Element{}, order: list.New()}
+}
+
+func (cache *allValuesCache) len() int {
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.order.Len()
+}
+
+func (cache *allValuesCache) get(config *parsedConfig, userKey [sha256.Size]byte) (map[string]interface{}, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	if cache.config != config {
+		return nil, false
+	}
+	element, ok := cache.entries[userKey]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	entry := element.Value.(*allValuesEntry)
+	cache.budget.touch(entry.memo)
+	return entry.values, true
+}
+
+func (cache *allValuesCache) put(config *parsedConfig, userKey [sha256.Size]byte, values map[string]interface{}) {
+	var evicted []*memoEntry
+	defer func() {
+		release(evicted)
+	}()
+	cache.Lock()
+	defer cache.Unlock()
+
+	if cache.config != config {
+		for element := cache.order.Front(); element != nil; element = element.Next() {
+			cache.budget.remove(element.Value.(*allValuesEntry).memo)
+		}
+		cache.config = config
+		cache.entries = map[[sha256.Size]byte]*list.Element{}
+		cache.order.Init()
+	}
+	if element, ok := cache.entries[userKey]; ok {
+		element.Value.(*allValuesEntry).values = values
+		cache.order.MoveToFront(element)
+		cache.budget.touch(element.Value.(*allValuesEntry).memo)
+		return
+	}
+	entry := &allValuesEntry{userKey: userKey, values: values}
+	size := int64(memoEntryOverhead)
+	for key, value := range values {
+		size += int64(len(key)) + estimateValueSize(value)
+	}
+	entry.memo, evicted = cache.budget.add(cache, userKey, size)
+	cache.entries[userKey] = cache.order.PushFront(entry)
+	if cache.order.Len() > cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*allValuesEntry).userKey)
+		cache.budget.remove(oldest.Value.(*allValuesEntry).memo)
+	}
+}
+
+// evict removes the entry evicted by the memoization budget.
+func (cache *allValuesCache) evict(memo *memoEntry) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	element, ok := cache.entries[memo.key.([sha256.Size]byte)]
+	if ok && element.Value.(*allValuesEntry).memo == memo {
+		cache.order.Remove(element)
+		delete(cache.entries, memo.key.([sha256.Size]byte))
+	}
+}
+
+// userCacheKey hashes the attributes of the user, the users with the same attributes evaluate the same.
+func userCacheKey(user *User) [sha256.Size]byte {
+	if user == nil {
+		return [sha256.Size]byte{}
+	}
+
+	names := make([]string, 0, len(user.attributes))
+	for name := range user.attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(user.attributes[name]))
+		hash.Write([]byte{0})
+	}
+	var key [sha256.Size]byte
+	copy(key[:], hash.Sum(nil))
+	return key
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package configcat
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_GetAllValues(t *testing.T) {
+	var evaluations int32
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:               ManualPoll(),
+		AllValuesCacheSize: 1,
+		Hooks:              Hooks{OnFlagEvaluated: func(Impression) { atomic.AddInt32(&evaluations, 1) }},
+	}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"flag": { "v": false, "t": 0, "r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": true } ] },
+		"text": { "v": "value", "t": 1 }
+	}`})
+	client.Refresh()
+	client.RegisterDefault("missing", 1)
+
+	user := NewUserWithAdditionalAttributes("id", "a@example.com", "", nil)
+	values, err := client.GetAllValues(user)
+	if err != nil || len(values) != 2 || values["flag"] != true || values["text"] != "value" {
+		t.Fatalf("Unexpected values: %v, %v", values, err)
+	}
+	values["flag"] = false
+
+	values, _ = client.GetAllValues(NewUserWithAdditionalAttributes("id", "a@example.com", "", nil))
+	if values["flag"] != true || atomic.LoadInt32(&evaluations) != 2 {
+		t.Errorf("Expecting the cached values of the same user, got %v after %d evaluations", values, evaluations)
+	}
+
+	values, _ = client.GetAllValues(nil)
+	if values["flag"] != false || atomic.LoadInt32(&evaluations) != 4 {
+		t.Errorf("Expecting the values of another user, got %v after %d evaluations", values, evaluations)
+	}
+
+	client.GetAllValues(user)
+	if atomic.LoadInt32(&evaluations) != 6 {
+		t.Errorf("Expecting the evicted user to be evaluated again, got %d evaluations", evaluations)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "text": { "v": "changed", "t": 1 } }`})
+	client.Refresh()
+	values, _ = client.GetAllValues(user)
+	if len(values) != 1 || values["text"] != "changed" || atomic.LoadInt32(&evaluations) != 7 {
+		t.Errorf("Expecting the values of the changed config, got %v after %d evaluations", values, evaluations)
+	}
+}
+
+func TestUserCacheKey(t *testing.T) {
+	first := NewUserWithAdditionalAttributes("id", "", "", map[string]string{"a": "1", "b": "2"})
+	second := NewUserWithAdditionalAttributes("id", "", "", map[string]string{"b": "2", "a": "1"})
+	third := NewUserWithAdditionalAttributes("id", "", "", map[string]string{"a": "12"})
+	if userCacheKey(first) != userCacheKey(second) {
+		t.Error("Expecting the same key for the same attributes")
+	}
+	if userCacheKey(first) == userCacheKey(third) || userCacheKey(nil) == userCacheKey(first) {
+		t.Error("Expecting different keys for different attributes")
+	}
+}
+
+func TestClient_GetValues(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	var client *Client
+	refreshed := false
+	client = newInternal("fakeKey", ClientConfig{
+		Mode: ManualPoll(),
+		Hooks: Hooks{OnFlagEvaluated: func(Impression) {
+			if !refreshed {
+				refreshed = true
+				fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+					"first": { "v": "new", "t": 1 }, "second": { "v": "new", "t": 1 }
+				}`})
+				client.Refresh()
+			}
+		}},
+	}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"first": { "v": "old", "t": 1 }, "second": { "v": "old", "t": 1 }, "third": { "v": "old", "t": 1 }
+	}`})
+	client.Refresh()
+	client.RegisterDefault("missing", 1)
+
+	values, err := client.GetValues([]string{"first", "second", "missing", "unknown"}, nil)
+	if err != nil || len(values) != 3 || values["first"] != "old" || values["second"] != "old" || values["missing"] != 1 {
+		t.Fatalf("Expecting the values of the same config, got %v, %v", values, err)
+	}
+
+	values, _ = client.GetValues([]string{"first", "third"}, nil)
+	if len(values) != 1 || values["first"] != "new" {
+		t.Errorf("Expecting the values of the refreshed config, got %v", values)
+	}
+	for _, key := range []string{"first", "second", "missing", "unknown", "third"} {
+		if !client.evaluatedKeys.contains(key) {
+			t.Errorf("Expecting %s to be recorded as evaluated", key)
+		}
+	}
+}
@@ -17,15 +17,22 @@ import (
 //  })
 //  go func() { async.complete() }()
 type async struct {
-	state       uint32
-	completions []func()
+	state uint32
+	// the subscribed callbacks in the order of their subscriptions, nil once the operation is completed
+	completions []subscription
+	nextId      uint64
 	done        chan struct{}
-	sync.RWMutex
+	sync.Mutex
+}
+
+type subscription struct {
+	id         uint64
+	completion func()
 }
 
 // newAsync initializes a new async object.
 func newAsync() *async {
-	return &async{state: pending, completions: []func(){}, done: make(chan struct{})}
+	return &async{state: pending, completions: []subscription{}, done: make(chan struct{})}
 }
 
 // isCompleted returns true if the async operation is marked as completed, otherwise false.
@@ -47,47 +54,84 @@ func (async *async) isPending() bool {
 //     fmt.Print("operation completed")
 //  })
 func (async *async) accept(completion func()) *async {
-	if async.isCompleted() {
+	async.subscribe(completion)
+	return async
+}
+
+// subscribe registers a callback function called when the async operation completed, or calls it immediately
+// when it's already completed. The returned function removes the callback if it wasn't called yet, and
+// reports whether no callbacks are left.
+func (async *async) subscribe(completion func()) (unsubscribe func() bool) {
+	async.Lock()
+	if async.completions == nil {
+		async.Unlock()
 		completion()
+		return func() bool { return false }
 	}
 
-	if async.isPending() {
+	id := async.nextId
+	async.nextId++
+	async.completions = append(async.completions, subscription{id: id, completion: completion})
+	async.Unlock()
+
+	return func() bool {
 		async.Lock()
-		async.completions = append(async.completions, completion)
-		async.Unlock()
+		defer async.Unlock()
+		for i, subscription := range async.completions {
+			if subscription.id == id {
+				async.completions = append(async.completions[:i], async.completions[i+1:]...)
+				return len(async.completions) == 0
+			}
+		}
+		return false
 	}
+}
 
-	return async
+// subscriberCount returns the number of the callbacks waiting for the completion.
+func (async *async) subscriberCount() int {
+	async.Lock()
+	defer async.Unlock()
+	return len(async.completions)
 }
 
 // apply allows the chaining of the async operations after each other and subscribes a
 // callback function which called when the async operation completed.
-// Returns an asyncResult object which returns a result.
+// Returns an asyncResult object which returns a result. The returned asyncResult can be
+// cancelled, which removes the callback.
 // For example:
 //  async.apply(func() {
 //     return "new result"
 //  })
 func (async *async) apply(completion func() interface{}) *asyncResult {
 	asyncResult := newAsyncResult()
-	async.accept(func() {
+	unsubscribe := async.subscribe(func() {
 		newResult := completion()
 		asyncResult.complete(newResult)
 	})
+	asyncResult.setDetach(func() {
+		unsubscribe()
+	})
 
 	return asyncResult
 }
 
-// complete moves the async operation into the completed state.
-func (async *async) complete() {
-	if atomic.CompareAndSwapUint32(&async.state, pending, completed) {
-		close(async.done)
-		async.RLock()
-		defer async.RUnlock()
-		for _, comp := range async.completions {
-			comp()
-		}
+// complete moves the async operation into the completed state and calls the subscribed callbacks.
+// It reports whether the operation was pending.
+func (async *async) complete() bool {
+	async.Lock()
+	if !atomic.CompareAndSwapUint32(&async.state, pending, completed) {
+		async.Unlock()
+		return false
 	}
+	completions := async.completions
 	async.completions = nil
+	close(async.done)
+	async.Unlock()
+
+	for _, subscription := range completions {
+		subscription.completion()
+	}
+	return true
 }
 
 // wait blocks until the async operation is completed.
@@ -97,11 +141,11 @@ func (async *async) wait() {
 
 // waitOrTimeout blocks until the async operation is completed or until
 // the given timeout duration expires.
-func (async *async) waitOrTimeout(duration time.Duration) error {
-	timer := time.NewTimer(duration)
+func (async *async) waitOrTimeout(clock Clock, duration time.Duration) error {
+	timer := clock.NewTimer(duration)
 	defer timer.Stop()
 	select {
-	case <-timer.C:
+	case <-timer.C():
 		return errors.New("operation cancelled")
 	case <-async.done:
 		return nil
@@ -0,0 +1,138 @@
+package configcat
+
+import (
+	"sync"
+	"time"
+)
+
+// AsyncCacheConfig describes the configuration of an AsyncCache.
+type AsyncCacheConfig struct {
+	// The interval of reading the external cache in the background. The default is 10 seconds.
+	RefreshInterval time.Duration
+	// The logger of the background operations. If it's nil then the default logger is used.
+	Logger Logger
+	// The source of the time of the periodic reads. If it's nil then the system clock is used.
+	Clock Clock
+}
+
+// AsyncCache is a ConfigCache which serves the reads from memory and accesses the wrapped external cache
+// in the background: it's read periodically (read-through) and written asynchronously (write-behind),
+// so a slow external cache never blocks the flag evaluations.
+type AsyncCache struct {
+	cache   ConfigCache
+	logger  Logger
+	value   string
+	dirty   bool
+	writes  chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	sync.RWMutex
+}
+
+// NewAsyncCache wraps the external cache into an AsyncCache and starts its background worker,
+// call Close to stop it.
+func NewAsyncCache(cache ConfigCache, config AsyncCacheConfig) *AsyncCache {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Second * 10
+	}
+
+	if config.Logger == nil {
+		config.Logger = defaultConfig().Logger
+	}
+
+	if config.Clock == nil {
+		config.Clock = systemClock{}
+	}
+
+	asyncCache := &AsyncCache{cache: cache,
+		logger:  config.Logger,
+		writes:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{})}
+	go asyncCache.run(config.Clock.NewTicker(config.RefreshInterval))
+	return asyncCache
+}
+
+// Get reads the configuration from memory.
+func (cache *AsyncCache) Get() (string, error) {
+	cache.RLock()
+	defer cache.RUnlock()
+	return cache.value, nil
+}
+
+// Set writes the configuration into memory and schedules its write into the external cache.
+// Only the latest configuration is written when the writes are faster than the external cache.
+func (cache *AsyncCache) Set(value string) error {
+	cache.Lock()
+	cache.value = value
+	cache.dirty = true
+	cache.Unlock()
+
+	select {
+	case cache.writes <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close stops the background worker after writing the pending configuration into the external cache.
+func (cache *AsyncCache) Close() {
+	cache.once.Do(func() {
+		close(cache.stop)
+	})
+	<-cache.stopped
+}
+
+func (cache *AsyncCache) run(ticker Ticker) {
+	defer close(cache.stopped)
+	defer ticker.Stop()
+
+	cache.refresh()
+	for {
+		select {
+		case <-cache.writes:
+			cache.write()
+		case <-ticker.C():
+			cache.write()
+			cache.refresh()
+		case <-cache.stop:
+			cache.write()
+			return
+		}
+	}
+}
+
+// refresh reads the external cache, unless a newer configuration is waiting to be written into it.
+func (cache *AsyncCache) refresh() {
+	value, err := cache.cache.Get()
+	if err != nil {
+		logEvent(cache.logger, LogLevelError, LogEventCache, logFields{}, "Reading from the external cache failed, %s", err)
+		return
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if !cache.dirty && len(value) > 0 {
+		cache.value = value
+	}
+}
+
+// write writes the pending configuration into the external cache. A failed write is retried with the next
+// refresh.
+func (cache *AsyncCache) write() {
+	cache.Lock()
+	value, dirty := cache.value, cache.dirty
+	cache.dirty = false
+	cache.Unlock()
+	if !dirty {
+		return
+	}
+
+	if err := cache.cache.Set(value); err != nil {
+		logEvent(cache.logger, LogLevelError, LogEventCache, logFields{}, "Saving into the external cache failed, %s", err)
+		cache.Lock()
+		cache.dirty = true
+		cache.Unlock()
+	}
+}
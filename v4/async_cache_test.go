@@ -0,0 +1,141 @@
+package configcat
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slowCache struct {
+	value string
+	delay time.Duration
+	sync.Mutex
+}
+
+func (cache *slowCache) Get() (string, error) {
+	time.Sleep(cache.delay)
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.value, nil
+}
+
+func (cache *slowCache) Set(value string) error {
+	time.Sleep(cache.delay)
+	cache.Lock()
+	defer cache.Unlock()
+	cache.value = value
+	return nil
+}
+
+func (cache *slowCache) stored() string {
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.value
+}
+
+func TestAsyncCache(t *testing.T) {
+	external := &slowCache{value: "external", delay: time.Millisecond * 50}
+	cache := NewAsyncCache(external, AsyncCacheConfig{RefreshInterval: time.Millisecond * 100})
+
+	time.Sleep(time.Millisecond * 100)
+	if value, _ := cache.Get(); value != "external" {
+		t.Errorf("Expecting the external value, got %s", value)
+	}
+
+	start := time.Now()
+	_ = cache.Set("local")
+	if value, _ := cache.Get(); value != "local" || time.Since(start) > time.Millisecond*20 {
+		t.Errorf("Expecting the local value without waiting, got %s", value)
+	}
+
+	cache.Close()
+	if stored := external.stored(); stored != "local" {
+		t.Errorf("Expecting the local value in the external cache, got %s", stored)
+	}
+}
+
+// tickingClock is a Clock whose tickers tick only when a time is sent on ticks.
+type tickingClock struct {
+	systemClock
+	ticks chan time.Time
+}
+
+func (clock *tickingClock) NewTicker(d time.Duration) Ticker {
+	return manualTicker{ticks: clock.ticks}
+}
+
+type manualTicker struct {
+	ticks chan time.Time
+}
+
+func (ticker manualTicker) C() <-chan time.Time {
+	return ticker.ticks
+}
+
+func (ticker manualTicker) Stop() {
+}
+
+func TestAsyncCache_Clock(t *testing.T) {
+	external := &slowCache{value: "external"}
+	clock := &tickingClock{ticks: make(chan time.Time)}
+	cache := NewAsyncCache(external, AsyncCacheConfig{RefreshInterval: time.Millisecond, Clock: clock})
+	defer cache.Close()
+
+	for value, _ := cache.Get(); value != "external"; value, _ = cache.Get() {
+		time.Sleep(time.Millisecond)
+	}
+	_ = external.Set("updated")
+	time.Sleep(time.Millisecond * 20)
+	if value, _ := cache.Get(); value != "external" {
+		t.Errorf("Expecting no refresh without a tick, got %s", value)
+	}
+
+	clock.ticks <- time.Now()
+	for value, _ := cache.Get(); value != "updated"; value, _ = cache.Get() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// failingSetCache is a slowCache whose first writes fail.
+type failingSetCache struct {
+	slowCache
+	failures int
+}
+
+func (cache *failingSetCache) Set(value string) error {
+	cache.Lock()
+	if cache.failures > 0 {
+		cache.failures--
+		cache.Unlock()
+		return errors.New("fake write error")
+	}
+	cache.Unlock()
+	return cache.slowCache.Set(value)
+}
+
+func TestAsyncCache_FailedWrite(t *testing.T) {
+	external := &failingSetCache{slowCache: slowCache{value: "external"}, failures: 1}
+	clock := &tickingClock{ticks: make(chan time.Time)}
+	cache := NewAsyncCache(external, AsyncCacheConfig{RefreshInterval: time.Millisecond, Clock: clock,
+		Logger: DefaultLogger(LogLevelFatal)})
+	defer cache.Close()
+
+	for value, _ := cache.Get(); value != "external"; value, _ = cache.Get() {
+		time.Sleep(time.Millisecond)
+	}
+	_ = cache.Set("local")
+	time.Sleep(time.Millisecond * 20)
+
+	clock.ticks <- time.Now()
+	deadline := time.Now().Add(time.Second)
+	for external.stored() != "local" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expecting the failed write to be retried")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if value, _ := cache.Get(); value != "local" {
+		t.Errorf("Expecting the refresh to keep the local value, got %s", value)
+	}
+}
@@ -1,12 +1,16 @@
 package configcat
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// errAsyncCancelled is the error of the async operations cancelled because nothing waits for their results.
+var errAsyncCancelled = errors.New("async operation cancelled")
+
 // AsyncResult describes an object which used to control asynchronous operations with return value.
 // Allows the chaining of these operations after each other.
 // Usage:
@@ -18,18 +22,25 @@ import (
 //     fmt.Print("chained operation completed")
 //  })
 //  go func() { async.Complete("success") }()
+//
+// The results chained by apply, applyThen and applyThenWithError can be cancelled when their caller stops
+// waiting for them, e.g. after a timeout, see cancel.
 type asyncResult struct {
-	state       uint32
-	completions []func(result interface{})
-	done        chan struct{}
-	result      interface{}
+	result interface{}
+	err    error
+	// true once the result is set, the operation completes right after
+	settled bool
+	// removes the callback completing this result from the operation it's chained to, nil for the roots
+	detach func()
+	// the number of the holders keeping the operation alive, see hold
+	holds int32
 	*async
-	sync.RWMutex
+	sync.Mutex
 }
 
 // newAsyncResult initializes a new async object with result.
 func newAsyncResult() *asyncResult {
-	return &asyncResult{state: pending, completions: []func(result interface{}){}, done: make(chan struct{}), async: newAsync()}
+	return &asyncResult{async: newAsync()}
 }
 
 // asCompletedAsyncResult creates an already completed async object.
@@ -51,6 +62,17 @@ func (asyncResult *asyncResult) accept(completion func(result interface{})) *asy
 	})
 }
 
+// acceptWithError is like accept, but the subscribed callback function also gets the error
+// the async operation was completed with. For example:
+//  async.acceptWithError(func(result interface{}, err error) {
+//     fmt.Print(result, err)
+//  })
+func (asyncResult *asyncResult) acceptWithError(completion func(result interface{}, err error)) *async {
+	return asyncResult.async.accept(func() {
+		completion(asyncResult.result, asyncResult.err)
+	})
+}
+
 // applyThen allows the chaining of the async operations after each other and subscribes a
 // callback function which gets the operation result as argument and called when the async
 // operation completed. Returns an AsyncResult object which returns a different result type.
@@ -59,10 +81,30 @@ func (asyncResult *asyncResult) accept(completion func(result interface{})) *asy
 //     fmt.Print(result)
 //  })
 func (asyncResult *asyncResult) applyThen(completion func(result interface{}) interface{}) *asyncResult {
+	return asyncResult.applyThenWithError(func(result interface{}, err error) (interface{}, error) {
+		return completion(result), nil
+	})
+}
+
+// applyThenWithError is like applyThen, but the subscribed callback function also gets the error
+// the async operation was completed with, and it can complete the returned AsyncResult with an error.
+// For example:
+//  async.applyThenWithError(func(result interface{}, err error) (interface{}, error) {
+//     if err != nil {
+//        return nil, err
+//     }
+//     return "new result", nil
+//  })
+func (asyncResult *asyncResult) applyThenWithError(completion func(result interface{}, err error) (interface{}, error)) *asyncResult {
 	newAsyncResult := newAsyncResult()
-	asyncResult.accept(func(result interface{}) {
-		newResult := completion(result)
-		newAsyncResult.complete(newResult)
+	unsubscribe := asyncResult.async.subscribe(func() {
+		newAsyncResult.completeWith(completion(asyncResult.result, asyncResult.err))
+	})
+	newAsyncResult.setDetach(func() {
+		// the cancellation propagates up the chain while nothing else waits for the operations
+		if unsubscribe() {
+			asyncResult.cancel()
+		}
 	})
 	return newAsyncResult
 }
@@ -70,17 +112,63 @@ func (asyncResult *asyncResult) applyThen(completion func(result interface{}) in
 // complete moves the async operation into the completed state.
 // Gets the result of the operation as argument.
 func (asyncResult *asyncResult) complete(result interface{}) {
-	if atomic.CompareAndSwapUint32(&asyncResult.state, pending, completed) {
-		asyncResult.result = result
-		asyncResult.async.complete()
-		close(asyncResult.done)
-		asyncResult.RLock()
-		defer asyncResult.RUnlock()
-		for _, comp := range asyncResult.completions {
-			comp(result)
-		}
+	asyncResult.completeWith(result, nil)
+}
+
+// completeWithError moves the async operation into the completed state.
+// Gets the reason of the operation's failure as argument.
+func (asyncResult *asyncResult) completeWithError(err error) {
+	asyncResult.completeWith(nil, err)
+}
+
+func (asyncResult *asyncResult) completeWith(result interface{}, err error) {
+	asyncResult.Lock()
+	if asyncResult.settled {
+		asyncResult.Unlock()
+		return
+	}
+	asyncResult.settled = true
+	asyncResult.result = result
+	asyncResult.err = err
+	asyncResult.detach = nil
+	asyncResult.Unlock()
+
+	asyncResult.async.complete()
+}
+
+// setDetach sets the function removing the callback which completes the result from the operation it's chained to.
+func (asyncResult *asyncResult) setDetach(detach func()) {
+	asyncResult.Lock()
+	defer asyncResult.Unlock()
+	if !asyncResult.settled {
+		asyncResult.detach = detach
+	}
+}
+
+// hold keeps the operation alive even if nothing waits for its result, because its callbacks have side effects
+// (e.g. updating the cache with a fetched configuration). The held operations are never cancelled.
+func (asyncResult *asyncResult) hold() *asyncResult {
+	atomic.AddInt32(&asyncResult.holds, 1)
+	return asyncResult
+}
+
+// cancel completes the pending operation with errAsyncCancelled and removes it from the operation it's chained
+// to, unless it's held or other operations are chained to it. The operations it's chained to are cancelled as
+// well when nothing else waits for them, so the abandoned chains don't pile up waiting for a completion which
+// may never come.
+func (asyncResult *asyncResult) cancel() {
+	if atomic.LoadInt32(&asyncResult.holds) > 0 || asyncResult.subscriberCount() > 0 {
+		return
+	}
+
+	asyncResult.Lock()
+	detach := asyncResult.detach
+	asyncResult.Unlock()
+
+	asyncResult.completeWithError(errAsyncCancelled)
+	if detach != nil {
+		detach()
 	}
-	asyncResult.completions = nil
 }
 
 // get blocks until the async operation is completed,
@@ -90,16 +178,38 @@ func (asyncResult *asyncResult) get() interface{} {
 	return asyncResult.result
 }
 
+// getWithError blocks until the async operation is completed,
+// then returns the result and the error of the operation.
+func (asyncResult *asyncResult) getWithError() (interface{}, error) {
+	<-asyncResult.done
+	return asyncResult.result, asyncResult.err
+}
+
+// getWithContext blocks until the async operation is completed or until
+// the given context is done, then returns the result and the error of the operation.
+// The abandoned operation is cancelled.
+func (asyncResult *asyncResult) getWithContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-ctx.Done():
+		asyncResult.cancel()
+		return nil, ctx.Err()
+	case <-asyncResult.done:
+		return asyncResult.result, asyncResult.err
+	}
+}
+
 // GetOrTimeout blocks until the async operation is completed or until
 // the given timeout duration expires, then returns the result of the operation.
-func (asyncResult *asyncResult) getOrTimeout(duration time.Duration) (interface{}, error) {
-	timer := time.NewTimer(duration)
+// The abandoned operation is cancelled.
+func (asyncResult *asyncResult) getOrTimeout(clock Clock, duration time.Duration) (interface{}, error) {
+	timer := clock.NewTimer(duration)
 	defer timer.Stop()
 
 	select {
-	case <-timer.C:
+	case <-timer.C():
+		asyncResult.cancel()
 		return nil, errors.New("operation cancelled")
 	case <-asyncResult.done:
-		return asyncResult.result, nil
+		return asyncResult.result, asyncResult.err
 	}
 }
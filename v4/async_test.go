@@ -0,0 +1,75 @@
+package configcat
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+func TestAsyncResult_CancelPropagatesUpTheChain(t *testing.T) {
+	root := newAsyncResult()
+	middle := root.applyThen(func(result interface{}) interface{} { return result })
+	leaf := middle.applyThen(func(result interface{}) interface{} { return result })
+
+	leaf.cancel()
+	if _, err := leaf.getWithError(); err != errAsyncCancelled {
+		t.Errorf("Expecting the leaf to be cancelled, got %v", err)
+	}
+	if !middle.isCompleted() || !root.isCompleted() {
+		t.Error("Expecting the abandoned chain to be cancelled")
+	}
+}
+
+func TestAsyncResult_CancelStopsAtSharedAndHeldOperations(t *testing.T) {
+	root := newAsyncResult()
+	held := root.applyThen(func(result interface{}) interface{} { return result }).hold()
+	held.applyThen(func(result interface{}) interface{} { return result }).cancel()
+	if held.isCompleted() || root.subscriberCount() != 1 {
+		t.Error("Expecting the held operation to stay subscribed")
+	}
+
+	shared := newAsyncResult()
+	first := shared.applyThen(func(result interface{}) interface{} { return result })
+	second := shared.applyThen(func(result interface{}) interface{} { return result })
+	first.cancel()
+	if shared.isCompleted() || second.isCompleted() {
+		t.Error("Expecting the operation waited by others not to be cancelled")
+	}
+
+	shared.complete("done")
+	if result, err := second.getWithError(); result != "done" || err != nil {
+		t.Errorf("Unexpected result: %v, %v", result, err)
+	}
+	if result, err := first.getWithError(); err != errAsyncCancelled || result != nil {
+		t.Errorf("Expecting the cancelled result to be kept, got %v, %v", result, err)
+	}
+}
+
+func TestAsync_AbandonedWaitersAreRemoved(t *testing.T) {
+	init := newAsync()
+	for i := 0; i < 100; i++ {
+		if _, err := init.apply(func() interface{} { return nil }).getOrTimeout(systemClock{}, time.Microsecond); err == nil {
+			t.Fatal("Expecting a timeout")
+		}
+	}
+	if count := init.subscriberCount(); count != 0 {
+		t.Errorf("Expecting the timed out waiters to be removed, got %d", count)
+	}
+}
+
+func TestClient_NoGoroutineLeaks(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	fetcher := newFakeConfigProvider()
+	fetcher.SetResponseWithDelay(fetchResponse{status: Fetched, body: `{ "key": { "v": true, "t": 0 } }`}, 50*time.Millisecond)
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:                    AutoPoll(time.Hour),
+		MaxWaitTimeForSyncCalls: time.Millisecond,
+		EvaluationStats:         EvaluationStatsConfig{Enabled: true, ReportInterval: time.Hour, OnReport: func(EvaluationStatsReport) {}},
+	}, fetcher)
+	for i := 0; i < 10; i++ {
+		client.GetValue("key", false)
+	}
+	client.Close()
+}
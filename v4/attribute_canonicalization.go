@@ -0,0 +1,146 @@
+package configcat
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Comparator is the comparator of a targeting rule, with the same value as in the config JSON.
+type Comparator int
+
+const (
+	// ComparatorIsOneOf matches when the attribute is one of the comma separated comparison values.
+	ComparatorIsOneOf Comparator = 0
+	// ComparatorIsNotOneOf matches when the attribute is none of the comma separated comparison values.
+	ComparatorIsNotOneOf Comparator = 1
+	// ComparatorContains matches when the attribute contains the comparison value.
+	ComparatorContains Comparator = 2
+	// ComparatorDoesNotContain matches when the attribute doesn't contain the comparison value.
+	ComparatorDoesNotContain Comparator = 3
+	// ComparatorEquals matches when the attribute equals one of the comparison values.
+	ComparatorEquals Comparator = 28
+	// ComparatorNotEquals matches when the attribute equals none of the comparison values.
+	ComparatorNotEquals Comparator = 29
+	// ComparatorStartsWithAnyOf matches when the attribute starts with one of the comparison values.
+	ComparatorStartsWithAnyOf Comparator = 30
+	// ComparatorNotStartsWithAnyOf matches when the attribute starts with none of the comparison values.
+	ComparatorNotStartsWithAnyOf Comparator = 31
+	// ComparatorEndsWithAnyOf matches when the attribute ends with one of the comparison values.
+	ComparatorEndsWithAnyOf Comparator = 32
+	// ComparatorNotEndsWithAnyOf matches when the attribute ends with none of the comparison values.
+	ComparatorNotEndsWithAnyOf Comparator = 33
+	// ComparatorArrayContainsAnyOf matches when the JSON array attribute contains one of the comparison values.
+	ComparatorArrayContainsAnyOf Comparator = 34
+	// ComparatorArrayNotContainsAnyOf matches when the JSON array attribute contains none of the comparison values.
+	ComparatorArrayNotContainsAnyOf Comparator = 35
+)
+
+// String returns the name of the comparator as displayed on the ConfigCat dashboard, or "CUSTOM (id)" for
+// the comparators registered by RegisterComparator.
+func (comparator Comparator) String() string {
+	if text, ok := comparatorTexts[int(comparator)]; ok {
+		return text
+	}
+	if lookupComparator(float64(comparator)) != nil {
+		return "CUSTOM (" + strconv.Itoa(int(comparator)) + ")"
+	}
+
+	return "unknown"
+}
+
+// UnicodeNormalization is the Unicode normalization form of the canonicalized attributes.
+type UnicodeNormalization int
+
+const (
+	// UnicodeNone leaves the text as it is.
+	UnicodeNone UnicodeNormalization = 0
+	// UnicodeNFC composes the characters, e.g. "e" followed by a combining acute accent becomes "é".
+	UnicodeNFC UnicodeNormalization = 1
+	// UnicodeNFKC composes the characters and replaces the compatibility characters, e.g. the full width
+	// letters, with their canonical equivalents.
+	UnicodeNFKC UnicodeNormalization = 2
+)
+
+// AttributeCanonicalization describes how the user attributes are canonicalized before they are compared by the
+// targeting rules, so the targeting isn't broken by the inconsistent casing or the stray white space of e.g. the
+// emails and the countries. The comparison values of the plain text comparators (the ones with Comparator
+// constants) are canonicalized the same way. The comparison values of the confidential comparators are hashed,
+// so only the attributes are canonicalized for them.
+type AttributeCanonicalization struct {
+	// Removes the leading and trailing white space.
+	TrimSpace bool
+	// The Unicode normalization form of the text, UnicodeNone by default.
+	Unicode UnicodeNormalization
+	// The plain text comparators matching case-insensitively, e.g. ComparatorIsOneOf and ComparatorContains.
+	CaseInsensitive []Comparator
+}
+
+// canonicalizer applies an AttributeCanonicalization, it's comparable so the canonicalized rules can be cached
+// by it.
+type canonicalizer struct {
+	trim    bool
+	unicode UnicodeNormalization
+	// the bits of the case-insensitive comparators
+	folded uint64
+}
+
+func newCanonicalizer(config AttributeCanonicalization) canonicalizer {
+	canonicalizer := canonicalizer{trim: config.TrimSpace, unicode: config.Unicode}
+	for _, comparator := range config.CaseInsensitive {
+		if isPlainTextComparator(float64(comparator)) {
+			canonicalizer.folded |= 1 << uint(comparator)
+		}
+	}
+	return canonicalizer
+}
+
+// enabled returns true if the canonicalizer changes anything.
+func (canonicalizer canonicalizer) enabled() bool {
+	return canonicalizer.trim || canonicalizer.unicode != UnicodeNone || canonicalizer.folded != 0
+}
+
+// isPlainTextComparator returns true if the comparison value of the comparator is compared as plain text.
+func isPlainTextComparator(comparator float64) bool {
+	return (comparator >= 0 && comparator <= 3) || (comparator >= 28 && comparator <= 35)
+}
+
+// value canonicalizes a user attribute compared with the comparator.
+func (canonicalizer canonicalizer) value(value string, comparator float64) string {
+	if canonicalizer.trim {
+		value = strings.TrimSpace(value)
+	}
+	switch canonicalizer.unicode {
+	case UnicodeNFC:
+		value = norm.NFC.String(value)
+	case UnicodeNFKC:
+		value = norm.NFKC.String(value)
+	}
+	if comparator >= 0 && comparator < 64 && canonicalizer.folded&(1<<uint(comparator)) != 0 {
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
+// rule returns the rule with its comparison value canonicalized. The canonicalized rule is cached in the rule
+// by the canonicalizer, as the compiled rules are shared by the clients of the process, which may canonicalize
+// them differently.
+func (canonicalizer canonicalizer) rule(rule *compiledRule) *compiledRule {
+	if !rule.hasComparator || !isPlainTextComparator(rule.comparator) {
+		return rule
+	}
+	if cached, ok := rule.canonical.Load(canonicalizer); ok {
+		return cached.(*compiledRule)
+	}
+
+	node := map[string]interface{}{
+		"a": rule.attribute,
+		"t": rule.comparator,
+		"c": canonicalizer.value(rule.comparisonValue, rule.comparator),
+		"v": rule.value,
+		"i": rule.variationId,
+	}
+	canonical, _ := rule.canonical.LoadOrStore(canonicalizer, compileRule(node))
+	return canonical.(*compiledRule)
+}
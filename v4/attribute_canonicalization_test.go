@@ -0,0 +1,76 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestClient_AttributeCanonicalization(t *testing.T) {
+	body := `{
+		"country": { "v": "default", "t": 1, "r": [ { "o": 0, "a": "Country", "t": 0, "c": "Hungary, Germany", "v": "matched" } ] },
+		"domain": { "v": "default", "t": 1, "r": [ { "o": 0, "a": "Email", "t": 32, "c": "@Example.com", "v": "matched" } ] },
+		"cafe": { "v": "default", "t": 1, "r": [ { "o": 0, "a": "Shop", "t": 28, "c": "café", "v": "matched" } ] },
+		"age": { "v": "default", "t": 1, "r": [ { "o": 0, "a": "Age", "t": 14, "c": "18", "v": "matched" } ] }
+	}`
+	users := map[string]*User{
+		"country": NewUserWithAdditionalAttributes("id", "", " HUNGARY ", nil),
+		"domain":  NewUserWithAdditionalAttributes("id", "john@EXAMPLE.COM", "", nil),
+		"cafe":    NewUserWithAdditionalAttributes("id", "", "", map[string]string{"Shop": "cafe\u0301"}),
+		"age":     NewUserWithAdditionalAttributes("id", "", "", map[string]string{"Age": " 21 "}),
+	}
+
+	tests := []struct {
+		name            string
+		canonicalize    AttributeCanonicalization
+		expectedMatches map[string]bool
+	}{
+		{"none", AttributeCanonicalization{},
+			map[string]bool{"country": false, "domain": false, "cafe": false, "age": false}},
+		{"trim only", AttributeCanonicalization{TrimSpace: true},
+			map[string]bool{"country": false, "domain": false, "cafe": false, "age": true}},
+		{"case-insensitive is one of", AttributeCanonicalization{TrimSpace: true, CaseInsensitive: []Comparator{ComparatorIsOneOf}},
+			map[string]bool{"country": true, "domain": false, "cafe": false, "age": true}},
+		{"all", AttributeCanonicalization{TrimSpace: true, Unicode: UnicodeNFC,
+			CaseInsensitive: []Comparator{ComparatorIsOneOf, ComparatorEndsWithAnyOf}},
+			map[string]bool{"country": true, "domain": true, "cafe": true, "age": true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetcher := newFakeConfigProvider()
+			fetcher.SetResponse(fetchResponse{status: Fetched, body: body})
+			client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), AttributeCanonicalization: test.canonicalize}, fetcher)
+			defer client.Close()
+			client.Refresh()
+
+			for key, matched := range test.expectedMatches {
+				value := client.GetValueForUser(key, "", users[key])
+				if (value == "matched") != matched {
+					t.Errorf("%s: expecting match %v, got %v", key, matched, value)
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalizer_SharedRules(t *testing.T) {
+	rule := compileRule(map[string]interface{}{"a": "Country", "t": float64(0), "c": "Hungary", "v": true})
+	folding := newCanonicalizer(AttributeCanonicalization{CaseInsensitive: []Comparator{ComparatorIsOneOf}})
+	trimming := newCanonicalizer(AttributeCanonicalization{TrimSpace: true})
+
+	if folded := folding.rule(rule); folded.sortedItems[0] != "hungary" || folding.rule(rule) != folded {
+		t.Errorf("Expecting the cached folded rule, got %v", folded.sortedItems)
+	}
+	if trimmed := trimming.rule(rule); trimmed.sortedItems[0] != "Hungary" {
+		t.Errorf("Expecting the rule canonicalized by the other canonicalizer, got %v", trimmed.sortedItems)
+	}
+	if rule.sortedItems[0] != "Hungary" {
+		t.Errorf("Expecting the shared rule to be unchanged, got %v", rule.sortedItems)
+	}
+	folded, trimmed := folding.rule(rule), trimming.rule(rule)
+	if folding.rule(rule) != folded || trimming.rule(rule) != trimmed {
+		t.Error("Expecting the rules of both canonicalizers to stay cached")
+	}
+	if ComparatorEndsWithAnyOf.String() != "ENDS WITH ANY OF" || Comparator(99).String() != "unknown" {
+		t.Error("Unexpected comparator names")
+	}
+}
@@ -0,0 +1,20 @@
+package configcat
+
+// AttributeResolver is called during the evaluation when a targeting rule references an attribute
+// which is missing from the User, so attributes can be looked up lazily (e.g. the plan of the user from a cache)
+// instead of populating every attribute up front. It returns false if the attribute is unknown.
+// It's called for every rule referencing the missing attribute, so it should be fast and cache its lookups.
+type AttributeResolver func(user *User, attribute string) (string, bool)
+
+// attribute returns the user attribute compared by the rule, resolved with the
+// attribute resolver when the user doesn't have it.
+func (evaluator *rolloutEvaluator) attribute(user *User, rule *compiledRule) string {
+	value := user.attributes[rule.lowerAttribute]
+	if len(value) == 0 && len(rule.attribute) > 0 && evaluator.attributeResolver != nil {
+		if resolved, ok := evaluator.attributeResolver(user, rule.attribute); ok {
+			return resolved
+		}
+	}
+
+	return value
+}
@@ -1,6 +1,7 @@
 package configcat
 
 import (
+	"math/rand"
 	"sync/atomic"
 	"time"
 )
@@ -13,7 +14,16 @@ type autoPollingPolicy struct {
 	initialized      uint32
 	stop             chan struct{}
 	closed           uint32
+	paused           uint32
+	resumed          chan struct{}
 	configChanged    func()
+	clock            Clock
+	retryPolicy      RetryPolicy
+	startJitter      time.Duration
+	phaseOffset      bool
+	random           *rand.Rand
+	// 1 while the polling goroutine runs
+	polling int32
 }
 
 // autoPollConfig describes the configuration for auto polling.
@@ -22,6 +32,12 @@ type autoPollConfig struct {
 	autoPollInterval time.Duration
 	// The configuration change listener.
 	changeListener func()
+	// The retries of a failed poll within a polling interval, nil if there are no retries.
+	retryPolicy RetryPolicy
+	// The maximum random delay of the first poll.
+	startJitter time.Duration
+	// Whether the polling ticker is shifted by a random offset within the polling interval.
+	phaseOffset bool
 }
 
 func (config autoPollConfig) getModeIdentifier() string {
@@ -49,6 +65,7 @@ func newAutoPollingPolicy(
 	configFetcher configProvider,
 	store *configStore,
 	logger Logger,
+	clock Clock,
 	autoPollConfig autoPollConfig) *autoPollingPolicy {
 	policy := &autoPollingPolicy{
 		configRefresher:  configRefresher{configFetcher: configFetcher, store: store, logger: logger},
@@ -56,7 +73,13 @@ func newAutoPollingPolicy(
 		init:             newAsync(),
 		initialized:      no,
 		stop:             make(chan struct{}),
+		resumed:          make(chan struct{}, 1),
 		configChanged:    autoPollConfig.changeListener,
+		clock:            clock,
+		retryPolicy:      autoPollConfig.retryPolicy,
+		startJitter:      autoPollConfig.startJitter,
+		phaseOffset:      autoPollConfig.phaseOffset,
+		random:           rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 	policy.startPolling()
 	return policy
@@ -73,6 +96,15 @@ func (policy *autoPollingPolicy) getConfigurationAsync() *asyncResult {
 	})
 }
 
+// cachedConfiguration reads the current configuration value once the first poll completed.
+func (policy *autoPollingPolicy) cachedConfiguration() (string, bool) {
+	if !policy.init.isCompleted() {
+		return "", false
+	}
+
+	return policy.store.get(), true
+}
+
 // close shuts down the policy.
 func (policy *autoPollingPolicy) close() {
 	if atomic.CompareAndSwapUint32(&policy.closed, no, yes) {
@@ -80,43 +112,151 @@ func (policy *autoPollingPolicy) close() {
 	}
 }
 
+// pause suspends the polling until resume is called.
+func (policy *autoPollingPolicy) pause() {
+	if atomic.CompareAndSwapUint32(&policy.paused, no, yes) {
+		policy.logger.Debugln("Auto polling paused.")
+	}
+}
+
+// resume continues the suspended polling with an immediate poll.
+func (policy *autoPollingPolicy) resume() {
+	if atomic.CompareAndSwapUint32(&policy.paused, yes, no) {
+		policy.logger.Debugln("Auto polling resumed.")
+		select {
+		case policy.resumed <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (policy *autoPollingPolicy) startPolling() {
 	policy.logger.Debugf("Auto polling started with %+v interval.", policy.autoPollInterval)
 
-	ticker := time.NewTicker(policy.autoPollInterval)
+	startDelay := policy.randomDuration(policy.startJitter)
+	var phase time.Duration
+	if policy.phaseOffset {
+		phase = policy.randomDuration(policy.autoPollInterval)
+	}
+	var ticker Ticker
+	if phase == 0 {
+		ticker = policy.clock.NewTicker(policy.autoPollInterval)
+	}
 
+	atomic.StoreInt32(&policy.polling, 1)
 	go func() {
-		defer ticker.Stop()
+		defer atomic.StoreInt32(&policy.polling, 0)
+		defer func() {
+			if ticker != nil {
+				ticker.Stop()
+			}
+		}()
+		if !policy.sleep(startDelay) {
+			return
+		}
 		policy.poll()
+		if ticker == nil {
+			policy.logger.Debugf("Auto polling is shifted by %v.", phase)
+			if !policy.sleep(phase) {
+				return
+			}
+			ticker = policy.clock.NewTicker(policy.autoPollInterval)
+		}
 		for {
 			select {
 			case <-policy.stop:
 				policy.logger.Debugf("Auto polling stopped.")
 				return
-			case <-ticker.C:
+			case <-ticker.C():
+				if atomic.LoadUint32(&policy.paused) == no {
+					policy.poll()
+				}
+			case <-policy.resumed:
 				policy.poll()
 			}
 		}
 	}()
 }
 
+// randomDuration returns a random duration in [0, max), the random source is only used by the polling goroutine.
+func (policy *autoPollingPolicy) randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(policy.random.Int63n(int64(max)))
+}
+
+// sleep waits for the given duration and reports false when the policy was closed in the meantime.
+func (policy *autoPollingPolicy) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := policy.clock.NewTimer(d)
+	select {
+	case <-policy.stop:
+		timer.Stop()
+		policy.logger.Debugf("Auto polling stopped.")
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
 func (policy *autoPollingPolicy) poll() {
-	policy.logger.Debugln("Polling the latest configuration.")
-	response := policy.configFetcher.getConfigurationAsync().get().(fetchResponse)
+	defer func() {
+		if r := recover(); r != nil {
+			err := newPanicError(r)
+			logEvent(policy.logger, LogLevelError, LogEventFetch, logFields{}, "Polling the latest configuration failed. %s.\n%s", err.Error(), err.Stack)
+		}
+
+		if atomic.CompareAndSwapUint32(&policy.initialized, no, yes) {
+			policy.init.complete()
+		}
+	}()
+
+	logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Polling the latest configuration.")
+	result, err := policy.fetchWithRetries()
+	if err != nil {
+		logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Polling the latest configuration failed: %s.", err.Error())
+		return
+	}
+
+	response := asFetchResponse(result)
 	cached := policy.store.get()
 	if response.isFetched() && cached != response.body {
-		policy.store.set(response.body)
+		policy.store.setFetched(response)
 		if policy.configChanged != nil {
 			policy.configChanged()
 		}
 	}
+}
 
-	if atomic.CompareAndSwapUint32(&policy.initialized, no, yes) {
-		policy.init.complete()
+// fetchWithRetries fetches the configuration and retries the failures while the retry policy allows
+// and the next retry fits into the polling interval.
+func (policy *autoPollingPolicy) fetchWithRetries() (interface{}, error) {
+	start := policy.clock.Now()
+	for attempt := 1; ; attempt++ {
+		result, err := policy.configFetcher.getConfigurationAsync().getWithError()
+		if err == nil || policy.retryPolicy == nil {
+			return result, err
+		}
+		delay, retry := policy.retryPolicy.NextDelay(attempt, err)
+		if !retry || policy.clock.Now().Sub(start)+delay >= policy.autoPollInterval {
+			return result, err
+		}
+
+		logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Polling the latest configuration failed: %s. Retrying in %v.", err.Error(), delay)
+		timer := policy.clock.NewTimer(delay)
+		select {
+		case <-policy.stop:
+			timer.Stop()
+			return result, err
+		case <-timer.C():
+		}
 	}
 }
 
 func (policy *autoPollingPolicy) readCache() *asyncResult {
-	policy.logger.Debugln("Reading from cache.")
+	logEvent(policy.logger, LogLevelDebug, LogEventCache, logFields{}, "Reading from cache.")
 	return asCompletedAsyncResult(policy.store.get())
 }
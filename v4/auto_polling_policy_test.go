@@ -1,6 +1,7 @@
 package configcat
 
 import (
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -14,7 +15,8 @@ func TestAutoPollingPolicy_GetConfigurationAsync(t *testing.T) {
 		fetcher,
 		newConfigStore(logger, newInMemoryConfigCache()),
 		logger,
-		autoPollConfig{time.Second * 2, nil},
+		systemClock{},
+		autoPollConfig{autoPollInterval: time.Second * 2},
 	)
 	defer policy.close()
 
@@ -48,7 +50,8 @@ func TestAutoPollingPolicy_GetConfigurationAsync_Fail(t *testing.T) {
 		fetcher,
 		newConfigStore(logger, newInMemoryConfigCache()),
 		logger,
-		autoPollConfig{time.Second * 2, nil},
+		systemClock{},
+		autoPollConfig{autoPollInterval: time.Second * 2},
 	)
 	defer policy.close()
 
@@ -69,6 +72,7 @@ func TestAutoPollingPolicy_GetConfigurationAsync_WithListener(t *testing.T) {
 		fetcher,
 		newConfigStore(logger, newInMemoryConfigCache()),
 		logger,
+		systemClock{},
 		AutoPollWithChangeListener(
 			time.Second*2,
 			func() { c <- true },
@@ -81,3 +85,134 @@ func TestAutoPollingPolicy_GetConfigurationAsync_WithListener(t *testing.T) {
 		t.Error("Expecting test as result")
 	}
 }
+
+func TestAutoPollingPolicy_PauseResume(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "test"})
+	logger := DefaultLogger(LogLevelWarn)
+	policy := newAutoPollingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		systemClock{},
+		autoPollConfig{autoPollInterval: time.Millisecond * 100},
+	)
+	defer policy.close()
+
+	config := policy.getConfigurationAsync().get().(string)
+	if config != "test" {
+		t.Error("Expecting test as result")
+	}
+
+	policy.pause()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "test2"})
+	time.Sleep(time.Millisecond * 300)
+	config = policy.getConfigurationAsync().get().(string)
+
+	if config != "test" {
+		t.Error("Expecting test as result while paused")
+	}
+
+	policy.resume()
+	time.Sleep(time.Millisecond * 50)
+	config = policy.getConfigurationAsync().get().(string)
+
+	if config != "test2" {
+		t.Error("Expecting test2 as result after resume")
+	}
+}
+
+// flakyConfigProvider fails with the given error for the given number of fetches, then succeeds.
+type flakyConfigProvider struct {
+	failures int32
+	err      error
+	attempts int32
+}
+
+func (provider *flakyConfigProvider) getConfigurationAsync() *asyncResult {
+	result := newAsyncResult()
+	if atomic.AddInt32(&provider.attempts, 1) <= provider.failures {
+		result.completeWithError(provider.err)
+	} else {
+		result.complete(fetchResponse{status: Fetched, body: "test"})
+	}
+	return result
+}
+
+func TestAutoPollingPolicy_Retries(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		retries  int
+		attempts int32
+		expected string
+	}{
+		{"transient", &StatusError{StatusCode: 503}, 3, 3, "test"},
+		{"budget exhausted", &StatusError{StatusCode: 502}, 1, 2, ""},
+		{"not transient", &StatusError{StatusCode: 403}, 3, 1, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetcher := &flakyConfigProvider{failures: 2, err: test.err}
+			logger := DefaultLogger(LogLevelWarn)
+			policy := newAutoPollingPolicy(
+				fetcher,
+				newConfigStore(logger, newInMemoryConfigCache()),
+				logger,
+				systemClock{},
+				autoPollConfig{autoPollInterval: time.Second * 10, retryPolicy: ExponentialRetry(time.Millisecond*5, 0, test.retries)},
+			)
+			defer policy.close()
+
+			config := policy.getConfigurationAsync().get().(string)
+			if config != test.expected || atomic.LoadInt32(&fetcher.attempts) != test.attempts {
+				t.Errorf("Expecting %q after %d attempts, got %q after %d", test.expected, test.attempts, config, fetcher.attempts)
+			}
+		})
+	}
+}
+
+func TestAutoPollingPolicy_RandomizedStart(t *testing.T) {
+	fetcher := &flakyConfigProvider{}
+	logger := DefaultLogger(LogLevelWarn)
+	policy := newAutoPollingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		systemClock{},
+		autoPollConfig{autoPollInterval: time.Millisecond * 100, startJitter: time.Millisecond * 20, phaseOffset: true},
+	)
+	defer policy.close()
+
+	config := policy.getConfigurationAsync().get().(string)
+	if config != "test" {
+		t.Errorf("Expecting the polled config, got %q", config)
+	}
+	for i := 0; i < 100; i++ {
+		if d := policy.randomDuration(time.Second); d < 0 || d >= time.Second {
+			t.Fatalf("Random duration %v out of range", d)
+		}
+	}
+	if policy.randomDuration(0) != 0 {
+		t.Error("Expecting no delay without a maximum")
+	}
+}
+
+func TestAutoPollingPolicy_CloseDuringStartDelay(t *testing.T) {
+	fetcher := &flakyConfigProvider{}
+	logger := DefaultLogger(LogLevelWarn)
+	policy := newAutoPollingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		systemClock{},
+		autoPollConfig{autoPollInterval: time.Second, startJitter: time.Hour},
+	)
+	policy.close()
+	time.Sleep(time.Millisecond * 20)
+
+	if attempts := atomic.LoadInt32(&fetcher.attempts); attempts != 0 {
+		t.Errorf("Expecting no poll after closing during the start delay, got %d", attempts)
+	}
+}
@@ -0,0 +1,118 @@
+package benchmarks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+var benchmarkModes = []struct {
+	name string
+	mode func() configcat.RefreshMode
+}{
+	{"auto", func() configcat.RefreshMode { return configcat.AutoPoll(time.Minute) }},
+	{"lazy", func() configcat.RefreshMode { return configcat.LazyLoad(time.Minute, false) }},
+	{"manual", configcat.ManualPoll},
+}
+
+var benchmarkCaches = []struct {
+	name  string
+	cache func(dir string) configcat.ConfigCache
+}{
+	{"memory", func(dir string) configcat.ConfigCache { return nil }},
+	{"file", func(dir string) configcat.ConfigCache {
+		return configcat.NewFileCache(filepath.Join(dir, "config.json"))
+	}},
+	{"async", func(dir string) configcat.ConfigCache {
+		return configcat.NewAsyncCache(configcat.NewFileCache(filepath.Join(dir, "config.json")), configcat.AsyncCacheConfig{})
+	}},
+}
+
+var benchmarkSizes = []int{10, 100, 1000}
+
+func newBenchmarkClient(b *testing.B, cdn *mockCDN, mode configcat.RefreshMode, cache configcat.ConfigCache) *configcat.Client {
+	client := configcat.NewCustomClient("benchmark", configcat.ClientConfig{
+		BaseUrl: cdn.URL,
+		Mode:    mode,
+		Cache:   cache,
+		Logger:  configcat.DefaultLogger(configcat.LogLevelError),
+	})
+	client.Refresh()
+	if value := client.GetValue("setting0", false); value != true {
+		b.Fatalf("the config is not loaded, got %v", value)
+	}
+
+	return client
+}
+
+// BenchmarkGetValue measures the concurrent evaluation of the settings without and with a user.
+func BenchmarkGetValue(b *testing.B) {
+	dir, err := ioutil.TempDir("", "benchmarks")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	user := configcat.NewUserWithAdditionalAttributes("id", "a@example.com", "HU", map[string]string{"Version": "1.3.0"})
+	for _, size := range benchmarkSizes {
+		cdn := newMockCDN(generateConfig(size))
+		for _, mode := range benchmarkModes {
+			for _, cache := range benchmarkCaches {
+				name := mode.name + "/" + cache.name + "/" + strconv.Itoa(size)
+				configCache := cache.cache(dir)
+				client := newBenchmarkClient(b, cdn, mode.mode(), configCache)
+				keys := make([]string, size)
+				for i := range keys {
+					keys[i] = "setting" + strconv.Itoa(i)
+				}
+
+				b.Run(name, func(b *testing.B) {
+					b.ReportAllocs()
+					var counter uint64
+					b.RunParallel(func(pb *testing.PB) {
+						for pb.Next() {
+							i := atomic.AddUint64(&counter, 1)
+							client.GetValue(keys[i%uint64(size)], nil)
+						}
+					})
+				})
+				b.Run(name+"/user", func(b *testing.B) {
+					b.ReportAllocs()
+					var counter uint64
+					b.RunParallel(func(pb *testing.PB) {
+						for pb.Next() {
+							i := atomic.AddUint64(&counter, 1)
+							client.GetValueForUser(keys[i%uint64(size)], nil, user)
+						}
+					})
+				})
+				client.Close()
+				// the client doesn't own the cache, the background goroutine of the async cache is stopped here
+				if closer, ok := configCache.(interface{ Close() }); ok {
+					closer.Close()
+				}
+			}
+		}
+		cdn.Close()
+	}
+}
+
+// BenchmarkRefresh measures the forced refreshes answered with 304 Not Modified by the CDN.
+func BenchmarkRefresh(b *testing.B) {
+	cdn := newMockCDN(generateConfig(100))
+	defer cdn.Close()
+	client := newBenchmarkClient(b, cdn, configcat.ManualPoll(), nil)
+	defer client.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client.Refresh()
+	}
+}
@@ -0,0 +1,11 @@
+// Package benchmarks measures the throughput of the ConfigCat client, so performance regressions
+// in the store and the evaluator are caught. The benchmarks evaluate flags concurrently across the
+// refresh modes, cache implementations and config sizes, the configuration is served by a mock CDN:
+//
+//	go test -bench . -benchmem ./benchmarks
+//
+// The load test hammers a client polling the mock CDN for a given duration and reports the
+// evaluation latencies and the CDN requests:
+//
+//	go test ./benchmarks -run TestLoad -v -loadtest.duration 30s
+package benchmarks
@@ -0,0 +1,83 @@
+package benchmarks
+
+import (
+	"flag"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+var (
+	loadTestDuration = flag.Duration("loadtest.duration", 0, "the duration of the load test, it's skipped when it's 0")
+	loadTestWorkers  = flag.Int("loadtest.workers", runtime.GOMAXPROCS(0)*4, "the number of the concurrent evaluating goroutines")
+	loadTestSettings = flag.Int("loadtest.settings", 500, "the number of the settings of the served config")
+	loadTestPoll     = flag.Duration("loadtest.poll", time.Second, "the auto polling interval of the client")
+)
+
+// TestLoad evaluates the settings from many goroutines while the client polls the mock CDN,
+// and reports the throughput, the latency percentiles and the number of the CDN requests.
+func TestLoad(t *testing.T) {
+	if *loadTestDuration <= 0 {
+		t.Skip("the load test is enabled with -loadtest.duration")
+	}
+
+	cdn := newMockCDN(generateConfig(*loadTestSettings))
+	defer cdn.Close()
+	client := configcat.NewCustomClient("loadtest", configcat.ClientConfig{
+		BaseUrl: cdn.URL,
+		Mode:    configcat.AutoPoll(*loadTestPoll),
+		Logger:  configcat.DefaultLogger(configcat.LogLevelError),
+	})
+	defer client.Close()
+
+	user := configcat.NewUserWithAdditionalAttributes("id", "a@example.com", "HU", map[string]string{"Version": "1.3.0"})
+	var evaluations, defaults int64
+	latencies := make([][]time.Duration, *loadTestWorkers)
+	deadline := time.Now().Add(*loadTestDuration)
+	var wg sync.WaitGroup
+	for w := 0; w < *loadTestWorkers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; time.Now().Before(deadline); i++ {
+				key := "setting" + strconv.Itoa(i%*loadTestSettings)
+				start := time.Now()
+				value := client.GetValueForUser(key, nil, user)
+				// sampled to keep the memory use of long runs bounded
+				if i%100 == 0 {
+					latencies[w] = append(latencies[w], time.Since(start))
+				}
+				atomic.AddInt64(&evaluations, 1)
+				if value == nil {
+					atomic.AddInt64(&defaults, 1)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var all []time.Duration
+	for _, worker := range latencies {
+		all = append(all, worker...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	percentile := func(p float64) time.Duration {
+		if len(all) == 0 {
+			return 0
+		}
+		return all[int(float64(len(all)-1)*p)]
+	}
+
+	t.Logf("%d evaluations (%.0f/s) by %d workers, p50 %v, p99 %v, max %v, %d CDN requests",
+		evaluations, float64(evaluations)/loadTestDuration.Seconds(), *loadTestWorkers,
+		percentile(0.5), percentile(0.99), percentile(1), atomic.LoadInt64(&cdn.requests))
+	if defaults > 0 {
+		t.Errorf("%d evaluations returned the default value", defaults)
+	}
+}
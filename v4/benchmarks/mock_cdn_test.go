@@ -0,0 +1,67 @@
+package benchmarks
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+)
+
+// mockCDN serves a config JSON like the ConfigCat CDN, answering the requests with a matching ETag with 304.
+type mockCDN struct {
+	*httptest.Server
+	body     string
+	eTag     string
+	requests int64
+}
+
+func newMockCDN(body string) *mockCDN {
+	sum := sha1.Sum([]byte(body))
+	cdn := &mockCDN{body: body, eTag: `"` + hex.EncodeToString(sum[:]) + `"`}
+	cdn.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&cdn.requests, 1)
+		if !strings.HasSuffix(r.URL.Path, "/config_v4.json") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("ETag", cdn.eTag)
+		if r.Header.Get("If-None-Match") == cdn.eTag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, _ = w.Write([]byte(cdn.body))
+	}))
+	return cdn
+}
+
+// generateConfig returns a config JSON with the given number of settings, every third setting has targeting
+// rules and every third has percentage options. The settings are named "setting<n>".
+func generateConfig(settings int) string {
+	var builder strings.Builder
+	builder.WriteString("{")
+	for i := 0; i < settings; i++ {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+
+		switch i % 3 {
+		case 0:
+			fmt.Fprintf(&builder, `"setting%d": { "v": true, "i": "a%d" }`, i, i)
+		case 1:
+			fmt.Fprintf(&builder, `"setting%d": { "v": "default", "i": "a%d", "r": [
+				{ "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": "internal", "i": "b%d" },
+				{ "o": 1, "a": "Country", "t": 0, "c": "HU, DE, AT", "v": "local", "i": "c%d" },
+				{ "o": 2, "a": "Version", "t": 8, "c": "1.2.0", "v": "new", "i": "d%d" } ] }`, i, i, i, i, i)
+		case 2:
+			fmt.Fprintf(&builder, `"setting%d": { "v": 1, "i": "a%d", "p": [
+				{ "o": 0, "v": 1, "p": 30, "i": "b%d" }, { "o": 1, "v": 2, "p": 70, "i": "c%d" } ] }`, i, i, i, i)
+		}
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
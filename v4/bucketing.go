@@ -0,0 +1,31 @@
+package configcat
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+)
+
+// PercentageBucket returns the bucket (0-99) the user identified by the given identifier falls into for the
+// setting identified by the given key. It's the same hash the percentage rules are evaluated with, so services
+// can make sticky decisions (e.g. queue routing) consistent with the percentage rollouts of ConfigCat:
+// a user is served the first percentage rule whose cumulative percentage is greater than its bucket.
+func PercentageBucket(key string, identifier string) int {
+	sha := sha1.New()
+	sha.Write([]byte(key + identifier))
+	hash := hex.EncodeToString(sha.Sum(nil))[:7]
+	num, _ := strconv.ParseInt(hash, 16, 64)
+	return int(num % 100)
+}
+
+// Bucketer assigns the users to the percentage buckets (0-99) of a setting, see ClientConfig.Bucketer.
+type Bucketer func(key string, identifier string) int
+
+// bucket returns the percentage bucket of the user for the setting identified by the given key.
+func (evaluator *rolloutEvaluator) bucket(key string, identifier string) int {
+	if evaluator.bucketer != nil {
+		return evaluator.bucketer(key, identifier)
+	}
+
+	return PercentageBucket(key, identifier)
+}
@@ -0,0 +1,29 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPercentageBucket_ConsistentWithPercentageRules(t *testing.T) {
+	parser := newParser(DefaultLogger(LogLevelWarn))
+	json := `{ "key": { "v": "default", "p": [ { "p": 30, "v": "a" }, { "p": 70, "v": "b" } ] } }`
+
+	for i := 0; i < 100; i++ {
+		identifier := fmt.Sprintf("user%d", i)
+		bucket := PercentageBucket("key", identifier)
+		if bucket < 0 || bucket > 99 {
+			t.Fatalf("Bucket out of range: %d", bucket)
+		}
+
+		expected := "b"
+		if bucket < 30 {
+			expected = "a"
+		}
+
+		value, err := parser.ParseWithUser(json, "key", NewUser(identifier))
+		if err != nil || value != expected {
+			t.Errorf("Expecting %s for bucket %d, got %v", expected, bucket, value)
+		}
+	}
+}
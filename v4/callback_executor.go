@@ -0,0 +1,195 @@
+package configcat
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// callbackQueueSize is the number of user callbacks which can wait for execution in the default pool.
+const callbackQueueSize = 128
+
+// callbackWorkers is the number of goroutines of the default pool running the user callbacks. A single goroutine
+// runs the callbacks one at a time in their order, a larger pool can be configured with ClientConfig.Executor.
+const callbackWorkers = 1
+
+// Executor runs the user callbacks of the Client: the change listeners and the completions of the async
+// calls (e.g. GetValueAsync). The panics of the callbacks are recovered before they reach the Executor.
+type Executor interface {
+	// Execute runs the task, typically on another goroutine.
+	Execute(task func())
+}
+
+// ExecutorFunc is an adapter to use ordinary functions as Executor.
+type ExecutorFunc func(task func())
+
+// Execute calls the function.
+func (executor ExecutorFunc) Execute(task func()) {
+	executor(task)
+}
+
+// PoolExecutor is an Executor running the tasks on a bounded pool of goroutines, the default Executor of the Client.
+// The goroutines are started on demand. When all of them are busy the tasks are queued, and when the queue is
+// full as well the tasks are run on new goroutines, so a slow callback can't block the polling and the
+// fetching of the configuration.
+type PoolExecutor struct {
+	queue   chan func()
+	workers int
+	started int
+	closed  bool
+	logger  Logger
+	// the number of the running goroutines of the pool
+	live int32
+	sync.Mutex
+}
+
+// NewPoolExecutor creates a PoolExecutor with at most the given number of goroutines and the given queue size.
+// Close stops its goroutines.
+func NewPoolExecutor(workers int, queueSize int) *PoolExecutor {
+	return newPoolExecutor(workers, queueSize, DefaultLogger(LogLevelWarn))
+}
+
+func newPoolExecutor(workers int, queueSize int, logger Logger) *PoolExecutor {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &PoolExecutor{queue: make(chan func(), queueSize), workers: workers, logger: logger}
+}
+
+// Execute schedules the task on the pool. When the queue is full or the pool is already closed,
+// the task is run on a new goroutine instead.
+func (pool *PoolExecutor) Execute(task func()) {
+	pool.Lock()
+	if !pool.closed {
+		select {
+		case pool.queue <- task:
+			// the worker is started under the same lock, so Close can't leave the queued task without a worker
+			pool.startWorker()
+			pool.Unlock()
+			return
+		default:
+			pool.logger.Warnln("The callback queue is full, executing the callback on a new goroutine.")
+		}
+	}
+	pool.Unlock()
+
+	go task()
+}
+
+// startWorker starts a new goroutine while the pool isn't full, it's called with the lock held.
+func (pool *PoolExecutor) startWorker() {
+	if pool.started >= pool.workers {
+		return
+	}
+
+	pool.started++
+	atomic.AddInt32(&pool.live, 1)
+	go func() {
+		defer atomic.AddInt32(&pool.live, -1)
+		for task := range pool.queue {
+			task()
+		}
+	}()
+}
+
+// Close stops the goroutines of the pool after the already scheduled tasks are run.
+func (pool *PoolExecutor) Close() {
+	pool.Lock()
+	defer pool.Unlock()
+	if !pool.closed {
+		pool.closed = true
+		close(pool.queue)
+	}
+}
+
+// callbackExecutor runs the user callbacks (change listeners, async completions) with an Executor.
+// Panicking callbacks are recovered, logged and reported to the OnError callback.
+type callbackExecutor struct {
+	executor Executor
+	// the pool created by the client, closed together with it
+	pool    *PoolExecutor
+	logger  Logger
+	onError func(err error)
+	// the clock measuring the execution times of the callbacks
+	clock Clock
+	// if it's true then the callbacks run on the calling goroutine, there is no executor
+	inline bool
+	// the number of the executed callbacks, and their total and maximum execution time in nanoseconds
+	executed     uint64
+	totalLatency int64
+	maxLatency   int64
+}
+
+// newCallbackExecutor initializes a new callbackExecutor with the default pool.
+func newCallbackExecutor(logger Logger) *callbackExecutor {
+	pool := newPoolExecutor(callbackWorkers, callbackQueueSize, logger)
+	return &callbackExecutor{executor: pool, pool: pool, logger: logger, clock: systemClock{}}
+}
+
+// newCustomCallbackExecutor initializes a callbackExecutor running the callbacks with the given Executor.
+func newCustomCallbackExecutor(executor Executor, logger Logger) *callbackExecutor {
+	return &callbackExecutor{executor: executor, logger: logger, clock: systemClock{}}
+}
+
+// newInlineCallbackExecutor initializes a callbackExecutor running the callbacks on the calling goroutine.
+func newInlineCallbackExecutor(logger Logger) *callbackExecutor {
+	return &callbackExecutor{logger: logger, clock: systemClock{}, inline: true}
+}
+
+// execute schedules the callback on the executor.
+func (executor *callbackExecutor) execute(callback func()) {
+	if executor.inline {
+		executor.run(callback)
+		return
+	}
+
+	executor.executor.Execute(func() {
+		executor.run(callback)
+	})
+}
+
+// wrap returns a function which schedules the callback on the executor, or nil if the callback is nil.
+func (executor *callbackExecutor) wrap(callback func()) func() {
+	if callback == nil {
+		return nil
+	}
+
+	return func() {
+		executor.execute(callback)
+	}
+}
+
+// close stops the default pool after the already scheduled callbacks are executed.
+// The custom executors are left to their owners.
+func (executor *callbackExecutor) close() {
+	if executor.pool != nil {
+		executor.pool.Close()
+	}
+}
+
+func (executor *callbackExecutor) run(callback func()) {
+	start := executor.clock.Now()
+	defer func() {
+		latency := int64(executor.clock.Now().Sub(start))
+		atomic.AddUint64(&executor.executed, 1)
+		atomic.AddInt64(&executor.totalLatency, latency)
+		for max := atomic.LoadInt64(&executor.maxLatency); latency > max; max = atomic.LoadInt64(&executor.maxLatency) {
+			if atomic.CompareAndSwapInt64(&executor.maxLatency, max, latency) {
+				break
+			}
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			err := newPanicError(r)
+			executor.logger.Errorf("Callback panicked: %s.\n%s", err.Error(), err.Stack)
+			if executor.onError != nil {
+				executor.onError(err)
+			}
+		}
+	}()
+
+	callback()
+}
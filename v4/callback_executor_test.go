@@ -0,0 +1,122 @@
+package configcat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallbackExecutor_PanicIsolation(t *testing.T) {
+	executor := newCallbackExecutor(DefaultLogger(LogLevelFatal))
+	defer executor.close()
+	c := make(chan bool, 1)
+
+	executor.execute(func() { panic("fake panicking callback") })
+	executor.execute(func() { c <- true })
+
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Error("Expecting the callback after the panicking one to be executed")
+	}
+}
+
+func TestCallbackExecutor_Order(t *testing.T) {
+	executor := newCallbackExecutor(DefaultLogger(LogLevelFatal))
+	defer executor.close()
+	c := make(chan int, 10)
+
+	for i := 0; i < 10; i++ {
+		i := i
+		executor.execute(func() { c <- i })
+	}
+
+	for i := 0; i < 10; i++ {
+		if executed := <-c; executed != i {
+			t.Fatalf("Expecting the callbacks in their order, got %d instead of %d", executed, i)
+		}
+	}
+}
+
+func TestCallbackExecutor_SlowCallback(t *testing.T) {
+	executor := newCallbackExecutor(DefaultLogger(LogLevelFatal))
+	defer executor.close()
+	block := make(chan struct{})
+	defer close(block)
+
+	start := time.Now()
+	for i := 0; i < callbackQueueSize*2; i++ {
+		executor.execute(func() { <-block })
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("Expecting execute not to block on slow callbacks")
+	}
+}
+
+func TestCallbackExecutor_PanicReportedToOnError(t *testing.T) {
+	panics := make(chan error, 1)
+	var executed []string
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:     ManualPoll(),
+		Logger:   DefaultLogger(LogLevelFatal),
+		Executor: ExecutorFunc(func(task func()) { executed = append(executed, "task"); task() }),
+		OnError: func(err error) {
+			if _, ok := err.(*PanicError); ok {
+				panics <- err
+			}
+		},
+	}, newFakeConfigProvider())
+	defer client.Close()
+
+	client.GetValueAsync("key", false, func(result interface{}) { panic("fake panicking callback") })
+
+	select {
+	case <-panics:
+		if len(executed) != 1 {
+			t.Errorf("Expecting the callback on the custom executor, got %v", executed)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expecting the panic to be reported")
+	}
+}
+
+func TestPoolExecutor_BoundedWorkers(t *testing.T) {
+	pool := NewPoolExecutor(2, 10)
+	defer pool.Close()
+	block := make(chan struct{})
+	done := make(chan struct{}, 5)
+	for i := 0; i < 5; i++ {
+		pool.Execute(func() { <-block; done <- struct{}{} })
+	}
+
+	pool.Lock()
+	started := pool.started
+	pool.Unlock()
+	if started != 2 {
+		t.Errorf("Expecting 2 workers, got %d", started)
+	}
+
+	close(block)
+	for i := 0; i < 5; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expecting the queued tasks to be executed")
+		}
+	}
+}
+
+func TestPoolExecutor_ExecuteDuringClose(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		pool := NewPoolExecutor(1, 10)
+		done := make(chan struct{}, 1)
+		go pool.Execute(func() { done <- struct{}{} })
+		pool.Close()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expecting the task scheduled during Close to be executed")
+		}
+	}
+}
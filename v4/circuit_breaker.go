@@ -0,0 +1,235 @@
+package configcat
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// CircuitState describes the state of the circuit breaker around the config fetches.
+type CircuitState int
+
+const (
+	// CircuitClosed indicates that the config fetches are attempted as usual.
+	CircuitClosed CircuitState = 0
+	// CircuitOpen indicates that the config fetches are skipped and the cached configuration is served
+	// until the cool-down elapses.
+	CircuitOpen CircuitState = 1
+	// CircuitHalfOpen indicates that a single probe fetch is attempted to check whether the fetches recovered.
+	CircuitHalfOpen CircuitState = 2
+)
+
+// String returns the text representation of the circuit state.
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerConfig describes the circuit breaker around the config fetches.
+type CircuitBreakerConfig struct {
+	// The number of consecutive failed fetches opening the circuit. The circuit breaker is disabled when it's 0.
+	FailureThreshold int
+	// The time the circuit stays open before a probe fetch is attempted, 30 seconds by default.
+	CoolDown time.Duration
+}
+
+// CircuitStateCache is implemented by the ConfigCaches which can persist the state of the circuit breaker next to
+// the configuration, e.g. the file cache. The open circuit is restored when the client is created, so the instances
+// restarted during an outage of the CDN wait for the remaining cool-down instead of retrying immediately.
+// The state is an opaque string, an empty string clears it.
+type CircuitStateCache interface {
+	GetCircuitState() (string, error)
+	SetCircuitState(state string) error
+}
+
+// persistedCircuit is the state of an open circuit stored in a CircuitStateCache.
+type persistedCircuit struct {
+	OpenedAt time.Time `json:"openedAt"`
+}
+
+// circuitBreaker is a configProvider skipping the fetches of the wrapped provider while they are failing.
+// The skipped fetches are served from the cache.
+type circuitBreaker struct {
+	configProvider
+	threshold      int
+	coolDown       time.Duration
+	clock          Clock
+	logger         Logger
+	state          CircuitState
+	failures       int
+	openedAt       time.Time
+	onStateChanged func(state CircuitState)
+	// the cache persisting the open circuit, nil if the cache can't persist it
+	stateCache CircuitStateCache
+	sync.Mutex
+}
+
+func newCircuitBreaker(provider configProvider, config CircuitBreakerConfig, clock Clock, logger Logger) *circuitBreaker {
+	if config.CoolDown <= 0 {
+		config.CoolDown = time.Second * 30
+	}
+
+	return &circuitBreaker{
+		configProvider: provider,
+		threshold:      config.FailureThreshold,
+		coolDown:       config.CoolDown,
+		clock:          clock,
+		logger:         logger,
+	}
+}
+
+// getConfigurationAsync fetches through the wrapped provider unless the circuit is open.
+func (breaker *circuitBreaker) getConfigurationAsync() *asyncResult {
+	breaker.Lock()
+	switch breaker.state {
+	case CircuitHalfOpen:
+		// a probe is in progress
+		breaker.Unlock()
+		return asCompletedAsyncResult(fetchResponse{status: ServedFromCache})
+	case CircuitOpen:
+		if breaker.clock.Now().Sub(breaker.openedAt) < breaker.coolDown {
+			breaker.Unlock()
+			return asCompletedAsyncResult(fetchResponse{status: ServedFromCache})
+		}
+		breaker.setState(CircuitHalfOpen)
+		breaker.Unlock()
+		breaker.notify(CircuitHalfOpen)
+	default:
+		breaker.Unlock()
+	}
+
+	result := breaker.configProvider.getConfigurationAsync()
+	result.acceptWithError(func(value interface{}, err error) {
+		breaker.record(err == nil && !asFetchResponse(value).isFailed())
+	})
+	return result
+}
+
+// record updates the state with the outcome of a fetch.
+func (breaker *circuitBreaker) record(succeeded bool) {
+	breaker.Lock()
+	changed := false
+	if succeeded {
+		breaker.failures = 0
+		changed = breaker.setState(CircuitClosed)
+	} else {
+		breaker.failures++
+		if breaker.state == CircuitHalfOpen || breaker.failures >= breaker.threshold {
+			breaker.openedAt = breaker.clock.Now()
+			changed = breaker.setState(CircuitOpen)
+		}
+	}
+	state := breaker.state
+	openedAt := breaker.openedAt
+	breaker.Unlock()
+
+	if changed {
+		breaker.persist(state, openedAt)
+		breaker.notify(state)
+	}
+}
+
+// restore opens the circuit when it was open according to the state cache, e.g. before the process restarted.
+// The circuit stays open for the rest of its cool-down, the state of a circuit whose cool-down has already
+// passed is cleared and the circuit stays closed.
+func (breaker *circuitBreaker) restore(stateCache CircuitStateCache) {
+	breaker.Lock()
+	breaker.stateCache = stateCache
+	breaker.Unlock()
+	value, err := stateCache.GetCircuitState()
+	if err != nil {
+		logEvent(breaker.logger, LogLevelWarn, LogEventCache, logFields{}, "Reading the circuit state from the cache failed, %s", err)
+		return
+	}
+	if len(value) == 0 {
+		return
+	}
+
+	var persisted persistedCircuit
+	if err := json.Unmarshal([]byte(value), &persisted); err != nil {
+		logEvent(breaker.logger, LogLevelWarn, LogEventCache, logFields{}, "The circuit state in the cache is malformed, %s", err)
+		return
+	}
+
+	remaining := breaker.coolDown - breaker.clock.Now().Sub(persisted.OpenedAt)
+	if remaining <= 0 {
+		breaker.persist(CircuitClosed, time.Time{})
+		return
+	}
+
+	breaker.Lock()
+	breaker.openedAt = persisted.OpenedAt
+	breaker.failures = breaker.threshold
+	changed := breaker.setState(CircuitOpen)
+	breaker.Unlock()
+
+	breaker.logger.Warnf("The config fetch circuit was open before the restart, the fetches resume in %s.", remaining)
+	if changed {
+		breaker.notify(CircuitOpen)
+	}
+}
+
+// setStateCache replaces the cache persisting the open circuit when the cache of the client is reconfigured,
+// and writes the current state into it. A cache which can't persist the state turns the persistence off.
+func (breaker *circuitBreaker) setStateCache(cache ConfigCache) {
+	stateCache, _ := cache.(CircuitStateCache)
+	breaker.Lock()
+	breaker.stateCache = stateCache
+	state, openedAt := breaker.state, breaker.openedAt
+	breaker.Unlock()
+
+	breaker.persist(state, openedAt)
+}
+
+// persist stores the state in the state cache, the open circuit with the time it was opened,
+// the closed one as an empty state. The half-open state isn't stored, the circuit is still open until the probe
+// succeeds.
+func (breaker *circuitBreaker) persist(state CircuitState, openedAt time.Time) {
+	breaker.Lock()
+	stateCache := breaker.stateCache
+	breaker.Unlock()
+	if stateCache == nil || state == CircuitHalfOpen {
+		return
+	}
+
+	value := ""
+	if state == CircuitOpen {
+		encoded, _ := json.Marshal(persistedCircuit{OpenedAt: openedAt})
+		value = string(encoded)
+	}
+	if err := stateCache.SetCircuitState(value); err != nil {
+		logEvent(breaker.logger, LogLevelWarn, LogEventCache, logFields{}, "Saving the circuit state into the cache failed, %s", err)
+	}
+}
+
+// setState changes the state and reports whether it was changed, it must be called with the lock held.
+func (breaker *circuitBreaker) setState(state CircuitState) bool {
+	if breaker.state == state {
+		return false
+	}
+
+	breaker.logger.Infof("Config fetch circuit %s.", state)
+	breaker.state = state
+	return true
+}
+
+// notify calls the state change listener, it must be called without the lock held.
+func (breaker *circuitBreaker) notify(state CircuitState) {
+	if breaker.onStateChanged != nil {
+		breaker.onStateChanged(state)
+	}
+}
+
+func (breaker *circuitBreaker) currentState() CircuitState {
+	breaker.Lock()
+	defer breaker.Unlock()
+	return breaker.state
+}
@@ -0,0 +1,144 @@
+package configcat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var states []CircuitState
+	var mutex sync.Mutex
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:           ManualPoll(),
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 2, CoolDown: time.Millisecond * 50},
+		Hooks: Hooks{OnCircuitStateChanged: func(state CircuitState) {
+			mutex.Lock()
+			states = append(states, state)
+			mutex.Unlock()
+		}},
+	}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "key": { "v": "value" } }`})
+	client.Refresh()
+	fetcher.SetError(errors.New("network error"))
+	client.Refresh()
+	if state := client.Status().CircuitState; state != CircuitClosed {
+		t.Errorf("Expecting closed circuit after one failure, got %v", state)
+	}
+
+	client.Refresh()
+	if state := client.Status().CircuitState; state != CircuitOpen {
+		t.Errorf("Expecting open circuit, got %v", state)
+	}
+
+	fetchCount := client.Status().FetchErrorCount
+	client.Refresh()
+	if status := client.Status(); status.FetchErrorCount != fetchCount || client.GetValue("key", "") != "value" {
+		t.Errorf("Expecting a skipped fetch serving the cache, got %+v", status)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	fetcher.SetResponse(fetchResponse{status: NotModified})
+	client.Refresh()
+	if state := client.Status().CircuitState; state != CircuitClosed {
+		t.Errorf("Expecting closed circuit after a successful probe, got %v", state)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(states) != len(expected) {
+		t.Fatalf("Expecting %v, got %v", expected, states)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Errorf("Expecting %v, got %v", expected, states)
+		}
+	}
+}
+
+func TestClient_CircuitBreaker_PersistedAcrossRestarts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	clock := &manualClock{now: time.Unix(1000, 0)}
+	breakerConfig := CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute}
+	fetcher := newFakeConfigProvider()
+	fetcher.SetError(errors.New("network error"))
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: NewFileCache(path), Clock: clock, CircuitBreaker: breakerConfig}, fetcher)
+	client.Refresh()
+	client.Close()
+	if _, err := os.Stat(path + ".circuit"); err != nil {
+		t.Fatalf("Expecting the open circuit to be persisted, got %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Second * 30)
+	provider := &countingConfigProvider{body: fmt.Sprintf(jsonFormat, "key", "true")}
+	restarted := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: NewFileCache(path), Clock: clock,
+		CircuitBreaker: breakerConfig, ConfigProvider: provider})
+	defer restarted.Close()
+	if state := restarted.Status().CircuitState; state != CircuitOpen {
+		t.Errorf("Expecting the circuit to be restored open, got %v", state)
+	}
+	restarted.Refresh()
+	if count := atomic.LoadInt32(&provider.count); count != 0 {
+		t.Errorf("Expecting no fetch during the remaining cool-down, got %d", count)
+	}
+
+	clock.now = clock.now.Add(time.Second * 31)
+	restarted.Refresh()
+	if count := atomic.LoadInt32(&provider.count); count != 1 || restarted.Status().CircuitState != CircuitClosed {
+		t.Errorf("Expecting a successful probe after the cool-down, got %d fetches", count)
+	}
+	if _, err := os.Stat(path + ".circuit"); !os.IsNotExist(err) {
+		t.Errorf("Expecting the closed circuit to clear the persisted state, got %v", err)
+	}
+}
+
+func TestClient_CircuitBreaker_ExpiredPersistedState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	clock := &manualClock{now: time.Unix(1000, 0)}
+	cache := NewFileCache(path).(CircuitStateCache)
+	encoded, _ := json.Marshal(persistedCircuit{OpenedAt: clock.now.Add(-time.Minute * 2)})
+	if err := cache.SetCircuitState(string(encoded)); err != nil {
+		t.Fatal(err)
+	}
+
+	var states []CircuitState
+	provider := &countingConfigProvider{body: fmt.Sprintf(jsonFormat, "key", "true")}
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: NewFileCache(path), Clock: clock,
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute}, ConfigProvider: provider,
+		Executor: inlineExecutor, Hooks: Hooks{OnCircuitStateChanged: func(state CircuitState) { states = append(states, state) }}})
+	defer client.Close()
+
+	if state := client.Status().CircuitState; state != CircuitClosed || len(states) != 0 {
+		t.Errorf("Expecting the expired circuit to stay closed without notification, got %v, %v", state, states)
+	}
+	if _, err := os.Stat(path + ".circuit"); !os.IsNotExist(err) {
+		t.Errorf("Expecting the expired state to be cleared, got %v", err)
+	}
+	client.Refresh()
+	if count := atomic.LoadInt32(&provider.count); count != 1 {
+		t.Errorf("Expecting the fetch to run, got %d fetches", count)
+	}
+}
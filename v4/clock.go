@@ -0,0 +1,64 @@
+package configcat
+
+import (
+	"time"
+)
+
+// Clock is the source of the time used by the refresh policies, the timeouts of the synchronous calls,
+// the refresh rate limit, the fetch status and the delivery of the impressions. Tests can replace it with
+// a fake clock to advance the time without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a Timer which fires once after the given duration.
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a Ticker which fires repeatedly with the given period.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event created by a Clock, see time.Timer.
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer already fired or was stopped.
+	Stop() bool
+}
+
+// Ticker is a repeated event created by a Clock, see time.Ticker.
+type Ticker interface {
+	// C returns the channel the ticks are sent on.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (timer systemTimer) C() <-chan time.Time {
+	return timer.Timer.C
+}
+
+func (ticker systemTicker) C() <-chan time.Time {
+	return ticker.Ticker.C
+}
@@ -0,0 +1,256 @@
+// Command configcat fetches ConfigCat configurations, lists their keys, evaluates flags for a user
+// compares two configurations and validates a configuration. It's built on the public API of the SDK.
+//
+// Usage:
+//
+//	configcat keys -config SOURCE
+//	configcat eval -config SOURCE [-user JSON] KEY
+//	configcat dump -config SOURCE [-user JSON]
+//	configcat diff -config SOURCE -against SOURCE [-user JSON]
+//	configcat lint -config SOURCE
+//
+// SOURCE is either an SDK key or a path of a config JSON file prefixed with "file:".
+// The user is given as JSON, e.g. {"identifier":"id","email":"a@b.com","country":"HU","custom":{"plan":"pro"}}.
+// The diff command exits with status 1 when the configurations differ,
+// the lint command exits with status 1 when it found problems in the configuration.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+const filePrefix = "file:"
+
+type userJson struct {
+	Identifier string            `json:"identifier"`
+	Email      string            `json:"email"`
+	Country    string            `json:"country"`
+	Custom     map[string]string `json:"custom"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: configcat keys|eval|dump|diff|lint [flags]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("configcat "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	source := flags.String("config", "", "SDK key or config JSON file path prefixed with \"file:\"")
+	against := flags.String("against", "", "the source to compare with (diff only)")
+	userArg := flags.String("user", "", "the user to evaluate for, as JSON")
+	baseUrl := flags.String("base-url", "", "the base url of the ConfigCat CDN or a proxy")
+	timeout := flags.Duration("timeout", 10*time.Second, "the timeout of the config fetch")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	user, err := parseUser(*userArg)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	loader := configLoader{baseUrl: *baseUrl, timeout: *timeout}
+	switch args[0] {
+	case "keys":
+		err = loader.withSnapshot(*source, user, func(snapshot *configcat.Snapshot) error {
+			return listKeys(snapshot, stdout)
+		})
+	case "eval":
+		if flags.NArg() != 1 {
+			fmt.Fprintln(stderr, "usage: configcat eval -config SOURCE [-user JSON] KEY")
+			return 2
+		}
+		err = loader.withSnapshot(*source, user, func(snapshot *configcat.Snapshot) error {
+			return printJson(snapshot.GetValue(flags.Arg(0), nil), stdout)
+		})
+	case "dump":
+		err = loader.withSnapshot(*source, user, func(snapshot *configcat.Snapshot) error {
+			values, err := allValues(snapshot)
+			if err != nil {
+				return err
+			}
+			return printJson(values, stdout)
+		})
+	case "diff":
+		var differs bool
+		err = loader.withSnapshot(*source, user, func(from *configcat.Snapshot) error {
+			return loader.withSnapshot(*against, user, func(to *configcat.Snapshot) error {
+				differs, err = diff(from, to, stdout)
+				return err
+			})
+		})
+		if err == nil && differs {
+			return 1
+		}
+	case "lint":
+		var findings []configcat.ValidationFinding
+		err = loader.withSnapshot(*source, user, func(snapshot *configcat.Snapshot) error {
+			findings, err = configcat.ValidateConfig([]byte(snapshot.ConfigJSON()))
+			return err
+		})
+		for _, finding := range findings {
+			fmt.Fprintln(stdout, finding)
+		}
+		if err == nil && len(findings) > 0 {
+			return 1
+		}
+	default:
+		fmt.Fprintln(stderr, "unknown command: "+args[0])
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	return 0
+}
+
+type configLoader struct {
+	baseUrl string
+	timeout time.Duration
+}
+
+// withSnapshot loads the configuration from the source and calls fn with its snapshot.
+func (loader configLoader) withSnapshot(source string, user *configcat.User, fn func(snapshot *configcat.Snapshot) error) error {
+	if len(source) == 0 {
+		return errors.New("missing config source")
+	}
+
+	config := configcat.ClientConfig{
+		Mode:    configcat.ManualPoll(),
+		BaseUrl: loader.baseUrl,
+		Logger:  configcat.DefaultLogger(configcat.LogLevelError),
+	}
+
+	apiKey := source
+	if strings.HasPrefix(source, filePrefix) {
+		path := strings.TrimPrefix(source, filePrefix)
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+		apiKey = "local"
+		config.Offline = true
+		config.Cache = configcat.NewFileCache(path)
+	}
+
+	client := configcat.NewCustomClient(apiKey, config)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), loader.timeout)
+	defer cancel()
+	if err := client.RefreshWithContext(ctx); err != nil {
+		return err
+	}
+
+	return fn(client.Snapshot(user))
+}
+
+func parseUser(value string) (*configcat.User, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var user userJson
+	if err := json.Unmarshal([]byte(value), &user); err != nil {
+		return nil, fmt.Errorf("invalid user: %v", err)
+	}
+
+	return configcat.NewUserWithAdditionalAttributes(user.Identifier, user.Email, user.Country, user.Custom), nil
+}
+
+func listKeys(snapshot *configcat.Snapshot, out io.Writer) error {
+	keys, err := snapshot.GetAllKeys()
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintln(out, key)
+	}
+
+	return nil
+}
+
+func allValues(snapshot *configcat.Snapshot) (map[string]interface{}, error) {
+	keys, err := snapshot.GetAllKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		values[key] = snapshot.GetValue(key, nil)
+	}
+
+	return values, nil
+}
+
+// diff prints the keys added, removed or evaluated differently in the second configuration
+// and reports whether there was any difference.
+func diff(from *configcat.Snapshot, to *configcat.Snapshot, out io.Writer) (bool, error) {
+	fromValues, err := allValues(from)
+	if err != nil {
+		return false, err
+	}
+
+	toValues, err := allValues(to)
+	if err != nil {
+		return false, err
+	}
+
+	keys := make([]string, 0, len(fromValues)+len(toValues))
+	for key := range fromValues {
+		keys = append(keys, key)
+	}
+	for key := range toValues {
+		if _, ok := fromValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	differs := false
+	for _, key := range keys {
+		fromValue, inFrom := fromValues[key]
+		toValue, inTo := toValues[key]
+		switch {
+		case !inTo:
+			fmt.Fprintf(out, "- %s: %v\n", key, fromValue)
+		case !inFrom:
+			fmt.Fprintf(out, "+ %s: %v\n", key, toValue)
+		case fromValue != toValue:
+			fmt.Fprintf(out, "~ %s: %v -> %v\n", key, fromValue, toValue)
+		default:
+			continue
+		}
+		differs = true
+	}
+
+	return differs, nil
+}
+
+func printJson(value interface{}, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return "file:" + path
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := writeConfig(t, dir, "first.json", `{ "a": { "v": true }, "b": { "v": "x" } }`)
+	second := writeConfig(t, dir, "second.json", `{ "a": { "v": false }, "c": { "v": 1 } }`)
+
+	var out bytes.Buffer
+	if code := run([]string{"keys", "-config", first}, &out, &out); code != 0 || out.String() != "a\nb\n" {
+		t.Errorf("Unexpected keys output (%d): %s", code, out.String())
+	}
+
+	out.Reset()
+	if code := run([]string{"eval", "-config", first, "-user", `{"identifier":"id"}`, "b"}, &out, &out); code != 0 || strings.TrimSpace(out.String()) != `"x"` {
+		t.Errorf("Unexpected eval output (%d): %s", code, out.String())
+	}
+
+	out.Reset()
+	if code := run([]string{"diff", "-config", first, "-against", second}, &out, &out); code != 1 || out.String() != "~ a: true -> false\n- b: x\n+ c: 1\n" {
+		t.Errorf("Unexpected diff output (%d): %s", code, out.String())
+	}
+
+	out.Reset()
+	if code := run([]string{"diff", "-config", first, "-against", first}, &out, &out); code != 0 || out.Len() != 0 {
+		t.Errorf("Unexpected diff output (%d): %s", code, out.String())
+	}
+
+	out.Reset()
+	if code := run([]string{"lint", "-config", first}, &out, &out); code != 0 || out.Len() != 0 {
+		t.Errorf("Unexpected lint output (%d): %s", code, out.String())
+	}
+
+	invalid := writeConfig(t, dir, "invalid.json", `{ "a": { "v": true, "p": [ { "o": 0, "v": true, "p": 50 } ] } }`)
+	out.Reset()
+	if code := run([]string{"lint", "-config", invalid}, &out, &out); code != 1 || out.String() != "a p: invalid percentages: the percentages sum to 50 instead of 100\n" {
+		t.Errorf("Unexpected lint output (%d): %s", code, out.String())
+	}
+}
@@ -0,0 +1,48 @@
+package configcat
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ComparatorFunc is a custom comparator registered by RegisterComparator. It returns true if the user attribute
+// matches the comparison value of the targeting rule, both as they appear in the User and in the config JSON.
+type ComparatorFunc func(userValue string, comparisonValue string) bool
+
+// comparatorRegistry holds the custom comparators by their IDs.
+var comparatorRegistry = struct {
+	comparators map[Comparator]ComparatorFunc
+	sync.RWMutex
+}{comparators: map[Comparator]ComparatorFunc{}}
+
+// RegisterComparator registers a custom comparator for the targeting rules whose comparator ("t" in the config
+// JSON) is the given ID, e.g. in forks or self-hosted setups extending the config schema. The comparator is used
+// by all the clients of the process, so it should be registered at startup, before the clients are created, as
+// the memoized evaluations aren't updated by a later registration. Registering an ID again replaces its
+// comparator, and a nil comparator removes it. The IDs of the built-in comparators can't be registered, IDs from
+// 1000 upwards are recommended to avoid the ones ConfigCat may introduce.
+func RegisterComparator(id Comparator, match ComparatorFunc) error {
+	if _, builtIn := comparatorTexts[int(id)]; builtIn {
+		return fmt.Errorf("comparator %d is a built-in comparator (%s)", int(id), id.String())
+	}
+
+	comparatorRegistry.Lock()
+	defer comparatorRegistry.Unlock()
+	if match == nil {
+		delete(comparatorRegistry.comparators, id)
+	} else {
+		comparatorRegistry.comparators[id] = match
+	}
+	return nil
+}
+
+// lookupComparator returns the custom comparator registered with the ID, or nil if there is none.
+func lookupComparator(id float64) ComparatorFunc {
+	if id != float64(int(id)) {
+		return nil
+	}
+
+	comparatorRegistry.RLock()
+	defer comparatorRegistry.RUnlock()
+	return comparatorRegistry.comparators[Comparator(id)]
+}
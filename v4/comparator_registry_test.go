@@ -0,0 +1,44 @@
+package configcat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterComparator(t *testing.T) {
+	const hasDomain = Comparator(1000)
+	if err := RegisterComparator(hasDomain, func(userValue string, comparisonValue string) bool {
+		return strings.HasSuffix(strings.ToLower(userValue), "@"+comparisonValue)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer RegisterComparator(hasDomain, nil)
+
+	body := `{ "key": { "v": "default", "t": 1, "r": [ { "o": 0, "a": "Email", "t": 1000, "c": "example.com", "v": "matched" } ] } }`
+	evaluator, err := NewEvaluator([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := evaluator.Evaluate("key", "", NewUser("id")); value != "default" {
+		t.Errorf("Expecting the default value without the attribute, got %v", value)
+	}
+	user := NewUserWithAdditionalAttributes("id", "John@Example.com", "", nil)
+	if value, _ := evaluator.Evaluate("key", "", user); value != "matched" {
+		t.Errorf("Expecting the custom comparator to match, got %v", value)
+	}
+	if findings, _ := ValidateConfig([]byte(body)); len(findings) != 0 {
+		t.Errorf("Expecting the custom comparator to be valid, got %v", findings)
+	}
+	if name := hasDomain.String(); name != "CUSTOM (1000)" {
+		t.Errorf("Unexpected name: %s", name)
+	}
+
+	if err := RegisterComparator(ComparatorContains, func(string, string) bool { return true }); err == nil {
+		t.Error("Expecting the built-in comparators to be protected")
+	}
+
+	RegisterComparator(hasDomain, nil)
+	if value, _ := Evaluate([]byte(body), "key", "", user); value != "default" {
+		t.Errorf("Expecting the removed comparator not to match, got %v", value)
+	}
+}
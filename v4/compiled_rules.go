@@ -0,0 +1,202 @@
+package configcat
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+)
+
+// compiledSetting is a setting prepared for the evaluation when the config JSON is deserialized,
+// so the evaluation doesn't need to parse the comparison values again and again.
+type compiledSetting struct {
+	value       interface{}
+	variationId string
+	rules       []*compiledRule
+	percentages []compiledPercentage
+	// the salt of the config followed by the key of the setting, appended to the values of the hashed comparators
+	salt string
+	// true if the setting has targeting rules or percentage options, even invalid ones
+	targeted bool
+}
+
+// compiledRule is a targeting rule with its comparison value parsed according to its comparator.
+type compiledRule struct {
+	attribute       string
+	lowerAttribute  string
+	comparator      float64
+	hasComparator   bool
+	comparisonValue string
+	value           interface{}
+	variationId     string
+	// the trimmed items of the comma separated comparison value, in their original order
+	items []string
+	// the trimmed items sorted for binary search
+	sortedItems []string
+	// the set of the trimmed items
+	itemSet map[string]struct{}
+	// the parsed comparison value of the semver, number and date time comparators
+	versions []semver.Version
+	version  semver.Version
+	number   float64
+	// the parsed items of the hashed starts with and ends with comparators
+	affixes []hashedAffix
+	// the error of parsing the comparison value, the rule is skipped when it's set
+	err error
+	// the rules canonicalized by the canonicalizers applied to the rule, keyed by the canonicalizer
+	canonical sync.Map
+}
+
+// hashedAffix is an item of a hashed starts with or ends with comparison value.
+type hashedAffix struct {
+	length int
+	hash   string
+}
+
+// compiledPercentage is a percentage option with its cumulative threshold.
+type compiledPercentage struct {
+	threshold   int64
+	value       interface{}
+	variationId string
+}
+
+// compileSettings prepares the settings of the root node for the evaluation, salt is the salt of the config.
+func compileSettings(rootNode map[string]interface{}, salt string) map[string]*compiledSetting {
+	settings := make(map[string]*compiledSetting, len(rootNode))
+	for key, node := range rootNode {
+		if settingNode, ok := node.(map[string]interface{}); ok {
+			setting := compileSetting(settingNode)
+			setting.salt = salt + key
+			settings[key] = setting
+		}
+	}
+
+	return settings
+}
+
+func compileSetting(node map[string]interface{}) *compiledSetting {
+	setting := &compiledSetting{value: node["v"]}
+	setting.variationId, _ = node["i"].(string)
+
+	rules, _ := node["r"].([]interface{})
+	for _, r := range rules {
+		if rule, ok := r.(map[string]interface{}); ok {
+			setting.rules = append(setting.rules, compileRule(rule))
+		}
+	}
+
+	percentages, _ := node["p"].([]interface{})
+	threshold := int64(0)
+	for _, r := range percentages {
+		option, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		p, ok := option["p"].(float64)
+		if !ok {
+			continue
+		}
+
+		threshold += int64(p)
+		variationId, _ := option["i"].(string)
+		setting.percentages = append(setting.percentages, compiledPercentage{threshold: threshold, value: option["v"], variationId: variationId})
+	}
+
+	setting.targeted = len(rules) > 0 || len(percentages) > 0
+	return setting
+}
+
+func compileRule(node map[string]interface{}) *compiledRule {
+	rule := &compiledRule{value: node["v"]}
+	rule.attribute, _ = node["a"].(string)
+	rule.lowerAttribute = strings.ToLower(rule.attribute)
+	rule.comparisonValue, _ = node["c"].(string)
+	rule.comparator, rule.hasComparator = node["t"].(float64)
+	rule.variationId, _ = node["i"].(string)
+
+	for _, item := range strings.Split(rule.comparisonValue, ",") {
+		rule.items = append(rule.items, strings.TrimSpace(item))
+	}
+
+	switch rule.comparator {
+	case 0, 1, 20, 21, 28, 29:
+		rule.sortedItems = append([]string(nil), rule.items...)
+		sort.Strings(rule.sortedItems)
+	case 16, 17, 26, 27, 34, 35:
+		rule.itemSet = make(map[string]struct{}, len(rule.items))
+		for _, item := range rule.items {
+			rule.itemSet[item] = struct{}{}
+		}
+	case 4, 5:
+		for _, item := range rule.items {
+			if len(item) == 0 {
+				continue
+			}
+
+			version, err := semver.Make(item)
+			if err != nil {
+				rule.err = err
+				break
+			}
+			rule.versions = append(rule.versions, version)
+		}
+	case 6, 7, 8, 9:
+		rule.version, rule.err = semver.Make(strings.TrimSpace(rule.comparisonValue))
+	case 10, 11, 12, 13, 14, 15:
+		rule.number, rule.err = strconv.ParseFloat(strings.Replace(rule.comparisonValue, ",", ".", -1), 64)
+	case 18, 19:
+		rule.number, rule.err = strconv.ParseFloat(strings.TrimSpace(rule.comparisonValue), 64)
+	case 22, 23, 24, 25:
+		for _, item := range rule.items {
+			// the hashed items are in <length>_<hash of the first or last length bytes> format
+			parts := strings.SplitN(item, "_", 2)
+			length, err := strconv.Atoi(parts[0])
+			if len(parts) != 2 || err != nil || length < 0 {
+				continue
+			}
+			rule.affixes = append(rule.affixes, hashedAffix{length: length, hash: parts[1]})
+		}
+	}
+
+	return rule
+}
+
+// containsSorted returns true if the sorted items contain the value.
+func containsSorted(sortedItems []string, value string) bool {
+	i := sort.SearchStrings(sortedItems, value)
+	return i < len(sortedItems) && sortedItems[i] == value
+}
+
+// containsSubstring returns true if any of the items contains the value, with a binary search
+// for the items equal to the value first.
+func containsSubstring(rule *compiledRule, value string) bool {
+	if containsSorted(rule.sortedItems, value) {
+		return true
+	}
+
+	for _, item := range rule.items {
+		if strings.Contains(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsHash returns true if any of the items contains the hash, with a lookup of the items equal to the hash first.
+func containsHash(rule *compiledRule, hash string) bool {
+	if _, ok := rule.itemSet[hash]; ok {
+		return true
+	}
+
+	for _, item := range rule.items {
+		if len(item) > len(hash) && strings.Contains(item, hash) {
+			return true
+		}
+	}
+
+	return false
+}
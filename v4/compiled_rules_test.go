@@ -0,0 +1,47 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestCompileSetting(t *testing.T) {
+	setting := compileSetting(map[string]interface{}{
+		"v": "default",
+		"i": "a",
+		"r": []interface{}{
+			map[string]interface{}{"a": "Email", "t": 0.0, "c": " b@x.com, a@x.com ", "v": "one of"},
+			map[string]interface{}{"a": "Version", "t": 4.0, "c": "1.0.0, invalid", "v": "semver"},
+			map[string]interface{}{"a": "Age", "t": 12.0, "c": "18,5", "v": "number"},
+			map[string]interface{}{"a": "Id", "t": 24.0, "c": "3_hash, invalid", "v": "ends with"},
+		},
+		"p": []interface{}{
+			map[string]interface{}{"v": "first", "p": 20.0, "i": "b"},
+			map[string]interface{}{"v": "second", "p": 80.0, "i": "c"},
+		},
+	})
+
+	if !setting.targeted || setting.value != "default" || setting.variationId != "a" || len(setting.rules) != 4 {
+		t.Fatalf("Unexpected setting: %+v", setting)
+	}
+
+	oneOf, semver, number, endsWith := setting.rules[0], setting.rules[1], setting.rules[2], setting.rules[3]
+	if oneOf.lowerAttribute != "email" || len(oneOf.sortedItems) != 2 || oneOf.sortedItems[0] != "a@x.com" {
+		t.Errorf("Unexpected one of rule: %+v", oneOf)
+	}
+
+	if semver.err == nil || len(semver.versions) != 1 {
+		t.Errorf("Expecting the invalid semver to be reported: %+v", semver)
+	}
+
+	if number.err != nil || number.number != 18.5 {
+		t.Errorf("Unexpected number rule: %+v", number)
+	}
+
+	if len(endsWith.affixes) != 1 || endsWith.affixes[0] != (hashedAffix{3, "hash"}) {
+		t.Errorf("Unexpected ends with rule: %+v", endsWith)
+	}
+
+	if len(setting.percentages) != 2 || setting.percentages[0].threshold != 20 || setting.percentages[1].threshold != 100 {
+		t.Errorf("Unexpected percentages: %+v", setting.percentages)
+	}
+}
@@ -0,0 +1,75 @@
+package configcat
+
+// ConfigAcceptance checks a downloaded configuration before it's served, e.g. that a key exists and serves
+// a boolean, and returns an error if the configuration must be rejected.
+type ConfigAcceptance func(candidate *Evaluator) error
+
+// ConfigRejectedError describes a downloaded configuration rejected by ClientConfig.AcceptConfig.
+// The previously accepted configuration is served instead.
+type ConfigRejectedError struct {
+	// The error of the acceptance check.
+	Err error
+}
+
+// Error is the error message.
+func (e *ConfigRejectedError) Error() string {
+	return "config rejected by the acceptance check: " + e.Err.Error()
+}
+
+// Unwrap returns the error of the acceptance check.
+func (e *ConfigRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// acceptanceProvider is a configProvider which runs the acceptance check on the fetched configurations,
+// the rejected ones are reported as failed fetches so they never reach the store.
+type acceptanceProvider struct {
+	configProvider
+	accept ConfigAcceptance
+	logger Logger
+	// called with the rejected config JSON and the error of the check
+	onRejected func(config string, err error)
+}
+
+func newAcceptanceProvider(provider configProvider, accept ConfigAcceptance, logger Logger) *acceptanceProvider {
+	return &acceptanceProvider{configProvider: provider, accept: accept, logger: logger}
+}
+
+func (provider *acceptanceProvider) getConfigurationAsync() *asyncResult {
+	return provider.configProvider.getConfigurationAsync().applyThenWithError(func(result interface{}, err error) (interface{}, error) {
+		if err != nil {
+			return result, err
+		}
+		response := asFetchResponse(result)
+		if !response.isFetched() {
+			return result, nil
+		}
+
+		if err := provider.check(response.body); err != nil {
+			provider.logger.Errorf("Config fetch failed: %s.", err.Error())
+			if provider.onRejected != nil {
+				provider.onRejected(response.body, err.Err)
+			}
+			return fetchResponse{status: Failure}, err
+		}
+		return result, nil
+	})
+}
+
+// check runs the acceptance check on the config JSON, the unparsable configurations are rejected as well.
+func (provider *acceptanceProvider) check(body string) (rejected *ConfigRejectedError) {
+	defer func() {
+		if r := recover(); r != nil {
+			rejected = &ConfigRejectedError{Err: newPanicError(r)}
+		}
+	}()
+
+	candidate, err := NewEvaluator([]byte(body))
+	if err == nil {
+		err = provider.accept(candidate)
+	}
+	if err != nil {
+		return &ConfigRejectedError{Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package configcat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClient_AcceptConfig(t *testing.T) {
+	accept := func(candidate *Evaluator) error {
+		if value, err := candidate.Evaluate("enabled", nil, nil); err != nil {
+			return err
+		} else if _, ok := value.(bool); !ok {
+			return errors.New("enabled must be a boolean")
+		}
+		return nil
+	}
+	var rejected []string
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), AcceptConfig: accept, Executor: inlineExecutor, Hooks: Hooks{
+		OnConfigRejected: func(config string, err error) {
+			rejected = append(rejected, err.Error())
+		},
+	}}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "enabled", "true")})
+	client.Refresh()
+	if value := client.GetValue("enabled", false); value != true || len(rejected) != 0 {
+		t.Fatalf("Expecting the accepted value, got %v (rejected: %v)", value, rejected)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "enabled", `"yes"`)})
+	err := client.RefreshWithContext(context.Background())
+	var rejectedErr *ConfigRejectedError
+	if !errors.As(err, &rejectedErr) {
+		t.Errorf("Expecting a ConfigRejectedError, got %v", err)
+	}
+	if value := client.GetValue("enabled", false); value != true {
+		t.Errorf("Expecting the previous value to be served, got %v", value)
+	}
+	if len(rejected) != 1 || rejected[0] != "enabled must be a boolean" {
+		t.Errorf("Expecting the rejection to be reported, got %v", rejected)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "other": { "v": true } }`})
+	client.Refresh()
+	if value := client.GetValue("enabled", false); value != true || len(rejected) != 2 {
+		t.Errorf("Expecting the configuration without the key to be rejected, got %v", value)
+	}
+}
+
+func TestClient_AcceptConfig_PanickingHook(t *testing.T) {
+	errs := make(chan error, 1)
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:         ManualPoll(),
+		Logger:       DefaultLogger(LogLevelFatal),
+		AcceptConfig: func(candidate *Evaluator) error { return errors.New("fake rejection") },
+		OnError:      func(err error) { errs <- err },
+		Hooks: Hooks{
+			OnConfigRejected: func(config string, err error) { panic("fake panicking hook") },
+		},
+	}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "enabled", "true")})
+	var rejectedErr *ConfigRejectedError
+	if err := client.RefreshWithContext(context.Background()); !errors.As(err, &rejectedErr) {
+		t.Errorf("Expecting a ConfigRejectedError, got %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if _, ok := err.(*PanicError); !ok {
+			t.Errorf("Expecting the recovered panic of the hook, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expecting the panic of the hook to be reported")
+	}
+}
@@ -1,7 +1,9 @@
 package configcat
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // ConfigCache is a cache API used to make custom cache implementations.
@@ -18,12 +20,55 @@ type inMemoryConfigCache struct {
 
 // configStore is used to maintain the cached configuration.
 type configStore struct {
-	cache         ConfigCache
-	logger        Logger
-	inMemoryValue string
+	// the number of failed reads and writes of the cache, first for the alignment of the atomic operations
+	readErrorCount  uint64
+	writeErrorCount uint64
+	cache           ConfigCache
+	logger          Logger
+	inMemoryValue   string
+	bootstrap       string
+	// the names of the config sources of the current and the previous configuration, see ClientConfig.Sources
+	configSource         string
+	previousValue        string
+	previousConfigSource string
+	// the configuration decoded by the fetch of the current value, nil if it wasn't decoded
+	decoded *parsedConfig
+	// the listeners notified of the changes, copied on write so they can be added and removed during a notification
+	listeners      []*storeListener
+	listenersMutex sync.Mutex
+	// serializes the writes, so the changes are queued in order
+	setMutex sync.Mutex
+	// the changes waiting for their notification, and whether a caller of set is notifying the listeners
+	pending     []storeChange
+	notifying   bool
+	notifyMutex sync.Mutex
 	sync.RWMutex
 }
 
+// storeChange is a change of the configuration waiting for the notification of the listeners.
+type storeChange struct {
+	previous string
+	current  string
+}
+
+// CacheErrorPolicy describes the behavior of the Client when the cache can't be read at startup.
+type CacheErrorPolicy int
+
+const (
+	// CacheErrorServeEmpty serves the bootstrap config, or the default values if there is none,
+	// until the first configuration is fetched.
+	CacheErrorServeEmpty CacheErrorPolicy = 0
+	// CacheErrorBlockUntilFetch makes the getters wait for the first fetch, which is started immediately.
+	CacheErrorBlockUntilFetch CacheErrorPolicy = 1
+	// CacheErrorFail fails the creation of the Client, see NewCustomClientWithError.
+	CacheErrorFail CacheErrorPolicy = 2
+)
+
+// storeListener is a listener registered with configStore.addListener.
+type storeListener struct {
+	onChanged func(previous string, current string)
+}
+
 func newConfigStore(log Logger, cache ConfigCache) *configStore {
 	return &configStore{cache: cache, logger: log}
 }
@@ -50,20 +95,205 @@ func (store *configStore) get() string {
 	defer store.RUnlock()
 	value, err := store.cache.Get()
 	if err != nil {
-		store.logger.Errorf("Reading from the cache failed, %s", err)
-		return store.inMemoryValue
+		atomic.AddUint64(&store.readErrorCount, 1)
+		logEvent(store.logger, LogLevelError, LogEventCache, logFields{}, "Reading from the cache failed, %s", err)
+		value = store.inMemoryValue
+	}
+
+	if len(value) == 0 {
+		return store.bootstrap
 	}
 
 	return value
 }
 
-// set writes the configuration.
+// empty returns true if no configuration was stored or found in the cache yet, the bootstrap config isn't counted.
+// The cache is read without holding the lock, and only until the first configuration is stored.
+func (store *configStore) empty() bool {
+	store.RLock()
+	inMemoryValue, cache := store.inMemoryValue, store.cache
+	store.RUnlock()
+	if len(inMemoryValue) > 0 {
+		return false
+	}
+	value, err := cache.Get()
+	return err != nil || len(value) == 0
+}
+
+// decodedConfig returns the configuration decoded by the fetch if it's the given one, otherwise nil.
+func (store *configStore) decodedConfig(value string) *parsedConfig {
+	store.RLock()
+	defer store.RUnlock()
+	if store.decoded != nil && store.decoded.json == value {
+		return store.decoded
+	}
+	return nil
+}
+
+// sourceOf returns the name of the config source the given configuration was stored from, when it's the current
+// or the previous configuration of the store, otherwise an empty string.
+func (store *configStore) sourceOf(value string) string {
+	store.RLock()
+	defer store.RUnlock()
+	switch value {
+	case store.inMemoryValue:
+		return store.configSource
+	case store.previousValue:
+		return store.previousConfigSource
+	}
+	return ""
+}
+
+// source returns the kind of the cache, "memory" or "external".
+func (store *configStore) source() string {
+	store.RLock()
+	defer store.RUnlock()
+	if _, ok := store.cache.(*inMemoryConfigCache); ok {
+		return "memory"
+	}
+	return "external"
+}
+
+// setCache replaces the cache after writing the current configuration into the new cache, so it's served
+// without interruption. The cache isn't replaced when the write fails. The caches are read and written
+// without holding the lock of the reads, only the writes of the store wait for the handover.
+func (store *configStore) setCache(cache ConfigCache) error {
+	store.setMutex.Lock()
+	defer store.setMutex.Unlock()
+
+	store.RLock()
+	current, inMemoryValue := store.cache, store.inMemoryValue
+	store.RUnlock()
+
+	value, err := current.Get()
+	if err != nil || len(value) == 0 {
+		value = inMemoryValue
+	}
+	if len(value) > 0 {
+		if err := cache.Set(value); err != nil {
+			atomic.AddUint64(&store.writeErrorCount, 1)
+			logEvent(store.logger, LogLevelError, LogEventCache, logFields{}, "Saving into the new cache failed, %s", err)
+			return fmt.Errorf("saving into the new cache failed: %w", err)
+		}
+	}
+
+	store.Lock()
+	store.cache = cache
+	store.Unlock()
+	return nil
+}
+
+// probe reads the cache to check whether it's available, a panic of the cache is returned as *PanicError.
+func (store *configStore) probe() (err error) {
+	store.RLock()
+	defer store.RUnlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r)
+			atomic.AddUint64(&store.readErrorCount, 1)
+			logEvent(store.logger, LogLevelError, LogEventCache, logFields{}, "Reading from the cache failed, %s", err)
+		}
+	}()
+	_, err = store.cache.Get()
+	if err != nil {
+		atomic.AddUint64(&store.readErrorCount, 1)
+		logEvent(store.logger, LogLevelError, LogEventCache, logFields{}, "Reading from the cache failed, %s", err)
+	}
+	return err
+}
+
+// set writes the configuration and notifies the listeners when it differs from the previous one.
+// The listeners are called after the write is completed, so a slow listener doesn't block the other writes.
+// The changes are notified one at a time in the order of the writes, a change made while the listeners are
+// notified (e.g. by a listener) is notified by the caller already notifying them after the current one.
 func (store *configStore) set(value string) {
+	store.setFetched(fetchResponse{body: value})
+}
+
+// setFetched is like set, but it records the name of the config source and the configuration decoded by
+// the fetch of the response as well.
+func (store *configStore) setFetched(response fetchResponse) {
+	store.setMutex.Lock()
+	value := internJson(response.body)
 	store.Lock()
-	defer store.Unlock()
-	store.inMemoryValue = value
+	previous := store.inMemoryValue
+	if previous != value {
+		store.previousValue, store.previousConfigSource = previous, store.configSource
+	}
+	store.inMemoryValue, store.configSource, store.decoded = value, response.source, response.config
 	err := store.cache.Set(value)
+	store.Unlock()
 	if err != nil {
-		store.logger.Errorf("Saving into the cache failed, %s", err)
+		atomic.AddUint64(&store.writeErrorCount, 1)
+		logEvent(store.logger, LogLevelError, LogEventCache, logFields{}, "Saving into the cache failed, %s", err)
+	}
+
+	changed := previous != value
+	if changed {
+		store.notifyMutex.Lock()
+		store.pending = append(store.pending, storeChange{previous: previous, current: value})
+		store.notifyMutex.Unlock()
+	}
+	store.setMutex.Unlock()
+
+	if changed {
+		store.notify()
+	}
+}
+
+// notify notifies the listeners of the pending changes unless another caller is already notifying them.
+func (store *configStore) notify() {
+	store.notifyMutex.Lock()
+	if store.notifying {
+		store.notifyMutex.Unlock()
+		return
+	}
+	store.notifying = true
+	completed := false
+	defer func() {
+		// a panicking listener mustn't stop the notifications of the later changes
+		if !completed {
+			store.notifyMutex.Lock()
+			store.notifying = false
+			store.notifyMutex.Unlock()
+		}
+	}()
+
+	for len(store.pending) > 0 {
+		change := store.pending[0]
+		store.pending = store.pending[1:]
+		store.notifyMutex.Unlock()
+
+		store.listenersMutex.Lock()
+		listeners := store.listeners
+		store.listenersMutex.Unlock()
+		for _, listener := range listeners {
+			listener.onChanged(change.previous, change.current)
+		}
+
+		store.notifyMutex.Lock()
+	}
+	store.notifying, completed = false, true
+	store.notifyMutex.Unlock()
+}
+
+// addListener registers a listener called with the previous and the current configuration on each change,
+// and returns the function removing it.
+func (store *configStore) addListener(onChanged func(previous string, current string)) (remove func()) {
+	listener := &storeListener{onChanged: onChanged}
+	store.listenersMutex.Lock()
+	store.listeners = append(store.listeners[:len(store.listeners):len(store.listeners)], listener)
+	store.listenersMutex.Unlock()
+
+	return func() {
+		store.listenersMutex.Lock()
+		defer store.listenersMutex.Unlock()
+		listeners := make([]*storeListener, 0, len(store.listeners))
+		for _, registered := range store.listeners {
+			if registered != listener {
+				listeners = append(listeners, registered)
+			}
+		}
+		store.listeners = listeners
 	}
 }
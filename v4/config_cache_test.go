@@ -0,0 +1,157 @@
+package configcat
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigStore_Listeners(t *testing.T) {
+	logger := DefaultLogger(LogLevelWarn)
+	store := newConfigStore(logger, newInMemoryConfigCache())
+
+	var changes []string
+	var remove func()
+	remove = store.addListener(func(previous string, current string) {
+		changes = append(changes, previous+"->"+current)
+		if current == "b" {
+			remove()
+			store.addListener(func(previous string, current string) {
+				changes = append(changes, "added:"+current)
+			})
+		}
+	})
+
+	store.set("a")
+	store.set("a")
+	store.set("b")
+	store.set("c")
+
+	expected := []string{"->a", "a->b", "added:c"}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expecting %v, got %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expecting %v, got %v", expected, changes)
+		}
+	}
+}
+
+func TestConfigStore_ConcurrentNotifications(t *testing.T) {
+	logger := DefaultLogger(LogLevelWarn)
+	store := newConfigStore(logger, newInMemoryConfigCache())
+
+	var mutex sync.Mutex
+	last := ""
+	notified := 0
+	store.addListener(func(previous string, current string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if previous != last || previous == current {
+			t.Errorf("Unexpected change from %q to %q after %q", previous, current, last)
+		}
+		last = current
+		notified++
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				store.set(strconv.Itoa((i + j) % 3))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if last != store.get() || notified == 0 {
+		t.Errorf("Expecting the last notification to match the stored %q, got %q after %d", store.get(), last, notified)
+	}
+}
+
+func TestConfigStore_ListenerSets(t *testing.T) {
+	store := newConfigStore(DefaultLogger(LogLevelWarn), newInMemoryConfigCache())
+
+	var changes []string
+	store.addListener(func(previous string, current string) {
+		changes = append(changes, previous+"->"+current)
+		if current == "a" {
+			store.set("b")
+		}
+	})
+	store.set("a")
+
+	if len(changes) != 2 || changes[0] != "->a" || changes[1] != "a->b" {
+		t.Errorf("Expecting the change of the listener to be notified after the current one, got %v", changes)
+	}
+}
+
+func TestConfigStore_SlowListener(t *testing.T) {
+	store := newConfigStore(DefaultLogger(LogLevelWarn), newInMemoryConfigCache())
+	block := make(chan struct{})
+	store.addListener(func(previous string, current string) {
+		if current == "a" {
+			<-block
+		}
+	})
+	go store.set("a")
+	for store.get() != "a" {
+		runtime.Gosched()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		store.set("b")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expecting the write not to wait for the slow listener")
+	}
+	close(block)
+}
+
+type failingCache struct{}
+
+func (failingCache) Get() (string, error) {
+	return "", errors.New("cache unavailable")
+}
+
+func (failingCache) Set(value string) error {
+	return errors.New("cache unavailable")
+}
+
+func TestClient_CacheErrorPolicy(t *testing.T) {
+	body := `{ "key": { "v": "fetched", "t": 1 } }`
+
+	fetcher := newFakeConfigProvider()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: body})
+	client, err := newClient("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: failingCache{}, CacheErrorPolicy: CacheErrorFail}, fetcher)
+	if client != nil || err == nil {
+		t.Fatalf("Expecting the creation to fail, got %v", err)
+	}
+
+	client = newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: failingCache{}}, fetcher)
+	if value := client.GetValue("key", "default"); value != "default" {
+		t.Errorf("Expecting the default value before the first fetch, got %v", value)
+	}
+	client.Refresh()
+	status := client.Status()
+	if status.CacheReadErrorCount == 0 || status.CacheWriteErrorCount != 1 {
+		t.Errorf("Expecting the cache errors to be counted, got %+v", status)
+	}
+	client.Close()
+
+	client = newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: failingCache{}, CacheErrorPolicy: CacheErrorBlockUntilFetch}, fetcher)
+	defer client.Close()
+	if value := client.GetValue("key", "default"); value != "fetched" {
+		t.Errorf("Expecting the getter to wait for the first fetch, got %v", value)
+	}
+}
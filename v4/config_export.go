@@ -0,0 +1,43 @@
+package configcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ExportConfig returns the currently active configuration in canonical JSON: the settings and their
+// properties are ordered by key, without insignificant whitespace and with the number literals kept as
+// they were downloaded. The result can be diffed between environments, stored as a backup or used as
+// the config file of an offline client (see NewFileCache) or as the Bootstrap of a client.
+func (client *Client) ExportConfig() ([]byte, error) {
+	json, err := client.getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(json) == 0 {
+		return nil, errors.New("no configuration is available")
+	}
+
+	return canonicalJson([]byte(json))
+}
+
+// canonicalJson re-encodes the JSON with ordered object keys and without insignificant whitespace.
+func canonicalJson(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
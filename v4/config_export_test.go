@@ -0,0 +1,28 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestClient_ExportConfig(t *testing.T) {
+	fetcher, client := getTestClients()
+	if _, err := client.ExportConfig(); err == nil {
+		t.Error("Expecting an error without configuration")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"b": { "v": 1.50, "i": "x" },
+		"a": { "v": "<html>", "r": [ { "t": 0, "o": 0, "a": "Email", "c": "a@b.com", "v": "y" } ] }
+	}`})
+	client.Refresh()
+
+	exported, err := client.ExportConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"a":{"r":[{"a":"Email","c":"a@b.com","o":0,"t":0,"v":"y"}],"v":"<html>"},"b":{"i":"x","v":1.50}}`
+	if string(exported) != expected {
+		t.Errorf("Expecting %s, got %s", expected, exported)
+	}
+}
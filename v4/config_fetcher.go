@@ -1,8 +1,19 @@
 package configcat
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 )
 
 // configProvider describes a configuration provider which used to collect the actual configuration.
@@ -11,71 +22,283 @@ type configProvider interface {
 	getConfigurationAsync() *asyncResult
 }
 
+// StatusError describes an unexpected HTTP response status received while fetching the configuration.
+type StatusError struct {
+	// The status code of the response.
+	StatusCode int
+}
+
+// Error is the error message.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status: %v", e.StatusCode)
+}
+
+// isTransientFetchError returns true if the fetch failed because of a timeout or a temporary
+// unavailability of the server, so it's worth retrying shortly.
+func isTransientFetchError(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusBadGateway ||
+			statusErr.StatusCode == http.StatusServiceUnavailable ||
+			statusErr.StatusCode == http.StatusGatewayTimeout
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
 // configFetcher used to fetch the actual configuration over HTTP.
 type configFetcher struct {
-	apiKey, eTag, mode, baseUrl string
-	client                      *http.Client
-	logger                      Logger
+	apiKey, eTag, baseUrl string
+	userAgent, platform   string
+	fetchTimeout          time.Duration
+	maxConfigSize         int64
+	verify                ConfigVerifier
+	signatureHeader       string
+	client                *http.Client
+	logger                Logger
+	onError               func(err error)
+	onBeforeFetch         func(request *http.Request)
+	onAfterFetch          func(attempt FetchAttempt)
+	inFlight              *asyncResult
+	// if it's true then the fetches run on the calling goroutine
+	inline bool
+	sync.Mutex
 }
 
 func newConfigFetcher(apiKey string, config ClientConfig) *configFetcher {
+	userAgent := "ConfigCat-Go/" + config.Mode.getModeIdentifier() + "-" + version
+	if len(config.ApplicationId) > 0 {
+		userAgent += " " + config.ApplicationId
+	}
+
 	return &configFetcher{apiKey: apiKey,
-		mode:    config.Mode.getModeIdentifier(),
-		baseUrl: config.BaseUrl,
-		logger:  config.Logger,
-		client:  &http.Client{Timeout: config.HttpTimeout, Transport: config.Transport}}
+		userAgent:       userAgent,
+		platform:        runtime.Version() + "; " + runtime.GOOS + "/" + runtime.GOARCH,
+		baseUrl:         config.BaseUrl,
+		logger:          config.Logger,
+		onError:         config.OnError,
+		onBeforeFetch:   config.Hooks.OnBeforeFetch,
+		onAfterFetch:    config.Hooks.OnAfterFetch,
+		fetchTimeout:    config.FetchTimeout,
+		maxConfigSize:   config.MaxConfigSize,
+		verify:          config.Integrity.Verify,
+		signatureHeader: config.Integrity.Header,
+		inline:          config.NoBackgroundGoroutines,
+		client:          &http.Client{Transport: newTransport(config)}}
 }
 
 // getConfigurationAsync collects the actual configuration over HTTP.
+// Concurrent calls share the result of the fetch already in flight.
 func (fetcher *configFetcher) getConfigurationAsync() *asyncResult {
+	fetcher.Lock()
+	if fetcher.inFlight != nil {
+		inFlight := fetcher.inFlight
+		fetcher.Unlock()
+		fetcher.logger.Debugln("Config fetch is already in progress, joining.")
+		return inFlight
+	}
+
 	result := newAsyncResult()
+	fetcher.inFlight = result
+	fetcher.Unlock()
+
+	fetch := func() {
+		response, err := fetcher.safeFetch()
 
-	go func() {
-		request, requestError := http.NewRequest("GET", fetcher.baseUrl+"/configuration-files/"+fetcher.apiKey+"/config_v4.json", nil)
-		if requestError != nil {
-			result.complete(fetchResponse{status: Failure})
+		fetcher.Lock()
+		fetcher.inFlight = nil
+		fetcher.Unlock()
+
+		if err != nil {
+			result.completeWithError(err)
 			return
 		}
+		result.complete(response)
+	}
 
-		request.Header.Add("X-ConfigCat-UserAgent", "ConfigCat-Go/"+fetcher.mode+"-"+version)
+	if fetcher.inline {
+		fetch()
+	} else {
+		go fetch()
+	}
+	return result
+}
 
-		if fetcher.eTag != "" {
-			request.Header.Add("If-None-Match", fetcher.eTag)
+// safeFetch is like fetch but converts a panic into an error.
+func (fetcher *configFetcher) safeFetch() (response fetchResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := newPanicError(r)
+			logEvent(fetcher.logger, LogLevelError, LogEventFetch, logFields{status: Failure.String()},
+				"Config fetch failed: %s.\n%s", panicErr.Error(), panicErr.Stack)
+			fetcher.reportError(panicErr)
+			response, err = fetchResponse{status: Failure}, panicErr
 		}
+	}()
 
-		response, responseError := fetcher.client.Do(request)
-		if responseError != nil {
-			fetcher.logger.Errorf("Config fetch failed: %s.", responseError.Error())
-			result.complete(fetchResponse{status: Failure, body: ""})
-			return
-		}
+	return fetcher.fetch()
+}
 
-		defer response.Body.Close()
+// fetch downloads the actual configuration over HTTP.
+func (fetcher *configFetcher) fetch() (result fetchResponse, err error) {
+	fetcher.Lock()
+	baseUrl := fetcher.baseUrl
+	fetcher.Unlock()
+	request, requestError := http.NewRequest("GET", baseUrl+"/configuration-files/"+fetcher.apiKey+"/config_v4.json", nil)
+	if requestError != nil {
+		return fetchResponse{status: Failure}, requestError
+	}
 
-		if response.StatusCode == 304 {
-			fetcher.logger.Debugln("Config fetch succeeded: not modified.")
-			result.complete(fetchResponse{status: NotModified})
-			return
-		}
+	if fetcher.fetchTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), fetcher.fetchTimeout)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+
+	request.Header.Add("X-ConfigCat-UserAgent", fetcher.userAgent)
+	request.Header.Add("User-Agent", "ConfigCat-Go/"+version+" ("+fetcher.platform+")")
+	request.Header.Add("Accept-Encoding", "gzip")
 
-		if response.StatusCode >= 200 && response.StatusCode < 300 {
-			body, bodyError := ioutil.ReadAll(response.Body)
-			if bodyError != nil {
-				fetcher.logger.Errorf("Config fetch failed: %s.", bodyError.Error())
-				result.complete(fetchResponse{status: Failure})
-				return
+	if fetcher.eTag != "" {
+		request.Header.Add("If-None-Match", fetcher.eTag)
+	}
+
+	if fetcher.onBeforeFetch != nil {
+		fetcher.onBeforeFetch(request)
+	}
+	var response *http.Response
+	started := time.Now()
+	if fetcher.onAfterFetch != nil {
+		defer func() {
+			attempt := FetchAttempt{Request: request, Duration: time.Since(started), Err: err}
+			if response != nil {
+				attempt.StatusCode = response.StatusCode
+				attempt.Header = response.Header
 			}
+			fetcher.onAfterFetch(attempt)
+		}()
+	}
 
-			fetcher.logger.Debugln("Config fetch succeeded: new config fetched.")
-			fetcher.eTag = response.Header.Get("Etag")
-			result.complete(fetchResponse{status: Fetched, body: string(body)})
-			return
+	response, responseError := fetcher.client.Do(request)
+	if responseError != nil {
+		logFetch(fetcher.logger, LogLevelError, started, Failure, "", "Config fetch failed: %s.", responseError.Error())
+		return fetchResponse{status: Failure}, responseError
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == 304 {
+		logFetch(fetcher.logger, LogLevelDebug, started, NotModified, fetcher.eTag, "Config fetch succeeded: not modified.")
+		return fetchResponse{status: NotModified, eTag: fetcher.eTag}.withCacheHeaders(response.Header), nil
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		config, bodyError := decodeBody(response, fetcher.maxConfigSize)
+		if bodyError != nil {
+			logFetch(fetcher.logger, LogLevelError, started, Failure, "", "Config fetch failed: %s.", bodyError.Error())
+			return fetchResponse{status: Failure}, bodyError
 		}
 
-		fetcher.logger.Errorf("Double-check your API KEY at https://app.configcat.com/apikey. "+
-			"Received unexpected response: %v.", response.StatusCode)
-		result.complete(fetchResponse{status: Failure})
-	}()
+		if err := verifyIntegrity(fetcher.verify, config.json, response.Header.Get(fetcher.signatureHeader)); err != nil {
+			logFetch(fetcher.logger, LogLevelError, started, Failure, "", "Config fetch failed: %s.", err.Error())
+			return fetchResponse{status: Failure}, err
+		}
 
-	return result
+		fetcher.eTag = response.Header.Get("Etag")
+		logFetch(fetcher.logger, LogLevelDebug, started, Fetched, fetcher.eTag, "Config fetch succeeded: new config fetched.")
+		return fetchResponse{status: Fetched, body: config.json, eTag: fetcher.eTag, config: config}.withCacheHeaders(response.Header), nil
+	}
+
+	logFetch(fetcher.logger, LogLevelError, started, Failure, "", "Double-check your API KEY at https://app.configcat.com/apikey. "+
+		"Received unexpected response: %v.", response.StatusCode)
+	return fetchResponse{status: Failure}, &StatusError{StatusCode: response.StatusCode}
+}
+
+// logFetch logs the message about the outcome of the fetch started at the given time, together with the status,
+// the ETag of the config and the duration of the fetch.
+func logFetch(logger Logger, level LogLevel, started time.Time, status fetchStatus, eTag string, format string, args ...interface{}) {
+	fields := logFields{status: status.String(), eTag: eTag, duration: time.Since(started)}
+	logEvent(logger, level, LogEventFetch, fields, format, args...)
+}
+
+// decodeBody decodes the configuration while the response body is streamed, so malformed configurations are
+// rejected before reaching the cache and the parser doesn't decode the body again. The size of the
+// (decompressed) body is limited to protect against decompression bombs.
+func decodeBody(response *http.Response, maxSize int64) (*parsedConfig, error) {
+	reader, err := bodyReader(response)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var body strings.Builder
+	limited := &io.LimitedReader{R: reader, N: maxSize + 1}
+	decoder := json.NewDecoder(io.TeeReader(limited, &body))
+	var root interface{}
+	decodeErr := decoder.Decode(&root)
+	if decodeErr == nil {
+		// the rest of the body must be empty, like for json.Unmarshal
+		if err := decoder.Decode(&json.RawMessage{}); err != io.EOF {
+			decodeErr = errors.New("invalid character after top-level value")
+		}
+	}
+
+	if limited.N <= 0 {
+		return nil, fmt.Errorf("config size exceeds the MaxConfigSize limit of %d bytes", maxSize)
+	}
+
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	return newParsedConfig(root, body.String())
+}
+
+// readBody reads the response body, decompressing it when it's gzip encoded.
+// The size of the (decompressed) body is limited to protect against decompression bombs.
+func readBody(response *http.Response, maxSize int64) ([]byte, error) {
+	reader, err := bodyReader(response)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("config size exceeds the MaxConfigSize limit of %d bytes", maxSize)
+	}
+
+	return body, nil
+}
+
+// bodyReader returns the reader of the response body, decompressing it when it's gzip encoded.
+func bodyReader(response *http.Response) (io.ReadCloser, error) {
+	if strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return gzip.NewReader(response.Body)
+	}
+	return ioutil.NopCloser(response.Body), nil
+}
+
+// setBaseUrl changes the base URL of the next fetches.
+func (fetcher *configFetcher) currentBaseUrl() string {
+	fetcher.Lock()
+	defer fetcher.Unlock()
+	return fetcher.baseUrl
+}
+
+func (fetcher *configFetcher) setBaseUrl(baseUrl string) {
+	fetcher.Lock()
+	defer fetcher.Unlock()
+	fetcher.baseUrl = baseUrl
+}
+
+func (fetcher *configFetcher) reportError(err error) {
+	if fetcher.onError != nil {
+		fetcher.onError(err)
+	}
 }
@@ -1,19 +1,40 @@
 package configcat
 
 import (
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestConfigFetcher_GetConfigurationJson(t *testing.T) {
 
 	fetcher := newConfigFetcher("PKDVCLf-Hq-h-kCzMp-L7Q/PaDVCFk9EpmD6sLpGLltTA", defaultConfig())
-	response := fetcher.getConfigurationAsync().get().(fetchResponse)
+	result, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(fetchResponse)
 
 	if !response.isFetched() {
 		t.Error("Expecting fetched")
 	}
 
-	response2 := fetcher.getConfigurationAsync().get().(fetchResponse)
+	result2, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response2 := result2.(fetchResponse)
 
 	if !response2.isNotModified() {
 		t.Error("Expecting not modified")
@@ -22,9 +43,424 @@ func TestConfigFetcher_GetConfigurationJson(t *testing.T) {
 
 func TestConfigFetcher_GetConfigurationJson_Fail(t *testing.T) {
 	fetcher := newConfigFetcher("thisshouldnotexist", defaultConfig())
-	response := fetcher.getConfigurationAsync().get().(fetchResponse)
+	_, err := fetcher.getConfigurationAsync().getWithError()
+
+	if err == nil {
+		t.Error("Expecting failed")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_HttpTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.HttpTimeout = time.Millisecond * 100
+	fetcher := newConfigFetcher("fakeKey", config)
+	_, err := fetcher.getConfigurationAsync().getWithError()
+
+	if err == nil {
+		t.Error("Expecting failed")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_FetchTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.FetchTimeout = time.Millisecond * 100
+	fetcher := newConfigFetcher("fakeKey", config)
+	_, err := fetcher.getConfigurationAsync().getWithError()
+
+	if err == nil {
+		t.Error("Expecting failed")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_Proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "cdn.configcat.invalid" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer proxy.Close()
+
+	proxyUrl, _ := url.Parse(proxy.URL)
+	config := defaultConfig()
+	config.BaseUrl = "http://cdn.configcat.invalid"
+	config.Proxy = http.ProxyURL(proxyUrl)
+	fetcher := newConfigFetcher("fakeKey", config)
+	result, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(fetchResponse)
+
+	if !response.isFetched() || response.body != "{}" {
+		t.Error("Expecting fetched through the proxy")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	caFile, err := ioutil.TempFile("", "configcat-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(caFile.Name())
+	_ = pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	_ = caFile.Close()
+
+	tlsConfig, err := NewTLSConfig(caFile.Name(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.TLSConfig = tlsConfig
+	fetcher := newConfigFetcher("fakeKey", config)
+	result, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(fetchResponse)
+
+	if !response.isFetched() {
+		t.Error("Expecting fetched")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_Headers(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.Mode = ManualPoll()
+	config.ApplicationId = "test-app/1.0"
+	fetcher := newConfigFetcher("fakeKey", config)
+	fetcher.getConfigurationAsync().get()
+
+	header := <-headers
+	if header.Get("X-ConfigCat-UserAgent") != "ConfigCat-Go/m-"+version+" test-app/1.0" {
+		t.Errorf("Unexpected X-ConfigCat-UserAgent header: %s", header.Get("X-ConfigCat-UserAgent"))
+	}
+
+	if !strings.HasPrefix(header.Get("User-Agent"), "ConfigCat-Go/"+version+" (") {
+		t.Errorf("Unexpected User-Agent header: %s", header.Get("User-Agent"))
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_Gzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		_, _ = writer.Write([]byte("{\"key\": {}}"))
+		_ = writer.Close()
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	fetcher := newConfigFetcher("fakeKey", config)
+	result, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := result.(fetchResponse)
+
+	if !response.isFetched() || response.body != "{\"key\": {}}" {
+		t.Errorf("Expecting decompressed body, got %s", response.body)
+	}
+}
 
-	if !response.isFailed() {
+func TestConfigFetcher_GetConfigurationJson_MaxConfigSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"key\": {\"v\": \"" + strings.Repeat("x", 1024) + "\"}}"))
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.MaxConfigSize = 512
+	fetcher := newConfigFetcher("fakeKey", config)
+	_, err := fetcher.getConfigurationAsync().getWithError()
+
+	if err == nil {
 		t.Error("Expecting failed")
 	}
 }
+
+func TestConfigFetcher_GetConfigurationJson_InvalidJson(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	fetcher := newConfigFetcher("fakeKey", config)
+	_, err := fetcher.getConfigurationAsync().getWithError()
+
+	if err == nil {
+		t.Error("Expecting failed")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_TrailingData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{ "key": { "v": true } } { "other": {} }`))
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	fetcher := newConfigFetcher("fakeKey", config)
+	if _, err := fetcher.getConfigurationAsync().getWithError(); err == nil {
+		t.Error("Expecting the trailing data to be rejected")
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_Decoded(t *testing.T) {
+	body := fmt.Sprintf(jsonFormat, "decodedKey", "true")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), BaseUrl: server.URL})
+	defer client.Close()
+	client.Refresh()
+
+	decoded := client.store.decodedConfig(body)
+	if decoded == nil || decoded.settings["decodedKey"] == nil {
+		t.Fatalf("Expecting the fetched config to be decoded by the fetch, got %v", decoded)
+	}
+	if config, err := client.parser.load(body); err != nil || config != decoded {
+		t.Errorf("Expecting the parser to reuse the decoded config, got %v", err)
+	}
+	if value := client.GetValue("decodedKey", false); value != true {
+		t.Errorf("Expecting the value of the decoded config, got %v", value)
+	}
+}
+
+func TestConfigFetcher_GetConfigurationJson_SingleFlight(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(time.Millisecond * 200)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	fetcher := newConfigFetcher("fakeKey", config)
+	first := fetcher.getConfigurationAsync()
+	second := fetcher.getConfigurationAsync()
+	_, err1 := first.getWithError()
+	_, err2 := second.getWithError()
+
+	if err1 != nil || err2 != nil {
+		t.Fatal("Expecting fetched")
+	}
+
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expecting 1 request, got %d", requests)
+	}
+}
+
+// serveFakeDNS answers the A queries of any name with 127.0.0.1 and the other queries with no records.
+func serveFakeDNS(t *testing.T) (address string, queries *int32, stop func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries = new(int32)
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(queries, 1)
+			query := buffer[:n]
+			end := 12
+			for end < n && query[end] != 0 {
+				end += int(query[end]) + 1
+			}
+			question := query[12 : end+5]
+			isA := query[end+1] == 0 && query[end+2] == 1
+			response := append([]byte{query[0], query[1], 0x81, 0x80, 0, 1, 0, 0, 0, 0, 0, 0}, question...)
+			if isA {
+				response[7] = 1
+				response = append(response, 0xc0, 0x0c, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 127, 0, 0, 1)
+			}
+			_, _ = conn.WriteTo(response, from)
+		}
+	}()
+	return conn.LocalAddr().String(), queries, func() { conn.Close() }
+}
+
+func TestConfigFetcher_GetConfigurationJson_Dialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	serverUrl, _ := url.Parse(server.URL)
+
+	resolver, queries, stop := serveFakeDNS(t)
+	defer stop()
+	config := defaultConfig()
+	config.BaseUrl = "http://cdn.configcat.invalid:" + serverUrl.Port()
+	config.Dialer = DialerConfig{Resolver: resolver, Family: IPv4Only}
+	fetcher := newConfigFetcher("fakeKey", config)
+	result, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.(fetchResponse).isFetched() || atomic.LoadInt32(queries) == 0 {
+		t.Error("Expecting fetched through the custom resolver")
+	}
+
+	config.BaseUrl = server.URL
+	config.Dialer = DialerConfig{Family: IPv6Only}
+	fetcher = newConfigFetcher("fakeKey", config)
+	if _, err := fetcher.getConfigurationAsync().getWithError(); err == nil {
+		t.Error("Expecting the IPv4 server to be unreachable over IPv6")
+	}
+}
+
+func TestConfigFetcher_Connection(t *testing.T) {
+	config := defaultConfig()
+	config.HttpTimeout = 0
+	config.Connection = ConnectionConfig{MaxIdleConnsPerHost: 8, IdleConnTimeout: 5 * time.Minute}
+	transport := newTransport(config).(*http.Transport)
+	if !transport.ForceAttemptHTTP2 || transport.MaxIdleConnsPerHost != 8 || transport.IdleConnTimeout != 5*time.Minute {
+		t.Errorf("Unexpected transport options: %v, %d, %v", transport.ForceAttemptHTTP2, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.ClientSessionCache == nil {
+		t.Error("Expecting the TLS session resumption to be enabled")
+	}
+
+	tlsConfig := &tls.Config{ServerName: "cdn.configcat.com"}
+	config.TLSConfig = tlsConfig
+	config.Connection = ConnectionConfig{DisableHTTP2: true}
+	transport = newTransport(config).(*http.Transport)
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil || tlsConfig.ClientSessionCache != nil ||
+		transport.TLSClientConfig.ServerName != "cdn.configcat.com" {
+		t.Error("Expecting HTTP/1.1 only without modifying the given TLS config")
+	}
+
+	config.Connection = ConnectionConfig{TLSSessionCacheSize: -1}
+	if transport = newTransport(config).(*http.Transport); transport.TLSClientConfig.ClientSessionCache != nil {
+		t.Error("Expecting the TLS session resumption to be disabled")
+	}
+}
+
+func TestConfigFetcher_TLSSessionResumption(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	var resumed []bool
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.TLSConfig = &tls.Config{
+		RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			resumed = append(resumed, state.DidResume)
+			return nil
+		},
+	}
+	fetcher := newConfigFetcher("fakeKey", config)
+	for i := 0; i < 2; i++ {
+		if _, err := fetcher.getConfigurationAsync().getWithError(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(resumed) != 2 || resumed[0] || !resumed[1] {
+		t.Errorf("Expecting the second connection to resume the TLS session, got %v", resumed)
+	}
+}
+
+func TestConfigFetcher_FetchHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == "etag" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Etag", "etag")
+		_, _ = w.Write([]byte(`{ "key": { "v": true } }`))
+	}))
+	defer server.Close()
+
+	var attempts []FetchAttempt
+	authorize := true
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.Hooks.OnBeforeFetch = func(request *http.Request) {
+		if authorize {
+			request.Header.Set("Authorization", "Bearer secret")
+		}
+	}
+	config.Hooks.OnAfterFetch = func(attempt FetchAttempt) {
+		attempts = append(attempts, attempt)
+	}
+	fetcher := newConfigFetcher("fakeKey", config)
+
+	for _, expected := range []fetchStatus{Fetched, NotModified} {
+		if response, err := fetcher.getConfigurationAsync().getWithError(); err != nil || response.(fetchResponse).status != expected {
+			t.Fatalf("Expecting status %v, got %v, %v", expected, response, err)
+		}
+	}
+	authorize = false
+	if _, err := fetcher.getConfigurationAsync().getWithError(); err == nil {
+		t.Error("Expecting failure without the authorization header")
+	}
+
+	if len(attempts) != 3 {
+		t.Fatalf("Expecting 3 attempts, got %d", len(attempts))
+	}
+	for i, expected := range []int{http.StatusOK, http.StatusNotModified, http.StatusUnauthorized} {
+		attempt := attempts[i]
+		if attempt.StatusCode != expected || attempt.Request == nil || attempt.Duration <= 0 || attempt.Header == nil {
+			t.Errorf("Unexpected attempt %d: %+v", i, attempt)
+		}
+		if (attempt.Err != nil) != (expected == http.StatusUnauthorized) {
+			t.Errorf("Unexpected error of attempt %d: %v", i, attempt.Err)
+		}
+	}
+	if attempts[0].Header.Get("Etag") != "etag" {
+		t.Errorf("Expecting the response headers, got %v", attempts[0].Header)
+	}
+}
@@ -0,0 +1,140 @@
+package configcat
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JournalConfig describes the configuration of the config change journal.
+type JournalConfig struct {
+	// The path of the journal file. The journaling is disabled when it's empty.
+	Path string
+	// The size in bytes the journal file is rotated at. The default is 10MB.
+	MaxSize int64
+	// The number of rotated journal files kept (e.g. journal.1, journal.2). The default is 3.
+	MaxBackups int
+}
+
+// JournalEntry is a line of the config change journal.
+type JournalEntry struct {
+	// The time the configuration changed.
+	Time time.Time `json:"time"`
+	// The ETag of the new configuration.
+	ETag string `json:"etag,omitempty"`
+	// The keys of the new settings.
+	Added []string `json:"added,omitempty"`
+	// The keys of the deleted settings.
+	Removed []string `json:"removed,omitempty"`
+	// The keys of the modified settings.
+	Changed []string `json:"changed,omitempty"`
+}
+
+// configJournal appends the accepted config changes to a JSON lines file.
+type configJournal struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	logger     Logger
+	sync.Mutex
+}
+
+func newConfigJournal(config JournalConfig, logger Logger) *configJournal {
+	if config.MaxSize <= 0 {
+		config.MaxSize = 10 * 1024 * 1024
+	}
+
+	if config.MaxBackups <= 0 {
+		config.MaxBackups = 3
+	}
+
+	return &configJournal{path: config.Path, maxSize: config.MaxSize, maxBackups: config.MaxBackups, logger: logger}
+}
+
+// record appends the summary of the change between the previous and the new config JSON.
+func (journal *configJournal) record(previous string, current string, eTag string, now time.Time) {
+	entry := diffConfigs(previous, current)
+	entry.Time = now
+	entry.ETag = eTag
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		journal.logger.Errorf("Writing the config journal failed: %s", err.Error())
+		return
+	}
+
+	if err := journal.append(append(line, '\n')); err != nil {
+		journal.logger.Errorf("Writing the config journal failed: %s", err.Error())
+	}
+}
+
+func (journal *configJournal) append(line []byte) error {
+	journal.Lock()
+	defer journal.Unlock()
+
+	if info, err := os.Stat(journal.path); err == nil && info.Size()+int64(len(line)) > journal.maxSize {
+		if err := journal.rotate(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(journal.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(line)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// rotate shifts the journal files, the oldest backup is deleted.
+func (journal *configJournal) rotate() error {
+	for i := journal.maxBackups - 1; i > 0; i-- {
+		from := journal.path + "." + strconv.Itoa(i)
+		if _, err := os.Stat(from); err == nil {
+			if err := os.Rename(from, journal.path+"."+strconv.Itoa(i+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return os.Rename(journal.path, journal.path+".1")
+}
+
+// diffConfigs lists the keys added, removed and changed by the new config JSON.
+func diffConfigs(previous string, current string) JournalEntry {
+	var previousSettings, currentSettings map[string]interface{}
+	_ = json.Unmarshal([]byte(previous), &previousSettings)
+	_ = json.Unmarshal([]byte(current), &currentSettings)
+	removePreferences(previousSettings)
+	removePreferences(currentSettings)
+
+	entry := JournalEntry{}
+	for key, setting := range currentSettings {
+		previousSetting, ok := previousSettings[key]
+		if !ok {
+			entry.Added = append(entry.Added, key)
+		} else if !reflect.DeepEqual(previousSetting, setting) {
+			entry.Changed = append(entry.Changed, key)
+		}
+	}
+
+	for key := range previousSettings {
+		if _, ok := currentSettings[key]; !ok {
+			entry.Removed = append(entry.Removed, key)
+		}
+	}
+
+	sort.Strings(entry.Added)
+	sort.Strings(entry.Removed)
+	sort.Strings(entry.Changed)
+	return entry
+}
@@ -0,0 +1,95 @@
+package configcat
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Journal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "journal.log")
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Journal: JournalConfig{Path: path}}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "a": { "v": 1 }, "b": { "v": 2 } }`, eTag: "e1"})
+	client.Refresh()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "a": { "v": 1 }, "b": { "v": 3 }, "c": { "v": 4 } }`, eTag: "e2"})
+	client.Refresh()
+	client.Refresh()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expecting 2 entries, got %d", len(entries))
+	}
+
+	if !reflect.DeepEqual(entries[0].Added, []string{"a", "b"}) {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+
+	if !reflect.DeepEqual(entries[1].Added, []string{"c"}) || !reflect.DeepEqual(entries[1].Changed, []string{"b"}) || entries[1].ETag != "e2" {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+}
+
+func TestConfigJournal_Rotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "journal.log")
+	journal := newConfigJournal(JournalConfig{Path: path, MaxSize: 100, MaxBackups: 2}, DefaultLogger(LogLevelError))
+	for i := 0; i < 10; i++ {
+		journal.record("{}", `{ "key": { "v": 1 } }`, "", time.Now())
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("Expecting %s to exist", name)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expecting at most 2 backups")
+	}
+}
+
+func TestDiffConfigs_Preferences(t *testing.T) {
+	entry := diffConfigs(`{ "a": { "v": 1 } }`, `{ "p": { "s": "salt" }, "a": { "v": 1 }, "b": { "v": 2 } }`)
+	if !reflect.DeepEqual(entry.Added, []string{"b"}) || len(entry.Removed) != 0 || len(entry.Changed) != 0 {
+		t.Errorf("Expecting only the added setting, got %+v", entry)
+	}
+
+	entry = diffConfigs(`{ "p": { "s": "salt" }, "a": { "v": 1 } }`, `{ "a": { "v": 1 } }`)
+	if len(entry.Added) != 0 || len(entry.Removed) != 0 || len(entry.Changed) != 0 {
+		t.Errorf("Expecting no changes, got %+v", entry)
+	}
+}
@@ -0,0 +1,47 @@
+package configcat
+
+import (
+	"time"
+)
+
+// ConfigMetadata identifies the configuration currently served by the Client, e.g. for logging which
+// flag snapshot served a request.
+type ConfigMetadata struct {
+	// The hex encoded SHA-256 hash of the config JSON, identifying the configuration regardless of where it came
+	// from. Empty if there is no configuration yet.
+	Hash string
+	// The ETag of the last downloaded configuration, empty if it wasn't downloaded by the client.
+	ETag string
+	// The time the configuration was downloaded, zero if it was read from the cache or the bootstrap config
+	// and wasn't downloaded by the client.
+	FetchTime time.Time
+	// The time the configuration was last confirmed to be up to date by a fetch (either fetched or
+	// not modified), zero if there was none.
+	LastSuccessfulFetchTime time.Time
+	// The number of settings in the configuration.
+	SettingCount int
+}
+
+// ConfigMetadata returns the metadata of the configuration currently served, without waiting for a fetch.
+// The config JSON of the v4 format carries no timestamp or version of its own, the Hash identifies it instead.
+func (client *Client) ConfigMetadata() ConfigMetadata {
+	fetchTime, eTag := client.statusRecorder.download()
+	metadata := ConfigMetadata{
+		ETag:                    eTag,
+		FetchTime:               fetchTime,
+		LastSuccessfulFetchTime: client.statusRecorder.status().LastSuccessfulFetchTime,
+	}
+
+	json, pinned := client.pinning.pinnedConfiguration()
+	if !pinned {
+		json = client.store.get()
+	}
+	if len(json) == 0 {
+		return metadata
+	}
+	if config, err := client.parser.load(json); err == nil {
+		metadata.Hash = config.hash
+		metadata.SettingCount = len(config.settings)
+	}
+	return metadata
+}
@@ -0,0 +1,36 @@
+package configcat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestClient_ConfigMetadata(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+
+	if metadata := client.ConfigMetadata(); metadata.Hash != "" || !metadata.FetchTime.IsZero() {
+		t.Errorf("Expecting empty metadata without a configuration, got %+v", metadata)
+	}
+
+	body := `{ "flag": { "v": true, "t": 0 }, "text": { "v": "value", "t": 1 } }`
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: body, eTag: "etag1"})
+	client.Refresh()
+
+	hash := sha256.Sum256([]byte(body))
+	metadata := client.ConfigMetadata()
+	if metadata.Hash != hex.EncodeToString(hash[:]) || metadata.ETag != "etag1" || metadata.SettingCount != 2 {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	}
+	if metadata.FetchTime.IsZero() || metadata.LastSuccessfulFetchTime != metadata.FetchTime {
+		t.Errorf("Expecting the fetch time to be recorded, got %+v", metadata)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: NotModified})
+	client.Refresh()
+	if revalidated := client.ConfigMetadata(); revalidated.FetchTime != metadata.FetchTime ||
+		revalidated.LastSuccessfulFetchTime.Before(metadata.FetchTime) || revalidated.Hash != metadata.Hash {
+		t.Errorf("Expecting the download to be kept, got %+v", revalidated)
+	}
+}
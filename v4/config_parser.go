@@ -1,8 +1,12 @@
 package configcat
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ParseError describes JSON parsing related errors.
@@ -15,10 +19,43 @@ func (p *ParseError) Error() string {
 	return p.msg
 }
 
+// MalformedSettingError describes a setting skipped because of its invalid structure.
+// The other settings of the configuration are served as usual.
+type MalformedSettingError struct {
+	// The key of the skipped setting.
+	Key string
+	// The reason the setting was skipped.
+	Reason string
+}
+
+// Error is the error message.
+func (e *MalformedSettingError) Error() string {
+	return "Setting " + e.Key + " is malformed and skipped: " + e.Reason + "."
+}
+
 // ConfigParser describes a JSON configuration parser.
 type ConfigParser struct {
 	evaluator *rolloutEvaluator
 	logger    Logger
+	// called with each malformed setting of a newly deserialized configuration
+	onMalformed func(err error)
+	// the last deserialized configuration, reused while the json body doesn't change
+	lastJson   string
+	lastConfig *parsedConfig
+	// optional lookup of the configurations already decoded by the fetch, nil if the json body must be decoded
+	decoded func(jsonBody string) *parsedConfig
+	sync.Mutex
+}
+
+// parsedConfig is a deserialized config JSON with its settings compiled for the evaluation.
+type parsedConfig struct {
+	root      map[string]interface{}
+	settings  map[string]*compiledSetting
+	malformed map[string]*MalformedSettingError
+	// the hex encoded SHA-256 hash of the json body
+	hash string
+	// the json body, shared by the clients of the same configuration, see internConfig
+	json string
 }
 
 func newParser(logger Logger) *ConfigParser {
@@ -54,48 +91,237 @@ func (parser *ConfigParser) GetAllKeys(jsonBody string) ([]string, error) {
 	return keys, nil
 }
 
+// KeyExists returns true if the given json config contains a setting identified by the key.
+func (parser *ConfigParser) KeyExists(jsonBody string, key string) (bool, error) {
+	rootNode, err := parser.deserialize(jsonBody)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := rootNode[key]
+	return ok, nil
+}
+
+// GetKeyMetadata retrieves the metadata of the setting identified by the key from the given json config.
+func (parser *ConfigParser) GetKeyMetadata(jsonBody string, key string) (*KeyMetadata, error) {
+	rootNode, err := parser.deserialize(jsonBody)
+	if err != nil {
+		return nil, err
+	}
+
+	node, ok := rootNode[key].(map[string]interface{})
+	if !ok {
+		return nil, &ParseError{"Value not found for key " + key + "."}
+	}
+
+	return newKeyMetadata(key, node), nil
+}
+
 func (parser *ConfigParser) parse(jsonBody string, key string, user *User) (interface{}, error) {
+	result, err := parser.parseVariation(jsonBody, key, user)
+	return result.value, err
+}
+
+// parseVariation is like parse, but also returns the variation ID of the evaluated value and the missing user attributes.
+func (parser *ConfigParser) parseVariation(jsonBody string, key string, user *User) (evaluation, error) {
 	if len(key) == 0 {
 		panic("Key cannot be empty")
 	}
 
-	rootNode, err := parser.deserialize(jsonBody)
+	var start time.Time
+	if parser.evaluator.timing {
+		start = time.Now()
+	}
+	config, err := parser.load(jsonBody)
 	if err != nil {
-		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+		return evaluation{}, &ParseError{"JSON parsing failed. " + err.Error() + "."}
 	}
+	var parsing time.Duration
+	if parser.evaluator.timing {
+		parsing = time.Since(start)
+	}
+
+	setting := config.settings[key]
+	if setting == nil {
+		if err, ok := config.malformed[key]; ok {
+			return evaluation{}, err
+		}
 
-	node := rootNode[key]
-	if node == nil {
-		keys := make([]string, len(rootNode))
+		keys := make([]string, len(config.settings))
 		i := 0
-		for k := range rootNode {
+		for k := range config.settings {
 			keys[i] = k
 			i++
 		}
 
-		return nil, &ParseError{"Value not found for key " + key +
+		return evaluation{}, &ParseError{"Value not found for key " + key +
 			". Here are the available keys: " + strings.Join(keys, ", ")}
 	}
 
-	parsed := parser.evaluator.evaluate(node, key, user)
-	if parsed == nil {
-		return nil, &ParseError{"Null evaluated for key " + key + "."}
+	if user == nil && setting.targeted && parser.evaluator.strictTargeting {
+		return evaluation{}, &UserRequiredError{Key: key}
+	}
+
+	result := parser.evaluator.evaluate(setting, key, user)
+	result.timing.Parse = parsing
+	if result.value == nil {
+		return evaluation{}, &ParseError{"Null evaluated for key " + key + "."}
 	}
 
-	return parsed, nil
+	return result, nil
 }
 
 func (parser *ConfigParser) deserialize(jsonBody string) (map[string]interface{}, error) {
-	var root interface{}
-	err := json.Unmarshal([]byte(jsonBody), &root)
+	config, err := parser.load(jsonBody)
 	if err != nil {
 		return nil, err
 	}
 
+	return config.root, nil
+}
+
+// load deserializes the json config and compiles its settings, the result is reused while the json body doesn't change.
+func (parser *ConfigParser) load(jsonBody string) (*parsedConfig, error) {
+	parser.Lock()
+	if parser.lastConfig != nil && parser.lastJson == jsonBody {
+		config := parser.lastConfig
+		parser.Unlock()
+		return config, nil
+	}
+	parser.Unlock()
+
+	var config *parsedConfig
+	if parser.decoded != nil {
+		config = parser.decoded(jsonBody)
+	}
+	if config == nil {
+		config = lookupConfig(hashJson(jsonBody))
+	}
+	if config == nil {
+		var root interface{}
+		err := json.Unmarshal([]byte(jsonBody), &root)
+		if err != nil {
+			return nil, err
+		}
+
+		if config, err = newParsedConfig(root, jsonBody); err != nil {
+			return nil, err
+		}
+	}
+	parser.Lock()
+	parser.lastJson = config.json
+	parser.lastConfig = config
+	parser.Unlock()
+
+	for _, err := range config.malformed {
+		parser.logger.Errorln(err.Error())
+		if parser.onMalformed != nil {
+			parser.onMalformed(err)
+		}
+	}
+
+	return config, nil
+}
+
+// newParsedConfig compiles the settings of the decoded config JSON and interns the configuration.
+func newParsedConfig(root interface{}, jsonBody string) (*parsedConfig, error) {
 	rootNode, ok := root.(map[string]interface{})
 	if !ok {
 		return nil, &ParseError{"JSON mapping failed, json: " + jsonBody}
 	}
 
-	return rootNode, nil
+	salt := removePreferences(rootNode)
+	malformed := removeMalformedSettings(rootNode)
+	return internConfig(&parsedConfig{root: rootNode, settings: compileSettings(rootNode, salt), malformed: malformed,
+		hash: hashJson(jsonBody), json: jsonBody}), nil
+}
+
+// hashJson returns the hex encoded SHA-256 hash of the json body.
+func hashJson(jsonBody string) string {
+	sum := sha256.Sum256([]byte(jsonBody))
+	return hex.EncodeToString(sum[:])
+}
+
+// preferencesKey is the root property of the config JSON holding the preferences of the config, told apart
+// from a setting of the same key by the missing value.
+const preferencesKey = "p"
+
+// removePreferences removes the preferences from the root node and returns the salt of the hashed comparators.
+func removePreferences(rootNode map[string]interface{}) string {
+	node, ok := rootNode[preferencesKey].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if _, isSetting := node["v"]; isSetting {
+		return ""
+	}
+	salt, ok := node["s"].(string)
+	if !ok {
+		return ""
+	}
+
+	delete(rootNode, preferencesKey)
+	return salt
+}
+
+// removeMalformedSettings removes the settings with invalid structure from the root node, so a single bad
+// setting doesn't fail the evaluation of the others, and returns the reasons of the removals.
+func removeMalformedSettings(rootNode map[string]interface{}) map[string]*MalformedSettingError {
+	var malformed map[string]*MalformedSettingError
+	for key, node := range rootNode {
+		reason := malformedReason(node)
+		if len(reason) == 0 {
+			continue
+		}
+
+		if malformed == nil {
+			malformed = make(map[string]*MalformedSettingError)
+		}
+		malformed[key] = &MalformedSettingError{Key: key, Reason: reason}
+		delete(rootNode, key)
+	}
+
+	return malformed
+}
+
+// malformedReason describes why the setting node can't be evaluated, or returns an empty string if it's valid.
+func malformedReason(value interface{}) string {
+	node, ok := value.(map[string]interface{})
+	if !ok {
+		return "the setting is not an object"
+	}
+
+	if !isScalar(node["v"]) {
+		return "the setting has no valid value"
+	}
+
+	for _, property := range []string{"r", "p"} {
+		rules, ok := node[property]
+		if !ok || rules == nil {
+			continue
+		}
+
+		items, ok := rules.([]interface{})
+		if !ok {
+			return "the " + property + " property is not an array"
+		}
+
+		for _, item := range items {
+			rule, ok := item.(map[string]interface{})
+			if !ok || !isScalar(rule["v"]) {
+				return "the " + property + " property contains an invalid item"
+			}
+		}
+	}
+
+	return ""
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case bool, string, float64:
+		return true
+	}
+
+	return false
 }
@@ -66,3 +66,65 @@ func TestConfigParser_EmptyNode(t *testing.T) {
 
 	t.Log(err.Error())
 }
+
+func TestConfigParser_GetKeyMetadata(t *testing.T) {
+	jsonBody := "{ \"key\": { \"v\": 12, \"i\": \"id0\", \"t\": 2, " +
+		"\"p\": [{ \"v\": 1, \"p\": 50, \"i\": \"id1\" }, { \"v\": 2, \"p\": 50, \"i\": \"id2\" }], " +
+		"\"r\": [{ \"v\": 3, \"a\": \"Email\", \"t\": 2, \"c\": \"@example.com\", \"i\": \"id3\" }] }}"
+	parser := newParser(DefaultLogger(LogLevelWarn))
+
+	exists, err := parser.KeyExists(jsonBody, "key")
+	if err != nil || !exists {
+		t.Error("Expecting existing key")
+	}
+
+	exists, err = parser.KeyExists(jsonBody, "nonExisting")
+	if err != nil || exists {
+		t.Error("Expecting non existing key")
+	}
+
+	metadata, err := parser.GetKeyMetadata(jsonBody, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Type != IntSetting || metadata.VariationId != "id0" ||
+		metadata.RolloutRuleCount != 1 || metadata.PercentageRuleCount != 2 || len(metadata.VariationIds) != 4 {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	}
+
+	_, err = parser.GetKeyMetadata(jsonBody, "nonExisting")
+	if err == nil {
+		t.Error("Expecting error for non existing key")
+	}
+}
+
+func TestConfigParser_MalformedSetting(t *testing.T) {
+	var reported []error
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:    ManualPoll(),
+		OnError: func(err error) { reported = append(reported, err) },
+	}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "good": { "v": "value" }, "bad": { "v": "x", "r": "oops" } }`})
+	client.Refresh()
+
+	if value := client.GetValue("good", ""); value != "value" {
+		t.Errorf("Expecting the good setting to be served, got %v", value)
+	}
+
+	details := client.GetValueDetails("bad", "default", nil)
+	malformed, ok := details.Error.(*MalformedSettingError)
+	if details.Value != "default" || !ok || malformed.Key != "bad" {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+
+	if len(reported) < 2 || reported[0] != malformed {
+		t.Errorf("Expecting the malformed setting reported first, got %v", reported)
+	}
+
+	if keys, _ := client.GetAllKeys(); len(keys) != 1 || keys[0] != "good" {
+		t.Errorf("Expecting only the good key, got %v", keys)
+	}
+}
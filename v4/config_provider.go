@@ -0,0 +1,150 @@
+package configcat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConfigResponse is the result of a ConfigProvider download.
+type ConfigResponse struct {
+	// The config JSON, it's ignored when NotModified is true.
+	Body string
+	// The entity tag of the config JSON, passed back to the next download.
+	ETag string
+	// True if the config JSON is unchanged since the download identified by the passed entity tag.
+	NotModified bool
+	// The signature or checksum of the config JSON checked by ClientConfig.Integrity, if it's enabled.
+	Signature string
+}
+
+// ConfigProvider is the source of the config JSON, it replaces the ConfigCat CDN when set in the ClientConfig.
+type ConfigProvider interface {
+	// GetConfig downloads the config JSON. The eTag is the entity tag of the previous download,
+	// empty on the first call.
+	GetConfig(ctx context.Context, eTag string) (ConfigResponse, error)
+}
+
+// sizeLimitedProvider is implemented by the ConfigProviders of the SDK which stop reading the downloads at the
+// MaxConfigSize of the client.
+type sizeLimitedProvider interface {
+	withMaxConfigSize(maxConfigSize int64) ConfigProvider
+}
+
+// customConfigProvider adapts a ConfigProvider to the refresh policies.
+type customConfigProvider struct {
+	provider      ConfigProvider
+	eTag          string
+	fetchTimeout  time.Duration
+	maxConfigSize int64
+	verify        ConfigVerifier
+	logger        Logger
+	onError       func(err error)
+	inFlight      *asyncResult
+	// if it's true then the downloads run on the calling goroutine
+	inline bool
+	sync.Mutex
+}
+
+func newCustomConfigProvider(provider ConfigProvider, config ClientConfig) *customConfigProvider {
+	if limited, ok := provider.(sizeLimitedProvider); ok {
+		provider = limited.withMaxConfigSize(config.MaxConfigSize)
+	}
+	return &customConfigProvider{provider: provider,
+		fetchTimeout:  config.FetchTimeout,
+		maxConfigSize: config.MaxConfigSize,
+		verify:        config.Integrity.Verify,
+		logger:        config.Logger,
+		onError:       config.OnError,
+		inline:        config.NoBackgroundGoroutines}
+}
+
+// getConfigurationAsync downloads the config JSON with the ConfigProvider.
+// Concurrent calls share the result of the download already in flight.
+func (adapter *customConfigProvider) getConfigurationAsync() *asyncResult {
+	adapter.Lock()
+	if adapter.inFlight != nil {
+		inFlight := adapter.inFlight
+		adapter.Unlock()
+		adapter.logger.Debugln("Config fetch is already in progress, joining.")
+		return inFlight
+	}
+
+	result := newAsyncResult()
+	adapter.inFlight = result
+	adapter.Unlock()
+
+	fetch := func() {
+		response, err := adapter.safeFetch()
+
+		adapter.Lock()
+		adapter.inFlight = nil
+		adapter.Unlock()
+
+		result.completeWith(response, err)
+	}
+
+	if adapter.inline {
+		fetch()
+	} else {
+		go fetch()
+	}
+	return result
+}
+
+// safeFetch is like fetch but converts a panic of the ConfigProvider into an error.
+func (adapter *customConfigProvider) safeFetch() (response fetchResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := newPanicError(r)
+			logEvent(adapter.logger, LogLevelError, LogEventFetch, logFields{status: Failure.String()},
+				"Config fetch failed: %s.\n%s", panicErr.Error(), panicErr.Stack)
+			if adapter.onError != nil {
+				adapter.onError(panicErr)
+			}
+			response, err = fetchResponse{status: Failure}, panicErr
+		}
+	}()
+
+	return adapter.fetch()
+}
+
+func (adapter *customConfigProvider) fetch() (fetchResponse, error) {
+	ctx := context.Background()
+	if adapter.fetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, adapter.fetchTimeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	response, err := adapter.provider.GetConfig(ctx, adapter.eTag)
+	if err != nil {
+		logFetch(adapter.logger, LogLevelError, started, Failure, "", "Config fetch failed: %s.", err.Error())
+		return fetchResponse{status: Failure}, err
+	}
+
+	if response.NotModified {
+		logFetch(adapter.logger, LogLevelDebug, started, NotModified, adapter.eTag, "Config fetch succeeded: not modified.")
+		return fetchResponse{status: NotModified, eTag: adapter.eTag}, nil
+	}
+
+	if int64(len(response.Body)) > adapter.maxConfigSize {
+		err = fmt.Errorf("config size exceeds the MaxConfigSize limit of %d bytes", adapter.maxConfigSize)
+	} else if !json.Valid([]byte(response.Body)) {
+		err = fmt.Errorf("config is not a valid JSON")
+	} else {
+		err = verifyIntegrity(adapter.verify, response.Body, response.Signature)
+	}
+
+	if err != nil {
+		logFetch(adapter.logger, LogLevelError, started, Failure, "", "Config fetch failed: %s.", err.Error())
+		return fetchResponse{status: Failure}, err
+	}
+
+	adapter.eTag = response.ETag
+	logFetch(adapter.logger, LogLevelDebug, started, Fetched, adapter.eTag, "Config fetch succeeded: new config fetched.")
+	return fetchResponse{status: Fetched, body: response.Body, eTag: response.ETag}, nil
+}
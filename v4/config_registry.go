@@ -0,0 +1,62 @@
+//go:build go1.24
+// +build go1.24
+
+package configcat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// configRegistry interns the parsed configurations by the hash of their config JSON, so the clients of the same
+// configuration in a process (e.g. plugins or tests creating many clients for the same SDK key) share a single
+// copy of the config JSON and of its compiled settings. The entries are weak, they don't keep the configurations
+// alive, and they are removed once the configurations are garbage collected.
+var configRegistry = struct {
+	entries map[string]weak.Pointer[parsedConfig]
+	sync.Mutex
+}{entries: map[string]weak.Pointer[parsedConfig]{}}
+
+// lookupConfig returns the live configuration with the given hash, or nil if there is none.
+func lookupConfig(hash string) *parsedConfig {
+	configRegistry.Lock()
+	defer configRegistry.Unlock()
+	return configRegistry.entries[hash].Value()
+}
+
+// internConfig returns the live configuration with the hash of the given one, or registers the given one.
+func internConfig(config *parsedConfig) *parsedConfig {
+	configRegistry.Lock()
+	defer configRegistry.Unlock()
+	if existing := configRegistry.entries[config.hash].Value(); existing != nil {
+		return existing
+	}
+
+	configRegistry.entries[config.hash] = weak.Make(config)
+	runtime.AddCleanup(config, removeConfig, config.hash)
+	return config
+}
+
+// internJson returns the config JSON of the live configuration with the same content, or the given one if there is none.
+func internJson(json string) string {
+	if len(json) == 0 {
+		return json
+	}
+
+	sum := sha256.Sum256([]byte(json))
+	if config := lookupConfig(hex.EncodeToString(sum[:])); config != nil {
+		return config.json
+	}
+	return json
+}
+
+func removeConfig(hash string) {
+	configRegistry.Lock()
+	defer configRegistry.Unlock()
+	if entry, ok := configRegistry.entries[hash]; ok && entry.Value() == nil {
+		delete(configRegistry.entries, hash)
+	}
+}
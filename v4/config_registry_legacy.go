@@ -0,0 +1,18 @@
+//go:build !go1.24
+// +build !go1.24
+
+package configcat
+
+// The configurations are only interned with weak references, which need Go 1.24, see config_registry.go.
+
+func lookupConfig(hash string) *parsedConfig {
+	return nil
+}
+
+func internConfig(config *parsedConfig) *parsedConfig {
+	return config
+}
+
+func internJson(json string) string {
+	return json
+}
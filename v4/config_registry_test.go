@@ -0,0 +1,55 @@
+//go:build go1.24
+// +build go1.24
+
+package configcat
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestConfigRegistry_Interning(t *testing.T) {
+	body := `{ "interned": { "v": true, "t": 0 } }`
+	// separately allocated copies of the same config JSON, as downloaded by separate clients
+	first, second := strings.Clone(body), strings.Clone(body)
+
+	logger := DefaultLogger(LogLevelFatal)
+	firstConfig, err := newParser(logger).load(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondConfig, _ := newParser(logger).load(second)
+	if firstConfig != secondConfig {
+		t.Error("Expecting the parsers to share the configuration")
+	}
+
+	store := newConfigStore(logger, newInMemoryConfigCache())
+	store.set(strings.Clone(body))
+	if unsafe.StringData(store.get()) != unsafe.StringData(first) {
+		t.Error("Expecting the store to share the config JSON")
+	}
+	runtime.KeepAlive(firstConfig)
+}
+
+func TestConfigRegistry_WeakEntries(t *testing.T) {
+	config := internConfig(&parsedConfig{hash: "weak-entry", json: "{}"})
+	if lookupConfig("weak-entry") != config {
+		t.Fatal("Expecting the registered configuration")
+	}
+	config = nil
+
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		configRegistry.Lock()
+		_, ok := configRegistry.entries["weak-entry"]
+		configRegistry.Unlock()
+		if !ok {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Error("Expecting the entry of the collected configuration to be removed")
+}
@@ -0,0 +1,217 @@
+package configcat
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// ConfigSource is a source of the configuration in the ClientConfig.Sources chain, created by OverrideSource,
+// CacheSource, CDNSource, BootstrapSource or ProviderSource.
+type ConfigSource interface {
+	// Name identifies the source in ClientStatus.ConfigSource and EvaluationDetails.ConfigSource.
+	Name() string
+	// provider creates the configProvider of the source for a client.
+	provider(apiKey string, config ClientConfig) configProvider
+}
+
+// errNoSourceConfig is returned by the built-in sources which have no configuration.
+var errNoSourceConfig = errors.New("the source has no configuration")
+
+type namedSource struct {
+	name   string
+	create func(apiKey string, config ClientConfig) configProvider
+	// the cache read by CacheSource, which mustn't be the cache of the client
+	cache ConfigCache
+}
+
+func (source *namedSource) Name() string {
+	return source.name
+}
+
+func (source *namedSource) provider(apiKey string, config ClientConfig) configProvider {
+	return source.create(apiKey, config)
+}
+
+// OverrideSource returns a ConfigSource named "override" serving the given config JSON, e.g. a local file
+// overriding the remote configuration during development. An empty config JSON falls through to the next source.
+func OverrideSource(config []byte) ConfigSource {
+	return &namedSource{name: "override", create: func(string, ClientConfig) configProvider {
+		return &staticSource{body: string(config)}
+	}}
+}
+
+// BootstrapSource returns a ConfigSource named "bootstrap" serving the given config JSON, e.g. one embedded with
+// go:embed as the last fallback of the chain. It's only served until the client has a configuration, afterwards
+// the failures of the other sources keep the last configuration and are reported like failed fetches.
+func BootstrapSource(config []byte) ConfigSource {
+	return &namedSource{name: "bootstrap", create: func(string, ClientConfig) configProvider {
+		return &staticSource{body: string(config), fallback: true}
+	}}
+}
+
+// CacheSource returns a ConfigSource named "cache" serving the configuration found in the cache, e.g. an external
+// cache shared with the other instances. An empty cache falls through to the next source. The cache can't be
+// the ClientConfig.Cache, as the client writes each served configuration there, so the sources after the cache
+// source would never be consulted again.
+func CacheSource(cache ConfigCache) ConfigSource {
+	return &namedSource{name: "cache", cache: cache, create: func(string, ClientConfig) configProvider {
+		return &cacheSource{cache: cache}
+	}}
+}
+
+// CDNSource returns a ConfigSource named "cdn" downloading the configuration from the ConfigCat CDN (or BaseUrl)
+// with the SDK key and the HTTP settings of the client.
+func CDNSource() ConfigSource {
+	return &namedSource{name: "cdn", create: func(apiKey string, config ClientConfig) configProvider {
+		return newConfigFetcher(apiKey, config)
+	}}
+}
+
+// ProviderSource returns a ConfigSource with the given name downloading the configuration with the ConfigProvider,
+// like the ClientConfig.ConfigProvider.
+func ProviderSource(name string, provider ConfigProvider) ConfigSource {
+	return &namedSource{name: name, create: func(apiKey string, config ClientConfig) configProvider {
+		return newCustomConfigProvider(provider, config)
+	}}
+}
+
+// staticSource is the configProvider of a source serving a fixed config JSON.
+type staticSource struct {
+	body string
+	// if it's true then the source is skipped when the client already has a configuration
+	fallback bool
+}
+
+func (source *staticSource) getConfigurationAsync() *asyncResult {
+	if len(source.body) == 0 {
+		return asFailedAsyncResult(errNoSourceConfig)
+	}
+	return asCompletedAsyncResult(fetchResponse{status: Fetched, body: source.body})
+}
+
+// asFailedAsyncResult returns an async result completed with the error.
+func asFailedAsyncResult(err error) *asyncResult {
+	result := newAsyncResult()
+	result.completeWithError(err)
+	return result
+}
+
+// cacheSource is the configProvider of a source reading a ConfigCache.
+type cacheSource struct {
+	cache ConfigCache
+}
+
+func (source *cacheSource) getConfigurationAsync() *asyncResult {
+	body, err := source.cache.Get()
+	if err == nil && len(body) == 0 {
+		err = errNoSourceConfig
+	}
+	if err != nil {
+		return asFailedAsyncResult(err)
+	}
+	return asCompletedAsyncResult(fetchResponse{status: Fetched, body: body})
+}
+
+// sourceChain is a configProvider trying the sources in priority order on each refresh, the configuration of the
+// first source having one is served. The name of the source is recorded with the configuration in the store.
+type sourceChain struct {
+	names     []string
+	providers []configProvider
+	logger    Logger
+	// the index of the last source which served a configuration, -1 until a source served one
+	current int32
+	// the last configuration served by each source, so a not modified response of a source which wasn't
+	// the effective one can be served
+	bodies   []string
+	inFlight *asyncResult
+	// if it's true then the sources are tried on the calling goroutine
+	inline bool
+	// the store of the client, the fallback sources are skipped when it has a configuration
+	store *configStore
+	sync.Mutex
+}
+
+func newSourceChain(apiKey string, sources []ConfigSource, config ClientConfig) (*sourceChain, error) {
+	chain := &sourceChain{logger: config.Logger, current: -1, bodies: make([]string, len(sources)),
+		inline: config.NoBackgroundGoroutines}
+	for _, source := range sources {
+		if named, ok := source.(*namedSource); ok && named.cache != nil && sameCache(named.cache, config.Cache) {
+			return nil, errors.New("the cache of the cache source can't be the cache of the client")
+		}
+		chain.names = append(chain.names, source.Name())
+		chain.providers = append(chain.providers, source.provider(apiKey, config))
+	}
+	return chain, nil
+}
+
+// sameCache returns true if the caches are the same, the caches of uncomparable types are never the same.
+func sameCache(first ConfigCache, second ConfigCache) bool {
+	return reflect.TypeOf(first) == reflect.TypeOf(second) && reflect.TypeOf(first).Comparable() && first == second
+}
+
+// getConfigurationAsync tries the sources in their order. Concurrent calls share the result in flight.
+func (chain *sourceChain) getConfigurationAsync() *asyncResult {
+	chain.Lock()
+	if chain.inFlight != nil {
+		inFlight := chain.inFlight
+		chain.Unlock()
+		return inFlight
+	}
+	result := newAsyncResult()
+	chain.inFlight = result
+	chain.Unlock()
+
+	try := func() {
+		response, err := chain.try()
+
+		chain.Lock()
+		chain.inFlight = nil
+		chain.Unlock()
+
+		result.completeWith(response, err)
+	}
+
+	if chain.inline {
+		try()
+	} else {
+		go try()
+	}
+	return result
+}
+
+// try returns the configuration of the first source having one, or Failure with the error of the last failed
+// source. The fallback sources are skipped when the store has a configuration, so a failure doesn't overwrite it.
+func (chain *sourceChain) try() (fetchResponse, error) {
+	lastErr := errNoSourceConfig
+	for i, provider := range chain.providers {
+		if static, ok := provider.(*staticSource); ok && static.fallback && chain.store != nil && !chain.store.empty() {
+			continue
+		}
+		value, err := provider.getConfigurationAsync().getWithError()
+		response := asFetchResponse(value)
+		if err == nil && response.isNotModified() && len(chain.bodies[i]) > 0 {
+			if atomic.LoadInt32(&chain.current) != int32(i) {
+				response = fetchResponse{status: Fetched, body: chain.bodies[i], eTag: response.eTag}
+			}
+		} else if err == nil && response.isFetched() && len(response.body) > 0 {
+			chain.bodies[i] = response.body
+		} else {
+			if err == nil {
+				err = errNoSourceConfig
+			}
+			logEvent(chain.logger, LogLevelDebug, LogEventFetch, logFields{status: Failure.String()}, "The config source %s failed: %s.", chain.names[i], err.Error())
+			lastErr = err
+			continue
+		}
+
+		if previous := atomic.SwapInt32(&chain.current, int32(i)); previous != int32(i) {
+			logEvent(chain.logger, LogLevelInfo, LogEventFetch, logFields{}, "The configuration is served from the %s source.", chain.names[i])
+		}
+		response.source = chain.names[i]
+		return response, nil
+	}
+
+	return fetchResponse{status: Failure}, lastErr
+}
@@ -0,0 +1,143 @@
+package configcat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// sourceTestProvider is a ConfigProvider answering not modified after its first download when notModified is set.
+type sourceTestProvider struct {
+	body        string
+	notModified bool
+}
+
+func (provider *sourceTestProvider) GetConfig(ctx context.Context, eTag string) (ConfigResponse, error) {
+	if provider.notModified && len(eTag) > 0 {
+		return ConfigResponse{NotModified: true}, nil
+	}
+	return ConfigResponse{Body: provider.body, ETag: "etag"}, nil
+}
+
+func TestClient_Sources(t *testing.T) {
+	remote := &sourceTestProvider{body: fmt.Sprintf(jsonFormat, "key", `"remote"`)}
+	cache := newInMemoryConfigCache()
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Sources: []ConfigSource{
+		OverrideSource(nil),
+		CacheSource(cache),
+		ProviderSource("remote", remote),
+		BootstrapSource([]byte(fmt.Sprintf(jsonFormat, "key", `"bootstrap"`))),
+	}})
+	defer client.Close()
+
+	expectSource := func(value string, source string) {
+		t.Helper()
+		client.Refresh()
+		details := client.GetValueDetails("key", "", nil)
+		if details.Value != value || details.ConfigSource != source || client.Status().ConfigSource != source {
+			t.Errorf("Expecting %s from %s, got %v from %s", value, source, details.Value, details.ConfigSource)
+		}
+	}
+
+	expectSource("remote", "remote")
+
+	remote.body = ""
+	if err := client.RefreshWithContext(context.Background()); err == nil {
+		t.Error("Expecting the failure of every source to be reported")
+	}
+	expectSource("remote", "remote")
+
+	cache.Set(fmt.Sprintf(jsonFormat, "key", `"cached"`))
+	expectSource("cached", "cache")
+
+	cache.Set("")
+	remote.body, remote.notModified = fmt.Sprintf(jsonFormat, "key", `"changed"`), true
+	expectSource("remote", "remote")
+}
+
+func TestClient_SourcesOverride(t *testing.T) {
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Sources: []ConfigSource{
+		OverrideSource([]byte(fmt.Sprintf(jsonFormat, "key", `"override"`))),
+		ProviderSource("remote", &sourceTestProvider{body: fmt.Sprintf(jsonFormat, "key", `"remote"`)}),
+	}})
+	defer client.Close()
+	client.Refresh()
+
+	if value := client.GetValue("key", ""); value != "override" || client.Status().ConfigSource != "override" {
+		t.Errorf("Expecting the override to win, got %v", value)
+	}
+}
+
+func TestClient_SourcesBootstrapUntilConfig(t *testing.T) {
+	remote := &sourceTestProvider{}
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Sources: []ConfigSource{
+		ProviderSource("remote", remote),
+		BootstrapSource([]byte(fmt.Sprintf(jsonFormat, "key", `"bootstrap"`))),
+	}})
+	defer client.Close()
+
+	client.Refresh()
+	if value := client.GetValue("key", ""); value != "bootstrap" || client.Status().ConfigSource != "bootstrap" {
+		t.Errorf("Expecting the bootstrap config while there is no other, got %v", value)
+	}
+
+	remote.body = fmt.Sprintf(jsonFormat, "key", `"remote"`)
+	client.Refresh()
+	remote.body = ""
+	client.Refresh()
+	if value := client.GetValue("key", ""); value != "remote" || client.Status().ConfigSource != "remote" {
+		t.Errorf("Expecting the last downloaded config to be kept, got %v", value)
+	}
+}
+
+func TestClient_SourcesSharedCache(t *testing.T) {
+	cache := newInMemoryConfigCache()
+	_, err := NewCustomClientWithError("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: cache, Sources: []ConfigSource{
+		CacheSource(cache),
+		CDNSource(),
+	}})
+	if err == nil {
+		t.Error("Expecting the cache of the client to be rejected as a cache source")
+	}
+}
+
+func TestClient_SourcesRecordedWithTheStoredConfig(t *testing.T) {
+	remote := &sourceTestProvider{}
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Logger: DefaultLogger(LogLevelFatal),
+		AcceptConfig: func(candidate *Evaluator) error {
+			if value, _ := candidate.Evaluate("key", nil, nil); value == "rejected" {
+				return errors.New("fake rejection")
+			}
+			return nil
+		},
+		Sources: []ConfigSource{
+			ProviderSource("remote", remote),
+			BootstrapSource([]byte(fmt.Sprintf(jsonFormat, "key", `"bootstrap"`))),
+		}})
+	defer client.Close()
+
+	client.Refresh()
+	remote.body = fmt.Sprintf(jsonFormat, "key", `"rejected"`)
+	client.Refresh()
+	details := client.GetValueDetails("key", "", nil)
+	if details.Value != "bootstrap" || details.ConfigSource != "bootstrap" || client.Status().ConfigSource != "bootstrap" {
+		t.Errorf("Expecting the source of the served config after the rejection, got %v from %s", details.Value, details.ConfigSource)
+	}
+
+	if err := client.Pin(); err != nil {
+		t.Fatal(err)
+	}
+	remote.body = fmt.Sprintf(jsonFormat, "key", `"remote"`)
+	client.Refresh()
+	details = client.GetValueDetails("key", "", nil)
+	if details.Value != "bootstrap" || details.ConfigSource != "bootstrap" || client.Status().ConfigSource != "bootstrap" {
+		t.Errorf("Expecting the source of the pinned config, got %v from %s", details.Value, details.ConfigSource)
+	}
+
+	client.Unpin()
+	details = client.GetValueDetails("key", "", nil)
+	if details.Value != "remote" || details.ConfigSource != "remote" || client.Status().ConfigSource != "remote" {
+		t.Errorf("Expecting the source of the current config after unpinning, got %v from %s", details.Value, details.ConfigSource)
+	}
+}
@@ -0,0 +1,214 @@
+package configcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// ValidationFindingKind classifies the problems found by ValidateConfig.
+type ValidationFindingKind int
+
+const (
+	// InvalidSetting indicates a setting without a valid value or type.
+	InvalidSetting ValidationFindingKind = iota
+	// UnknownComparator indicates a targeting rule with a missing or unknown comparator.
+	UnknownComparator
+	// InvalidComparisonValue indicates a targeting rule whose comparison value can't be parsed by its comparator,
+	// e.g. an invalid semantic version.
+	InvalidComparisonValue
+	// InvalidPercentages indicates percentage options not summing to 100 or having a negative percentage.
+	InvalidPercentages
+	// ValueTypeMismatch indicates a rule serving a value of a different type than the setting.
+	ValueTypeMismatch
+)
+
+// String returns the name of the finding kind.
+func (kind ValidationFindingKind) String() string {
+	switch kind {
+	case InvalidSetting:
+		return "invalid setting"
+	case UnknownComparator:
+		return "unknown comparator"
+	case InvalidComparisonValue:
+		return "invalid comparison value"
+	case InvalidPercentages:
+		return "invalid percentages"
+	case ValueTypeMismatch:
+		return "value type mismatch"
+	}
+
+	return "unknown"
+}
+
+// ValidationFinding describes a problem of a config JSON found by ValidateConfig.
+type ValidationFinding struct {
+	// The key of the setting the problem was found in.
+	Key string
+	// The location of the problem in the setting, e.g. "r[2]" for the third targeting rule,
+	// "p" for the percentage options or empty for the setting itself.
+	Path string
+	// The kind of the problem.
+	Kind ValidationFindingKind
+	// The description of the problem.
+	Message string
+}
+
+// String returns the finding in the "key path: kind: message" format.
+func (finding ValidationFinding) String() string {
+	location := finding.Key
+	if len(finding.Path) > 0 {
+		location += " " + finding.Path
+	}
+
+	return location + ": " + finding.Kind.String() + ": " + finding.Message
+}
+
+// ValidateConfig checks the given config JSON (e.g. an exported configuration in a CI pipeline) and returns
+// the problems which would make the evaluations silently fall through: unknown comparators, comparison values
+// the comparator can't parse, percentages not summing to 100 and values not matching the type of the setting.
+// The findings are ordered by key. An error is returned only when the body isn't a valid config JSON.
+func ValidateConfig(body []byte) ([]ValidationFinding, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+	removePreferences(root)
+
+	keys := make([]string, 0, len(root))
+	for key := range root {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var findings []ValidationFinding
+	for _, key := range keys {
+		findings = validateSetting(findings, key, root[key])
+	}
+
+	return findings, nil
+}
+
+func validateSetting(findings []ValidationFinding, key string, value interface{}) []ValidationFinding {
+	node, ok := value.(map[string]interface{})
+	if !ok {
+		return append(findings, ValidationFinding{key, "", InvalidSetting, "the setting is not an object"})
+	}
+
+	settingType := settingTypeOf(node)
+	if settingType < BoolSetting || settingType > FloatSetting {
+		settingType = UnknownSetting
+	}
+	if node["v"] == nil {
+		findings = append(findings, ValidationFinding{key, "", InvalidSetting, "the setting has no value"})
+	} else if settingType == UnknownSetting {
+		findings = append(findings, ValidationFinding{key, "", InvalidSetting, "the setting has an unknown type"})
+	} else if !isValueOfType(node["v"], settingType) {
+		findings = append(findings, ValidationFinding{key, "", ValueTypeMismatch,
+			fmt.Sprintf("the value %v is not %s", node["v"], settingType)})
+	}
+
+	rules, _ := node["r"].([]interface{})
+	for i, r := range rules {
+		path := "r[" + strconv.Itoa(i) + "]"
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			findings = append(findings, ValidationFinding{key, path, InvalidSetting, "the rule is not an object"})
+			continue
+		}
+
+		findings = validateRule(findings, key, path, rule)
+		if settingType != UnknownSetting && !isValueOfType(rule["v"], settingType) {
+			findings = append(findings, ValidationFinding{key, path, ValueTypeMismatch,
+				fmt.Sprintf("the rule value %v is not %s", rule["v"], settingType)})
+		}
+	}
+
+	percentages, _ := node["p"].([]interface{})
+	if len(percentages) == 0 {
+		return findings
+	}
+
+	sum := 0.0
+	for i, p := range percentages {
+		path := "p[" + strconv.Itoa(i) + "]"
+		option, _ := p.(map[string]interface{})
+		percentage, ok := option["p"].(float64)
+		if !ok || percentage < 0 {
+			findings = append(findings, ValidationFinding{key, path, InvalidPercentages,
+				fmt.Sprintf("invalid percentage %v", option["p"])})
+			continue
+		}
+
+		sum += percentage
+		if settingType != UnknownSetting && !isValueOfType(option["v"], settingType) {
+			findings = append(findings, ValidationFinding{key, path, ValueTypeMismatch,
+				fmt.Sprintf("the option value %v is not %s", option["v"], settingType)})
+		}
+	}
+
+	if sum != 100 {
+		findings = append(findings, ValidationFinding{key, "p", InvalidPercentages,
+			fmt.Sprintf("the percentages sum to %v instead of 100", sum)})
+	}
+
+	return findings
+}
+
+func validateRule(findings []ValidationFinding, key string, path string, rule map[string]interface{}) []ValidationFinding {
+	comparator, ok := rule["t"].(float64)
+	_, known := comparatorTexts[int(comparator)]
+	known = known || lookupComparator(comparator) != nil
+	if !ok || !known || comparator != float64(int(comparator)) {
+		return append(findings, ValidationFinding{key, path, UnknownComparator,
+			fmt.Sprintf("unknown comparator %v", rule["t"])})
+	}
+
+	if attribute, _ := rule["a"].(string); len(attribute) == 0 {
+		findings = append(findings, ValidationFinding{key, path, InvalidSetting, "the rule has no comparison attribute"})
+	}
+
+	comparisonValue, _ := rule["c"].(string)
+	var err error
+	switch comparator {
+	case 4, 5:
+		for _, item := range strings.Split(comparisonValue, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				if _, err = semver.Make(item); err != nil {
+					break
+				}
+			}
+		}
+	case 6, 7, 8, 9:
+		_, err = semver.Make(strings.TrimSpace(comparisonValue))
+	case 10, 11, 12, 13, 14, 15:
+		_, err = strconv.ParseFloat(strings.Replace(comparisonValue, ",", ".", -1), 64)
+	case 18, 19:
+		_, err = parseDateTime(comparisonValue)
+	}
+
+	if err != nil {
+		findings = append(findings, ValidationFinding{key, path, InvalidComparisonValue,
+			fmt.Sprintf("%q is invalid for %s: %v", comparisonValue, comparatorTexts[int(comparator)], err)})
+	}
+
+	return findings
+}
+
+// isValueOfType returns true if the value can be served by a setting of the given type.
+func isValueOfType(value interface{}, settingType SettingType) bool {
+	switch value := value.(type) {
+	case bool:
+		return settingType == BoolSetting
+	case string:
+		return settingType == StringSetting
+	case float64:
+		return settingType == FloatSetting || (settingType == IntSetting && value == float64(int64(value)))
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	findings, err := ValidateConfig([]byte(`{
+		"valid": { "v": false, "t": 0,
+			"r": [ { "o": 0, "a": "Version", "t": 8, "c": "1.2.3", "v": true } ],
+			"p": [ { "o": 0, "v": true, "p": 30 }, { "o": 1, "v": false, "p": 70 } ] },
+		"broken": { "v": 1, "t": 2,
+			"r": [
+				{ "o": 0, "a": "Version", "t": 6, "c": "1.2", "v": 2 },
+				{ "o": 1, "a": "Age", "t": 99, "c": "18", "v": 3 },
+				{ "o": 2, "a": "Age", "t": 12, "c": "eighteen", "v": "4" }
+			],
+			"p": [ { "o": 0, "v": 5, "p": 30 }, { "o": 1, "v": 6, "p": 60 } ] },
+		"novalue": { "t": 1 }
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ValidationFinding{
+		{"broken", "r[0]", InvalidComparisonValue, ""},
+		{"broken", "r[1]", UnknownComparator, ""},
+		{"broken", "r[2]", InvalidComparisonValue, ""},
+		{"broken", "r[2]", ValueTypeMismatch, ""},
+		{"broken", "p", InvalidPercentages, ""},
+		{"novalue", "", InvalidSetting, ""},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expecting %d findings, got %v", len(expected), findings)
+	}
+
+	for i, finding := range findings {
+		if finding.Key != expected[i].Key || finding.Path != expected[i].Path || finding.Kind != expected[i].Kind {
+			t.Errorf("Expecting %v, got %v", expected[i], finding)
+		}
+	}
+}
+
+func TestValidateConfig_InvalidJson(t *testing.T) {
+	if _, err := ValidateConfig([]byte("{")); err == nil {
+		t.Error("Expecting a parse error")
+	}
+}
+
+func TestValidateConfig_Preferences(t *testing.T) {
+	findings, err := ValidateConfig([]byte(`{
+		"p": { "s": "salt" },
+		"hashed": { "v": false, "t": 0,
+			"r": [ { "o": 0, "a": "Email", "t": 26, "c": "[\"abc\"]", "v": true } ] }
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("Expecting no findings for the preferences, got %v", findings)
+	}
+}
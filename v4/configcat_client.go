@@ -2,7 +2,14 @@
 package configcat
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,8 +18,37 @@ type Client struct {
 	store                   *configStore
 	parser                  *ConfigParser
 	refreshPolicy           refreshPolicy
+	pinning                 *pinningPolicy
 	maxWaitTimeForSyncCalls time.Duration
 	logger                  Logger
+	// the logger under the deduplication, which can be replaced by Reconfigure
+	baseLogger *swappableLogger
+	// the provider of the configuration, wrapped by the retries, the circuit breaker and the acceptance check
+	fetcher        configProvider
+	onError        func(err error)
+	evaluatedKeys  *evaluatedKeys
+	executor       *callbackExecutor
+	refreshLimiter *refreshLimiter
+	statusRecorder *statusRecorder
+	mode           string
+	offline        bool
+	clock          Clock
+	impressions    *impressionRecorder
+	hooks          *hooks
+	circuitBreaker *circuitBreaker
+	shadow         *shadowEvaluator
+	defaults       defaultsRegistry
+	labels         map[string]string
+	stats          *evaluationStats
+	warmed         *warmedEvaluations
+	allValues      *allValuesCache
+	jsonValues     *jsonValueCache
+	sources        *sourceChain
+	memoBudget     *memoBudget
+	// the provider of the configuration without the retries, the circuit breaker and the acceptance check
+	provider     configProvider
+	retrying     *retryingProvider
+	killSwitches killSwitches
 }
 
 // ClientConfig describes custom configuration options for the Client.
@@ -21,17 +57,165 @@ type ClientConfig struct {
 	Logger Logger
 	// The custom cache implementation used to store the configuration.
 	Cache ConfigCache
+	// The window of the log deduplication: an identical warning or error message is logged at most once per window,
+	// the repetitions are counted and reported with the next logged occurrence. If it's 0 then no messages are suppressed.
+	LogDedupWindow time.Duration
 	// The maximum time how long at most the synchronous calls (e.g. client.get(...)) should block the caller.
 	// If it's 0 then the caller will be blocked in case of sync calls, until the operation succeeds or fails.
 	MaxWaitTimeForSyncCalls time.Duration
-	// The maximum wait time for a http response.
+	// The maximum wait time for a single http request, including reading the response body.
 	HttpTimeout time.Duration
+	// The maximum time allowed to establish a connection to the ConfigCat CDN.
+	// If it's 0 then the connect timeout of the transport is used.
+	ConnectTimeout time.Duration
+	// The minimum interval between forced refreshes (e.g. client.Refresh()). Refreshes requested
+	// more frequently are skipped and the cached configuration is kept. If it's 0 then there is no limit.
+	MinRefreshInterval time.Duration
+	// The age after which the configuration is considered stale because the fetches are failing,
+	// reported by Status and the OnConfigStale hook. If it's 0 then the configuration is never considered stale.
+	MaxStaleness time.Duration
+	// The maximum number of retries of a poll failed with a timeout or a 502, 503 or 504 response within
+	// a polling interval (auto polling only). The retries wait PollRetryBackoff, doubled for each retry,
+	// and are given up when the next retry wouldn't fit into the polling interval. If it's 0 then there are no retries.
+	// It's ignored when RetryPolicy is set, so the retries of the two settings don't multiply.
+	PollRetries int
+	// The wait before the first retry of a failed poll, 500 milliseconds by default.
+	PollRetryBackoff time.Duration
+	// Optional policy retrying the failed config fetches of every polling mode before the failure is reported,
+	// e.g. ExponentialRetry or DecorrelatedJitterRetry. The circuit breaker counts a retried fetch as one fetch.
+	// The retries are given up when the client is closed, and with NoBackgroundGoroutines when they wouldn't
+	// fit into the FetchTimeout (or the HttpTimeout). When it's set, PollRetries and PollRetryBackoff are ignored.
+	RetryPolicy RetryPolicy
+	// The maximum random delay of the first poll (auto polling only), spreading the fetches of instances
+	// started at the same time. The evaluations wait for the delayed first poll like for an immediate one.
+	// If it's 0 then the first poll starts immediately.
+	PollStartJitter time.Duration
+	// Shifts the polling ticker by a random offset within the polling interval after the first poll
+	// (auto polling only), so the instances started at the same time don't poll in lockstep.
+	PollPhaseOffset bool
+	// Optional verification of the signature or checksum delivered with the downloaded config JSONs,
+	// rejecting the tampered or corrupted ones before they reach the cache.
+	Integrity IntegrityConfig
+	// Optional acceptance check of the downloaded configurations, e.g. that the critical keys exist with the
+	// expected types. The rejected configurations are never served, the previous one is kept and
+	// Hooks.OnConfigRejected is called. The config fetcher downloads a rejected configuration again
+	// only after it changes.
+	AcceptConfig ConfigAcceptance
+	// Optional circuit breaker skipping the config fetches while they are failing, the cached configuration
+	// is served instead. The open circuit is persisted when the Cache implements CircuitStateCache.
+	CircuitBreaker CircuitBreakerConfig
+	// The overall deadline of a configuration download, including redirects.
+	// If it's 0 then only the HttpTimeout limits the download.
+	FetchTimeout time.Duration
 	// The base ConfigCat CDN url.
 	BaseUrl string
+	// The maximum accepted size of a downloaded configuration in bytes.
+	MaxConfigSize int64
 	// The custom http transport object.
 	Transport http.RoundTripper
+	// Optional source of the config JSON used instead of the ConfigCat CDN, see NewObjectStorageProvider.
+	ConfigProvider ConfigProvider
+	// Optional chain of the config sources in priority order, e.g. OverrideSource, CacheSource, CDNSource and
+	// BootstrapSource. Each refresh serves the configuration of the first source having one, and records its
+	// name in ClientStatus.ConfigSource and EvaluationDetails.ConfigSource. When every source fails the refresh
+	// fails like a failed fetch and the last configuration is kept. It replaces the ConfigProvider.
+	Sources []ConfigSource
+	// The proxy used to reach the ConfigCat CDN, for example http.ProxyURL(proxyUrl) or http.ProxyFromEnvironment.
+	// If it's nil then the proxy settings of the transport are used.
+	Proxy func(*http.Request) (*url.URL, error)
+	// Optional options of the connections to the ConfigCat CDN: a custom DNS resolver, the IP family
+	// and the Happy Eyeballs fallback delay.
+	Dialer DialerConfig
+	// Optional options of the connection reuse: HTTP/2, the idle connections and the TLS session resumption.
+	Connection ConnectionConfig
+	// The TLS configuration used to reach the ConfigCat CDN or a self-hosted proxy, see NewTLSConfig.
+	// If it's nil then the TLS settings of the transport are used.
+	TLSConfig *tls.Config
 	// The refresh mode of the cached configuration.
 	Mode RefreshMode
+	// The source of the time used by the refresh policies, the timeouts, the fetch status and the impressions.
+	// If it's nil then the system clock is used.
+	Clock Clock
+	// Optional config JSON (e.g. embedded with go:embed) served until the first configuration is fetched or
+	// found in the cache, so evaluations right after a cold start use real rules instead of the default values.
+	Bootstrap []byte
+	// If it's true then the client doesn't download the configuration, it's served from the cache.
+	Offline bool
+	// Optional callback resolving the user attributes referenced by targeting rules but missing from the User.
+	AttributeResolver AttributeResolver
+	// Optional canonicalization of the user attributes before they are compared by the targeting rules,
+	// e.g. trimming them or matching some of the comparators case-insensitively.
+	AttributeCanonicalization AttributeCanonicalization
+	// Defines the evaluation of the settings with targeting rules or percentage options without a user.
+	// By default they fall through to the non-targeted value of the setting with a warning, in strict mode
+	// the default value is returned with a UserRequiredError (matching ErrUserRequired) instead.
+	StrictTargeting bool
+	// Optional replacement of the percentage bucketing, intended for tests which force users into
+	// given buckets of the percentage rollouts, see configcattest.Buckets. If it's nil then PercentageBucket is used.
+	Bucketer Bucketer
+	// Optional journal of the accepted config changes, giving an audit trail of when the settings changed.
+	Journal JournalConfig
+	// Optional identifier of the application (e.g. "my-service/1.2.3") appended to the
+	// X-ConfigCat-UserAgent header sent with the config fetch requests.
+	ApplicationId string
+	// Optional recording of the flag evaluations, delivered in batches to the configured exporter.
+	Impressions ImpressionConfig
+	// Optional callbacks invoked on the events of the client.
+	Hooks Hooks
+	// Optional shadow evaluation of the flags against a candidate configuration, reporting the mismatches.
+	Shadow ShadowConfig
+	// The number of users whose GetAllValues results are cached until the configuration changes, the least
+	// recently used ones are evicted first. The cached results don't invoke the OnFlagEvaluated hooks and
+	// aren't recorded as impressions. If it's 0 then the results aren't cached.
+	AllValuesCacheSize int
+	// Optional limits of the total size of the memoization caches (Warmup, GetAllValues and GetJSONValue),
+	// evicting their least recently used entries, e.g. in memory-constrained containers.
+	MemoizationBudget MemoizationBudget
+	// The behavior when the cache can't be read at startup: CacheErrorServeEmpty (the default),
+	// CacheErrorBlockUntilFetch or CacheErrorFail.
+	CacheErrorPolicy CacheErrorPolicy
+	// Optional aggregation of the evaluation counts and value distributions per key, showing which flags
+	// are actually exercised and which always return the same value.
+	EvaluationStats EvaluationStatsConfig
+	// If it's true then the duration of the evaluations (the parsing, the rule walk and the hashing) is measured,
+	// reported in Impression.Timing to the OnFlagEvaluated hooks and aggregated per key by the evaluation stats,
+	// to find the flags whose complex rules slow down the hot request paths.
+	EvaluationTiming bool
+	// Optional static labels of the client (e.g. service name, environment, region) attached to its
+	// log messages, impressions and status, so the telemetry of multiple clients can be told apart.
+	Labels map[string]string
+	// Optional executor of the user callbacks (change listeners, async completions), e.g. NewPoolExecutor.
+	// The panics of the callbacks are recovered and reported to OnError. If it's nil then a single goroutine runs
+	// the callbacks in their order (unless its queue is full), closed together with the client. It's ignored when
+	// NoBackgroundGoroutines is set.
+	Executor Executor
+	// If it's true then the client doesn't start any goroutines or tickers, for environments forbidding background
+	// work (e.g. AWS Lambda or short-lived CLIs). The fetches and the callbacks run on the calling goroutine, bounded
+	// by HttpTimeout and FetchTimeout, and Close returns immediately. The auto polling mode refreshes the expired
+	// configuration on access like LazyLoad with the polling interval as the cache interval, the asynchronous
+	// refresh of LazyLoad is disabled, and the impressions and the evaluation stats are only delivered by Flush
+	// and Close, and by EvaluationStats.
+	NoBackgroundGoroutines bool
+	// Optional callback invoked when an error occurs during fetching or evaluation.
+	// Recovered internal panics are reported as *PanicError.
+	OnError func(err error)
+}
+
+// PanicError describes an internal panic recovered by the Client.
+type PanicError struct {
+	// The value passed to panic.
+	Value interface{}
+	// The stack trace captured at the time of the recovery.
+	Stack []byte
+}
+
+// Error is the error message.
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("recovered from panic: %v", p.Value)
+}
+
+func newPanicError(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
 }
 
 func defaultConfig() ClientConfig {
@@ -41,8 +225,12 @@ func defaultConfig() ClientConfig {
 		Cache:                   newInMemoryConfigCache(),
 		MaxWaitTimeForSyncCalls: 0,
 		HttpTimeout:             time.Second * 15,
+		PollRetryBackoff:        time.Millisecond * 500,
+		MaxConfigSize:           50 * 1024 * 1024,
 		Transport:               http.DefaultTransport,
 		Mode:                    AutoPoll(time.Second * 120),
+		Clock:                   systemClock{},
+		Integrity:               IntegrityConfig{Header: "X-Config-Signature"},
 	}
 }
 
@@ -52,13 +240,28 @@ func NewClient(apiKey string) *Client {
 }
 
 // NewCustomClient initializes a new ConfigCat Client with advanced configuration. The api key parameter is mandatory.
+// It panics when the client can't be created, see NewCustomClientWithError.
 func NewCustomClient(apiKey string, config ClientConfig) *Client {
 	return newInternal(apiKey, config, nil)
 }
 
+// NewCustomClientWithError is like NewCustomClient, but it returns an error instead of panicking when the api key
+// is empty or the cache can't be read with CacheErrorFail.
+func NewCustomClientWithError(apiKey string, config ClientConfig) (*Client, error) {
+	return newClient(apiKey, config, nil)
+}
+
 func newInternal(apiKey string, config ClientConfig, fetcher configProvider) *Client {
+	client, err := newClient(apiKey, config, fetcher)
+	if err != nil {
+		panic(err.Error())
+	}
+	return client
+}
+
+func newClient(apiKey string, config ClientConfig, fetcher configProvider) (*Client, error) {
 	if len(apiKey) == 0 {
-		panic("apiKey cannot be empty")
+		return nil, errors.New("apiKey cannot be empty")
 	}
 
 	defaultConfig := defaultConfig()
@@ -83,25 +286,221 @@ func newInternal(apiKey string, config ClientConfig, fetcher configProvider) *Cl
 		config.HttpTimeout = defaultConfig.HttpTimeout
 	}
 
+	if config.ConnectTimeout < 0 {
+		config.ConnectTimeout = defaultConfig.ConnectTimeout
+	}
+
+	if config.FetchTimeout < 0 {
+		config.FetchTimeout = defaultConfig.FetchTimeout
+	}
+
+	if config.MaxConfigSize <= 0 {
+		config.MaxConfigSize = defaultConfig.MaxConfigSize
+	}
+
+	if config.PollRetryBackoff <= 0 {
+		config.PollRetryBackoff = defaultConfig.PollRetryBackoff
+	}
+
 	if config.Transport == nil {
 		config.Transport = defaultConfig.Transport
 	}
 
+	if len(config.Integrity.Header) == 0 {
+		config.Integrity.Header = defaultConfig.Integrity.Header
+	}
+
 	if config.Mode == nil {
 		config.Mode = defaultConfig.Mode
 	}
 
+	if config.Clock == nil {
+		config.Clock = defaultConfig.Clock
+	}
+
+	labels := make(map[string]string, len(config.Labels))
+	for name, value := range config.Labels {
+		labels[name] = value
+	}
+	baseLogger := newSwappableLogger(newLabelLogger(config.Logger, labels))
+	config.Logger = baseLogger
+
+	if config.LogDedupWindow > 0 {
+		config.Logger = newDedupLogger(config.Logger, config.LogDedupWindow, config.Clock)
+	}
+
+	var chain *sourceChain
 	if fetcher == nil {
-		fetcher = newConfigFetcher(apiKey, config)
+		if fileMode, ok := config.Mode.(localFilePollConfig); ok {
+			fetcher = newLocalFileConfigProvider(fileMode.path, config.Logger)
+		} else if config.Offline {
+			fetcher = newOfflineConfigProvider(config.Logger)
+		} else if len(config.Sources) > 0 {
+			var err error
+			if chain, err = newSourceChain(apiKey, config.Sources, config); err != nil {
+				return nil, err
+			}
+			fetcher = chain
+		} else if config.ConfigProvider != nil {
+			fetcher = newCustomConfigProvider(config.ConfigProvider, config)
+		} else {
+			fetcher = newConfigFetcher(apiKey, config)
+		}
+	}
+	provider := fetcher
+
+	var retrying *retryingProvider
+	if config.RetryPolicy != nil {
+		retrying = newRetryingProvider(fetcher, config.RetryPolicy, config.Clock, config.Logger)
+		retrying.inline = config.NoBackgroundGoroutines
+		retrying.deadline = config.FetchTimeout
+		if retrying.deadline <= 0 {
+			retrying.deadline = config.HttpTimeout
+		}
+		fetcher = retrying
+	}
+
+	var breaker *circuitBreaker
+	if config.CircuitBreaker.FailureThreshold > 0 {
+		breaker = newCircuitBreaker(fetcher, config.CircuitBreaker, config.Clock, config.Logger)
+		fetcher = breaker
+	}
+
+	var acceptance *acceptanceProvider
+	if config.AcceptConfig != nil {
+		acceptance = newAcceptanceProvider(fetcher, config.AcceptConfig, config.Logger)
+		fetcher = acceptance
 	}
 
+	recorder := newStatusRecorder(fetcher, config.MaxStaleness, config.Clock)
 	store := newConfigStore(config.Logger, config.Cache)
+	store.bootstrap = string(config.Bootstrap)
+	if chain != nil {
+		chain.store = store
+	}
+	var cacheErr error
+	if config.CacheErrorPolicy != CacheErrorServeEmpty {
+		cacheErr = store.probe()
+	}
+	if cacheErr != nil && config.CacheErrorPolicy == CacheErrorFail {
+		return nil, fmt.Errorf("reading the cache failed: %w", cacheErr)
+	}
+	if len(config.Journal.Path) > 0 {
+		journal := newConfigJournal(config.Journal, config.Logger)
+		clock := config.Clock
+		store.addListener(func(previous string, current string) {
+			journal.record(previous, current, recorder.status().ETag, clock.Now())
+		})
+	}
+	var executor *callbackExecutor
+	if config.NoBackgroundGoroutines {
+		executor = newInlineCallbackExecutor(config.Logger)
+	} else if config.Executor != nil {
+		executor = newCustomCallbackExecutor(config.Executor, config.Logger)
+	} else {
+		executor = newCallbackExecutor(config.Logger)
+	}
+	executor.onError = config.OnError
+	executor.clock = config.Clock
+
+	hooks := newHooks(config.Hooks)
+	evaluated := newEvaluatedKeys()
+	logger := config.Logger
+	store.addListener(func(previous string, current string) {
+		hooks.keysChanged(previous, current, evaluated, logger, executor)
+	})
+	recorder.onStale = hooks.configStale
+	if acceptance != nil {
+		acceptance.onRejected = func(config string, err error) {
+			executor.execute(func() {
+				hooks.configRejected(config, err)
+			})
+		}
+	}
+	if breaker != nil {
+		breaker.onStateChanged = hooks.circuitStateChanged
+		if stateCache, ok := config.Cache.(CircuitStateCache); ok {
+			breaker.restore(stateCache)
+		}
+	}
+	var impressions *impressionRecorder
+	if config.Impressions.Exporter != nil {
+		impressions = newImpressionRecorder(config.Impressions, config.Logger, config.Clock, !config.NoBackgroundGoroutines)
+		hooks.addOnFlagEvaluated(impressions.record)
+	}
+
+	factory := newRefreshPolicyFactory(recorder, store, config.Logger, executor, config.Clock)
+	if config.PollRetries > 0 && config.RetryPolicy == nil {
+		factory.pollRetryPolicy = ExponentialRetry(config.PollRetryBackoff, 0, config.PollRetries)
+	}
+	factory.pollStartJitter, factory.pollPhaseOffset = config.PollStartJitter, config.PollPhaseOffset
+	factory.inline = config.NoBackgroundGoroutines
+	if _, ok := config.Mode.(streamingConfig); ok {
+		// the stream is kept open, so the HttpTimeout limiting the requests doesn't apply
+		streamConfig := config
+		streamConfig.HttpTimeout = 0
+		factory.streamClient = &http.Client{Transport: newTransport(streamConfig)}
+		factory.maxEventSize = config.MaxConfigSize
+		baseUrl := config.BaseUrl
+		factory.streamUrl = func() string {
+			return baseUrl + "/sse"
+		}
+		if httpFetcher, ok := provider.(*configFetcher); ok {
+			factory.streamUrl = func() string {
+				return httpFetcher.currentBaseUrl() + "/sse"
+			}
+		}
+	}
+
+	parser := newParser(config.Logger)
+	parser.decoded = store.decodedConfig
+	parser.evaluator.attributeResolver = config.AttributeResolver
+	parser.evaluator.bucketer = config.Bucketer
+	parser.evaluator.canonicalizer = newCanonicalizer(config.AttributeCanonicalization)
+	parser.evaluator.strictTargeting = config.StrictTargeting
+	parser.evaluator.timing = config.EvaluationTiming
+	parser.onMalformed = config.OnError
+
+	if config.NoBackgroundGoroutines {
+		config.EvaluationStats.ReportInterval = 0
+	}
+
+	refreshPolicy := config.Mode.accept(factory)
+	if cacheErr != nil && config.CacheErrorPolicy == CacheErrorBlockUntilFetch {
+		refreshPolicy = newFirstFetchGate(refreshPolicy, store)
+	}
+	pinning := newPinningPolicy(refreshPolicy)
+	memoBudget := newMemoBudget(config.MemoizationBudget)
 
 	return &Client{store: store,
-		parser:                  newParser(config.Logger),
-		refreshPolicy:           config.Mode.accept(newRefreshPolicyFactory(fetcher, store, config.Logger)),
+		impressions:             impressions,
+		hooks:                   hooks,
+		circuitBreaker:          breaker,
+		shadow:                  newShadowEvaluator(config.Shadow, config.Logger),
+		allValues:               newAllValuesCache(config.AllValuesCacheSize, memoBudget),
+		jsonValues:              newJsonValueCache(memoBudget),
+		memoBudget:              memoBudget,
+		sources:                 chain,
+		parser:                  parser,
+		refreshPolicy:           pinning,
+		pinning:                 pinning,
+		executor:                executor,
+		refreshLimiter:          newRefreshLimiter(config.MinRefreshInterval, config.Clock),
+		clock:                   config.Clock,
+		statusRecorder:          recorder,
+		mode:                    modeName(config.Mode),
+		offline:                 config.Offline,
 		maxWaitTimeForSyncCalls: config.MaxWaitTimeForSyncCalls,
-		logger:                  config.Logger}
+		logger:                  config.Logger,
+		baseLogger:              baseLogger,
+		fetcher:                 fetcher,
+		provider:                provider,
+		retrying:                retrying,
+		onError:                 config.OnError,
+		labels:                  labels,
+		stats:                   newEvaluationStats(config.EvaluationStats, config.Clock),
+		warmed:                  newWarmedEvaluations(memoBudget),
+		evaluatedKeys:           evaluated}, nil
 }
 
 // GetValue returns a value synchronously as interface{} from the configuration identified by the given key.
@@ -110,25 +509,42 @@ func (client *Client) GetValue(key string, defaultValue interface{}) interface{}
 }
 
 // GetValueAsync reads and sends a value asynchronously to a callback function as interface{} from the configuration identified by the given key.
+//
+// Deprecated: use GetValueWithContext in a goroutine instead, which also reports the reason of failed evaluations.
 func (client *Client) GetValueAsync(key string, defaultValue interface{}, completion func(result interface{})) {
 	client.GetValueAsyncForUser(key, defaultValue, nil, completion)
 }
 
 // GetValueForUser returns a value synchronously as interface{} from the configuration identified by the given key.
 // Optional user argument can be passed to identify the caller.
-func (client *Client) GetValueForUser(key string, defaultValue interface{}, user *User) interface{} {
+func (client *Client) GetValueForUser(key string, defaultValue interface{}, user *User) (result interface{}) {
 	if len(key) == 0 {
 		panic("key cannot be empty")
 	}
 
+	client.evaluatedKeys.add(key)
+	defaultValue = client.defaults.resolve(key, defaultValue)
+	defer func() {
+		if r := recover(); r != nil {
+			client.recovered(r, key, defaultValue)
+			result = defaultValue
+		}
+	}()
+
+	if json, ok := client.refreshPolicy.cachedConfiguration(); ok {
+		return client.parseJson(json, key, defaultValue, user)
+	}
+
 	if client.maxWaitTimeForSyncCalls > 0 {
-		json, err := client.refreshPolicy.getConfigurationAsync().getOrTimeout(client.maxWaitTimeForSyncCalls)
+		json, err := client.refreshPolicy.getConfigurationAsync().getOrTimeout(client.clock, client.maxWaitTimeForSyncCalls)
 		if err != nil {
 			client.logger.Errorf("Policy could not provide the configuration: %s", err.Error())
-			return client.parseJson(client.store.get(), key, defaultValue, user)
+			client.reportError(err)
+			return client.parseJson(client.servedConfiguration(), key, defaultValue, user)
 		}
 
-		return client.parseJson(json.(string), key, defaultValue, user)
+		jsonString, _ := json.(string)
+		return client.parseJson(jsonString, key, defaultValue, user)
 	}
 
 	json, _ := client.refreshPolicy.getConfigurationAsync().get().(string)
@@ -137,68 +553,379 @@ func (client *Client) GetValueForUser(key string, defaultValue interface{}, user
 
 // GetValueAsyncForUser reads and sends a value asynchronously to a callback function as interface{} from the configuration identified by the given key.
 // Optional user argument can be passed to identify the caller.
+//
+// Deprecated: use GetValueWithContext in a goroutine instead, which also reports the reason of failed evaluations.
 func (client *Client) GetValueAsyncForUser(key string, defaultValue interface{}, user *User, completion func(result interface{})) {
 	if len(key) == 0 {
 		panic("key cannot be empty")
 	}
 
+	client.evaluatedKeys.add(key)
+	defaultValue = client.defaults.resolve(key, defaultValue)
 	client.refreshPolicy.getConfigurationAsync().accept(func(res interface{}) {
-		completion(client.parseJson(res.(string), key, defaultValue, user))
+		json, _ := res.(string)
+		result := client.parseJson(json, key, defaultValue, user)
+		client.executor.execute(func() {
+			completion(result)
+		})
 	})
 }
 
-// GetAllKeys retrieves all the setting keys.
-func (client *Client) GetAllKeys() ([]string, error) {
-	if client.maxWaitTimeForSyncCalls > 0 {
-		json, err := client.refreshPolicy.getConfigurationAsync().getOrTimeout(client.maxWaitTimeForSyncCalls)
-		if err != nil {
-			client.logger.Errorf("Policy could not provide the configuration: %s", err.Error())
-			return nil, err
+// GetValueWithContext returns a value as interface{} from the configuration identified by the given key.
+// It blocks until the configuration is available or the context is done. Optional user argument can be
+// passed to identify the caller. When the evaluation fails, the default value is returned with the reason.
+// When the fetch of the configuration fails, or the context is done first (e.g. the deadline of the request
+// expired while a lazy loading fetch is in progress), the value is evaluated from the cached configuration,
+// or the default value is returned if there is none, together with the error of the fetch or the context.
+func (client *Client) GetValueWithContext(ctx context.Context, key string, defaultValue interface{}, user *User) (result interface{}, err error) {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	client.evaluatedKeys.add(key)
+	defaultValue = client.defaults.resolve(key, defaultValue)
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = defaultValue, client.recovered(r, key, defaultValue)
 		}
+	}()
 
-		return client.parser.GetAllKeys(json.(string))
+	if json, ok := client.refreshPolicy.cachedConfiguration(); ok {
+		return client.evaluate(json, key, defaultValue, user)
+	}
+
+	json, err := client.refreshPolicy.getConfigurationAsync().getWithContext(ctx)
+	if err != nil {
+		if cached := client.servedConfiguration(); len(cached) > 0 {
+			result, _ = client.evaluate(cached, key, defaultValue, user)
+			return result, err
+		}
+		return defaultValue, err
+	}
+
+	jsonString, _ := json.(string)
+	return client.evaluate(jsonString, key, defaultValue, user)
+}
+
+// GetValueDetails returns the value identified by the given key together with the details of the evaluation.
+// Optional user argument can be passed to identify the caller.
+func (client *Client) GetValueDetails(key string, defaultValue interface{}, user *User) EvaluationDetails {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	client.evaluatedKeys.add(key)
+	defaultValue = client.defaults.resolve(key, defaultValue)
+	json, err := client.getConfiguration()
+	if err != nil {
+		client.reportError(err)
+		json = client.servedConfiguration()
+	}
+
+	return client.evaluateDetails(json, key, defaultValue, user)
+}
+
+// GetAllKeys retrieves all the setting keys.
+func (client *Client) GetAllKeys() ([]string, error) {
+	json, err := client.getConfiguration()
+	if err != nil {
+		return nil, err
 	}
 
-	json, _ := client.refreshPolicy.getConfigurationAsync().get().(string)
 	return client.parser.GetAllKeys(json)
 }
 
 // GetAllKeysAsync retrieves all the setting keys asynchronously.
+//
+// Deprecated: use GetAllKeysWithContext in a goroutine instead.
 func (client *Client) GetAllKeysAsync(completion func(result []string, err error)) {
 	client.refreshPolicy.getConfigurationAsync().accept(func(res interface{}) {
-		completion(client.parser.GetAllKeys(res.(string)))
+		json, _ := res.(string)
+		keys, err := client.parser.GetAllKeys(json)
+		client.executor.execute(func() {
+			completion(keys, err)
+		})
 	})
 }
 
+// GetAllKeysWithContext retrieves all the setting keys. It blocks until the configuration is available or the context is done.
+func (client *Client) GetAllKeysWithContext(ctx context.Context) ([]string, error) {
+	json, err := client.refreshPolicy.getConfigurationAsync().getWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonString, _ := json.(string)
+	return client.parser.GetAllKeys(jsonString)
+}
+
+// KeyExists returns true if the configuration contains a setting identified by the given key.
+func (client *Client) KeyExists(key string) bool {
+	json, err := client.getConfiguration()
+	if err != nil {
+		return false
+	}
+
+	exists, err := client.parser.KeyExists(json, key)
+	return err == nil && exists
+}
+
+// GetKeyMetadata retrieves the metadata (type, variation IDs, number of rules) of the setting identified by the given key.
+func (client *Client) GetKeyMetadata(key string) (*KeyMetadata, error) {
+	json, err := client.getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.parser.GetKeyMetadata(json, key)
+}
+
+// GetFlagUsageReport compares the setting keys of the configuration with the keys evaluated by this client
+// and the optionally passed keys referenced by the application. The result contains the setting keys
+// never referenced and the referenced keys missing from the configuration.
+func (client *Client) GetFlagUsageReport(referencedKeys ...string) (*FlagUsageReport, error) {
+	keys, err := client.GetAllKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := client.evaluatedKeys.copy()
+	for _, key := range referencedKeys {
+		referenced[key] = struct{}{}
+	}
+
+	return newFlagUsageReport(keys, referenced), nil
+}
+
 // Refresh initiates a force refresh synchronously on the cached configuration.
 func (client *Client) Refresh() {
 	if client.maxWaitTimeForSyncCalls > 0 {
-		client.refreshPolicy.refreshAsync().waitOrTimeout(client.maxWaitTimeForSyncCalls)
+		client.refresh().waitOrTimeout(client.clock, client.maxWaitTimeForSyncCalls)
 	} else {
-		client.refreshPolicy.refreshAsync().wait()
+		client.refresh().wait()
 	}
 }
 
 // RefreshAsync initiates a force refresh asynchronously on the cached configuration.
+//
+// Deprecated: use RefreshWithContext in a goroutine instead, which also reports the reason of failed fetches.
 func (client *Client) RefreshAsync(completion func()) {
-	client.refreshPolicy.refreshAsync().accept(completion)
+	client.refresh().async.accept(func() {
+		client.executor.execute(completion)
+	})
+}
+
+// RefreshWithContext initiates a force refresh on the cached configuration and blocks until it's completed
+// or the context is done. The reason of a failed fetch is returned instead of silently keeping the cached configuration.
+func (client *Client) RefreshWithContext(ctx context.Context) error {
+	result, err := client.refresh().getWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if asFetchResponse(result).isFailed() {
+		return errors.New("config fetch failed")
+	}
+
+	return nil
+}
+
+// Pause suspends the background polling of the auto polling mode until Resume is called, the cached
+// configuration is still served. Unlike the offline mode, forced refreshes (e.g. client.Refresh()) are not affected.
+// It has no effect in the other refresh modes.
+func (client *Client) Pause() {
+	if policy := client.autoPollingPolicy(); policy != nil {
+		policy.pause()
+	}
+}
+
+// Resume continues the background polling suspended by Pause with an immediate refresh.
+func (client *Client) Resume() {
+	if policy := client.autoPollingPolicy(); policy != nil {
+		policy.resume()
+	}
+}
+
+// autoPollingPolicy returns the refresh policy of the auto polling mode, or nil in the other modes.
+func (client *Client) autoPollingPolicy() *autoPollingPolicy {
+	policy := client.pinning.refreshPolicy
+	if gate, ok := policy.(*firstFetchGate); ok {
+		policy = gate.refreshPolicy
+	}
+	autoPolling, _ := policy.(*autoPollingPolicy)
+	return autoPolling
+}
+
+// Status returns the current state of the client: the polling mode, the outcome of the last fetch,
+// the ETag of the configuration, the source of the cache, the offline state and the error counters.
+func (client *Client) Status() ClientStatus {
+	status := client.statusRecorder.status()
+	status.Mode = client.mode
+	status.CacheSource = client.store.source()
+	status.Offline = client.offline
+	_, status.Pinned = client.pinning.pinnedConfiguration()
+	status.Memoization = client.memoStatus()
+	if client.sources != nil {
+		status.ConfigSource = client.configSource(client.servedConfiguration())
+	}
+	if len(client.labels) > 0 {
+		status.Labels = make(map[string]string, len(client.labels))
+		for name, value := range client.labels {
+			status.Labels[name] = value
+		}
+	}
+	status.CacheReadErrorCount = atomic.LoadUint64(&client.store.readErrorCount)
+	status.CacheWriteErrorCount = atomic.LoadUint64(&client.store.writeErrorCount)
+	if client.circuitBreaker != nil {
+		status.CircuitState = client.circuitBreaker.currentState()
+	}
+	return status
+}
+
+// Flush delivers the recorded impressions to the configured exporter immediately.
+func (client *Client) Flush() error {
+	if client.impressions == nil {
+		return nil
+	}
+
+	return client.impressions.flush()
 }
 
-// Close shuts down the client, after closing, it shouldn't be used
+// Close shuts down the client and flushes the recorded impressions, after closing, it shouldn't be used
 func (client *Client) Close() {
+	if client.retrying != nil {
+		client.retrying.close()
+	}
+	client.killSwitches.close()
 	client.refreshPolicy.close()
+	client.executor.close()
+	if client.impressions != nil {
+		_ = client.impressions.close()
+	}
+	if client.stats != nil {
+		client.stats.close()
+	}
+}
+
+// refresh initiates a force refresh on the cached configuration unless it's rate limited.
+func (client *Client) refresh() *asyncResult {
+	if !client.refreshLimiter.allow() {
+		logEvent(client.logger, LogLevelWarn, LogEventFetch, logFields{}, "Refresh skipped, the previous refresh happened less than MinRefreshInterval ago.")
+		result := newAsyncResult()
+		result.completeWithError(ErrRefreshRateLimited)
+		return result
+	}
+
+	return client.refreshPolicy.refresh()
+}
+
+// getConfiguration reads the current configuration synchronously, respecting the maximum wait time for sync calls.
+func (client *Client) getConfiguration() (string, error) {
+	if json, ok := client.refreshPolicy.cachedConfiguration(); ok {
+		return json, nil
+	}
+
+	if client.maxWaitTimeForSyncCalls > 0 {
+		json, err := client.refreshPolicy.getConfigurationAsync().getOrTimeout(client.clock, client.maxWaitTimeForSyncCalls)
+		if err != nil {
+			client.logger.Errorf("Policy could not provide the configuration: %s", err.Error())
+			return "", err
+		}
+
+		jsonString, _ := json.(string)
+		return jsonString, nil
+	}
+
+	json, _ := client.refreshPolicy.getConfigurationAsync().get().(string)
+	return json, nil
 }
 
 func (client *Client) parseJson(json string, key string, defaultValue interface{}, user *User) interface{} {
-	parsed, err := client.parser.ParseWithUser(json, key, user)
+	result, _ := client.evaluate(json, key, defaultValue, user)
+	return result
+}
+
+// evaluate returns the value identified by the key from the given json config, or the default value
+// together with the reason when the evaluation fails or panics.
+func (client *Client) evaluate(json string, key string, defaultValue interface{}, user *User) (interface{}, error) {
+	details := client.evaluateDetails(json, key, defaultValue, user)
+	return details.Value, details.Error
+}
+
+// evaluateDetails is like evaluate, but it describes the result with EvaluationDetails.
+func (client *Client) evaluateDetails(json string, key string, defaultValue interface{}, user *User) (details EvaluationDetails) {
+	details = EvaluationDetails{Key: key, Value: defaultValue, IsDefaultValue: true, User: user}
+	if client.sources != nil {
+		details.ConfigSource = client.configSource(json)
+	}
+	if client.shadow != nil {
+		defer func() {
+			client.shadow.compare(details)
+		}()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			details.Value, details.VariationId, details.IsDefaultValue = defaultValue, "", true
+			details.Error = client.recovered(r, key, defaultValue)
+		}
+	}()
+
+	var start time.Time
+	if client.parser.evaluator.timing {
+		start = time.Now()
+	}
+	result, err := client.variation(json, key, user)
+	if client.parser.evaluator.timing {
+		result.timing.Total = time.Since(start)
+	}
+	details.MissingAttributes = result.missingAttributes
 	if err != nil {
 		client.logger.Errorf(
 			"Evaluating GetValue(%s) failed. Returning defaultValue: [%v]. %s.",
 			key,
 			defaultValue,
 			err.Error())
-		return defaultValue
+		client.statusRecorder.evaluationFailed()
+		client.reportError(err)
+		details.Error = err
+		return details
 	}
 
-	return parsed
+	if client.hooks.hasOnFlagEvaluated() {
+		impression := Impression{Key: key, VariationId: result.variationId, Timestamp: client.clock.Now(), Labels: client.labels}
+		if user != nil {
+			impression.UserId = user.identifier
+		}
+		if client.parser.evaluator.timing {
+			timing := result.timing
+			impression.Timing = &timing
+		}
+		client.hooks.flagEvaluated(impression)
+	}
+
+	if client.stats != nil {
+		client.stats.record(key, result.value, result.variationId, result.timing.Total)
+	}
+
+	details.Value, details.VariationId, details.IsDefaultValue = result.value, result.variationId, false
+	return details
+}
+
+func (client *Client) recovered(value interface{}, key string, defaultValue interface{}) error {
+	client.statusRecorder.evaluationFailed()
+	err := newPanicError(value)
+	client.logger.Errorf(
+		"Evaluating GetValue(%s) panicked. Returning defaultValue: [%v]. %s.\n%s",
+		key,
+		defaultValue,
+		err.Error(),
+		err.Stack)
+	client.reportError(err)
+	return err
+}
+
+func (client *Client) reportError(err error) {
+	if client.onError != nil {
+		client.onError(err)
+	}
 }
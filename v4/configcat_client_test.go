@@ -1,8 +1,11 @@
 package configcat
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -24,6 +27,19 @@ func (cache *FailingCache) Set(value string) error {
 	return errors.New("fake failing cache fails to set")
 }
 
+type PanickingCache struct {
+}
+
+// get reads the configuration from the cache.
+func (cache *PanickingCache) Get() (string, error) {
+	panic("fake panicking cache panics on get")
+}
+
+// set writes the configuration into the cache.
+func (cache *PanickingCache) Set(value string) error {
+	return nil
+}
+
 func getTestClients() (*fakeConfigProvider, *Client) {
 
 	config := ClientConfig{Mode: ManualPoll()}
@@ -154,6 +170,32 @@ func TestClient_Get_WithFailingCache(t *testing.T) {
 	}
 }
 
+func TestClient_Get_WithPanickingCache(t *testing.T) {
+	var reported error
+	config := ClientConfig{Mode: ManualPoll(), Cache: &PanickingCache{}, OnError: func(err error) {
+		reported = err
+	}}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey",
+		config,
+		fetcher)
+
+	result := client.GetValue("key", 0)
+
+	if result != 0 {
+		t.Error("Expecting default value")
+	}
+
+	panicErr, ok := reported.(*PanicError)
+	if !ok {
+		t.Fatalf("Expecting PanicError, got %v", reported)
+	}
+
+	if len(panicErr.Stack) == 0 {
+		t.Error("Expecting stack trace")
+	}
+}
+
 func TestClient_GetAllKeys(t *testing.T) {
 
 	client := NewClient("PKDVCLf-Hq-h-kCzMp-L7Q/psuH7BGHoUmdONrzzUOY7A")
@@ -168,3 +210,323 @@ func TestClient_GetAllKeys(t *testing.T) {
 		t.Error("Expecting 16 items")
 	}
 }
+
+func TestClient_GetFlagUsageReport(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched,
+		body: "{ \"used\": { \"v\": true }, \"unused\": { \"v\": false }, \"explicit\": { \"v\": false } }"})
+	client.Refresh()
+	client.GetValue("used", false)
+	client.GetValue("missing", false)
+
+	report, err := client.GetFlagUsageReport("explicit", "missingExplicit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Unused) != 1 || report.Unused[0] != "unused" {
+		t.Errorf("Unexpected unused keys: %v", report.Unused)
+	}
+
+	if len(report.Missing) != 2 || report.Missing[0] != "missing" || report.Missing[1] != "missingExplicit" {
+		t.Errorf("Unexpected missing keys: %v", report.Missing)
+	}
+}
+
+func TestClient_GetValueWithContext(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"value\"")})
+
+	err := client.RefreshWithContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.GetValueWithContext(context.Background(), "key", "default", nil)
+	if err != nil || result != "value" {
+		t.Error("Expecting non default string value")
+	}
+
+	result, err = client.GetValueWithContext(context.Background(), "nonExisting", "default", nil)
+	if err == nil || result != "default" {
+		t.Error("Expecting default value with error")
+	}
+}
+
+func TestClient_RefreshWithContext_Fail(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetError(errors.New("fake fetch error"))
+
+	err := client.RefreshWithContext(context.Background())
+	if err == nil || err.Error() != "fake fetch error" {
+		t.Errorf("Expecting fetch error, got %v", err)
+	}
+}
+
+func TestClient_RefreshWithContext_Timeout(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponseWithDelay(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"value\"")}, time.Second*10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	err := client.RefreshWithContext(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expecting deadline exceeded, got %v", err)
+	}
+}
+
+func TestClient_RefreshWithContext_RateLimited(t *testing.T) {
+	config := ClientConfig{Mode: ManualPoll(), MinRefreshInterval: time.Hour}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey",
+		config,
+		fetcher)
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"value\"")})
+	if err := client.RefreshWithContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"value2\"")})
+	if err := client.RefreshWithContext(context.Background()); err != ErrRefreshRateLimited {
+		t.Errorf("Expecting rate limited, got %v", err)
+	}
+
+	client.Refresh()
+	if client.GetValue("key", "default") != "value" {
+		t.Error("Expecting the cached value")
+	}
+}
+
+func TestClient_Status(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"value\""), eTag: "etag"})
+	client.Refresh()
+	client.GetValue("nonExisting", "default")
+	fetcher.SetError(errors.New("fake fetch error"))
+	client.Refresh()
+
+	status := client.Status()
+	if status.Mode != "manual" || status.CacheSource != "memory" || status.ETag != "etag" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if status.FetchCount != 2 || status.FetchErrorCount != 1 || status.EvaluationErrorCount != 1 {
+		t.Errorf("Unexpected counters: %+v", status)
+	}
+
+	if status.LastFetchStatus != "failed" || status.LastFetchError == nil || status.LastFetchTime.IsZero() {
+		t.Errorf("Unexpected last fetch: %+v", status)
+	}
+}
+
+func TestClient_MaxStaleness(t *testing.T) {
+	var staleCalls []time.Duration
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:         ManualPoll(),
+		MaxStaleness: time.Millisecond * 50,
+		Hooks:        Hooks{OnConfigStale: func(age time.Duration) { staleCalls = append(staleCalls, age) }},
+	}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "{}"})
+	client.Refresh()
+	fetcher.SetError(errors.New("network error"))
+	client.Refresh()
+	if status := client.Status(); status.Stale || status.LastSuccessfulFetchTime.IsZero() || len(staleCalls) != 0 {
+		t.Errorf("Unexpected stale status: %+v", status)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	if !client.Status().Stale {
+		t.Error("Expecting a stale config")
+	}
+
+	client.Refresh()
+	client.Refresh()
+	if len(staleCalls) != 1 || staleCalls[0] < time.Millisecond*50 {
+		t.Errorf("Expecting one stale call, got %v", staleCalls)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: NotModified})
+	client.Refresh()
+	if client.Status().Stale {
+		t.Error("Expecting a fresh config after a successful fetch")
+	}
+}
+
+func TestClient_MaxStaleness_Clock(t *testing.T) {
+	clock := &manualClock{now: time.Unix(0, 0)}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), MaxStaleness: time.Minute, Clock: clock}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "{}"})
+	client.Refresh()
+	if status := client.Status(); status.Stale || !status.LastFetchTime.Equal(clock.now) {
+		t.Errorf("Expecting the fetch time of the clock, got %+v", status)
+	}
+
+	clock.now = clock.now.Add(time.Minute * 2)
+	if !client.Status().Stale {
+		t.Error("Expecting the config to be stale by the clock")
+	}
+}
+
+func TestClient_Bootstrap(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Bootstrap: []byte(`{ "key": { "v": "bootstrap" } }`)}, fetcher)
+	defer client.Close()
+	fetcher.SetError(errors.New("network error"))
+
+	if value := client.GetValue("key", "default"); value != "bootstrap" {
+		t.Errorf("Expecting the bootstrap value, got %v", value)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "key": { "v": "fetched" } }`})
+	client.Refresh()
+
+	if value := client.GetValue("key", "default"); value != "fetched" {
+		t.Errorf("Expecting the fetched value, got %v", value)
+	}
+}
+
+func TestClient_AttributeResolver(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	var resolved []string
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), AttributeResolver: func(user *User, attribute string) (string, bool) {
+		resolved = append(resolved, attribute)
+		if attribute == "Plan" && user.GetAttribute("identifier") == "paying" {
+			return "pro", true
+		}
+		return "", false
+	}}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "key": { "v": "basic", "r": [ { "a": "Plan", "t": 0, "c": "pro", "v": "premium" } ] } }`})
+	client.Refresh()
+
+	if value := client.GetValueForUser("key", "", NewUser("paying")); value != "premium" {
+		t.Errorf("Expecting premium for the resolved plan, got %v", value)
+	}
+
+	if value := client.GetValueForUser("key", "", NewUser("free")); value != "basic" {
+		t.Errorf("Expecting basic for the unresolved plan, got %v", value)
+	}
+
+	if value := client.GetValueForUser("key", "", NewUserWithAdditionalAttributes("free", "", "", map[string]string{"Plan": "pro"})); value != "premium" || len(resolved) != 2 {
+		t.Errorf("Expecting the user attribute without resolving, got %v, %v", value, resolved)
+	}
+}
+
+func TestClient_MissingAttributes(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "key": { "v": "default", "r": [
+		{ "a": "Plan", "t": 0, "c": "pro", "v": "premium" },
+		{ "a": "Email", "t": 2, "c": "@example.com", "v": "internal" },
+		{ "a": "Plan", "t": 0, "c": "team", "v": "premium" }
+	] } }`})
+	client.Refresh()
+
+	details := client.GetValueDetails("key", "", NewUser("id"))
+	if details.Value != "default" || len(details.MissingAttributes) != 2 || details.MissingAttributes[0] != "Plan" || details.MissingAttributes[1] != "Email" {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+
+	details = client.GetValueDetails("key", "", NewUserWithAdditionalAttributes("id", "jane@example.com", "", nil))
+	if details.Value != "internal" || len(details.MissingAttributes) != 1 {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+}
+
+func TestClient_GetValueWithContext_DeadlineServesCache(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: LazyLoad(time.Millisecond*10, false)}, fetcher)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	fetcher.SetResponseWithDelay(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"cached\"")}, time.Second)
+	result, err := client.GetValueWithContext(ctx, "key", "default", nil)
+	if err != context.DeadlineExceeded || result != "default" {
+		t.Errorf("Expecting the default value without cache, got %v, %v", result, err)
+	}
+
+	client.store.set(fmt.Sprintf(jsonFormat, "key", "\"cached\""))
+	time.Sleep(time.Millisecond * 20)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	start := time.Now()
+	result, err = client.GetValueWithContext(ctx, "key", "default", nil)
+	if err != context.DeadlineExceeded || result != "cached" || time.Since(start) > time.Millisecond*500 {
+		t.Errorf("Expecting the cached value on deadline, got %v, %v", result, err)
+	}
+}
+
+func TestClient_GetValueWithContext_FetchFailureServesCache(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	clock := &manualClock{now: time.Unix(1000, 0)}
+	client := newInternal("fakeKey", ClientConfig{Mode: LazyLoad(time.Minute, false), Clock: clock,
+		Logger: DefaultLogger(LogLevelFatal)}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "\"cached\"")})
+	if result, err := client.GetValueWithContext(context.Background(), "key", "default", nil); err != nil || result != "cached" {
+		t.Fatalf("Expecting the fetched value, got %v, %v", result, err)
+	}
+
+	fetchErr := errors.New("fake fetch error")
+	fetcher.SetError(fetchErr)
+	clock.now = clock.now.Add(time.Minute * 2)
+	result, err := client.GetValueWithContext(context.Background(), "key", "default", nil)
+	if err != fetchErr || result != "cached" {
+		t.Errorf("Expecting the cached value with the fetch error, got %v, %v", result, err)
+	}
+}
+
+type countingConfigProvider struct {
+	body  string
+	count int32
+}
+
+func (provider *countingConfigProvider) GetConfig(ctx context.Context, eTag string) (ConfigResponse, error) {
+	atomic.AddInt32(&provider.count, 1)
+	return ConfigResponse{Body: provider.body}, nil
+}
+
+func TestClient_NoBackgroundGoroutines(t *testing.T) {
+	provider := &countingConfigProvider{body: fmt.Sprintf(jsonFormat, "key", "true")}
+	changed := 0
+	var exported []Impression
+	before := runtime.NumGoroutine()
+	client := NewCustomClient("fakeKey", ClientConfig{
+		Mode:                   AutoPollWithChangeListener(time.Hour, func() { changed++ }),
+		ConfigProvider:         provider,
+		RetryPolicy:            ConstantRetry(time.Millisecond, 1),
+		Impressions:            ImpressionConfig{Exporter: ImpressionSink(func(impressions []Impression) error { exported = impressions; return nil })},
+		EvaluationStats:        EvaluationStatsConfig{Enabled: true, ReportInterval: time.Millisecond, OnReport: func(EvaluationStatsReport) {}},
+		NoBackgroundGoroutines: true,
+	})
+
+	if value := client.GetValue("key", false); value != true {
+		t.Errorf("Expecting the inline fetched value, got %v", value)
+	}
+	completed := false
+	client.GetValueAsync("key", false, func(result interface{}) { completed = result == true })
+	if !completed || changed != 1 {
+		t.Errorf("Expecting the callbacks to run inline, got %v, %d", completed, changed)
+	}
+	client.GetValue("key", false)
+	if count := atomic.LoadInt32(&provider.count); count != 1 {
+		t.Errorf("Expecting a single fetch within the polling interval, got %d", count)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expecting no goroutines to be started, got %d instead of %d", after, before)
+	}
+
+	client.Close()
+	if len(exported) != 3 {
+		t.Errorf("Expecting the impressions to be delivered by Close, got %v", exported)
+	}
+}
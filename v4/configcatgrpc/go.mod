@@ -0,0 +1,23 @@
+module github.com/configcat/go-sdk/v4/configcatgrpc
+
+go 1.17
+
+// the module is developed together with the SDK in the same repository
+replace github.com/configcat/go-sdk/v4 => ../
+
+require (
+	github.com/configcat/go-sdk/v4 v4.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.56.3
+)
+
+require (
+	github.com/blang/semver v3.5.1+incompatible // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/sirupsen/logrus v1.4.2 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/protobuf v1.30.0 // indirect
+)
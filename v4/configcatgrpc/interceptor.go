@@ -0,0 +1,62 @@
+// Package configcatgrpc contains gRPC server interceptors which attach a ConfigCat snapshot to the request context.
+package configcatgrpc
+
+import (
+	"context"
+
+	"github.com/configcat/go-sdk/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UserExtractor identifies the user of an incoming call from its metadata.
+type UserExtractor func(md metadata.MD) *configcat.User
+
+// UserFromMetadata creates a UserExtractor which uses the value of the given metadata key as the user identifier.
+// No user is identified when the metadata key is missing.
+func UserFromMetadata(key string) UserExtractor {
+	return func(md metadata.MD) *configcat.User {
+		values := md.Get(key)
+		if len(values) == 0 || len(values[0]) == 0 {
+			return nil
+		}
+
+		return configcat.NewUser(values[0])
+	}
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor which attaches a snapshot of the client's
+// configuration to the context of every unary call. Use configcat.FromContext to retrieve the snapshot in the handlers.
+func UnaryServerInterceptor(client *configcat.Client, getUser UserExtractor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(newContext(ctx, client, getUser), req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor which attaches a snapshot of the client's
+// configuration to the context of every stream. Use configcat.FromContext to retrieve the snapshot in the handlers.
+func StreamServerInterceptor(client *configcat.Client, getUser UserExtractor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: stream, ctx: newContext(stream.Context(), client, getUser)})
+	}
+}
+
+func newContext(ctx context.Context, client *configcat.Client, getUser UserExtractor) context.Context {
+	var user *configcat.User
+	if getUser != nil {
+		md, _ := metadata.FromIncomingContext(ctx)
+		user = getUser(md)
+	}
+
+	return configcat.NewContext(ctx, client.Snapshot(user))
+}
+
+// serverStream overrides the context of the wrapped stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *serverStream) Context() context.Context {
+	return stream.ctx
+}
@@ -0,0 +1,64 @@
+package configcatgrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/configcat/go-sdk/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestClient() (*httptest.Server, *configcat.Client) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{ \"key\": { \"v\": true, \"p\": [], \"r\": [] }}"))
+	}))
+
+	client := configcat.NewCustomClient("fakeKey", configcat.ClientConfig{BaseUrl: server.URL, Mode: configcat.ManualPoll()})
+	client.Refresh()
+	return server, client
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	server, client := newTestClient()
+	defer server.Close()
+	interceptor := UnaryServerInterceptor(client, UserFromMetadata("user-id"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-id", "id"))
+
+	_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		snapshot := configcat.FromContext(ctx)
+		if snapshot == nil || snapshot.User() == nil || snapshot.User().GetAttribute("identifier") != "id" {
+			t.Error("Expecting snapshot with user")
+		}
+
+		if !configcat.BoolFromContext(ctx, "key", false) {
+			t.Error("Expecting true")
+		}
+		return nil, nil
+	})
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *testServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	server, client := newTestClient()
+	defer server.Close()
+	interceptor := StreamServerInterceptor(client, nil)
+	stream := &testServerStream{ctx: context.Background()}
+
+	_ = interceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		if !configcat.BoolFromContext(stream.Context(), "key", false) {
+			t.Error("Expecting true")
+		}
+		return nil
+	})
+}
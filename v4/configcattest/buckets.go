@@ -0,0 +1,22 @@
+package configcattest
+
+import (
+	"github.com/configcat/go-sdk/v4"
+)
+
+// Buckets assigns user identifiers to fixed percentage buckets (0-99) for all settings, so a test can put
+// a user into e.g. the first 25% of a rollout without searching for a matching identifier:
+//
+//	config.Bucketer = configcattest.Buckets{"user-x": 10}.Bucket
+//
+// The users not listed are bucketed as in production.
+type Buckets map[string]int
+
+// Bucket returns the bucket assigned to the identifier, or the production bucket if it's not listed.
+func (buckets Buckets) Bucket(key string, identifier string) int {
+	if bucket, ok := buckets[identifier]; ok {
+		return bucket
+	}
+
+	return configcat.PercentageBucket(key, identifier)
+}
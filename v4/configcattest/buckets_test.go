@@ -0,0 +1,37 @@
+package configcattest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+type staticProvider string
+
+func (provider staticProvider) GetConfig(ctx context.Context, eTag string) (configcat.ConfigResponse, error) {
+	return configcat.ConfigResponse{Body: string(provider)}, nil
+}
+
+func TestBuckets(t *testing.T) {
+	client := configcat.NewCustomClient("fakeKey", configcat.ClientConfig{
+		Mode: configcat.ManualPoll(),
+		ConfigProvider: staticProvider(`{ "rollout": { "v": "off", "p": [
+			{ "o": 0, "v": "on", "p": 25 }, { "o": 1, "v": "off", "p": 75 } ] } }`),
+		Bucketer: Buckets{"in": 24, "out": 25}.Bucket,
+	})
+	defer client.Close()
+	client.Refresh()
+
+	if value := client.GetValueForUser("rollout", "", configcat.NewUser("in")); value != "on" {
+		t.Errorf("Expecting the user in the rollout, got %v", value)
+	}
+
+	if value := client.GetValueForUser("rollout", "", configcat.NewUser("out")); value != "off" {
+		t.Errorf("Expecting the user out of the rollout, got %v", value)
+	}
+
+	if bucket := (Buckets{}).Bucket("rollout", "other"); bucket != configcat.PercentageBucket("rollout", "other") {
+		t.Errorf("Expecting the production bucket, got %d", bucket)
+	}
+}
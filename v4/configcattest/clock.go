@@ -0,0 +1,113 @@
+// Package configcattest contains helpers for testing code which uses the ConfigCat client.
+package configcattest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+// FakeClock is a configcat.Clock whose time only moves when Advance is called,
+// so tests can expire caches and trigger polls without sleeping.
+type FakeClock struct {
+	now     time.Time
+	waiters []*fakeWaiter
+	sync.Mutex
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration
+	c        chan time.Time
+	stopped  bool
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+// NewFakeClock creates a FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (clock *FakeClock) Now() time.Time {
+	clock.Lock()
+	defer clock.Unlock()
+	return clock.now
+}
+
+// NewTimer creates a Timer which fires when the clock is advanced by the given duration.
+func (clock *FakeClock) NewTimer(d time.Duration) configcat.Timer {
+	return clock.addWaiter(d, 0)
+}
+
+// NewTicker creates a Ticker which fires every time the clock is advanced by the given period.
+// Like time.Ticker, it drops the ticks the receiver is not ready for.
+func (clock *FakeClock) NewTicker(d time.Duration) configcat.Ticker {
+	return fakeTicker{clock.addWaiter(d, d)}
+}
+
+// Advance moves the time of the clock forward and fires the timers and tickers which became due.
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.Lock()
+	defer clock.Unlock()
+	clock.now = clock.now.Add(d)
+
+	active := clock.waiters[:0]
+	for _, waiter := range clock.waiters {
+		if waiter.stopped {
+			continue
+		}
+
+		if !waiter.deadline.After(clock.now) {
+			select {
+			case waiter.c <- clock.now:
+			default:
+			}
+
+			if waiter.period <= 0 {
+				waiter.stopped = true
+				continue
+			}
+
+			for !waiter.deadline.After(clock.now) {
+				waiter.deadline = waiter.deadline.Add(waiter.period)
+			}
+		}
+
+		active = append(active, waiter)
+	}
+
+	clock.waiters = active
+}
+
+func (clock *FakeClock) addWaiter(d time.Duration, period time.Duration) *fakeWaiter {
+	clock.Lock()
+	defer clock.Unlock()
+	waiter := &fakeWaiter{clock: clock, deadline: clock.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	clock.waiters = append(clock.waiters, waiter)
+	return waiter
+}
+
+// C returns the channel the time is sent on when the waiter fires.
+func (waiter *fakeWaiter) C() <-chan time.Time {
+	return waiter.c
+}
+
+// Stop prevents the waiter from firing again.
+func (waiter *fakeWaiter) Stop() bool {
+	waiter.clock.Lock()
+	defer waiter.clock.Unlock()
+	active := !waiter.stopped
+	waiter.stopped = true
+	return active
+}
+
+// Stop turns off the ticker.
+func (ticker fakeTicker) Stop() {
+	ticker.fakeWaiter.Stop()
+}
@@ -0,0 +1,65 @@
+package configcattest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+type countingProvider struct {
+	count int32
+}
+
+func (provider *countingProvider) GetConfig(ctx context.Context, eTag string) (configcat.ConfigResponse, error) {
+	atomic.AddInt32(&provider.count, 1)
+	return configcat.ConfigResponse{Body: `{ "key": { "v": true } }`}, nil
+}
+
+func TestFakeClock_Timer(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	timer := clock.NewTimer(time.Minute)
+
+	clock.Advance(time.Second * 59)
+	select {
+	case <-timer.C():
+		t.Fatal("Timer fired early")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("Expecting the timer to fire")
+	}
+
+	if timer.Stop() {
+		t.Error("Expecting false for a fired timer")
+	}
+}
+
+func TestFakeClock_LazyLoadExpiration(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	provider := &countingProvider{}
+	client := configcat.NewCustomClient("fakeKey", configcat.ClientConfig{
+		Mode:           configcat.LazyLoad(time.Minute, false),
+		ConfigProvider: provider,
+		Clock:          clock,
+	})
+	defer client.Close()
+
+	client.GetValue("key", false)
+	client.GetValue("key", false)
+	if count := atomic.LoadInt32(&provider.count); count != 1 {
+		t.Errorf("Expecting 1 fetch, got %d", count)
+	}
+
+	clock.Advance(time.Minute * 2)
+	client.GetValue("key", false)
+	if count := atomic.LoadInt32(&provider.count); count != 2 {
+		t.Errorf("Expecting 2 fetches after the cache expired, got %d", count)
+	}
+}
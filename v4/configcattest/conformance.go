@@ -0,0 +1,173 @@
+package configcattest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+// ConformanceSuite is a golden configuration with the values expected for a matrix of users, following the
+// evaluation rules of the reference ConfigCat SDKs.
+type ConformanceSuite struct {
+	// The name of the suite.
+	Name string
+	// The golden config JSON.
+	Config string
+	// The users and the values expected for them.
+	Cases []ConformanceCase
+}
+
+// ConformanceCase is a row of the matrix of a ConformanceSuite.
+type ConformanceCase struct {
+	// The name of the case.
+	Name string
+	// The user of the evaluations, nil to evaluate without a user.
+	User *configcat.User
+	// The expected values by the setting keys.
+	Expected map[string]interface{}
+}
+
+// Evaluator returns the value of the setting identified by the key in the given config JSON for the user.
+type Evaluator func(config string, key string, user *configcat.User) interface{}
+
+// ConformanceSuites covers the rule ordering (the first matching targeting rule wins, the rules are evaluated
+// before the percentage options), the tie-breaking of the percentage options (a user whose bucket equals the
+// cumulative percentage of an option gets the next option), the skipping of the rules with missing attributes
+// and unparsable values, and the fallback to the default value.
+var ConformanceSuites = []ConformanceSuite{
+	{
+		Name: "rule ordering",
+		Config: `{
+			"ordered": { "v": "default", "t": 1, "i": "d0", "p": [], "r": [
+				{ "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": "first", "i": "r0" },
+				{ "o": 1, "a": "Email", "t": 2, "c": "admin", "v": "second", "i": "r1" },
+				{ "o": 2, "a": "Country", "t": 0, "c": "Hungary, Germany", "v": "third", "i": "r2" } ] },
+			"rollout": { "v": "default", "t": 1, "i": "d1",
+				"r": [ { "o": 0, "a": "Identifier", "t": 0, "c": "u2", "v": "targeted", "i": "r3" } ],
+				"p": [ { "o": 0, "v": "low", "p": 25, "i": "p0" }, { "o": 1, "v": "high", "p": 75, "i": "p1" } ] },
+			"tiebreak": { "v": "default", "t": 1, "i": "d2", "r": [], "p": [
+				{ "o": 0, "v": "zero", "p": 0, "i": "p2" },
+				{ "o": 1, "v": "A", "p": 43, "i": "p3" },
+				{ "o": 2, "v": "B", "p": 57, "i": "p4" } ] },
+			"enabled": { "v": false, "t": 0, "i": "d3", "p": [],
+				"r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": true, "i": "r4" } ] }
+		}`,
+		Cases: []ConformanceCase{
+			{
+				Name:     "no user",
+				Expected: map[string]interface{}{"ordered": "default", "rollout": "default", "tiebreak": "default", "enabled": false},
+			},
+			{
+				Name: "all rules match",
+				User: configcat.NewUserWithAdditionalAttributes("a", "admin@example.com", "Hungary", nil),
+				// the bucket of "a" is 24 for rollout and 43 for tiebreak
+				Expected: map[string]interface{}{"ordered": "first", "rollout": "low", "tiebreak": "B", "enabled": true},
+			},
+			{
+				Name: "rule before percentages",
+				User: configcat.NewUserWithAdditionalAttributes("u2", "admin@other.com", "Germany", nil),
+				// the bucket of "u2" is 6 for rollout and 70 for tiebreak
+				Expected: map[string]interface{}{"ordered": "second", "rollout": "targeted", "tiebreak": "B", "enabled": false},
+			},
+			{
+				Name: "last rule matches",
+				User: configcat.NewUserWithAdditionalAttributes("c", "c@other.com", "Germany", nil),
+				// the bucket of "c" is 79 for rollout and 91 for tiebreak
+				Expected: map[string]interface{}{"ordered": "third", "rollout": "high", "tiebreak": "B", "enabled": false},
+			},
+			{
+				Name: "no rule matches",
+				User: configcat.NewUserWithAdditionalAttributes("d", "", "France", nil),
+				// the bucket of "d" is 36 for rollout and 12 for tiebreak
+				Expected: map[string]interface{}{"ordered": "default", "rollout": "high", "tiebreak": "A", "enabled": false},
+			},
+			{
+				Name: "case sensitive comparison",
+				User: configcat.NewUserWithAdditionalAttributes("e", "e@EXAMPLE.COM", "hungary", nil),
+				// the bucket of "e" is 17 for rollout and 6 for tiebreak
+				Expected: map[string]interface{}{"ordered": "default", "rollout": "low", "tiebreak": "A", "enabled": false},
+			},
+		},
+	},
+	{
+		Name: "skipped rules",
+		Config: `{
+			"age": { "v": "default", "t": 1, "i": "d0", "p": [], "r": [
+				{ "o": 0, "a": "Age", "t": 14, "c": "18", "v": "adult", "i": "r0" },
+				{ "o": 1, "a": "Age", "t": 13, "c": "not a number", "v": "invalid", "i": "r1" },
+				{ "o": 2, "a": "Plan", "t": 0, "c": "pro", "v": "pro", "i": "r2" } ] }
+		}`,
+		Cases: []ConformanceCase{
+			{
+				Name:     "first rule matches",
+				User:     configcat.NewUserWithAdditionalAttributes("f", "", "", map[string]string{"Age": "21", "Plan": "pro"}),
+				Expected: map[string]interface{}{"age": "adult"},
+			},
+			{
+				Name:     "decimal comma",
+				User:     configcat.NewUserWithAdditionalAttributes("f", "", "", map[string]string{"Age": "18,5"}),
+				Expected: map[string]interface{}{"age": "adult"},
+			},
+			{
+				Name:     "unparsable attribute",
+				User:     configcat.NewUserWithAdditionalAttributes("g", "", "", map[string]string{"Age": "abc", "Plan": "pro"}),
+				Expected: map[string]interface{}{"age": "pro"},
+			},
+			{
+				Name:     "unparsable comparison value",
+				User:     configcat.NewUserWithAdditionalAttributes("h", "", "", map[string]string{"Age": "12", "Plan": "pro"}),
+				Expected: map[string]interface{}{"age": "pro"},
+			},
+			{
+				Name:     "missing attribute",
+				User:     configcat.NewUserWithAdditionalAttributes("u1", "", "", map[string]string{"Age": "12"}),
+				Expected: map[string]interface{}{"age": "default"},
+			},
+		},
+	},
+}
+
+// RunConformance runs the ConformanceSuites as subtests of t with the given evaluator, e.g. to check a
+// wrapper or a proxy of the client. A nil evaluator evaluates with a configcat.Client.
+func RunConformance(t *testing.T, evaluate Evaluator) {
+	if evaluate == nil {
+		evaluate = evaluateWithClient
+	}
+
+	for _, suite := range ConformanceSuites {
+		suite := suite
+		t.Run(suite.Name, func(t *testing.T) {
+			for _, c := range suite.Cases {
+				c := c
+				t.Run(c.Name, func(t *testing.T) {
+					for key, expected := range c.Expected {
+						if value := evaluate(suite.Config, key, c.User); !reflect.DeepEqual(value, expected) {
+							t.Errorf("%s: expected %v (%T), got %v (%T)", key, expected, expected, value, value)
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// staticConfigProvider serves a fixed config JSON.
+type staticConfigProvider string
+
+func (provider staticConfigProvider) GetConfig(ctx context.Context, eTag string) (configcat.ConfigResponse, error) {
+	return configcat.ConfigResponse{Body: string(provider)}, nil
+}
+
+func evaluateWithClient(config string, key string, user *configcat.User) interface{} {
+	client := configcat.NewCustomClient("fakeKey", configcat.ClientConfig{
+		Mode:           configcat.ManualPoll(),
+		ConfigProvider: staticConfigProvider(config),
+		Logger:         configcat.DefaultLogger(configcat.LogLevelError),
+	})
+	defer client.Close()
+	client.Refresh()
+
+	return client.GetValueForUser(key, nil, user)
+}
@@ -0,0 +1,9 @@
+package configcattest
+
+import (
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	RunConformance(t, nil)
+}
@@ -10,8 +10,26 @@ const (
 	NotModified fetchStatus = 1
 	// Failure indicates that the current configuration fetch is failed.
 	Failure fetchStatus = 2
+	// ServedFromCache indicates that no download was attempted and the cached configuration is served.
+	ServedFromCache fetchStatus = 3
 )
 
+// String returns the text representation of the fetch status.
+func (status fetchStatus) String() string {
+	switch status {
+	case Fetched:
+		return "fetched"
+	case NotModified:
+		return "not modified"
+	case Failure:
+		return "failed"
+	case ServedFromCache:
+		return "served from cache"
+	}
+
+	return "unknown"
+}
+
 const (
 	no  = 0
 	yes = 1
@@ -0,0 +1,62 @@
+package configcat
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// CountryName returns the English name of the country identified by its ISO 3166-1 code, e.g. "Hungary" for "HU",
+// "hun" or "348", as the country of the User is usually compared by the targeting rules. The alpha-2, alpha-3 and
+// numeric codes are accepted case-insensitively. It returns an empty string when the code isn't the code of a country.
+func CountryName(code string) string {
+	region, err := language.ParseRegion(strings.TrimSpace(code))
+	if err != nil || !region.IsCountry() {
+		return ""
+	}
+
+	return display.English.Regions().Name(region)
+}
+
+// maxAcceptLanguageLength limits the parsed part of an Accept-Language header, the header is untrusted input
+// and the parsing time of the long headers grows quadratically.
+const maxAcceptLanguageLength = 256
+
+// CountryFromAcceptLanguage returns the English name of the country of the most preferred language with a country
+// in the value of an Accept-Language header, e.g. "Hungary" for "hu-HU,en;q=0.8". The languages without a country
+// (e.g. "en") or with a wider region (e.g. "es-419") are skipped, and an empty string is returned when there is none.
+// Only the languages within the first 256 bytes of the header are considered.
+func CountryFromAcceptLanguage(header string) string {
+	if len(header) > maxAcceptLanguageLength {
+		header = header[:maxAcceptLanguageLength]
+		if comma := strings.LastIndexByte(header, ','); comma >= 0 {
+			header = header[:comma]
+		}
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return ""
+	}
+
+	for _, tag := range tags {
+		if region, confidence := tag.Region(); confidence == language.Exact && region.IsCountry() {
+			return display.English.Regions().Name(region)
+		}
+	}
+	return ""
+}
+
+// CountryFromRequest returns the English name of the country of the request, from the CF-IPCountry header set by
+// Cloudflare, or from the Accept-Language header when the former is missing or unknown. It returns an empty string
+// when neither tells the country. For example:
+//
+//	user := configcat.NewUserWithAdditionalAttributes(id, email, configcat.CountryFromRequest(r), nil)
+func CountryFromRequest(request *http.Request) string {
+	if country := CountryName(request.Header.Get("CF-IPCountry")); len(country) > 0 {
+		return country
+	}
+
+	return CountryFromAcceptLanguage(request.Header.Get("Accept-Language"))
+}
@@ -0,0 +1,71 @@
+package configcat
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCountryName(t *testing.T) {
+	tests := map[string]string{
+		"HU":   "Hungary",
+		"hu":   "Hungary",
+		"GBR":  "United Kingdom",
+		"840":  "United States",
+		" de ": "Germany",
+		"XX":   "",
+		"EU":   "",
+		"419":  "",
+		"":     "",
+	}
+	for code, expected := range tests {
+		if name := CountryName(code); name != expected {
+			t.Errorf("%q: expecting %q, got %q", code, expected, name)
+		}
+	}
+}
+
+func TestCountryFromAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"hu-HU,hu;q=0.9,en;q=0.8":            "Hungary",
+		"en;q=0.8, es-419, fr-CA;q=0.5":      "Canada",
+		"en":                                 "",
+		"":                                   "",
+		"not a header;;":                     "",
+		strings.Repeat("en,", 100) + "hu-HU": "",
+		"de-AT," + strings.Repeat("en,", 100) + "hu-HU": "Austria",
+		strings.Repeat("-", 400000):                     "",
+	}
+	for header, expected := range tests {
+		if country := CountryFromAcceptLanguage(header); country != expected {
+			t.Errorf("%q: expecting %q, got %q", header, expected, country)
+		}
+	}
+}
+
+func TestCountryFromRequest(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Accept-Language", "de-AT,de;q=0.9")
+	if country := CountryFromRequest(request); country != "Austria" {
+		t.Errorf("Expecting the country of the language, got %q", country)
+	}
+
+	request.Header.Set("CF-IPCountry", "XX")
+	if country := CountryFromRequest(request); country != "Austria" {
+		t.Errorf("Expecting the unknown IP country to be skipped, got %q", country)
+	}
+
+	request.Header.Set("CF-IPCountry", "JP")
+	if country := CountryFromRequest(request); country != "Japan" {
+		t.Errorf("Expecting the IP country, got %q", country)
+	}
+
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "geo": { "v": false, "t": 0,
+		"r": [ { "o": 0, "a": "Country", "t": 0, "c": "Japan", "v": true } ] } }`})
+	client.Refresh()
+	if value := client.GetValueForUser("geo", false, NewUserWithAdditionalAttributes("id", "", CountryFromRequest(request), nil)); value != true {
+		t.Errorf("Expecting the geo-targeted value, got %v", value)
+	}
+}
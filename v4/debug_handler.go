@@ -0,0 +1,109 @@
+package configcat
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// redactedValue replaces the redacted setting values in the output of the DebugHandler.
+const redactedValue = "[redacted]"
+
+// DebugHandlerConfig describes the configuration options of the DebugHandler.
+type DebugHandlerConfig struct {
+	// If it's true then all the setting values are redacted.
+	RedactValues bool
+	// The keys of the settings whose values are redacted.
+	RedactedKeys []string
+}
+
+type debugState struct {
+	Mode              string                 `json:"mode"`
+	Labels            map[string]string      `json:"labels,omitempty"`
+	ETag              string                 `json:"etag"`
+	LastFetchTime     time.Time              `json:"lastFetchTime"`
+	LastFetchStatus   string                 `json:"lastFetchStatus"`
+	User              map[string]string      `json:"user,omitempty"`
+	Values            map[string]interface{} `json:"values"`
+	Error             string                 `json:"error,omitempty"`
+	RecentFetchErrors []fetchErrorRecord     `json:"recentFetchErrors"`
+}
+
+// DebugHandler returns an http.Handler which renders the current flag state of the client as JSON:
+// the configuration version, all the setting keys with their values evaluated for the user described
+// by the query parameters, and the recent fetch errors. The "identifier", "email" and "country" query
+// parameters identify the user, the other parameters are used as custom attributes.
+// The handler is meant for operators, it should not be exposed publicly.
+func DebugHandler(client *Client, config DebugHandlerConfig) http.Handler {
+	redacted := make(map[string]struct{}, len(config.RedactedKeys))
+	for _, key := range config.RedactedKeys {
+		redacted[key] = struct{}{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := client.Status()
+		state := debugState{
+			Mode:              status.Mode,
+			Labels:            status.Labels,
+			ETag:              status.ETag,
+			LastFetchTime:     status.LastFetchTime,
+			LastFetchStatus:   status.LastFetchStatus,
+			Values:            map[string]interface{}{},
+			RecentFetchErrors: client.statusRecorder.recentFetchErrors(),
+		}
+
+		user := debugUser(r)
+		if user != nil {
+			state.User = user.attributes
+		}
+
+		jsonBody, err := client.getConfiguration()
+		if err == nil {
+			var keys []string
+			keys, err = client.parser.GetAllKeys(jsonBody)
+			sort.Strings(keys)
+			for _, key := range keys {
+				_, isRedacted := redacted[key]
+				if config.RedactValues || isRedacted {
+					state.Values[key] = redactedValue
+					continue
+				}
+
+				value, parseErr := client.parser.ParseWithUser(jsonBody, key, user)
+				if parseErr != nil {
+					value = nil
+				}
+				state.Values[key] = value
+			}
+		}
+
+		if err != nil {
+			state.Error = err.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(state)
+	})
+}
+
+// debugUser creates the user described by the query parameters, or nil if there's no identifier.
+func debugUser(r *http.Request) *User {
+	query := r.URL.Query()
+	identifier := query.Get("identifier")
+	if len(identifier) == 0 {
+		return nil
+	}
+
+	custom := map[string]string{}
+	for key, values := range query {
+		if key == "identifier" || key == "email" || key == "country" || len(values) == 0 {
+			continue
+		}
+		custom[key] = values[0]
+	}
+
+	return NewUserWithAdditionalAttributes(identifier, query.Get("email"), query.Get("country"), custom)
+}
@@ -0,0 +1,28 @@
+package configcat
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "{ \"key\": { \"v\": \"value\", \"p\": [], " +
+		"\"r\": [{ \"v\": \"targeted\", \"a\": \"Email\", \"t\": 2, \"c\": \"@example.com\" }] }, " +
+		"\"secret\": { \"v\": \"secret\" } }"})
+	client.Refresh()
+
+	handler := DebugHandler(client, DebugHandlerConfig{RedactedKeys: []string{"secret"}})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/?identifier=id&email=a@example.com", nil))
+
+	var state debugState
+	if err := json.Unmarshal(recorder.Body.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+
+	if state.Mode != "manual" || state.Values["key"] != "targeted" || state.Values["secret"] != redactedValue {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+}
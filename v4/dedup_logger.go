@@ -0,0 +1,146 @@
+package configcat
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxDedupEntries limits the number of distinct messages tracked by the dedupLogger.
+const maxDedupEntries = 1000
+
+// dedupLogger is a Logger decorator which suppresses the repeated warning and error messages:
+// an identical message is logged at most once per window, together with the number of suppressed repetitions.
+// The debug and info messages are passed through.
+type dedupLogger struct {
+	Logger
+	window  time.Duration
+	clock   Clock
+	entries map[string]*dedupEntry
+	sync.Mutex
+}
+
+type dedupEntry struct {
+	logged     time.Time
+	suppressed int
+}
+
+func newDedupLogger(logger Logger, window time.Duration, clock Clock) *dedupLogger {
+	return &dedupLogger{Logger: logger, window: window, clock: clock, entries: make(map[string]*dedupEntry)}
+}
+
+// IsLevelEnabled tells whether the wrapped logger logs the given level.
+func (logger *dedupLogger) IsLevelEnabled(level logrus.Level) bool {
+	return isLogLevelEnabled(logger.Logger, LogLevel(level))
+}
+
+func (logger *dedupLogger) Warnf(format string, args ...interface{}) {
+	if message, ok := logger.allow("W", fmt.Sprintf(format, args...)); ok {
+		logger.Logger.Warn(message)
+	}
+}
+
+func (logger *dedupLogger) Errorf(format string, args ...interface{}) {
+	if message, ok := logger.allow("E", fmt.Sprintf(format, args...)); ok {
+		logger.Logger.Error(message)
+	}
+}
+
+func (logger *dedupLogger) Warn(args ...interface{}) {
+	if message, ok := logger.allow("W", fmt.Sprint(args...)); ok {
+		logger.Logger.Warn(message)
+	}
+}
+
+func (logger *dedupLogger) Error(args ...interface{}) {
+	if message, ok := logger.allow("E", fmt.Sprint(args...)); ok {
+		logger.Logger.Error(message)
+	}
+}
+
+func (logger *dedupLogger) Warnln(args ...interface{}) {
+	if message, ok := logger.allow("W", strings.TrimSuffix(fmt.Sprintln(args...), "\n")); ok {
+		logger.Logger.Warn(message)
+	}
+}
+
+func (logger *dedupLogger) Errorln(args ...interface{}) {
+	if message, ok := logger.allow("E", strings.TrimSuffix(fmt.Sprintln(args...), "\n")); ok {
+		logger.Logger.Error(message)
+	}
+}
+
+// logEvent suppresses the repeated warning and error events like the messages, the other events are passed through.
+func (logger *dedupLogger) logEvent(level LogLevel, event string, fields logFields, message string) {
+	events, structured := logger.Logger.(eventLogger)
+	switch level {
+	case LogLevelWarn, LogLevelError:
+		prefix := "W"
+		if level == LogLevelError {
+			prefix = "E"
+		}
+		var ok bool
+		if message, ok = logger.allow(prefix, message); !ok {
+			return
+		}
+		if !structured {
+			if level == LogLevelWarn {
+				logger.Logger.Warn(message)
+			} else {
+				logger.Logger.Error(message)
+			}
+			return
+		}
+	default:
+		if !structured {
+			logf(logger.Logger, level, "%s", message)
+			return
+		}
+	}
+	events.logEvent(level, event, fields, message)
+}
+
+// allow reports whether the message can be logged, the returned message mentions the number of
+// repetitions suppressed since the message was last logged.
+func (logger *dedupLogger) allow(level string, message string) (string, bool) {
+	logger.Lock()
+	defer logger.Unlock()
+	now := logger.clock.Now()
+	key := level + message
+	entry, ok := logger.entries[key]
+	if ok && now.Sub(entry.logged) < logger.window {
+		entry.suppressed++
+		return "", false
+	}
+
+	if !ok {
+		if len(logger.entries) >= maxDedupEntries {
+			logger.evict(now)
+		}
+		entry = &dedupEntry{}
+		logger.entries[key] = entry
+	}
+
+	if entry.suppressed > 0 {
+		message = fmt.Sprintf("%s (suppressed %d repetitions)", message, entry.suppressed)
+	}
+	entry.logged = now
+	entry.suppressed = 0
+	return message, true
+}
+
+// evict removes the entries whose window expired, or all of them when none expired.
+func (logger *dedupLogger) evict(now time.Time) {
+	for key, entry := range logger.entries {
+		if now.Sub(entry.logged) >= logger.window {
+			delete(logger.entries, key)
+		}
+	}
+
+	if len(logger.entries) >= maxDedupEntries {
+		logger.entries = make(map[string]*dedupEntry)
+	}
+}
@@ -0,0 +1,54 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	Logger
+	messages []string
+}
+
+func (logger *recordingLogger) Warn(args ...interface{}) {
+	logger.messages = append(logger.messages, fmt.Sprint(args...))
+}
+
+func (logger *recordingLogger) Error(args ...interface{}) {
+	logger.messages = append(logger.messages, fmt.Sprint(args...))
+}
+
+type manualClock struct {
+	systemClock
+	now time.Time
+}
+
+func (clock *manualClock) Now() time.Time {
+	return clock.now
+}
+
+func TestDedupLogger(t *testing.T) {
+	recorder := &recordingLogger{Logger: DefaultLogger(LogLevelError)}
+	clock := &manualClock{now: time.Unix(0, 0)}
+	logger := newDedupLogger(recorder, time.Minute, clock)
+
+	for i := 0; i < 1000; i++ {
+		logger.Warnf("Flag %s is misconfigured.", "key")
+	}
+	logger.Errorln("Fetch failed.")
+	logger.Warnf("Flag %s is misconfigured.", "other")
+
+	clock.now = clock.now.Add(time.Minute)
+	logger.Warnf("Flag %s is misconfigured.", "key")
+
+	expected := []string{
+		"Flag key is misconfigured.",
+		"Fetch failed.",
+		"Flag other is misconfigured.",
+		"Flag key is misconfigured. (suppressed 999 repetitions)",
+	}
+	if fmt.Sprint(recorder.messages) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected messages: %q", recorder.messages)
+	}
+}
@@ -0,0 +1,67 @@
+package configcat
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultsRegistry holds the application-level defaults registered with Client.RegisterDefault.
+// The registrations are rare, so the map is copied on write and read without locking.
+type defaultsRegistry struct {
+	mu     sync.Mutex
+	values atomic.Value // map[string]interface{}
+}
+
+func (registry *defaultsRegistry) register(key string, value interface{}) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	current, _ := registry.values.Load().(map[string]interface{})
+	values := make(map[string]interface{}, len(current)+1)
+	for k, v := range current {
+		values[k] = v
+	}
+	values[key] = value
+	registry.values.Store(values)
+}
+
+// resolve returns the registered default of the key, or the given default when there is none.
+func (registry *defaultsRegistry) resolve(key string, defaultValue interface{}) interface{} {
+	values, _ := registry.values.Load().(map[string]interface{})
+	if value, ok := values[key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// resolveTyped is like resolve, but the registered default is only used when it has the type of the given default.
+func (registry *defaultsRegistry) resolveTyped(key string, defaultValue interface{}) interface{} {
+	values, _ := registry.values.Load().(map[string]interface{})
+	if value, ok := values[key]; ok && reflect.TypeOf(value) == reflect.TypeOf(defaultValue) {
+		return value
+	}
+	return defaultValue
+}
+
+// RegisterDefault registers the application-level default value of the setting identified by the given key.
+// Whenever the evaluation of the setting fails for any reason (e.g. the configuration isn't available, the key
+// doesn't exist or the value has a different type), the registered default is returned instead of the default
+// value passed to the getter, so the call sites can pass nil. The typed getters (e.g. GetBoolValue) only use the
+// registered default when it has the type of the getter.
+func (client *Client) RegisterDefault(key string, value interface{}) {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+	client.defaults.register(key, value)
+}
+
+// RegisteredDefaults returns the keys and values registered with RegisterDefault, for auditing the defaults centrally.
+func (client *Client) RegisteredDefaults() map[string]interface{} {
+	values, _ := client.defaults.values.Load().(map[string]interface{})
+	result := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestClient_RegisterDefault(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "flag": { "v": "on", "t": 1 } }`})
+	client.Refresh()
+
+	client.RegisterDefault("new-checkout", false)
+	client.RegisterDefault("flag", "off")
+
+	if value := client.GetValue("new-checkout", nil); value != false {
+		t.Errorf("Expecting the registered default of a missing key, got %v", value)
+	}
+	if value := client.GetValue("flag", nil); value != "on" {
+		t.Errorf("Expecting the evaluated value, got %v", value)
+	}
+	if details := client.GetValueDetails("new-checkout", true, nil); details.Value != false || !details.IsDefaultValue {
+		t.Errorf("Expecting the registered default to win over the call site, got %+v", details)
+	}
+	if value, err := client.GetBoolValue("new-checkout", true, nil); value != false || err == nil {
+		t.Errorf("Expecting the registered default with the error, got %v, %v", value, err)
+	}
+	if value, err := client.GetIntValue("flag", 7, nil); value != 7 || err == nil {
+		t.Errorf("Expecting the call site default when the registered one has another type, got %v, %v", value, err)
+	}
+	if value := client.Snapshot(nil).GetValue("new-checkout", nil); value != false {
+		t.Errorf("Expecting the registered default from the snapshot, got %v", value)
+	}
+
+	defaults := client.RegisteredDefaults()
+	if len(defaults) != 2 || defaults["new-checkout"] != false || defaults["flag"] != "off" {
+		t.Errorf("Unexpected registered defaults: %v", defaults)
+	}
+}
@@ -0,0 +1,165 @@
+package configcat
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Diagnostics is a snapshot of the internal resources of a Client, see Client.Diagnostics. Its String method
+// formats it for inclusion in support tickets.
+type Diagnostics struct {
+	// The time the snapshot was taken.
+	Time time.Time
+	// The version of the SDK.
+	Version string
+	// The polling mode of the client.
+	Mode string
+	// The number of the live goroutines owned by the client: the polling, the callback workers, the
+	// impression delivery, the evaluation stats reporting, the config fetch in flight and its retries.
+	Goroutines int
+	// The number of all the goroutines of the process.
+	ProcessGoroutines int
+	// True if the auto polling goroutine runs.
+	Polling bool
+	// The number of the running goroutines of the default callback pool, 0 with a custom Executor.
+	CallbackWorkers int
+	// The number of the callbacks waiting in the queue of the default callback pool.
+	CallbackQueueDepth int
+	// The capacity of the queue of the default callback pool.
+	CallbackQueueCapacity int
+	// The number of the executed callbacks.
+	CallbacksExecuted uint64
+	// The average execution time of the callbacks.
+	CallbackLatencyAverage time.Duration
+	// The longest execution time of the callbacks.
+	CallbackLatencyMax time.Duration
+	// True if a config fetch is in flight.
+	FetchInFlight bool
+	// The number of the async results waiting for the config fetch in flight.
+	PendingAsyncResults int
+	// The number of the impressions waiting for delivery.
+	ImpressionBufferDepth int
+	// The capacity of the impression buffer.
+	ImpressionBufferCapacity int
+	// The number of the keys aggregated by the evaluation stats.
+	EvaluationStatsKeys int
+	// The size of the served config JSON in bytes.
+	ConfigBytes int
+	// The number of the settings of the served configuration.
+	SettingCount int
+	// The sizes of the memoization caches.
+	Memoization MemoizationStatus
+}
+
+// String formats the diagnostics as "name: value" lines.
+func (diagnostics Diagnostics) String() string {
+	var builder strings.Builder
+	line := func(name string, value interface{}) {
+		fmt.Fprintf(&builder, "%s: %v\n", name, value)
+	}
+	line("time", diagnostics.Time.UTC().Format(time.RFC3339))
+	line("version", diagnostics.Version)
+	line("mode", diagnostics.Mode)
+	line("goroutines", diagnostics.Goroutines)
+	line("process goroutines", diagnostics.ProcessGoroutines)
+	line("polling", diagnostics.Polling)
+	line("callback workers", diagnostics.CallbackWorkers)
+	line("callback queue", fmt.Sprintf("%d/%d", diagnostics.CallbackQueueDepth, diagnostics.CallbackQueueCapacity))
+	line("callbacks executed", diagnostics.CallbacksExecuted)
+	line("callback latency", fmt.Sprintf("avg %v, max %v", diagnostics.CallbackLatencyAverage, diagnostics.CallbackLatencyMax))
+	line("fetch in flight", diagnostics.FetchInFlight)
+	line("pending async results", diagnostics.PendingAsyncResults)
+	line("impression buffer", fmt.Sprintf("%d/%d", diagnostics.ImpressionBufferDepth, diagnostics.ImpressionBufferCapacity))
+	line("evaluation stats keys", diagnostics.EvaluationStatsKeys)
+	line("config bytes", diagnostics.ConfigBytes)
+	line("settings", diagnostics.SettingCount)
+	line("memoization", fmt.Sprintf("warmed %d, all values %d, json values %d, %d bytes, %d evictions",
+		diagnostics.Memoization.WarmedEntries, diagnostics.Memoization.AllValuesEntries,
+		diagnostics.Memoization.JSONValueEntries, diagnostics.Memoization.EstimatedBytes, diagnostics.Memoization.Evictions))
+	return builder.String()
+}
+
+// inFlightFetcher is implemented by the configProviders sharing the fetch in flight between the callers.
+type inFlightFetcher interface {
+	// fetchInFlight returns the fetch in flight, or nil if there is none.
+	fetchInFlight() *asyncResult
+}
+
+func (fetcher *configFetcher) fetchInFlight() *asyncResult {
+	fetcher.Lock()
+	defer fetcher.Unlock()
+	return fetcher.inFlight
+}
+
+func (adapter *customConfigProvider) fetchInFlight() *asyncResult {
+	adapter.Lock()
+	defer adapter.Unlock()
+	return adapter.inFlight
+}
+
+func (chain *sourceChain) fetchInFlight() *asyncResult {
+	chain.Lock()
+	defer chain.Unlock()
+	return chain.inFlight
+}
+
+// Diagnostics returns a snapshot of the internal resources of the client: its goroutines, the depth of its
+// queues, the pending async results, the execution times of the callbacks and the sizes of its caches.
+// It's meant for troubleshooting, e.g. to attach client.Diagnostics().String() to a support ticket.
+func (client *Client) Diagnostics() Diagnostics {
+	diagnostics := Diagnostics{
+		Time:              client.clock.Now(),
+		Version:           version,
+		Mode:              client.mode,
+		ProcessGoroutines: runtime.NumGoroutine(),
+		Memoization:       client.memoStatus(),
+	}
+
+	if policy := client.autoPollingPolicy(); policy != nil && atomic.LoadInt32(&policy.polling) == 1 {
+		diagnostics.Polling = true
+		diagnostics.Goroutines++
+	}
+	if pool := client.executor.pool; pool != nil {
+		diagnostics.CallbackWorkers = int(atomic.LoadInt32(&pool.live))
+		diagnostics.CallbackQueueDepth, diagnostics.CallbackQueueCapacity = len(pool.queue), cap(pool.queue)
+		diagnostics.Goroutines += diagnostics.CallbackWorkers
+	}
+	executed := atomic.LoadUint64(&client.executor.executed)
+	diagnostics.CallbacksExecuted = executed
+	if executed > 0 {
+		diagnostics.CallbackLatencyAverage = time.Duration(atomic.LoadInt64(&client.executor.totalLatency) / int64(executed))
+		diagnostics.CallbackLatencyMax = time.Duration(atomic.LoadInt64(&client.executor.maxLatency))
+	}
+	if fetcher, ok := client.provider.(inFlightFetcher); ok {
+		if inFlight := fetcher.fetchInFlight(); inFlight != nil {
+			diagnostics.FetchInFlight = true
+			diagnostics.PendingAsyncResults = inFlight.subscriberCount()
+			diagnostics.Goroutines++
+		}
+	}
+	if client.retrying != nil {
+		diagnostics.Goroutines += int(atomic.LoadInt32(&client.retrying.running))
+	}
+	if client.impressions != nil {
+		diagnostics.ImpressionBufferDepth, diagnostics.ImpressionBufferCapacity = client.impressions.buffered()
+		select {
+		case <-client.impressions.stopped:
+		default:
+			diagnostics.Goroutines++
+		}
+	}
+	if client.stats != nil {
+		diagnostics.EvaluationStatsKeys = client.stats.keyCount()
+		diagnostics.Goroutines += int(atomic.LoadInt32(&client.stats.reporting))
+	}
+	if json := client.store.get(); len(json) > 0 {
+		diagnostics.ConfigBytes = len(json)
+		if config, err := client.parser.load(json); err == nil {
+			diagnostics.SettingCount = len(config.settings)
+		}
+	}
+	return diagnostics
+}
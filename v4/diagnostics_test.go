@@ -0,0 +1,128 @@
+package configcat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Diagnostics(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	changed := make(chan struct{}, 1)
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:            AutoPollWithChangeListener(time.Hour, func() { changed <- struct{}{} }),
+		EvaluationStats: EvaluationStatsConfig{Enabled: true},
+	}, fetcher)
+	<-changed
+	client.GetValue("key", false)
+
+	diagnostics := client.Diagnostics()
+	if !diagnostics.Polling || diagnostics.Mode != "auto" || diagnostics.SettingCount != 1 ||
+		diagnostics.ConfigBytes != len(fmt.Sprintf(jsonFormat, "key", "true")) || diagnostics.EvaluationStatsKeys != 1 {
+		t.Errorf("Unexpected diagnostics: %+v", diagnostics)
+	}
+	if diagnostics.CallbackQueueCapacity != callbackQueueSize || diagnostics.CallbackWorkers < 1 ||
+		diagnostics.Goroutines < 1+diagnostics.CallbackWorkers || diagnostics.ProcessGoroutines < diagnostics.Goroutines {
+		t.Errorf("Unexpected goroutine counts: %+v", diagnostics)
+	}
+	report := diagnostics.String()
+	for _, line := range []string{"mode: auto\n", "polling: true\n", "settings: 1\n", "callback queue: 0/128\n"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Expecting %q in the report:\n%s", line, report)
+		}
+	}
+
+	client.Close()
+	time.Sleep(50 * time.Millisecond)
+	if closed := client.Diagnostics(); closed.Polling || closed.CallbackWorkers != 0 {
+		t.Errorf("Expecting the goroutines to stop after closing, got %+v", closed)
+	}
+}
+
+// blockingConfigProvider blocks the downloads until the context is done.
+type blockingConfigProvider struct {
+	calls int32
+}
+
+func (provider *blockingConfigProvider) GetConfig(ctx context.Context, eTag string) (ConfigResponse, error) {
+	atomic.AddInt32(&provider.calls, 1)
+	<-ctx.Done()
+	return ConfigResponse{}, ctx.Err()
+}
+
+func TestClient_Diagnostics_WrappedFetcher(t *testing.T) {
+	provider := &blockingConfigProvider{}
+	client := NewCustomClient("fakeKey", ClientConfig{
+		Mode:           ManualPoll(),
+		ConfigProvider: provider,
+		FetchTimeout:   time.Hour,
+		RetryPolicy:    ConstantRetry(time.Hour, 1),
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 5},
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RefreshWithContext(ctx)
+	for atomic.LoadInt32(&provider.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	diagnostics := client.Diagnostics()
+	if !diagnostics.FetchInFlight {
+		t.Errorf("Expecting the fetch in flight behind the wrappers, got %+v", diagnostics)
+	}
+	if diagnostics.Goroutines < 2 {
+		t.Errorf("Expecting the fetch and the retry goroutines to be counted, got %+v", diagnostics)
+	}
+}
+
+func TestClient_Diagnostics_Retries(t *testing.T) {
+	fetcher := &flakyConfigProvider{failures: 100, err: &StatusError{StatusCode: 503}}
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:        ManualPoll(),
+		RetryPolicy: ConstantRetry(time.Hour, 10),
+	}, fetcher)
+
+	before := client.Diagnostics().Goroutines
+	go client.Refresh()
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&fetcher.attempts) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expecting the fetch to be attempted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if during := client.Diagnostics().Goroutines; during != before+1 {
+		t.Errorf("Expecting the retry goroutine to be counted, got %d before and %d during", before, during)
+	}
+
+	client.Close()
+	deadline = time.Now().Add(time.Second)
+	for client.Diagnostics().Goroutines != before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expecting the retry goroutine to stop, got %d goroutines instead of %d", client.Diagnostics().Goroutines, before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClient_Diagnostics_Clock(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1000, 0)}
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Clock: clock, Executor: inlineExecutor}, newFakeConfigProvider())
+	defer client.Close()
+
+	client.executor.execute(func() { clock.now = clock.now.Add(time.Second * 2) })
+
+	diagnostics := client.Diagnostics()
+	if !diagnostics.Time.Equal(time.Unix(1002, 0)) {
+		t.Errorf("Expecting the time of the clock, got %v", diagnostics.Time)
+	}
+	if diagnostics.CallbacksExecuted != 1 || diagnostics.CallbackLatencyMax != time.Second*2 {
+		t.Errorf("Expecting the callback latency measured by the clock, got %+v", diagnostics)
+	}
+}
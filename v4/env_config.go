@@ -0,0 +1,75 @@
+package configcat
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// The environment variables read by NewClientFromEnv.
+const (
+	EnvSdkKey       = "CONFIGCAT_SDK_KEY"
+	EnvBaseUrl      = "CONFIGCAT_BASE_URL"
+	EnvPollInterval = "CONFIGCAT_POLL_INTERVAL"
+	EnvOffline      = "CONFIGCAT_OFFLINE"
+	EnvCacheFile    = "CONFIGCAT_CACHE_FILE"
+)
+
+// NewClientFromEnv initializes a new ConfigCat Client configured by environment variables:
+//
+//	CONFIGCAT_SDK_KEY        the SDK key (mandatory)
+//	CONFIGCAT_BASE_URL       the base url of the ConfigCat CDN or a proxy
+//	CONFIGCAT_POLL_INTERVAL  the auto polling interval, in seconds or as a duration (e.g. "90s")
+//	CONFIGCAT_OFFLINE        "true" to serve the client from the cache without network access
+//	CONFIGCAT_CACHE_FILE     the path of the file used to cache the configuration
+func NewClientFromEnv() (*Client, error) {
+	apiKey, config, err := configFromEnv(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCustomClientWithError(apiKey, config)
+}
+
+func configFromEnv(lookup func(key string) (string, bool)) (string, ClientConfig, error) {
+	config := ClientConfig{}
+	apiKey, _ := lookup(EnvSdkKey)
+	if len(apiKey) == 0 {
+		return "", config, errors.New(EnvSdkKey + " is not set")
+	}
+
+	if baseUrl, ok := lookup(EnvBaseUrl); ok {
+		config.BaseUrl = baseUrl
+	}
+
+	if value, ok := lookup(EnvPollInterval); ok && len(value) > 0 {
+		interval, err := parseSecondsOrDuration(value)
+		if err != nil || interval <= 0 {
+			return "", config, errors.New(EnvPollInterval + " is invalid: " + value)
+		}
+		config.Mode = AutoPoll(interval)
+	}
+
+	if value, ok := lookup(EnvOffline); ok && len(value) > 0 {
+		offline, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", config, errors.New(EnvOffline + " is invalid: " + value)
+		}
+		config.Offline = offline
+	}
+
+	if path, ok := lookup(EnvCacheFile); ok && len(path) > 0 {
+		config.Cache = NewFileCache(path)
+	}
+
+	return apiKey, config, nil
+}
+
+func parseSecondsOrDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,49 @@
+package configcat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		EnvSdkKey:       "key",
+		EnvBaseUrl:      "https://proxy.example.com",
+		EnvPollInterval: "30",
+		EnvOffline:      "true",
+		EnvCacheFile:    "/tmp/configcat.json",
+	}
+	apiKey, config, err := configFromEnv(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if apiKey != "key" || config.BaseUrl != "https://proxy.example.com" || !config.Offline || config.Cache == nil {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	if mode, ok := config.Mode.(autoPollConfig); !ok || mode.autoPollInterval != time.Second*30 {
+		t.Errorf("Unexpected mode: %+v", config.Mode)
+	}
+}
+
+func TestConfigFromEnv_Invalid(t *testing.T) {
+	_, _, err := configFromEnv(func(key string) (string, bool) { return "", false })
+	if err == nil {
+		t.Error("Expecting error for missing SDK key")
+	}
+
+	_, _, err = configFromEnv(func(key string) (string, bool) {
+		if key == EnvPollInterval {
+			return "often", true
+		}
+		return "key", true
+	})
+	if err == nil {
+		t.Error("Expecting error for invalid poll interval")
+	}
+}
@@ -0,0 +1,185 @@
+package configcat
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// SettingDifference describes how a setting differs between two environments.
+type SettingDifference int
+
+const (
+	// SettingIdentical indicates that the setting is the same in both environments.
+	SettingIdentical SettingDifference = 0
+	// SettingOnlyInSource indicates that the setting is missing from the target environment.
+	SettingOnlyInSource SettingDifference = 1
+	// SettingOnlyInTarget indicates that the setting is missing from the source environment.
+	SettingOnlyInTarget SettingDifference = 2
+	// SettingTypeChanged indicates that the type of the setting value differs.
+	SettingTypeChanged SettingDifference = 3
+	// SettingValueChanged indicates that the default value of the setting differs, its rules are the same.
+	SettingValueChanged SettingDifference = 4
+	// SettingRulesChanged indicates that the targeting rules or the percentage rules of the setting differ.
+	SettingRulesChanged SettingDifference = 5
+)
+
+// String returns the name of the difference.
+func (difference SettingDifference) String() string {
+	switch difference {
+	case SettingIdentical:
+		return "identical"
+	case SettingOnlyInSource:
+		return "only in source"
+	case SettingOnlyInTarget:
+		return "only in target"
+	case SettingTypeChanged:
+		return "type changed"
+	case SettingValueChanged:
+		return "value changed"
+	case SettingRulesChanged:
+		return "rules changed"
+	}
+
+	return "unknown"
+}
+
+// SettingComparison describes a setting compared between two environments.
+type SettingComparison struct {
+	// The key of the setting.
+	Key string
+	// How the setting differs.
+	Difference SettingDifference
+	// The default value of the setting in the source environment, nil when it's missing there.
+	SourceValue interface{}
+	// The default value of the setting in the target environment, nil when it's missing there.
+	TargetValue interface{}
+}
+
+// EnvironmentComparison describes the differences of the settings between two environments.
+type EnvironmentComparison struct {
+	// The compared settings sorted by their keys, including the identical ones.
+	Settings []SettingComparison
+}
+
+// Differences returns the settings which aren't identical in the two environments.
+func (comparison *EnvironmentComparison) Differences() []SettingComparison {
+	var differences []SettingComparison
+	for _, setting := range comparison.Settings {
+		if setting.Difference != SettingIdentical {
+			differences = append(differences, setting)
+		}
+	}
+	return differences
+}
+
+// IdenticalExcept reports whether the two environments are identical apart from the given keys,
+// e.g. to check that the staging config can be promoted to production.
+func (comparison *EnvironmentComparison) IdenticalExcept(keys ...string) bool {
+	excepted := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		excepted[key] = struct{}{}
+	}
+	for _, setting := range comparison.Differences() {
+		if _, ok := excepted[setting.Key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareEnvironments compares the current configurations of two clients, typically created with the
+// SDK keys of two environments, setting by setting. If keys are given then only those settings are compared.
+// The variation IDs are ignored, as they differ between the environments even for identical settings.
+func CompareEnvironments(source *Client, target *Client, keys ...string) (*EnvironmentComparison, error) {
+	sourceSettings, err := comparedSettings(source)
+	if err != nil {
+		return nil, err
+	}
+	targetSettings, err := comparedSettings(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		for key := range sourceSettings {
+			keys = append(keys, key)
+		}
+		for key := range targetSettings {
+			if _, ok := sourceSettings[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	comparison := &EnvironmentComparison{Settings: make([]SettingComparison, 0, len(sorted))}
+	for i, key := range sorted {
+		if i > 0 && sorted[i-1] == key {
+			continue
+		}
+		comparison.Settings = append(comparison.Settings, compareSetting(key, sourceSettings[key], targetSettings[key]))
+	}
+	return comparison, nil
+}
+
+// comparedSettings returns the setting nodes of the current configuration of the client.
+func comparedSettings(client *Client) (map[string]interface{}, error) {
+	json, err := client.getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	if len(json) == 0 {
+		return nil, errors.New("no configuration is available")
+	}
+
+	config, err := client.parser.load(json)
+	if err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+	return config.root, nil
+}
+
+func compareSetting(key string, sourceNode interface{}, targetNode interface{}) SettingComparison {
+	source, _ := sourceNode.(map[string]interface{})
+	target, _ := targetNode.(map[string]interface{})
+	comparison := SettingComparison{Key: key, SourceValue: source["v"], TargetValue: target["v"]}
+	switch {
+	case source == nil && target == nil:
+		comparison.Difference = SettingIdentical
+	case target == nil:
+		comparison.Difference = SettingOnlyInSource
+	case source == nil:
+		comparison.Difference = SettingOnlyInTarget
+	case settingTypeOf(source) != settingTypeOf(target):
+		comparison.Difference = SettingTypeChanged
+	case !reflect.DeepEqual(withoutVariationIds(source["r"]), withoutVariationIds(target["r"])) ||
+		!reflect.DeepEqual(withoutVariationIds(source["p"]), withoutVariationIds(target["p"])):
+		comparison.Difference = SettingRulesChanged
+	case !reflect.DeepEqual(source["v"], target["v"]):
+		comparison.Difference = SettingValueChanged
+	}
+	return comparison
+}
+
+// withoutVariationIds returns the rules without their variation IDs, treating the missing rules as empty.
+func withoutVariationIds(rules interface{}) []interface{} {
+	list, _ := rules.([]interface{})
+	result := make([]interface{}, 0, len(list))
+	for _, r := range list {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			result = append(result, r)
+			continue
+		}
+		stripped := make(map[string]interface{}, len(rule))
+		for name, value := range rule {
+			if name != "i" {
+				stripped[name] = value
+			}
+		}
+		result = append(result, stripped)
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestCompareEnvironments(t *testing.T) {
+	stagingFetcher, staging := getTestClients()
+	defer staging.Close()
+	stagingFetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"same": { "v": true, "i": "s1", "t": 0, "r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": false, "i": "s2" } ] },
+		"value": { "v": "new", "t": 1 },
+		"rules": { "v": 1, "t": 2, "p": [ { "o": 0, "v": 1, "p": 50 }, { "o": 1, "v": 2, "p": 50 } ] },
+		"type": { "v": 1, "t": 2 },
+		"staging_only": { "v": false, "t": 0 }
+	}`})
+	staging.Refresh()
+
+	productionFetcher, production := getTestClients()
+	defer production.Close()
+	productionFetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"same": { "v": true, "i": "p1", "t": 0, "r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": false, "i": "p2" } ] },
+		"value": { "v": "old", "t": 1 },
+		"rules": { "v": 1, "t": 2, "p": [ { "o": 0, "v": 1, "p": 90 }, { "o": 1, "v": 2, "p": 10 } ] },
+		"type": { "v": 1.5, "t": 3 },
+		"production_only": { "v": false, "t": 0 }
+	}`})
+	production.Refresh()
+
+	comparison, err := CompareEnvironments(staging, production)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]SettingDifference{
+		"production_only": SettingOnlyInTarget,
+		"rules":           SettingRulesChanged,
+		"same":            SettingIdentical,
+		"staging_only":    SettingOnlyInSource,
+		"type":            SettingTypeChanged,
+		"value":           SettingValueChanged,
+	}
+	if len(comparison.Settings) != len(expected) {
+		t.Fatalf("Unexpected settings: %+v", comparison.Settings)
+	}
+	for i, setting := range comparison.Settings {
+		if i > 0 && comparison.Settings[i-1].Key > setting.Key {
+			t.Errorf("Expecting the settings sorted by key, got %+v", comparison.Settings)
+		}
+		if expected[setting.Key] != setting.Difference {
+			t.Errorf("Expecting %s for %s, got %s", expected[setting.Key], setting.Key, setting.Difference)
+		}
+	}
+	if len(comparison.Differences()) != 5 {
+		t.Errorf("Unexpected differences: %+v", comparison.Differences())
+	}
+	if comparison.IdenticalExcept("value", "rules") {
+		t.Error("Expecting other differences")
+	}
+	if !comparison.IdenticalExcept("value", "rules", "type", "staging_only", "production_only") {
+		t.Error("Expecting identical environments apart from the excepted keys")
+	}
+
+	filtered, err := CompareEnvironments(staging, production, "same", "value", "same")
+	if err != nil || len(filtered.Settings) != 2 || !filtered.IdenticalExcept("value") {
+		t.Errorf("Unexpected filtered comparison: %+v, %v", filtered, err)
+	}
+}
@@ -0,0 +1,49 @@
+package configcat
+
+import (
+	"testing"
+)
+
+const benchmarkConfig = `{
+	"flag": { "v": true, "i": "a" },
+	"rules": { "v": "default", "i": "b", "r": [
+		{ "o": 0, "a": "Country", "t": 0, "c": "HU, DE", "v": "local", "i": "c" },
+		{ "o": 1, "a": "Email", "t": 2, "c": "@example.com", "v": "internal", "i": "d" } ] }
+}`
+
+func newBenchmarkClient() *Client {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: benchmarkConfig})
+	client.Refresh()
+	return client
+}
+
+func TestClient_GetValue_NoAllocations(t *testing.T) {
+	client := newBenchmarkClient()
+	user := NewUserWithAdditionalAttributes("id", "a@example.com", "US", nil)
+
+	if allocs := testing.AllocsPerRun(100, func() { client.GetValue("flag", false) }); allocs != 0 {
+		t.Errorf("Expecting no allocations for a simple flag, got %v", allocs)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { client.GetValueForUser("rules", "", user) }); allocs != 0 {
+		t.Errorf("Expecting no allocations for targeting rules, got %v", allocs)
+	}
+}
+
+func BenchmarkClient_GetValue(b *testing.B) {
+	client := newBenchmarkClient()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		client.GetValue("flag", false)
+	}
+}
+
+func BenchmarkClient_GetValueForUser(b *testing.B) {
+	client := newBenchmarkClient()
+	user := NewUserWithAdditionalAttributes("id", "a@example.com", "US", nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		client.GetValueForUser("rules", "", user)
+	}
+}
@@ -0,0 +1,23 @@
+package configcat
+
+// EvaluationDetails describes the result of a setting evaluation.
+type EvaluationDetails struct {
+	// The key of the evaluated setting.
+	Key string
+	// The evaluated value, or the default value when the evaluation failed.
+	Value interface{}
+	// The variation ID of the evaluated value, empty when the default value is returned.
+	VariationId string
+	// True if the default value is returned.
+	IsDefaultValue bool
+	// The reason of the failed evaluation.
+	Error error
+	// The user the setting was evaluated for.
+	User *User
+	// The user attributes referenced by the targeting rules of the setting but missing from the User.
+	MissingAttributes []string
+	// The name of the source the value was evaluated from, set by MultiClient.
+	Source string
+	// The name of the config source the served configuration came from, see ClientConfig.Sources.
+	ConfigSource string
+}
@@ -0,0 +1,75 @@
+package configcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// EvaluationExporter exports the recorded flag evaluations to an analytics or experimentation backend.
+// Implementations are called from a single goroutine of the Client.
+type EvaluationExporter interface {
+	// Export delivers a batch of impressions.
+	Export(impressions []Impression) error
+}
+
+type jsonLinesExporter struct {
+	writer io.Writer
+	sync.Mutex
+}
+
+// NewJsonLinesExporter creates an EvaluationExporter which writes each impression as a JSON line
+// to the given writer, e.g. os.Stdout.
+func NewJsonLinesExporter(writer io.Writer) EvaluationExporter {
+	return &jsonLinesExporter{writer: writer}
+}
+
+func (exporter *jsonLinesExporter) Export(impressions []Impression) error {
+	exporter.Lock()
+	defer exporter.Unlock()
+	encoder := json.NewEncoder(exporter.writer)
+	for _, impression := range impressions {
+		if err := encoder.Encode(impression); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type httpExporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewHttpExporter creates an EvaluationExporter which posts the batches as a JSON array to the given url.
+// If the http client is nil then http.DefaultClient is used.
+func NewHttpExporter(url string, client *http.Client) EvaluationExporter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &httpExporter{url: url, client: client}
+}
+
+func (exporter *httpExporter) Export(impressions []Impression) error {
+	body, err := json.Marshal(impressions)
+	if err != nil {
+		return err
+	}
+
+	response, err := exporter.client.Post(exporter.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %v", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package configcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJsonLinesExporter(t *testing.T) {
+	var buffer bytes.Buffer
+	exporter := NewJsonLinesExporter(&buffer)
+
+	err := exporter.Export([]Impression{{Key: "key1"}, {Key: "key2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expecting 2 lines, got %d", len(lines))
+	}
+
+	var impression Impression
+	if err := json.Unmarshal(lines[1], &impression); err != nil || impression.Key != "key2" {
+		t.Errorf("Unexpected line: %s", lines[1])
+	}
+}
+
+func TestHttpExporter(t *testing.T) {
+	var posted []Impression
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+		if len(posted) > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	exporter := NewHttpExporter(server.URL, nil)
+
+	if err := exporter.Export([]Impression{{Key: "key1"}}); err != nil || len(posted) != 1 || posted[0].Key != "key1" {
+		t.Errorf("Unexpected export: %v, %v", err, posted)
+	}
+	if err := exporter.Export([]Impression{{Key: "key1"}, {Key: "key2"}}); err == nil {
+		t.Error("Expecting the status of the response to be reported")
+	}
+}
+
+func TestClient_Hooks_OnFlagEvaluated(t *testing.T) {
+	var evaluated []Impression
+	config := ClientConfig{Mode: ManualPoll(), Hooks: Hooks{OnFlagEvaluated: func(impression Impression) {
+		evaluated = append(evaluated, impression)
+	}}}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", config, fetcher)
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "{ \"key\": { \"v\": true, \"i\": \"id\" } }"})
+	client.Refresh()
+	client.GetValue("key", false)
+
+	if len(evaluated) != 1 || evaluated[0].VariationId != "id" {
+		t.Errorf("Unexpected evaluations: %v", evaluated)
+	}
+}
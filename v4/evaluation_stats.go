@@ -0,0 +1,173 @@
+package configcat
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EvaluationStatsConfig describes the aggregation of the evaluation counts and value distributions per key.
+type EvaluationStatsConfig struct {
+	// If it's true then the evaluations are aggregated, see Client.EvaluationStats.
+	Enabled bool
+	// The interval of the OnReport calls. If it's 0 then the report is only available through Client.EvaluationStats.
+	ReportInterval time.Duration
+	// Optional callback receiving the report periodically.
+	OnReport func(report EvaluationStatsReport)
+}
+
+// EvaluationStatsReport describes the successful evaluations of the settings since the creation of the Client.
+type EvaluationStatsReport struct {
+	// The time the aggregation started.
+	Since time.Time
+	// The time of the report.
+	Until time.Time
+	// The statistics of the evaluated settings, sorted by their keys.
+	Keys []KeyEvaluationStats
+}
+
+// KeyEvaluationStats describes the successful evaluations of a setting.
+type KeyEvaluationStats struct {
+	// The key of the setting.
+	Key string
+	// The number of the evaluations.
+	Count uint64
+	// The number of the evaluations per returned value, the most frequent first.
+	Values []ValueEvaluationCount
+	// True if every evaluation returned the same value, e.g. a flag rolled out to 100%.
+	OneSided bool
+	// The average duration of the evaluations, 0 unless ClientConfig.EvaluationTiming is set.
+	AverageDuration time.Duration
+	// The maximum duration of the evaluations, 0 unless ClientConfig.EvaluationTiming is set.
+	MaxDuration time.Duration
+}
+
+// ValueEvaluationCount is the number of the evaluations which returned a value.
+type ValueEvaluationCount struct {
+	// The returned value.
+	Value interface{}
+	// The variation ID of the returned value.
+	VariationId string
+	// The number of the evaluations.
+	Count uint64
+}
+
+// evaluationStats aggregates the evaluation counts per key and value.
+type evaluationStats struct {
+	since time.Time
+	clock Clock
+	keys  map[string]map[interface{}]*ValueEvaluationCount
+	// the total and the maximum evaluation duration per key
+	durations map[string]*keyDurations
+	stop      chan struct{}
+	stopOnce  sync.Once
+	// 1 while the reporting goroutine runs
+	reporting int32
+	sync.Mutex
+}
+
+type keyDurations struct {
+	total time.Duration
+	max   time.Duration
+}
+
+func newEvaluationStats(config EvaluationStatsConfig, clock Clock) *evaluationStats {
+	if !config.Enabled {
+		return nil
+	}
+
+	stats := &evaluationStats{since: clock.Now(), clock: clock, keys: map[string]map[interface{}]*ValueEvaluationCount{},
+		durations: map[string]*keyDurations{}, stop: make(chan struct{})}
+	if config.ReportInterval > 0 && config.OnReport != nil {
+		ticker := clock.NewTicker(config.ReportInterval)
+		stats.reporting = 1
+		go func() {
+			defer atomic.StoreInt32(&stats.reporting, 0)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stats.stop:
+					return
+				case <-ticker.C():
+					config.OnReport(stats.report())
+				}
+			}
+		}()
+	}
+	return stats
+}
+
+func (stats *evaluationStats) record(key string, value interface{}, variationId string, duration time.Duration) {
+	stats.Lock()
+	defer stats.Unlock()
+	values := stats.keys[key]
+	if values == nil {
+		values = map[interface{}]*ValueEvaluationCount{}
+		stats.keys[key] = values
+		stats.durations[key] = &keyDurations{}
+	}
+	durations := stats.durations[key]
+	durations.total += duration
+	if duration > durations.max {
+		durations.max = duration
+	}
+	count := values[value]
+	if count == nil {
+		count = &ValueEvaluationCount{Value: value, VariationId: variationId}
+		values[value] = count
+	}
+	count.Count++
+}
+
+func (stats *evaluationStats) report() EvaluationStatsReport {
+	stats.Lock()
+	defer stats.Unlock()
+	report := EvaluationStatsReport{Since: stats.since, Until: stats.clock.Now(), Keys: make([]KeyEvaluationStats, 0, len(stats.keys))}
+	for key, values := range stats.keys {
+		keyStats := KeyEvaluationStats{Key: key, Values: make([]ValueEvaluationCount, 0, len(values)), OneSided: len(values) == 1}
+		for _, count := range values {
+			keyStats.Count += count.Count
+			keyStats.Values = append(keyStats.Values, *count)
+		}
+		if durations := stats.durations[key]; keyStats.Count > 0 {
+			keyStats.AverageDuration = durations.total / time.Duration(keyStats.Count)
+			keyStats.MaxDuration = durations.max
+		}
+		sort.Slice(keyStats.Values, func(i, j int) bool {
+			if keyStats.Values[i].Count != keyStats.Values[j].Count {
+				return keyStats.Values[i].Count > keyStats.Values[j].Count
+			}
+			return fmt.Sprint(keyStats.Values[i].Value) < fmt.Sprint(keyStats.Values[j].Value)
+		})
+		report.Keys = append(report.Keys, keyStats)
+	}
+	sort.Slice(report.Keys, func(i, j int) bool {
+		return report.Keys[i].Key < report.Keys[j].Key
+	})
+	return report
+}
+
+// keyCount returns the number of the aggregated keys.
+func (stats *evaluationStats) keyCount() int {
+	stats.Lock()
+	defer stats.Unlock()
+	return len(stats.keys)
+}
+
+// close stops the periodic reports, it can be called repeatedly.
+func (stats *evaluationStats) close() {
+	stats.stopOnce.Do(func() {
+		close(stats.stop)
+	})
+}
+
+// EvaluationStats returns the evaluation counts and value distributions of the settings evaluated since the
+// creation of the client, enabled by ClientConfig.EvaluationStats. The report is empty when it's disabled.
+func (client *Client) EvaluationStats() EvaluationStatsReport {
+	if client.stats == nil {
+		return EvaluationStatsReport{}
+	}
+	return client.stats.report()
+}
@@ -0,0 +1,98 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClient_EvaluationStats(t *testing.T) {
+	config := ClientConfig{Mode: ManualPoll(), EvaluationStats: EvaluationStatsConfig{Enabled: true}}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", config, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"bool": { "v": true, "i": "on", "p": [], "r": [] },
+		"string": { "v": "default", "i": "d", "p": [], "r": [
+			{ "o": 0, "a": "Email", "t": 2, "c": "example.com", "v": "targeted", "i": "t" }
+		]}
+	}`})
+	client.Refresh()
+
+	for i := 0; i < 3; i++ {
+		client.GetBoolValue("bool", false, nil)
+	}
+	client.GetStringValue("string", "", NewUserWithAdditionalAttributes("a", "a@example.com", "", nil))
+	client.GetStringValue("string", "", NewUserWithAdditionalAttributes("b", "b@example.com", "", nil))
+	client.GetStringValue("string", "", NewUserWithAdditionalAttributes("c", "c@test.com", "", nil))
+	client.GetStringValue("missing", "", nil)
+
+	report := client.EvaluationStats()
+	if len(report.Keys) != 2 {
+		t.Fatalf("Expecting the 2 evaluated keys, got %v", report.Keys)
+	}
+	boolStats := report.Keys[0]
+	if boolStats.Key != "bool" || boolStats.Count != 3 || !boolStats.OneSided || boolStats.Values[0].VariationId != "on" {
+		t.Errorf("Unexpected stats of the bool key: %+v", boolStats)
+	}
+	stringStats := report.Keys[1]
+	if stringStats.Key != "string" || stringStats.Count != 3 || stringStats.OneSided {
+		t.Errorf("Unexpected stats of the string key: %+v", stringStats)
+	}
+	if stringStats.Values[0].Value != "targeted" || stringStats.Values[0].Count != 2 ||
+		stringStats.Values[1].Value != "default" || stringStats.Values[1].Count != 1 {
+		t.Errorf("Expecting the most frequent value first, got %+v", stringStats.Values)
+	}
+}
+
+func TestClient_EvaluationStats_Disabled(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+	client.GetBoolValue("key", false, nil)
+
+	if report := client.EvaluationStats(); len(report.Keys) != 0 {
+		t.Errorf("Expecting an empty report, got %v", report.Keys)
+	}
+}
+
+func TestClient_EvaluationStats_PeriodicReport(t *testing.T) {
+	reports := make(chan EvaluationStatsReport, 10)
+	config := ClientConfig{Mode: ManualPoll(), EvaluationStats: EvaluationStatsConfig{
+		Enabled:        true,
+		ReportInterval: 10 * time.Millisecond,
+		OnReport:       func(report EvaluationStatsReport) { reports <- report },
+	}}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", config, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+	client.GetBoolValue("key", false, nil)
+
+	select {
+	case report := <-reports:
+		if len(report.Keys) != 1 || report.Keys[0].Count != 1 || report.Until.Before(report.Since) {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expecting a periodic report")
+	}
+}
+
+func TestClient_EvaluationStats_CloseTwice(t *testing.T) {
+	config := ClientConfig{Mode: ManualPoll(), EvaluationStats: EvaluationStatsConfig{
+		Enabled:        true,
+		ReportInterval: time.Hour,
+		OnReport:       func(report EvaluationStatsReport) {},
+	}}
+	client := newInternal("fakeKey", config, newFakeConfigProvider())
+
+	client.Close()
+	client.Close()
+	if report := client.EvaluationStats(); len(report.Keys) != 0 {
+		t.Errorf("Expecting an empty report, got %v", report.Keys)
+	}
+}
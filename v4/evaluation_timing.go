@@ -0,0 +1,55 @@
+package configcat
+
+import (
+	"time"
+)
+
+// EvaluationTiming describes the duration of an evaluation, measured when ClientConfig.EvaluationTiming is set.
+// The phases of the evaluations served from the memoized results of Warmup are 0.
+type EvaluationTiming struct {
+	// The whole evaluation including the parsing, without the hooks.
+	Total time.Duration `json:"total"`
+	// The parsing of the config JSON, usually near 0 as the parsed configuration is reused until it changes.
+	Parse time.Duration `json:"parse"`
+	// The walk of the targeting rules and the percentage options, without the hashing.
+	Rules time.Duration `json:"rules"`
+	// The hashing of the confidential comparators and the percentage bucketing.
+	Hashing time.Duration `json:"hashing"`
+}
+
+// isHashedComparator returns true if the comparator hashes the user attribute.
+func isHashedComparator(comparator float64) bool {
+	return (comparator >= 16 && comparator <= 17) || (comparator >= 20 && comparator <= 27)
+}
+
+// timedEvaluate is like evaluate, but it also measures the rule walk and the hashing.
+func (evaluator *rolloutEvaluator) timedEvaluate(setting *compiledSetting, key string, user *User) evaluation {
+	var hashing time.Duration
+	start := time.Now()
+	result := evaluator.walk(setting, key, user, &hashing)
+	result.timing.Rules = time.Since(start) - hashing
+	result.timing.Hashing = hashing
+	return result
+}
+
+// timedMatches is like matches, but it adds the duration of the hashed comparators to hashing.
+func (evaluator *rolloutEvaluator) timedMatches(rule *compiledRule, userValue string, salt string, hashing *time.Duration) (bool, error) {
+	if hashing == nil || !isHashedComparator(rule.comparator) {
+		return evaluator.matches(rule, userValue, salt)
+	}
+	start := time.Now()
+	matched, err := evaluator.matches(rule, userValue, salt)
+	*hashing += time.Since(start)
+	return matched, err
+}
+
+// timedBucket is like bucket, but it adds the duration of the bucketing to hashing.
+func (evaluator *rolloutEvaluator) timedBucket(key string, identifier string, hashing *time.Duration) int {
+	if hashing == nil {
+		return evaluator.bucket(key, identifier)
+	}
+	start := time.Now()
+	bucket := evaluator.bucket(key, identifier)
+	*hashing += time.Since(start)
+	return bucket
+}
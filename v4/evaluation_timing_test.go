@@ -0,0 +1,72 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClient_EvaluationTiming(t *testing.T) {
+	var impressions []Impression
+	config := ClientConfig{
+		Mode:             ManualPoll(),
+		EvaluationTiming: true,
+		EvaluationStats:  EvaluationStatsConfig{Enabled: true},
+		Hooks:            Hooks{OnFlagEvaluated: func(impression Impression) { impressions = append(impressions, impression) }},
+	}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", config, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"key": { "v": "default", "p": [
+			{ "o": 0, "v": "a", "p": 50 }, { "o": 1, "v": "b", "p": 50 }
+		], "r": [
+			{ "o": 0, "a": "Email", "t": 16, "c": "` + hashValue("x@example.com") + `", "v": "sensitive" }
+		]}
+	}`})
+	client.Refresh()
+
+	client.GetStringValue("key", "", NewUserWithAdditionalAttributes("id", "y@example.com", "", nil))
+	client.GetStringValue("key", "", NewUserWithAdditionalAttributes("id", "x@example.com", "", nil))
+
+	if len(impressions) != 2 {
+		t.Fatalf("Expecting 2 impressions, got %v", impressions)
+	}
+	for _, impression := range impressions {
+		timing := impression.Timing
+		if timing == nil || timing.Hashing <= 0 || timing.Rules < 0 || timing.Total <= 0 {
+			t.Fatalf("Expecting the measured phases, got %+v", timing)
+		}
+		if timing.Total < timing.Parse+timing.Rules+timing.Hashing {
+			t.Errorf("Expecting the total to include the phases, got %+v", timing)
+		}
+	}
+
+	stats := client.EvaluationStats().Keys[0]
+	if stats.AverageDuration <= 0 || stats.MaxDuration < stats.AverageDuration {
+		t.Errorf("Expecting the aggregated durations, got %+v", stats)
+	}
+}
+
+func TestClient_EvaluationTiming_Disabled(t *testing.T) {
+	var impression Impression
+	config := ClientConfig{
+		Mode:            ManualPoll(),
+		EvaluationStats: EvaluationStatsConfig{Enabled: true},
+		Hooks:           Hooks{OnFlagEvaluated: func(evaluated Impression) { impression = evaluated }},
+	}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", config, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+	client.GetBoolValue("key", false, nil)
+
+	if impression.Timing != nil {
+		t.Errorf("Expecting no timing, got %+v", impression.Timing)
+	}
+	if stats := client.EvaluationStats().Keys[0]; stats.AverageDuration != 0 || stats.MaxDuration != 0 {
+		t.Errorf("Expecting no durations, got %+v", stats)
+	}
+}
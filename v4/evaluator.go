@@ -0,0 +1,74 @@
+package configcat
+
+import (
+	"sort"
+)
+
+// Evaluator evaluates the settings of a given config JSON without a Client, e.g. in serverless functions
+// and tests evaluating a provided config snapshot. It doesn't fetch, cache or invoke hooks.
+// It's safe for concurrent use.
+type Evaluator struct {
+	json   string
+	parser *ConfigParser
+}
+
+// NewEvaluator parses the config JSON and creates an Evaluator of its settings.
+func NewEvaluator(config []byte) (*Evaluator, error) {
+	evaluator := &Evaluator{json: string(config), parser: newParser(DefaultLogger(LogLevelWarn))}
+	if _, err := evaluator.parser.load(evaluator.json); err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+
+	return evaluator, nil
+}
+
+// Evaluate parses the config JSON and returns the value of the setting identified by the given key for the user,
+// or the default value together with the reason when the evaluation fails. Optional user argument can be passed
+// to identify the caller. Use an Evaluator to evaluate multiple settings of the same config JSON.
+func Evaluate(config []byte, key string, defaultValue interface{}, user *User) (interface{}, error) {
+	evaluator, err := NewEvaluator(config)
+	if err != nil {
+		return defaultValue, err
+	}
+
+	return evaluator.Evaluate(key, defaultValue, user)
+}
+
+// Evaluate returns the value of the setting identified by the given key for the user, or the default value
+// together with the reason when the evaluation fails. Optional user argument can be passed to identify the caller.
+func (evaluator *Evaluator) Evaluate(key string, defaultValue interface{}, user *User) (interface{}, error) {
+	details := evaluator.EvaluateDetails(key, defaultValue, user)
+	return details.Value, details.Error
+}
+
+// EvaluateDetails is like Evaluate, but it describes the result with EvaluationDetails.
+func (evaluator *Evaluator) EvaluateDetails(key string, defaultValue interface{}, user *User) (details EvaluationDetails) {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	details = EvaluationDetails{Key: key, Value: defaultValue, IsDefaultValue: true, User: user}
+	defer func() {
+		if r := recover(); r != nil {
+			details.Value, details.VariationId, details.IsDefaultValue = defaultValue, "", true
+			details.Error = newPanicError(r)
+		}
+	}()
+
+	result, err := evaluator.parser.parseVariation(evaluator.json, key, user)
+	details.MissingAttributes = result.missingAttributes
+	if err != nil {
+		details.Error = err
+		return details
+	}
+
+	details.Value, details.VariationId, details.IsDefaultValue = result.value, result.variationId, false
+	return details
+}
+
+// Keys returns the sorted keys of the settings.
+func (evaluator *Evaluator) Keys() []string {
+	keys, _ := evaluator.parser.GetAllKeys(evaluator.json)
+	sort.Strings(keys)
+	return keys
+}
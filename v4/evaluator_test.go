@@ -0,0 +1,37 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestEvaluator(t *testing.T) {
+	config := []byte(`{
+		"flag": { "v": false, "t": 0, "i": "off", "r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": true, "i": "on" } ] },
+		"text": { "v": "value", "t": 1 }
+	}`)
+	evaluator, err := NewEvaluator(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := NewUserWithAdditionalAttributes("id", "a@example.com", "", nil)
+	if details := evaluator.EvaluateDetails("flag", false, user); details.Value != true || details.VariationId != "on" || details.IsDefaultValue {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+	if value, err := evaluator.Evaluate("flag", true, nil); value != false || err != nil {
+		t.Errorf("Unexpected value without user: %v, %v", value, err)
+	}
+	if value, err := evaluator.Evaluate("missing", "default", nil); value != "default" || err == nil {
+		t.Errorf("Expecting the default value with an error, got %v, %v", value, err)
+	}
+	if keys := evaluator.Keys(); len(keys) != 2 || keys[0] != "flag" || keys[1] != "text" {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+
+	if value, err := Evaluate(config, "text", "", nil); value != "value" || err != nil {
+		t.Errorf("Unexpected stateless value: %v, %v", value, err)
+	}
+	if value, err := Evaluate([]byte("not json"), "text", "default", nil); value != "default" || err == nil {
+		t.Errorf("Expecting the default value for an invalid config, got %v, %v", value, err)
+	}
+}
@@ -4,6 +4,7 @@ import "time"
 
 type fakeConfigProvider struct {
 	result        fetchResponse
+	err           error
 	sleepDuration time.Duration
 }
 
@@ -17,6 +18,10 @@ func (fetcher *fakeConfigProvider) getConfigurationAsync() *asyncResult {
 		if fetcher.sleepDuration > 0 {
 			time.Sleep(fetcher.sleepDuration)
 		}
+		if fetcher.err != nil {
+			async.completeWithError(fetcher.err)
+			return
+		}
 		async.complete(fetcher.result)
 	}()
 
@@ -25,9 +30,16 @@ func (fetcher *fakeConfigProvider) getConfigurationAsync() *asyncResult {
 
 func (fetcher *fakeConfigProvider) SetResponse(response fetchResponse) {
 	fetcher.result = response
+	fetcher.err = nil
+}
+
+func (fetcher *fakeConfigProvider) SetError(err error) {
+	fetcher.result = fetchResponse{status: Failure}
+	fetcher.err = err
 }
 
 func (fetcher *fakeConfigProvider) SetResponseWithDelay(response fetchResponse, delayDuration time.Duration) {
 	fetcher.sleepDuration = delayDuration
 	fetcher.result = response
+	fetcher.err = nil
 }
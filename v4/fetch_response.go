@@ -1,9 +1,34 @@
 package configcat
 
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // fetchResponse represents a configuration fetch response.
 type fetchResponse struct {
 	status fetchStatus
 	body   string
+	eTag   string
+	// the freshness lifetime derived from the Cache-Control and Age headers, valid only if hasMaxAge is true
+	maxAge    time.Duration
+	hasMaxAge bool
+	// the name of the config source of the response, see ClientConfig.Sources
+	source string
+	// the configuration decoded from the body by the fetch, nil if it wasn't decoded
+	config *parsedConfig
+}
+
+// asFetchResponse converts the result of an async fetch into a fetchResponse,
+// unexpected values are treated as failures.
+func asFetchResponse(result interface{}) fetchResponse {
+	if response, ok := result.(fetchResponse); ok {
+		return response
+	}
+
+	return fetchResponse{status: Failure}
 }
 
 // isFailed returns true if the fetch is failed, otherwise false.
@@ -20,3 +45,31 @@ func (response fetchResponse) isNotModified() bool {
 func (response fetchResponse) isFetched() bool {
 	return response.status == Fetched
 }
+
+// withCacheHeaders sets the freshness lifetime of the response from the Cache-Control max-age directive
+// reduced by the Age header. The lifetime is 0 if the response mustn't be reused without revalidation.
+func (response fetchResponse) withCacheHeaders(header http.Header) fetchResponse {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			response.maxAge, response.hasMaxAge = 0, true
+			return response
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			response.maxAge, response.hasMaxAge = time.Duration(seconds)*time.Second, true
+		}
+	}
+
+	if age, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && age > 0 && response.hasMaxAge {
+		response.maxAge -= time.Duration(age) * time.Second
+		if response.maxAge < 0 {
+			response.maxAge = 0
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,83 @@
+package configcat
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fileConfigCache is a ConfigCache which stores the configuration in a file.
+type fileConfigCache struct {
+	path string
+}
+
+// NewFileCache creates a ConfigCache which stores the configuration in the file at the given path,
+// so the last downloaded configuration survives restarts.
+func NewFileCache(path string) ConfigCache {
+	return &fileConfigCache{path: path}
+}
+
+// Get reads the configuration from the file, a missing file is treated as an empty cache.
+func (cache *fileConfigCache) Get() (string, error) {
+	content, err := ioutil.ReadFile(cache.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// Set writes the configuration into the file. The content is replaced atomically,
+// so readers never observe a partially written configuration.
+func (cache *fileConfigCache) Set(value string) error {
+	return writeFileAtomically(cache.path, value)
+}
+
+// GetCircuitState reads the state of the circuit breaker from the file next to the configuration,
+// with the ".circuit" suffix.
+func (cache *fileConfigCache) GetCircuitState() (string, error) {
+	content, err := ioutil.ReadFile(cache.path + ".circuit")
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	return string(content), err
+}
+
+// SetCircuitState writes the state of the circuit breaker into the file next to the configuration,
+// the empty state removes the file.
+func (cache *fileConfigCache) SetCircuitState(state string) error {
+	if len(state) == 0 {
+		if err := os.Remove(cache.path + ".circuit"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return writeFileAtomically(cache.path+".circuit", state)
+}
+
+// writeFileAtomically replaces the content of the file with a rename, so readers never observe a partially
+// written content.
+func writeFileAtomically(path string, value string) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
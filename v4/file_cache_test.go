@@ -0,0 +1,153 @@
+package configcat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := NewFileCache(filepath.Join(dir, "config.json"))
+	value, err := cache.Get()
+	if err != nil || value != "" {
+		t.Error("Expecting empty cache")
+	}
+
+	if err := cache.Set("{}"); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err = cache.Get()
+	if err != nil || value != "{}" {
+		t.Error("Expecting the cached value")
+	}
+}
+
+func TestClient_Offline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache := NewFileCache(filepath.Join(dir, "config.json"))
+	_ = cache.Set("{ \"key\": { \"v\": true } }")
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Offline: true, Cache: cache})
+	defer client.Close()
+	client.Refresh()
+
+	if client.GetValue("key", false) != true || !client.Status().Offline {
+		t.Error("Expecting the cached value in offline mode")
+	}
+}
+
+func TestSharedFileCache_ConcurrentWriters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache := NewSharedFileCache(path, SharedFileCacheConfig{})
+			if err := cache.Set(fmt.Sprintf(`{ "writer": { "v": %d } }`, i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	value, err := NewSharedFileCache(path, SharedFileCacheConfig{}).Get()
+	if err != nil || !strings.HasPrefix(value, `{ "writer": `) {
+		t.Errorf("Expecting a complete config, got %v, %v", value, err)
+	}
+
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Error("Expecting the lock to be released")
+	}
+}
+
+func TestSharedFileCache_StaleLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path+".lock", []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Minute)
+	_ = os.Chtimes(path+".lock", past, past)
+
+	cache := NewSharedFileCache(path, SharedFileCacheConfig{LockTimeout: time.Millisecond * 100, StaleLockAge: time.Second})
+	if err := cache.Set("{}"); err != nil {
+		t.Errorf("Expecting the stale lock to be recovered, got %v", err)
+	}
+
+	_ = ioutil.WriteFile(path+".lock", []byte("1"), 0644)
+	if err := cache.Set("{ }"); err != errLockTimeout {
+		t.Errorf("Expecting lock timeout, got %v", err)
+	}
+}
+
+func TestSharedFileCache_TakenOverLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	cache := NewSharedFileCache(path, SharedFileCacheConfig{StaleLockAge: time.Second}).(*sharedFileConfigCache)
+	token, err := cache.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.takeOver("other") {
+		t.Error("Expecting the live lock to be kept")
+	}
+
+	past := time.Now().Add(-time.Minute)
+	_ = os.Chtimes(path+".lock", past, past)
+	other, err := cache.lock()
+	if err != nil || other == token {
+		t.Fatalf("Expecting the stale lock to be taken over, got %v, %v", other, err)
+	}
+	cache.unlock(token)
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Error("Expecting the lock taken over to be kept by the previous owner")
+	}
+
+	cache.unlock(other)
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Error("Expecting the lock to be released")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expecting no leftover files, got %v", files)
+	}
+
+	_ = ioutil.WriteFile(path+".lock", []byte("1"), 0644)
+	clock := &manualClock{now: time.Now().Add(time.Minute)}
+	clocked := NewSharedFileCache(path, SharedFileCacheConfig{StaleLockAge: time.Second, Clock: clock}).(*sharedFileConfigCache)
+	if clocked.takeOver("other") {
+		t.Error("Expecting the age of the lock to be measured with the wall time instead of the clock")
+	}
+}
@@ -0,0 +1,77 @@
+package configcat
+
+import (
+	"sort"
+	"sync"
+)
+
+// FlagUsageReport describes the difference between the setting keys of the configuration and the keys used by the application.
+type FlagUsageReport struct {
+	// The keys of the configuration which were never referenced by the application.
+	Unused []string
+	// The keys referenced by the application which are missing from the configuration.
+	Missing []string
+}
+
+// evaluatedKeys collects the keys evaluated by a Client.
+type evaluatedKeys struct {
+	keys map[string]struct{}
+	sync.RWMutex
+}
+
+func newEvaluatedKeys() *evaluatedKeys {
+	return &evaluatedKeys{keys: map[string]struct{}{}}
+}
+
+func (evaluated *evaluatedKeys) add(key string) {
+	evaluated.RLock()
+	_, ok := evaluated.keys[key]
+	evaluated.RUnlock()
+	if ok {
+		return
+	}
+
+	evaluated.Lock()
+	evaluated.keys[key] = struct{}{}
+	evaluated.Unlock()
+}
+
+func (evaluated *evaluatedKeys) contains(key string) bool {
+	evaluated.RLock()
+	defer evaluated.RUnlock()
+	_, ok := evaluated.keys[key]
+	return ok
+}
+
+func (evaluated *evaluatedKeys) copy() map[string]struct{} {
+	evaluated.RLock()
+	defer evaluated.RUnlock()
+	keys := make(map[string]struct{}, len(evaluated.keys))
+	for key := range evaluated.keys {
+		keys[key] = struct{}{}
+	}
+
+	return keys
+}
+
+// newFlagUsageReport compares the setting keys of the configuration with the referenced keys.
+func newFlagUsageReport(configKeys []string, referencedKeys map[string]struct{}) *FlagUsageReport {
+	report := &FlagUsageReport{Unused: []string{}, Missing: []string{}}
+	existing := make(map[string]struct{}, len(configKeys))
+	for _, key := range configKeys {
+		existing[key] = struct{}{}
+		if _, ok := referencedKeys[key]; !ok {
+			report.Unused = append(report.Unused, key)
+		}
+	}
+
+	for key := range referencedKeys {
+		if _, ok := existing[key]; !ok {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+
+	sort.Strings(report.Unused)
+	sort.Strings(report.Missing)
+	return report
+}
@@ -0,0 +1,57 @@
+//go:build go1.21
+// +build go1.21
+
+package configcat
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnsupportedType is matched by errors.Is for every UnsupportedTypeError.
+var ErrUnsupportedType = errors.New("unsupported setting type")
+
+// UnsupportedTypeError is returned by Get when the requested type isn't bool, string, int or float64.
+type UnsupportedTypeError struct {
+	// The key of the setting.
+	Key string
+	// The requested type.
+	Type reflect.Type
+}
+
+func (err *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("setting %s was requested as %v, only bool, string, int and float64 are supported", err.Key, err.Type)
+}
+
+// Is reports whether the target is ErrUnsupportedType.
+func (err *UnsupportedTypeError) Is(target error) bool {
+	return target == ErrUnsupportedType
+}
+
+// Get returns the value of the setting identified by the given key as T, which is one of bool, string, int
+// and float64. It's evaluated like by the typed getter of T (e.g. GetBoolValue for bool), so the default value
+// is returned with a *TypeMismatchError when the setting has another type. For any other T the default value
+// is returned with an *UnsupportedTypeError. Optional user argument can be passed to identify the caller.
+// It's available from Go 1.21, where the build constraint of a file enables the type parameters in a module
+// declaring an older Go version.
+func Get[T any](client *Client, key string, defaultValue T, user *User) (T, error) {
+	var value interface{}
+	var err error
+	switch typed := interface{}(defaultValue).(type) {
+	case bool:
+		value, err = client.GetBoolValue(key, typed, user)
+	case string:
+		value, err = client.GetStringValue(key, typed, user)
+	case int:
+		value, err = client.GetIntValue(key, typed, user)
+	case float64:
+		value, err = client.GetFloatValue(key, typed, user)
+	default:
+		err = &UnsupportedTypeError{Key: key, Type: reflect.TypeOf(&defaultValue).Elem()}
+		logEvent(client.logger, LogLevelError, LogEventEvaluation, logFields{key: key}, "Evaluating %s failed. Returning defaultValue: [%v]. %s.", key, defaultValue, err.Error())
+		client.reportError(err)
+		return defaultValue, err
+	}
+	return value.(T), err
+}
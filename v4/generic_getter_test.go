@@ -0,0 +1,47 @@
+//go:build go1.21
+// +build go1.21
+
+package configcat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"flag": { "v": true, "t": 0 },
+		"text": { "v": "value", "t": 1 },
+		"count": { "v": 42, "t": 2 },
+		"ratio": { "v": 0.5, "t": 3 }
+	}`})
+	client.Refresh()
+
+	if value, err := Get(client, "flag", false, nil); value != true || err != nil {
+		t.Errorf("Unexpected bool result: %v, %v", value, err)
+	}
+	if value, err := Get(client, "text", "", nil); value != "value" || err != nil {
+		t.Errorf("Unexpected string result: %v, %v", value, err)
+	}
+	if value, err := Get(client, "count", 0, nil); value != 42 || err != nil {
+		t.Errorf("Unexpected int result: %v, %v", value, err)
+	}
+	if value, err := Get(client, "ratio", 0.0, nil); value != 0.5 || err != nil {
+		t.Errorf("Unexpected float result: %v, %v", value, err)
+	}
+	if value, err := Get(client, "flag", "default", nil); value != "default" || !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expecting type mismatch, got %v, %v", value, err)
+	}
+
+	value, err := Get(client, "count", time.Second, nil)
+	var unsupported *UnsupportedTypeError
+	if value != time.Second || !errors.Is(err, ErrUnsupportedType) || !errors.As(err, &unsupported) {
+		t.Fatalf("Expecting unsupported type, got %v, %v", value, err)
+	}
+	if unsupported.Key != "count" || unsupported.Type.String() != "time.Duration" {
+		t.Errorf("Unexpected error: %+v", unsupported)
+	}
+}
@@ -5,4 +5,6 @@ go 1.13
 require (
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/sirupsen/logrus v1.4.2
+	go.uber.org/goleak v1.1.10
+	golang.org/x/text v0.3.8
 )
@@ -0,0 +1,171 @@
+package configcat
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Hooks contains optional callbacks invoked on the events of a Client.
+// The callbacks are called synchronously, so they should return quickly.
+type Hooks struct {
+	// Called after each successful flag evaluation, or after the sampled ones of the FlagEvaluatedKeys.
+	OnFlagEvaluated func(impression Impression)
+	// The fraction of the evaluations passed to OnFlagEvaluated, e.g. 0.01 for 1% of them, chosen randomly.
+	// If it's 0 (or at least 1) then all the evaluations are passed.
+	FlagEvaluatedSampleRate float64
+	// The keys of the settings whose evaluations are passed to OnFlagEvaluated. If it's empty then the
+	// evaluations of all the settings are passed.
+	FlagEvaluatedKeys []string
+	// Called when the configuration becomes older than ClientConfig.MaxStaleness because the fetches
+	// are failing, with the time elapsed since the last successful fetch. It's called again only after
+	// a successful fetch.
+	OnConfigStale func(age time.Duration)
+	// Called when the state of the circuit breaker around the config fetches changes, see ClientConfig.CircuitBreaker.
+	OnCircuitStateChanged func(state CircuitState)
+	// Called before each config download from the ConfigCat CDN (or BaseUrl) with the request, whose headers
+	// can be modified, e.g. to add the credentials of a private relay. It's not called for the configurations
+	// of a ConfigProvider or a local file.
+	OnBeforeFetch func(request *http.Request)
+	// Called after each config download from the ConfigCat CDN (or BaseUrl) with the outcome of the download,
+	// e.g. to record the fetch attempts in an audit log.
+	OnAfterFetch func(attempt FetchAttempt)
+	// Called for each key appearing in the configuration when it changes. The keys of the first configuration
+	// of the client aren't reported. It's run by the Executor of the client.
+	OnKeyAdded func(key string)
+	// Called for each key disappearing from the configuration when it changes, e.g. to catch the accidental
+	// deletions of the flags which switch the application to the default values of the code. It's run by
+	// the Executor of the client.
+	OnKeyRemoved func(key string)
+	// Called when a downloaded configuration is rejected by ClientConfig.AcceptConfig, with the rejected
+	// config JSON and the error of the acceptance check. It's run by the Executor of the client.
+	OnConfigRejected func(config string, err error)
+}
+
+// FetchAttempt describes a config download, see Hooks.OnAfterFetch.
+type FetchAttempt struct {
+	// The sent request.
+	Request *http.Request
+	// The status code of the response, 0 when no response was received.
+	StatusCode int
+	// The headers of the response, nil when no response was received.
+	Header http.Header
+	// The time elapsed from sending the request until the response was processed.
+	Duration time.Duration
+	// The error of the download, nil when it succeeded including the 304 Not Modified responses.
+	Err error
+}
+
+// hooks dispatches the events of a Client to the user hooks and the internal subscribers.
+type hooks struct {
+	onFlagEvaluated []func(impression Impression)
+	onConfigStale   func(age time.Duration)
+	onCircuitState  func(state CircuitState)
+	onKeyAdded      func(key string)
+	onKeyRemoved    func(key string)
+	onRejected      func(config string, err error)
+}
+
+func newHooks(userHooks Hooks) *hooks {
+	hooks := &hooks{onConfigStale: userHooks.OnConfigStale,
+		onCircuitState: userHooks.OnCircuitStateChanged,
+		onKeyAdded:     userHooks.OnKeyAdded,
+		onKeyRemoved:   userHooks.OnKeyRemoved,
+		onRejected:     userHooks.OnConfigRejected}
+	if userHooks.OnFlagEvaluated != nil {
+		hooks.addOnFlagEvaluated(filterFlagEvaluated(userHooks))
+	}
+
+	return hooks
+}
+
+// filterFlagEvaluated wraps the OnFlagEvaluated hook with the sampling and the key filtering of the hooks.
+func filterFlagEvaluated(userHooks Hooks) func(impression Impression) {
+	hook := userHooks.OnFlagEvaluated
+	var keys map[string]struct{}
+	if len(userHooks.FlagEvaluatedKeys) > 0 {
+		keys = make(map[string]struct{}, len(userHooks.FlagEvaluatedKeys))
+		for _, key := range userHooks.FlagEvaluatedKeys {
+			keys[key] = struct{}{}
+		}
+	}
+	rate := userHooks.FlagEvaluatedSampleRate
+	if keys == nil && (rate <= 0 || rate >= 1) {
+		return hook
+	}
+
+	return func(impression Impression) {
+		if keys != nil {
+			if _, ok := keys[impression.Key]; !ok {
+				return
+			}
+		}
+		if rate > 0 && rate < 1 && rand.Float64() >= rate {
+			return
+		}
+		hook(impression)
+	}
+}
+
+func (hooks *hooks) addOnFlagEvaluated(hook func(impression Impression)) {
+	hooks.onFlagEvaluated = append(hooks.onFlagEvaluated, hook)
+}
+
+func (hooks *hooks) hasOnFlagEvaluated() bool {
+	return len(hooks.onFlagEvaluated) > 0
+}
+
+func (hooks *hooks) flagEvaluated(impression Impression) {
+	for _, hook := range hooks.onFlagEvaluated {
+		hook(impression)
+	}
+}
+
+func (hooks *hooks) configStale(age time.Duration) {
+	if hooks.onConfigStale != nil {
+		hooks.onConfigStale(age)
+	}
+}
+
+func (hooks *hooks) configRejected(config string, err error) {
+	if hooks.onRejected != nil {
+		hooks.onRejected(config, err)
+	}
+}
+
+func (hooks *hooks) circuitStateChanged(state CircuitState) {
+	if hooks.onCircuitState != nil {
+		hooks.onCircuitState(state)
+	}
+}
+
+// keysChanged reports the keys added to and removed from the configuration by a change. The removal of a key
+// evaluated by the client is logged as well, as its evaluations fall back to the default value from now on.
+// The hooks are run by the executor, so they can call the client and their panics are recovered.
+func (hooks *hooks) keysChanged(previous string, current string, evaluated *evaluatedKeys, logger Logger, executor *callbackExecutor) {
+	if len(previous) == 0 {
+		return
+	}
+
+	diff := diffConfigs(previous, current)
+	for _, key := range diff.Removed {
+		if evaluated.contains(key) {
+			logEvent(logger, LogLevelWarn, LogEventEvaluation, logFields{key: key}, "Setting %s evaluated by the application "+
+				"was removed from the configuration, its default value is served from now on.", key)
+		}
+		if hooks.onKeyRemoved != nil {
+			key := key
+			executor.execute(func() {
+				hooks.onKeyRemoved(key)
+			})
+		}
+	}
+	if hooks.onKeyAdded != nil {
+		for _, key := range diff.Added {
+			key := key
+			executor.execute(func() {
+				hooks.onKeyAdded(key)
+			})
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHooks_FlagEvaluatedFiltering(t *testing.T) {
+	var evaluated []string
+	hooks := newHooks(Hooks{
+		OnFlagEvaluated:   func(impression Impression) { evaluated = append(evaluated, impression.Key) },
+		FlagEvaluatedKeys: []string{"audited"},
+	})
+	hooks.flagEvaluated(Impression{Key: "audited"})
+	hooks.flagEvaluated(Impression{Key: "other"})
+	if len(evaluated) != 1 || evaluated[0] != "audited" {
+		t.Errorf("Expecting only the audited key, got %v", evaluated)
+	}
+}
+
+func TestHooks_FlagEvaluatedSampling(t *testing.T) {
+	count := 0
+	hooks := newHooks(Hooks{
+		OnFlagEvaluated:         func(impression Impression) { count++ },
+		FlagEvaluatedSampleRate: 0.1,
+	})
+	for i := 0; i < 10000; i++ {
+		hooks.flagEvaluated(Impression{Key: "key"})
+	}
+	if count < 800 || count > 1200 {
+		t.Errorf("Expecting about 10%% of the evaluations, got %d", count)
+	}
+
+	count = 0
+	hooks = newHooks(Hooks{OnFlagEvaluated: func(impression Impression) { count++ }})
+	for i := 0; i < 100; i++ {
+		hooks.flagEvaluated(Impression{Key: "key"})
+	}
+	if count != 100 {
+		t.Errorf("Expecting all the evaluations without sampling, got %d", count)
+	}
+}
+
+func TestClient_KeyLifecycleHooks(t *testing.T) {
+	var added, removed []string
+	logger := &recordingLogger{Logger: DefaultLogger(LogLevelError)}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Logger: &warnfRecorder{logger}, Executor: inlineExecutor, Hooks: Hooks{
+		OnKeyAdded:   func(key string) { added = append(added, key) },
+		OnKeyRemoved: func(key string) { removed = append(removed, key) },
+	}}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "a": { "v": true }, "b": { "v": true } }`})
+	client.Refresh()
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("Expecting no events for the first config, got %v, %v", added, removed)
+	}
+	client.GetValue("a", false)
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "p": { "s": "salt" }, "b": { "v": false }, "d": { "v": true }, "c": { "v": true } }`})
+	client.Refresh()
+	if len(added) != 2 || added[0] != "c" || added[1] != "d" || len(removed) != 1 || removed[0] != "a" {
+		t.Errorf("Unexpected events: added %v, removed %v", added, removed)
+	}
+	if len(logger.messages) != 1 {
+		t.Errorf("Expecting a warning about the removed evaluated key, got %v", logger.messages)
+	}
+}
+
+// inlineExecutor runs the callbacks on the calling goroutine, so the tests can check them synchronously.
+var inlineExecutor = ExecutorFunc(func(task func()) { task() })
+
+func TestClient_KeyLifecycleHooksReentrant(t *testing.T) {
+	errs := make(chan error, 1)
+	added := make(chan string, 1)
+	fetcher := newFakeConfigProvider()
+	var client *Client
+	client = newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Logger: DefaultLogger(LogLevelFatal), OnError: func(err error) { errs <- err }, Hooks: Hooks{
+		OnKeyAdded: func(key string) {
+			client.Refresh()
+			added <- key
+		},
+		OnKeyRemoved: func(key string) { panic("fake panicking hook") },
+	}}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "a": { "v": true } }`})
+	client.Refresh()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "b": { "v": true } }`})
+	client.Refresh()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case key := <-added:
+			if key != "b" {
+				t.Errorf("Expecting the added key, got %s", key)
+			}
+		case err := <-errs:
+			if _, ok := err.(*PanicError); !ok {
+				t.Errorf("Expecting the recovered panic of the hook, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expecting the hooks to run without a deadlock")
+		}
+	}
+}
+
+// warnfRecorder records the formatted warnings with the recordingLogger.
+type warnfRecorder struct {
+	*recordingLogger
+}
+
+func (logger *warnfRecorder) Warnf(format string, args ...interface{}) {
+	logger.Warn(fmt.Sprintf(format, args...))
+}
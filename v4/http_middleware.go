@@ -0,0 +1,68 @@
+package configcat
+
+import (
+	"context"
+	"net/http"
+)
+
+type snapshotContextKey struct{}
+
+// Middleware returns an http.Handler which attaches a Snapshot of the client's configuration to the
+// context of every request before passing it to the next handler. The optional getUser function
+// is used to identify the user of the request, which is attached to the context by WithUser as well.
+// Use FromContext to retrieve the snapshot in the handlers.
+func Middleware(client *Client, getUser func(r *http.Request) *User, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user *User
+		if getUser != nil {
+			user = getUser(r)
+		}
+
+		ctx := WithUser(NewContext(r.Context(), client.Snapshot(user)), user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// NewContext returns a copy of the given context carrying the snapshot.
+func NewContext(ctx context.Context, snapshot *Snapshot) context.Context {
+	return context.WithValue(ctx, snapshotContextKey{}, snapshot)
+}
+
+// FromContext returns the Snapshot attached to the context, or nil if there is none.
+func FromContext(ctx context.Context) *Snapshot {
+	snapshot, _ := ctx.Value(snapshotContextKey{}).(*Snapshot)
+	return snapshot
+}
+
+// ValueFromContext returns a value from the Snapshot attached to the context identified by the given key.
+// The default value is returned when there is no snapshot attached.
+func ValueFromContext(ctx context.Context, key string, defaultValue interface{}) interface{} {
+	snapshot := FromContext(ctx)
+	if snapshot == nil {
+		return defaultValue
+	}
+
+	return snapshot.GetValue(key, defaultValue)
+}
+
+// BoolFromContext returns a boolean value from the Snapshot attached to the context identified by the given key.
+// The default value is returned when there is no snapshot attached or the value is not a boolean.
+func BoolFromContext(ctx context.Context, key string, defaultValue bool) bool {
+	value, ok := ValueFromContext(ctx, key, defaultValue).(bool)
+	if !ok {
+		return defaultValue
+	}
+
+	return value
+}
+
+// StringFromContext returns a text value from the Snapshot attached to the context identified by the given key.
+// The default value is returned when there is no snapshot attached or the value is not a text.
+func StringFromContext(ctx context.Context, key string, defaultValue string) string {
+	value, ok := ValueFromContext(ctx, key, defaultValue).(string)
+	if !ok {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,45 @@
+package configcat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+
+	var enabled bool
+	var user *User
+	handler := Middleware(client, func(r *http.Request) *User {
+		return NewUser(r.Header.Get("X-User"))
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enabled = BoolFromContext(r.Context(), "key", false)
+		user = FromContext(r.Context()).User()
+		if UserFromContext(r.Context()) != user {
+			t.Error("Expecting the user of the request attached to the context")
+		}
+	}))
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-User", "user-id")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if !enabled {
+		t.Error("Expecting true")
+	}
+
+	if user == nil || user.GetAttribute("identifier") != "user-id" {
+		t.Error("Expecting the user of the request")
+	}
+}
+
+func TestBoolFromContext_WithoutSnapshot(t *testing.T) {
+	if !BoolFromContext(context.Background(), "key", true) {
+		t.Error("Expecting default value")
+	}
+}
@@ -0,0 +1,164 @@
+package configcat
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Impression describes a flag evaluation.
+type Impression struct {
+	// The key of the evaluated setting.
+	Key string `json:"key"`
+	// The variation ID of the evaluated value.
+	VariationId string `json:"variationId"`
+	// The identifier of the user the setting was evaluated for, empty if there was no user.
+	UserId string `json:"userId,omitempty"`
+	// The time of the evaluation.
+	Timestamp time.Time `json:"timestamp"`
+	// The labels of the client (see ClientConfig.Labels), shared by the impressions so they mustn't be modified.
+	Labels map[string]string `json:"labels,omitempty"`
+	// The duration of the evaluation, nil unless ClientConfig.EvaluationTiming is set.
+	Timing *EvaluationTiming `json:"timing,omitempty"`
+}
+
+// ImpressionSink is an adapter to use an ordinary function as an EvaluationExporter.
+type ImpressionSink func(impressions []Impression) error
+
+// Export calls sink(impressions).
+func (sink ImpressionSink) Export(impressions []Impression) error {
+	return sink(impressions)
+}
+
+// HttpImpressionSink creates an ImpressionSink which posts the batches as a JSON array to the given url,
+// like NewHttpExporter. If the http client is nil then http.DefaultClient is used.
+func HttpImpressionSink(url string, client *http.Client) ImpressionSink {
+	return NewHttpExporter(url, client).Export
+}
+
+// ImpressionConfig describes the configuration of the impression recording.
+type ImpressionConfig struct {
+	// The exporter receiving the recorded impressions. The recording is disabled when it's nil.
+	Exporter EvaluationExporter
+	// The interval of the delivery of the recorded impressions to the exporter.
+	FlushInterval time.Duration
+	// The maximum number of impressions buffered between two deliveries.
+	// When the buffer is full, the oldest impressions are dropped.
+	BufferSize int
+}
+
+// impressionRecorder buffers the impressions in a ring buffer and delivers them to the exporter periodically.
+type impressionRecorder struct {
+	exporter EvaluationExporter
+	logger   Logger
+	buffer   []Impression
+	start    int
+	count    int
+	dropped  int
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	sync.Mutex
+	flushMutex sync.Mutex
+}
+
+// newImpressionRecorder initializes a new impressionRecorder. If periodic is false then the impressions are
+// only delivered by flush and close, without a background goroutine.
+func newImpressionRecorder(config ImpressionConfig, logger Logger, clock Clock, periodic bool) *impressionRecorder {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second * 10
+	}
+
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+
+	recorder := &impressionRecorder{
+		exporter: config.Exporter,
+		logger:   logger,
+		buffer:   make([]Impression, config.BufferSize),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	if !periodic {
+		close(recorder.stopped)
+		return recorder
+	}
+
+	go func() {
+		defer close(recorder.stopped)
+		ticker := clock.NewTicker(config.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-recorder.stop:
+				return
+			case <-ticker.C():
+				_ = recorder.flush()
+			}
+		}
+	}()
+
+	return recorder
+}
+
+// record adds an impression to the buffer, overwriting the oldest one when the buffer is full.
+func (recorder *impressionRecorder) record(impression Impression) {
+	recorder.Lock()
+	defer recorder.Unlock()
+	size := len(recorder.buffer)
+	recorder.buffer[(recorder.start+recorder.count)%size] = impression
+	if recorder.count < size {
+		recorder.count++
+		return
+	}
+
+	recorder.start = (recorder.start + 1) % size
+	recorder.dropped++
+}
+
+// buffered returns the number of the impressions waiting for delivery and the size of the buffer.
+func (recorder *impressionRecorder) buffered() (count int, size int) {
+	recorder.Lock()
+	defer recorder.Unlock()
+	return recorder.count, len(recorder.buffer)
+}
+
+// flush delivers the buffered impressions to the exporter.
+func (recorder *impressionRecorder) flush() error {
+	recorder.flushMutex.Lock()
+	defer recorder.flushMutex.Unlock()
+
+	recorder.Lock()
+	batch := make([]Impression, recorder.count)
+	for i := range batch {
+		batch[i] = recorder.buffer[(recorder.start+i)%len(recorder.buffer)]
+	}
+	dropped := recorder.dropped
+	recorder.start, recorder.count, recorder.dropped = 0, 0, 0
+	recorder.Unlock()
+
+	if dropped > 0 {
+		recorder.logger.Warnf("The impression buffer was full, %d impressions were dropped.", dropped)
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := recorder.exporter.Export(batch)
+	if err != nil {
+		recorder.logger.Errorf("Delivering %d impressions failed: %s.", len(batch), err.Error())
+	}
+	return err
+}
+
+// close stops the periodic delivery and flushes the remaining impressions, it can be called repeatedly.
+func (recorder *impressionRecorder) close() error {
+	recorder.stopOnce.Do(func() {
+		close(recorder.stop)
+	})
+	<-recorder.stopped
+	return recorder.flush()
+}
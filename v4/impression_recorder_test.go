@@ -0,0 +1,100 @@
+package configcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestImpressionRecorder_RingBuffer(t *testing.T) {
+	var delivered []Impression
+	recorder := newImpressionRecorder(ImpressionConfig{
+		Exporter: ImpressionSink(func(impressions []Impression) error {
+			delivered = impressions
+			return nil
+		}),
+		FlushInterval: time.Hour,
+		BufferSize:    2,
+	}, DefaultLogger(LogLevelFatal), systemClock{}, true)
+
+	recorder.record(Impression{Key: "key1"})
+	recorder.record(Impression{Key: "key2"})
+	recorder.record(Impression{Key: "key3"})
+	_ = recorder.close()
+
+	if len(delivered) != 2 || delivered[0].Key != "key2" || delivered[1].Key != "key3" {
+		t.Errorf("Expecting the latest 2 impressions, got %v", delivered)
+	}
+}
+
+func TestClient_Impressions(t *testing.T) {
+	c := make(chan []Impression, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var impressions []Impression
+		_ = json.NewDecoder(r.Body).Decode(&impressions)
+		c <- impressions
+	}))
+	defer server.Close()
+
+	config := ClientConfig{Mode: ManualPoll(), Impressions: ImpressionConfig{Exporter: HttpImpressionSink(server.URL, nil)}}
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", config, fetcher)
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "{ \"key\": { \"v\": true, \"i\": \"id\" } }"})
+	client.Refresh()
+	client.GetValueForUser("key", false, NewUser("user"))
+	client.Close()
+
+	impressions := <-c
+	if len(impressions) != 1 || impressions[0].Key != "key" || impressions[0].VariationId != "id" || impressions[0].UserId != "user" {
+		t.Errorf("Unexpected impressions: %v", impressions)
+	}
+}
+
+func TestClient_Impressions_CloseTwice(t *testing.T) {
+	var delivered int
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode: ManualPoll(),
+		Impressions: ImpressionConfig{Exporter: ImpressionSink(func(impressions []Impression) error {
+			delivered += len(impressions)
+			return nil
+		})},
+	}, fetcher)
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+	client.GetBoolValue("key", false, nil)
+
+	client.Close()
+	client.Close()
+	if delivered != 1 {
+		t.Errorf("Expecting the impression delivered once, got %d", delivered)
+	}
+}
+
+func TestImpressionRecorder_Clock(t *testing.T) {
+	delivered := make(chan []Impression, 1)
+	clock := &tickingClock{ticks: make(chan time.Time)}
+	recorder := newImpressionRecorder(ImpressionConfig{
+		Exporter: ImpressionSink(func(impressions []Impression) error {
+			delivered <- impressions
+			return nil
+		}),
+		FlushInterval: time.Millisecond,
+	}, DefaultLogger(LogLevelFatal), clock, true)
+	defer recorder.close()
+
+	recorder.record(Impression{Key: "key"})
+	select {
+	case <-delivered:
+		t.Fatal("Expecting no delivery without a tick")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	clock.ticks <- time.Now()
+	if impressions := <-delivered; len(impressions) != 1 || impressions[0].Key != "key" {
+		t.Errorf("Expecting the impression to be delivered on the tick, got %v", impressions)
+	}
+}
@@ -0,0 +1,122 @@
+package configcat
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// ConfigVerifier checks the integrity of a downloaded config JSON with the signature or checksum delivered
+// alongside it, and returns an error if the config JSON must be rejected.
+type ConfigVerifier func(body string, signature string) error
+
+// IntegrityConfig describes the verification of the downloaded configurations before they are accepted.
+type IntegrityConfig struct {
+	// Verifies the downloaded config JSONs, e.g. SHA256Checksum, HMACSHA256Signature or Ed25519Signature.
+	// The verification is disabled when it's nil.
+	Verify ConfigVerifier
+	// The response header carrying the signature or checksum, "X-Config-Signature" by default.
+	// The ConfigProvider implementations deliver it in ConfigResponse.Signature instead.
+	Header string
+}
+
+// IntegrityError describes a downloaded config JSON rejected by the ConfigVerifier.
+// The previously accepted configuration is served instead.
+type IntegrityError struct {
+	// The error of the verification.
+	Err error
+}
+
+// Error is the error message.
+func (e *IntegrityError) Error() string {
+	return "config integrity verification failed: " + e.Err.Error()
+}
+
+// Unwrap returns the error of the verification.
+func (e *IntegrityError) Unwrap() error {
+	return e.Err
+}
+
+// ErrSignatureMismatch is returned by the built-in verifiers when the signature doesn't match the config JSON.
+var ErrSignatureMismatch = errors.New("signature mismatch")
+
+// ErrSignatureMissing is returned by the built-in verifiers when no signature was delivered with the config JSON.
+var ErrSignatureMissing = errors.New("signature missing")
+
+// SHA256Checksum returns a ConfigVerifier accepting the config JSONs whose SHA-256 hash is the hex or base64
+// encoded signature. It detects corrupted downloads, but not deliberate tampering.
+func SHA256Checksum() ConfigVerifier {
+	return func(body string, signature string) error {
+		sum := sha256.Sum256([]byte(body))
+		return compareSignature(sum[:], signature)
+	}
+}
+
+// HMACSHA256Signature returns a ConfigVerifier accepting the config JSONs whose HMAC-SHA256 with the given key is
+// the hex or base64 encoded signature.
+func HMACSHA256Signature(key []byte) ConfigVerifier {
+	return func(body string, signature string) error {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(body))
+		return compareSignature(mac.Sum(nil), signature)
+	}
+}
+
+// Ed25519Signature returns a ConfigVerifier accepting the config JSONs signed by the private pair of the given
+// public key, the signature is hex or base64 encoded.
+func Ed25519Signature(publicKey ed25519.PublicKey) ConfigVerifier {
+	return func(body string, signature string) error {
+		decoded, err := decodeSignature(signature)
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(publicKey, []byte(body), decoded) {
+			return ErrSignatureMismatch
+		}
+		return nil
+	}
+}
+
+func compareSignature(expected []byte, signature string) error {
+	decoded, err := decodeSignature(signature)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, decoded) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// decodeSignature decodes a hex or a (standard or URL) base64 encoded signature.
+func decodeSignature(signature string) ([]byte, error) {
+	signature = strings.TrimSpace(signature)
+	if len(signature) == 0 {
+		return nil, ErrSignatureMissing
+	}
+	if decoded, err := hex.DecodeString(signature); err == nil {
+		return decoded, nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil {
+		return decoded, nil
+	}
+	if decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(signature, "=")); err == nil {
+		return decoded, nil
+	}
+	return nil, errors.New("signature is neither hex nor base64 encoded")
+}
+
+// verifyIntegrity checks the downloaded config JSON with the verifier, nil verifiers accept everything.
+func verifyIntegrity(verify ConfigVerifier, body string, signature string) error {
+	if verify == nil {
+		return nil
+	}
+	if err := verify(body, signature); err != nil {
+		return &IntegrityError{Err: err}
+	}
+	return nil
+}
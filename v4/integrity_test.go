@@ -0,0 +1,108 @@
+package configcat
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigVerifiers(t *testing.T) {
+	body := `{ "key": { "v": true, "t": 0 } }`
+	sum := sha256.Sum256([]byte(body))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(body))
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name      string
+		verify    ConfigVerifier
+		signature string
+		expected  error
+	}{
+		{"checksum hex", SHA256Checksum(), hex.EncodeToString(sum[:]), nil},
+		{"checksum base64", SHA256Checksum(), base64.StdEncoding.EncodeToString(sum[:]), nil},
+		{"checksum mismatch", SHA256Checksum(), hex.EncodeToString(sum[1:]), ErrSignatureMismatch},
+		{"checksum missing", SHA256Checksum(), "", ErrSignatureMissing},
+		{"hmac", HMACSHA256Signature([]byte("secret")), hex.EncodeToString(mac.Sum(nil)), nil},
+		{"hmac wrong key", HMACSHA256Signature([]byte("other")), hex.EncodeToString(mac.Sum(nil)), ErrSignatureMismatch},
+		{"ed25519", Ed25519Signature(publicKey), base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(body))), nil},
+		{"ed25519 tampered", Ed25519Signature(publicKey), base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(body+" "))), ErrSignatureMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.verify(body, test.signature); !errors.Is(err, test.expected) || (test.expected == nil && err != nil) {
+				t.Errorf("Expecting %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfigFetcher_Integrity(t *testing.T) {
+	body := `{ "key": { "v": true, "t": 0 } }`
+	signature := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Config-Signature", signature)
+		w.Header().Set("Etag", "etag")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	config := defaultConfig()
+	config.BaseUrl = server.URL
+	config.Integrity.Verify = HMACSHA256Signature([]byte("secret"))
+	fetcher := newConfigFetcher("fakeKey", config)
+
+	signature = "00"
+	_, err := fetcher.getConfigurationAsync().getWithError()
+	var integrityErr *IntegrityError
+	if !errors.As(err, &integrityErr) || !errors.Is(err, ErrSignatureMismatch) || fetcher.eTag != "" {
+		t.Fatalf("Expecting the tampered config to be rejected, got %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(body))
+	signature = hex.EncodeToString(mac.Sum(nil))
+	result, err := fetcher.getConfigurationAsync().getWithError()
+	if err != nil || asFetchResponse(result).body != body {
+		t.Errorf("Expecting the signed config to be accepted, got %v, %v", result, err)
+	}
+}
+
+type signedConfigProvider struct {
+	response ConfigResponse
+}
+
+func (provider signedConfigProvider) GetConfig(ctx context.Context, eTag string) (ConfigResponse, error) {
+	return provider.response, nil
+}
+
+func TestCustomConfigProvider_Integrity(t *testing.T) {
+	body := `{ "key": { "v": "mirrored", "t": 1 } }`
+	sum := sha256.Sum256([]byte(body))
+	config := ClientConfig{Mode: ManualPoll(), Integrity: IntegrityConfig{Verify: SHA256Checksum()}}
+
+	config.ConfigProvider = signedConfigProvider{ConfigResponse{Body: body, Signature: "bad"}}
+	client := NewCustomClient("fakeKey", config)
+	if err := client.RefreshWithContext(context.Background()); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("Expecting the config to be rejected, got %v", err)
+	}
+	if value := client.GetValue("key", "default"); value != "default" {
+		t.Errorf("Expecting the rejected config not to be served, got %v", value)
+	}
+	client.Close()
+
+	config.ConfigProvider = signedConfigProvider{ConfigResponse{Body: body, Signature: hex.EncodeToString(sum[:])}}
+	client = NewCustomClient("fakeKey", config)
+	defer client.Close()
+	client.Refresh()
+	if value := client.GetValue("key", "default"); value != "mirrored" {
+		t.Errorf("Expecting the verified config, got %v", value)
+	}
+}
@@ -0,0 +1,168 @@
+package configcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The events of the entries written by the structured loggers, see NewJSONLogger and NewSlogLogger. The fetches,
+// the evaluations and the cache operations log their messages with their event, the other messages of the client
+// are logged with LogEventClient.
+const (
+	// LogEventFetch is the event of the messages about the config fetches, the polling and the refreshes.
+	LogEventFetch = "fetch"
+	// LogEventCache is the event of the messages about the cache operations.
+	LogEventCache = "cache"
+	// LogEventEvaluation is the event of the messages about the flag evaluations.
+	LogEventEvaluation = "evaluation"
+	// LogEventClient is the event of the other messages of the client.
+	LogEventClient = "client"
+)
+
+// jsonLogEntry is a log entry written by the JSON logger.
+type jsonLogEntry struct {
+	Time     string            `json:"time"`
+	Level    string            `json:"level"`
+	Event    string            `json:"event"`
+	Message  string            `json:"message"`
+	Key      string            `json:"key,omitempty"`
+	Status   string            `json:"status,omitempty"`
+	ETag     string            `json:"etag,omitempty"`
+	Duration int64             `json:"duration,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// jsonLogger is a Logger writing a JSON object per line.
+type jsonLogger struct {
+	mu     *sync.Mutex
+	writer io.Writer
+	level  LogLevel
+	labels map[string]string
+	now    func() time.Time
+}
+
+// NewJSONLogger creates a Logger writing the messages of the specified level and above to the writer as JSON
+// objects, one per line, e.g. for shipping the logs to ELK or Datadog. The names of the fields are stable:
+//
+//	{"time":"2024-01-02T15:04:05.000000000Z","level":"error","event":"fetch","message":"Config fetch failed: ...","status":"failed","duration":1500000,"labels":{"service":"api"}}
+//
+// The time is in RFC 3339 format with nanoseconds in UTC, the level is "debug", "info", "warn" or "error", and the
+// event is one of the LogEvent constants. The fetches add the "status" (see Status.LastFetchStatus), the "etag" of
+// the fetched config and the "duration" of the fetch in nanoseconds, the evaluations add the "key" of the setting.
+// The fields without a value are left out, as are the labels (the ClientConfig.Labels) when there are none.
+func NewJSONLogger(writer io.Writer, level LogLevel) Logger {
+	return &jsonLogger{mu: &sync.Mutex{}, writer: writer, level: level, now: time.Now}
+}
+
+// withLabels returns the logger attaching the labels to its entries.
+func (logger *jsonLogger) withLabels(labels map[string]string) Logger {
+	labeled := *logger
+	labeled.labels = labels
+	return &labeled
+}
+
+// IsLevelEnabled tells whether the logger writes the given level.
+func (logger *jsonLogger) IsLevelEnabled(level logrus.Level) bool {
+	return LogLevel(level) <= logger.level
+}
+
+// logEvent writes the message with its event and fields.
+func (logger *jsonLogger) logEvent(level LogLevel, event string, fields logFields, message string) {
+	logger.writeEntry(level, jsonLogEntry{
+		Event:    event,
+		Message:  message,
+		Key:      fields.key,
+		Status:   fields.status,
+		ETag:     fields.eTag,
+		Duration: int64(fields.duration),
+	})
+}
+
+func (logger *jsonLogger) write(level LogLevel, message string) {
+	logger.writeEntry(level, jsonLogEntry{Event: LogEventClient, Message: message})
+}
+
+func (logger *jsonLogger) writeEntry(level LogLevel, entry jsonLogEntry) {
+	if level > logger.level {
+		return
+	}
+
+	entry.Time = logger.now().UTC().Format(time.RFC3339Nano)
+	entry.Level = jsonLevelName(level)
+	entry.Labels = logger.labels
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	_, _ = logger.writer.Write(append(line, '\n'))
+}
+
+// jsonLevelName returns the name of the level in the entries.
+func jsonLevelName(level LogLevel) string {
+	switch level {
+	case LogLevelDebug, LogLevelTrace:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	}
+
+	return "error"
+}
+
+func (logger *jsonLogger) Debugf(format string, args ...interface{}) {
+	logger.write(LogLevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (logger *jsonLogger) Infof(format string, args ...interface{}) {
+	logger.write(LogLevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (logger *jsonLogger) Warnf(format string, args ...interface{}) {
+	logger.write(LogLevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (logger *jsonLogger) Errorf(format string, args ...interface{}) {
+	logger.write(LogLevelError, fmt.Sprintf(format, args...))
+}
+
+func (logger *jsonLogger) Debug(args ...interface{}) {
+	logger.write(LogLevelDebug, fmt.Sprint(args...))
+}
+
+func (logger *jsonLogger) Info(args ...interface{}) {
+	logger.write(LogLevelInfo, fmt.Sprint(args...))
+}
+
+func (logger *jsonLogger) Warn(args ...interface{}) {
+	logger.write(LogLevelWarn, fmt.Sprint(args...))
+}
+
+func (logger *jsonLogger) Error(args ...interface{}) {
+	logger.write(LogLevelError, fmt.Sprint(args...))
+}
+
+func (logger *jsonLogger) Debugln(args ...interface{}) {
+	logger.write(LogLevelDebug, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *jsonLogger) Infoln(args ...interface{}) {
+	logger.write(LogLevelInfo, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *jsonLogger) Warnln(args ...interface{}) {
+	logger.write(LogLevelWarn, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *jsonLogger) Errorln(args ...interface{}) {
+	logger.write(LogLevelError, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
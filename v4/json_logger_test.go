@@ -0,0 +1,95 @@
+package configcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var output bytes.Buffer
+	logger := NewJSONLogger(&output, LogLevelInfo).(*jsonLogger)
+	logger.now = func() time.Time { return time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC) }
+	labeled := newLabelLogger(logger, map[string]string{"service": "api"})
+
+	fields := logFields{status: Failure.String(), duration: 1500 * time.Microsecond}
+	logEvent(labeled, LogLevelError, LogEventFetch, fields, "Config fetch failed: %s.", "timeout")
+	logEvent(labeled, LogLevelDebug, LogEventEvaluation, logFields{key: "key"}, "Evaluating GetValue(%s).", "key")
+	logger.Warnln("Saving into the cache failed,", "disk full")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expecting 2 entries, got %q", lines)
+	}
+	expected := `{"time":"2024-01-02T15:04:05Z","level":"error","event":"fetch","message":"Config fetch failed: timeout.",` +
+		`"status":"failed","duration":1500000,"labels":{"service":"api"}}`
+	if lines[0] != expected {
+		t.Errorf("Unexpected entry: %s", lines[0])
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["event"] != LogEventClient || entry["level"] != "warn" || entry["labels"] != nil || entry["status"] != nil {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if !isLogLevelEnabled(labeled, LogLevelInfo) || isLogLevelEnabled(labeled, LogLevelDebug) {
+		t.Error("Expecting the level of the logger to be reported")
+	}
+}
+
+func TestLogEvent_PlainLogger(t *testing.T) {
+	recorder := &warnfRecorder{&recordingLogger{Logger: DefaultLogger(LogLevelError)}}
+	logEvent(recorder, LogLevelWarn, LogEventCache, logFields{}, "Reading from the cache failed, %s", "timeout")
+
+	if len(recorder.messages) != 1 || recorder.messages[0] != "Reading from the cache failed, timeout" {
+		t.Errorf("Expecting the message only, got %q", recorder.messages)
+	}
+}
+
+func TestClient_JSONLoggerEvents(t *testing.T) {
+	body := fmt.Sprintf(jsonFormat, "key", "true")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Etag", "etag1")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	logger := NewJSONLogger(&output, LogLevelDebug)
+	client := NewCustomClient("fakeKey", ClientConfig{
+		Mode:           ManualPoll(),
+		BaseUrl:        server.URL,
+		Logger:         logger,
+		LogDedupWindow: time.Minute,
+	})
+	client.Refresh()
+	client.GetValue("key", false)
+	client.Close()
+
+	var fetched, evaluated map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		switch entry["message"] {
+		case "Config fetch succeeded: new config fetched.":
+			fetched = entry
+		case "Evaluating GetValue(key).":
+			evaluated = entry
+		}
+	}
+	if fetched["event"] != LogEventFetch || fetched["status"] != "fetched" || fetched["etag"] != "etag1" ||
+		fetched["duration"] == nil {
+		t.Errorf("Unexpected fetch entry: %v", fetched)
+	}
+	if evaluated["event"] != LogEventEvaluation || evaluated["key"] != "key" {
+		t.Errorf("Unexpected evaluation entry: %v", evaluated)
+	}
+}
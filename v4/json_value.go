@@ -0,0 +1,209 @@
+package configcat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrInvalidJSONValue is matched by errors.Is for every JSONValueError.
+var ErrInvalidJSONValue = errors.New("invalid JSON setting value")
+
+// JSONValueError is returned by GetJSONValue when the value of the setting can't be decoded into the target.
+type JSONValueError struct {
+	// The key of the setting.
+	Key string
+	// The error of the decoding.
+	Err error
+}
+
+func (err *JSONValueError) Error() string {
+	return fmt.Sprintf("the value of setting %s can't be decoded as JSON: %s", err.Key, err.Err.Error())
+}
+
+// Unwrap returns the error of the decoding.
+func (err *JSONValueError) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether the target is ErrInvalidJSONValue.
+func (err *JSONValueError) Is(target error) bool {
+	return target == ErrInvalidJSONValue
+}
+
+// GetJSONValue evaluates a string setting holding a JSON document (e.g. an object or an array) identified by the
+// given key and unmarshals it into the target, which must be a non-nil pointer, otherwise a
+// *json.InvalidUnmarshalError is returned. Optional user argument can be passed to identify the caller.
+// The target is left unchanged when the evaluation or the decoding fails.
+//
+// The decoded values are cached per configuration version and target type, so repeated calls don't unmarshal
+// the same document again. Every call gets its own copy of the maps, slices and pointers of the cached value,
+// so modifying it doesn't affect the other calls. Without a MemoizationBudget at most 1000 values are cached.
+func (client *Client) GetJSONValue(key string, target interface{}, user *User) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(target)}
+	}
+
+	value, err := client.getTypedValue(key, StringSetting, "", user)
+	if err != nil {
+		return err
+	}
+	text := value.(string)
+
+	config, _ := client.parser.load(client.servedConfiguration())
+	decoded, err := client.jsonValues.decode(config, text, targetValue.Type().Elem())
+	if err != nil {
+		err = &JSONValueError{Key: key, Err: err}
+		client.logger.Errorf("%s.", err.Error())
+		client.reportError(err)
+		return err
+	}
+
+	targetValue.Elem().Set(decoded)
+	return nil
+}
+
+// maxJSONValueEntries is the maximum number of the cached values of a configuration without a memoization budget.
+const maxJSONValueEntries = 1000
+
+// jsonValueCache caches the decoded JSON setting values of a configuration.
+type jsonValueCache struct {
+	budget *memoBudget
+	// the configuration of the cached values, the cache is emptied when it changes
+	config  *parsedConfig
+	entries map[jsonValueKey]jsonValueEntry
+	sync.Mutex
+}
+
+type jsonValueEntry struct {
+	value reflect.Value
+	memo  *memoEntry
+}
+
+type jsonValueKey struct {
+	text       string
+	targetType reflect.Type
+}
+
+func newJsonValueCache(budget *memoBudget) *jsonValueCache {
+	return &jsonValueCache{budget: budget, entries: map[jsonValueKey]jsonValueEntry{}}
+}
+
+func (cache *jsonValueCache) len() int {
+	cache.Lock()
+	defer cache.Unlock()
+	return len(cache.entries)
+}
+
+// decode unmarshals the text into a new value of the target type, or returns a copy of the cached value.
+func (cache *jsonValueCache) decode(config *parsedConfig, text string, targetType reflect.Type) (reflect.Value, error) {
+	key := jsonValueKey{text: text, targetType: targetType}
+	cache.Lock()
+	if cache.config == config {
+		if entry, ok := cache.entries[key]; ok {
+			cache.budget.touch(entry.memo)
+			cache.Unlock()
+			return copyDecoded(entry.value), nil
+		}
+	}
+	cache.Unlock()
+
+	decoded := reflect.New(targetType)
+	if err := json.Unmarshal([]byte(text), decoded.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
+	var evicted []*memoEntry
+	defer func() {
+		release(evicted)
+	}()
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.config != config {
+		for _, entry := range cache.entries {
+			cache.budget.remove(entry.memo)
+		}
+		cache.config = config
+		cache.entries = map[jsonValueKey]jsonValueEntry{}
+	}
+	if previous, ok := cache.entries[key]; ok {
+		cache.budget.remove(previous.memo)
+	} else if !cache.budget.limited() && len(cache.entries) >= maxJSONValueEntries {
+		return decoded.Elem(), nil
+	}
+	// the decoded value takes roughly as much memory as its text
+	entry := jsonValueEntry{value: decoded.Elem()}
+	entry.memo, evicted = cache.budget.add(cache, key, int64(memoEntryOverhead+2*len(text)))
+	cache.entries[key] = entry
+	return copyDecoded(entry.value), nil
+}
+
+// copyDecoded returns a deep copy of the maps, slices, pointers and interfaces of a decoded value. Only the
+// exported fields of the structs are copied deeply, the others aren't set by json.Unmarshal.
+func copyDecoded(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), copyDecoded(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(copyDecoded(value.Index(i)))
+		}
+		return copied
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(copyDecoded(value.Elem()))
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(copyDecoded(value.Elem()))
+		return copied
+	case reflect.Struct, reflect.Array:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		if value.Kind() == reflect.Array {
+			for i := 0; i < value.Len(); i++ {
+				copied.Index(i).Set(copyDecoded(value.Index(i)))
+			}
+			return copied
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(copyDecoded(value.Field(i)))
+			}
+		}
+		return copied
+	}
+	return value
+}
+
+// evict removes the entry evicted by the memoization budget.
+func (cache *jsonValueCache) evict(memo *memoEntry) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	key := memo.key.(jsonValueKey)
+	if entry, ok := cache.entries[key]; ok && entry.memo == memo {
+		delete(cache.entries, key)
+	}
+}
@@ -0,0 +1,138 @@
+package configcat
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type testBanner struct {
+	Title  string   `json:"title"`
+	Colors []string `json:"colors"`
+}
+
+func TestClient_GetJSONValue(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"banner": { "v": "{\"title\": \"Hello\", \"colors\": [\"red\", \"blue\"]}", "t": 1,
+			"r": [{ "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": "{\"title\": \"Hi\"}" }] },
+		"broken": { "v": "{", "t": 1 },
+		"flag": { "v": true, "t": 0 }
+	}`})
+	client.Refresh()
+
+	var banner testBanner
+	if err := client.GetJSONValue("banner", &banner, nil); err != nil {
+		t.Fatal(err)
+	}
+	if banner.Title != "Hello" || len(banner.Colors) != 2 || banner.Colors[1] != "blue" {
+		t.Errorf("Unexpected banner: %+v", banner)
+	}
+
+	var targeted testBanner
+	if err := client.GetJSONValue("banner", &targeted, NewUserWithAdditionalAttributes("id", "a@example.com", "", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if targeted.Title != "Hi" || targeted.Colors != nil {
+		t.Errorf("Unexpected targeted banner: %+v", targeted)
+	}
+
+	var generic map[string]interface{}
+	if err := client.GetJSONValue("banner", &generic, nil); err != nil || generic["title"] != "Hello" {
+		t.Errorf("Unexpected generic result: %v, %v", generic, err)
+	}
+
+	unchanged := testBanner{Title: "unchanged"}
+	err := client.GetJSONValue("broken", &unchanged, nil)
+	var jsonErr *JSONValueError
+	if !errors.Is(err, ErrInvalidJSONValue) || !errors.As(err, &jsonErr) || jsonErr.Key != "broken" || unchanged.Title != "unchanged" {
+		t.Errorf("Expecting decoding error, got %v, %+v", err, unchanged)
+	}
+	if err := client.GetJSONValue("flag", &unchanged, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expecting type mismatch, got %v", err)
+	}
+	if err := client.GetJSONValue("missing", &unchanged, nil); err == nil || unchanged.Title != "unchanged" {
+		t.Errorf("Expecting missing key error, got %v", err)
+	}
+}
+
+func TestClient_GetJSONValue_CachedPerConfig(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "banner": { "v": "{\"colors\": [\"red\"]}", "t": 1 } }`})
+	client.Refresh()
+
+	var first, second testBanner
+	_ = client.GetJSONValue("banner", &first, nil)
+	first.Colors[0] = "modified"
+	_ = client.GetJSONValue("banner", &second, nil)
+	if len(client.jsonValues.entries) != 1 {
+		t.Error("Expecting the decoded value to be cached")
+	}
+	if second.Colors[0] != "red" {
+		t.Errorf("Expecting a copy of the cached value, got %+v", second)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "banner": { "v": "{\"colors\": [\"green\"]}", "t": 1 } }`})
+	client.Refresh()
+
+	var third testBanner
+	_ = client.GetJSONValue("banner", &third, nil)
+	if third.Colors[0] != "green" || len(client.jsonValues.entries) != 1 {
+		t.Errorf("Expecting the cache to be emptied on config change, got %+v", third)
+	}
+}
+
+func TestClient_GetJSONValue_InvalidTarget(t *testing.T) {
+	_, client := getTestClients()
+	defer client.Close()
+
+	var invalidErr *json.InvalidUnmarshalError
+	if err := client.GetJSONValue("banner", testBanner{}, nil); !errors.As(err, &invalidErr) {
+		t.Errorf("Expecting invalid target error for non-pointer target, got %v", err)
+	}
+	var nilTarget *testBanner
+	if err := client.GetJSONValue("banner", nilTarget, nil); !errors.As(err, &invalidErr) {
+		t.Errorf("Expecting invalid target error for nil target, got %v", err)
+	}
+}
+
+func TestCopyDecoded(t *testing.T) {
+	type nested struct {
+		Values  map[string]interface{} `json:"values"`
+		Pointer *testBanner            `json:"pointer"`
+		Items   [2][]int               `json:"items"`
+	}
+	cache := newJsonValueCache(newMemoBudget(MemoizationBudget{}))
+	text := `{"values": {"list": [1, {"x": "y"}]}, "pointer": {"colors": ["red"]}, "items": [[1], [2]]}`
+	first, err := cache.decode(nil, text, reflect.TypeOf(nested{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := first.Interface().(nested)
+	decoded.Values["list"].([]interface{})[1].(map[string]interface{})["x"] = "modified"
+	decoded.Pointer.Colors[0] = "modified"
+	decoded.Items[0][0] = 100
+
+	second, _ := cache.decode(nil, text, reflect.TypeOf(nested{}))
+	copied := second.Interface().(nested)
+	if copied.Values["list"].([]interface{})[1].(map[string]interface{})["x"] != "y" ||
+		copied.Pointer.Colors[0] != "red" || copied.Items[0][0] != 1 {
+		t.Errorf("Expecting the cached value to be unchanged, got %+v", copied)
+	}
+}
+
+func TestJsonValueCache_Capacity(t *testing.T) {
+	cache := newJsonValueCache(newMemoBudget(MemoizationBudget{}))
+	for i := 0; i < maxJSONValueEntries+10; i++ {
+		if _, err := cache.decode(nil, strconv.Itoa(i), reflect.TypeOf(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.len() != maxJSONValueEntries {
+		t.Errorf("Expecting the cache to be capped without a budget, got %d entries", cache.len())
+	}
+}
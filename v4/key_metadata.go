@@ -0,0 +1,104 @@
+package configcat
+
+// SettingType describes the type of a setting value.
+type SettingType int
+
+const (
+	// BoolSetting indicates a boolean setting.
+	BoolSetting SettingType = 0
+	// StringSetting indicates a text setting.
+	StringSetting SettingType = 1
+	// IntSetting indicates a whole number setting.
+	IntSetting SettingType = 2
+	// FloatSetting indicates a decimal number setting.
+	FloatSetting SettingType = 3
+	// UnknownSetting indicates that the type of the setting could not be determined.
+	UnknownSetting SettingType = -1
+)
+
+// String returns the name of the setting type.
+func (settingType SettingType) String() string {
+	switch settingType {
+	case BoolSetting:
+		return "bool"
+	case StringSetting:
+		return "string"
+	case IntSetting:
+		return "int"
+	case FloatSetting:
+		return "float"
+	}
+
+	return "unknown"
+}
+
+// KeyMetadata describes a setting of the configuration identified by its key.
+type KeyMetadata struct {
+	// The key of the setting.
+	Key string
+	// The type of the setting value.
+	Type SettingType
+	// The variation ID of the default value.
+	VariationId string
+	// The variation IDs of the default value, the targeting rules and the percentage rules.
+	VariationIds []string
+	// The number of targeting rules.
+	RolloutRuleCount int
+	// The number of percentage rules.
+	PercentageRuleCount int
+}
+
+func newKeyMetadata(key string, node map[string]interface{}) *KeyMetadata {
+	metadata := &KeyMetadata{Key: key, Type: settingTypeOf(node)}
+	metadata.VariationId, _ = node["i"].(string)
+	if len(metadata.VariationId) > 0 {
+		metadata.VariationIds = append(metadata.VariationIds, metadata.VariationId)
+	}
+
+	if rolloutRules, ok := node["r"].([]interface{}); ok {
+		metadata.RolloutRuleCount = len(rolloutRules)
+		metadata.VariationIds = appendVariationIds(metadata.VariationIds, rolloutRules)
+	}
+
+	if percentageRules, ok := node["p"].([]interface{}); ok {
+		metadata.PercentageRuleCount = len(percentageRules)
+		metadata.VariationIds = appendVariationIds(metadata.VariationIds, percentageRules)
+	}
+
+	return metadata
+}
+
+func settingTypeOf(node map[string]interface{}) SettingType {
+	if t, ok := node["t"].(float64); ok {
+		return SettingType(t)
+	}
+
+	switch value := node["v"].(type) {
+	case bool:
+		return BoolSetting
+	case string:
+		return StringSetting
+	case float64:
+		if value == float64(int64(value)) {
+			return IntSetting
+		}
+		return FloatSetting
+	}
+
+	return UnknownSetting
+}
+
+func appendVariationIds(ids []string, rules []interface{}) []string {
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if id, ok := rule["i"].(string); ok && len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
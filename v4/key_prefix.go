@@ -0,0 +1,116 @@
+package configcat
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ScopedClient is a view of a Client restricted to the settings whose keys start with a prefix, e.g. the
+// namespace of a team sharing the configuration with other teams. Its getters prefix the given keys, and
+// its GetAllKeys lists the keys of the namespace without the prefix.
+type ScopedClient struct {
+	client *Client
+	prefix string
+}
+
+// WithKeyPrefix returns a view of the client whose getters prefix the keys with the given prefix.
+func (client *Client) WithKeyPrefix(prefix string) *ScopedClient {
+	return &ScopedClient{client: client, prefix: prefix}
+}
+
+// WithKeyPrefix returns a view of a nested namespace, the prefix is appended to the prefix of the view.
+func (scoped *ScopedClient) WithKeyPrefix(prefix string) *ScopedClient {
+	return &ScopedClient{client: scoped.client, prefix: scoped.prefix + prefix}
+}
+
+// Prefix returns the prefix of the keys of the view.
+func (scoped *ScopedClient) Prefix() string {
+	return scoped.prefix
+}
+
+// GetValue is like Client.GetValue with the prefixed key.
+func (scoped *ScopedClient) GetValue(key string, defaultValue interface{}) interface{} {
+	return scoped.client.GetValue(scoped.key(key), defaultValue)
+}
+
+// GetValueForUser is like Client.GetValueForUser with the prefixed key.
+func (scoped *ScopedClient) GetValueForUser(key string, defaultValue interface{}, user *User) interface{} {
+	return scoped.client.GetValueForUser(scoped.key(key), defaultValue, user)
+}
+
+// GetValueWithContext is like Client.GetValueWithContext with the prefixed key.
+func (scoped *ScopedClient) GetValueWithContext(ctx context.Context, key string, defaultValue interface{}, user *User) (interface{}, error) {
+	return scoped.client.GetValueWithContext(ctx, scoped.key(key), defaultValue, user)
+}
+
+// GetValueDetails is like Client.GetValueDetails with the prefixed key, the key of the details is the prefixed key.
+func (scoped *ScopedClient) GetValueDetails(key string, defaultValue interface{}, user *User) EvaluationDetails {
+	return scoped.client.GetValueDetails(scoped.key(key), defaultValue, user)
+}
+
+// GetBoolValue is like Client.GetBoolValue with the prefixed key.
+func (scoped *ScopedClient) GetBoolValue(key string, defaultValue bool, user *User) (bool, error) {
+	return scoped.client.GetBoolValue(scoped.key(key), defaultValue, user)
+}
+
+// GetStringValue is like Client.GetStringValue with the prefixed key.
+func (scoped *ScopedClient) GetStringValue(key string, defaultValue string, user *User) (string, error) {
+	return scoped.client.GetStringValue(scoped.key(key), defaultValue, user)
+}
+
+// GetIntValue is like Client.GetIntValue with the prefixed key.
+func (scoped *ScopedClient) GetIntValue(key string, defaultValue int, user *User) (int, error) {
+	return scoped.client.GetIntValue(scoped.key(key), defaultValue, user)
+}
+
+// GetFloatValue is like Client.GetFloatValue with the prefixed key.
+func (scoped *ScopedClient) GetFloatValue(key string, defaultValue float64, user *User) (float64, error) {
+	return scoped.client.GetFloatValue(scoped.key(key), defaultValue, user)
+}
+
+// KeyExists is like Client.KeyExists with the prefixed key.
+func (scoped *ScopedClient) KeyExists(key string) bool {
+	return scoped.client.KeyExists(scoped.key(key))
+}
+
+// GetAllKeys returns the sorted keys of the settings of the namespace, without the prefix.
+func (scoped *ScopedClient) GetAllKeys() ([]string, error) {
+	keys, err := scoped.client.GetAllKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	scopedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasPrefix(key, scoped.prefix) && len(key) > len(scoped.prefix) {
+			scopedKeys = append(scopedKeys, key[len(scoped.prefix):])
+		}
+	}
+	sort.Strings(scopedKeys)
+	return scopedKeys, nil
+}
+
+// GetAllValues is like Client.GetAllValues, but it returns the values of the namespace by their keys
+// without the prefix.
+func (scoped *ScopedClient) GetAllValues(user *User) (map[string]interface{}, error) {
+	values, err := scoped.client.GetAllValues(user)
+	if err != nil {
+		return nil, err
+	}
+
+	scopedValues := make(map[string]interface{})
+	for key, value := range values {
+		if strings.HasPrefix(key, scoped.prefix) && len(key) > len(scoped.prefix) {
+			scopedValues[key[len(scoped.prefix):]] = value
+		}
+	}
+	return scopedValues, nil
+}
+
+func (scoped *ScopedClient) key(key string) string {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+	return scoped.prefix + key
+}
@@ -0,0 +1,42 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestClient_WithKeyPrefix(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"team_checkout_enabled": { "v": true, "t": 0 },
+		"team_checkout_limit": { "v": 5, "t": 2 },
+		"team_search_enabled": { "v": false, "t": 0 }
+	}`})
+	client.Refresh()
+
+	checkout := client.WithKeyPrefix("team_").WithKeyPrefix("checkout_")
+	if checkout.Prefix() != "team_checkout_" {
+		t.Errorf("Unexpected prefix: %s", checkout.Prefix())
+	}
+	if value := checkout.GetValue("enabled", false); value != true {
+		t.Errorf("Expecting the prefixed setting, got %v", value)
+	}
+	if value, err := checkout.GetIntValue("limit", 0, nil); value != 5 || err != nil {
+		t.Errorf("Unexpected int value: %v, %v", value, err)
+	}
+	if details := checkout.GetValueDetails("enabled", false, nil); details.Key != "team_checkout_enabled" {
+		t.Errorf("Expecting the prefixed key in the details, got %s", details.Key)
+	}
+	if checkout.KeyExists("team_search_enabled") || !checkout.KeyExists("limit") {
+		t.Error("Expecting the keys to be prefixed")
+	}
+
+	keys, err := checkout.GetAllKeys()
+	if err != nil || len(keys) != 2 || keys[0] != "enabled" || keys[1] != "limit" {
+		t.Errorf("Expecting the keys of the namespace, got %v, %v", keys, err)
+	}
+	values, err := checkout.GetAllValues(nil)
+	if err != nil || len(values) != 2 || values["enabled"] != true {
+		t.Errorf("Expecting the values of the namespace, got %v, %v", values, err)
+	}
+}
@@ -0,0 +1,173 @@
+package configcat
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// KillSwitch watches a boolean flag of the configuration, e.g. to stop the long-running workers when the flag
+// is turned on. It's created by Client.WatchKillSwitch and it's safe for concurrent use.
+type KillSwitch struct {
+	client  *Client
+	key     string
+	user    *User
+	trigger bool
+	parent  context.Context
+	// the current value of the flag, 1 for true
+	value uint32
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	remove  func()
+	stopped bool
+}
+
+// WatchKillSwitch watches the boolean flag identified by the given key, evaluated for the optional user, and
+// engages the kill switch whenever the flag has the trigger value: true for a flag turning the work off, false
+// for a flag which must stay on. The flag is evaluated immediately (waiting for the configuration at most
+// MaxWaitTimeForSyncCalls) and on each change of the downloaded configuration, even while the client is pinned.
+// The changes are evaluated by the Executor of the client.
+// Until the flag is evaluated successfully, and when it's removed or isn't a boolean, the kill switch keeps
+// its previous state, which is disengaged at first. The contexts of the kill switch are derived from ctx,
+// so its deadline time-boxes the work as well. Stop must be called when the kill switch isn't needed anymore,
+// when the client is closed the kill switch stops watching the flag and keeps its last state.
+func (client *Client) WatchKillSwitch(ctx context.Context, key string, user *User, trigger bool) *KillSwitch {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	killSwitch := &KillSwitch{client: client, key: key, user: user, trigger: trigger, parent: ctx}
+	killSwitch.ctx, killSwitch.cancel = context.WithCancel(ctx)
+	if !trigger {
+		killSwitch.value = 1
+	}
+	killSwitch.mu.Lock()
+	killSwitch.remove = client.store.addListener(func(previous string, current string) {
+		client.executor.execute(killSwitch.update)
+	})
+	killSwitch.mu.Unlock()
+	if !client.killSwitches.add(killSwitch) {
+		killSwitch.unwatch()
+	}
+
+	if _, err := client.getConfiguration(); err != nil {
+		logEvent(client.logger, LogLevelWarn, LogEventEvaluation, logFields{key: key}, "Evaluating the kill switch %s failed: %s.", key, err.Error())
+	}
+	killSwitch.update()
+	return killSwitch
+}
+
+// update evaluates the flag on the current configuration and engages or disengages the kill switch.
+func (killSwitch *KillSwitch) update() {
+	killSwitch.mu.Lock()
+	defer killSwitch.mu.Unlock()
+	if killSwitch.stopped {
+		return
+	}
+
+	json := killSwitch.client.store.get()
+	if len(json) == 0 {
+		return
+	}
+	result, err := killSwitch.client.variation(json, killSwitch.key, killSwitch.user)
+	value, ok := result.value.(bool)
+	if err != nil || !ok {
+		return
+	}
+
+	engaged := killSwitch.Engaged()
+	if value {
+		atomic.StoreUint32(&killSwitch.value, 1)
+	} else {
+		atomic.StoreUint32(&killSwitch.value, 0)
+	}
+	if value == killSwitch.trigger && !engaged {
+		killSwitch.client.logger.Infof("Kill switch %s engaged.", killSwitch.key)
+		killSwitch.cancel()
+	} else if value != killSwitch.trigger && engaged {
+		killSwitch.client.logger.Infof("Kill switch %s disengaged.", killSwitch.key)
+		killSwitch.ctx, killSwitch.cancel = context.WithCancel(killSwitch.parent)
+	}
+}
+
+// Value returns the current value of the flag.
+func (killSwitch *KillSwitch) Value() bool {
+	return atomic.LoadUint32(&killSwitch.value) == 1
+}
+
+// Engaged returns true if the flag has the trigger value.
+func (killSwitch *KillSwitch) Engaged() bool {
+	return killSwitch.Value() == killSwitch.trigger
+}
+
+// Context returns a context which is cancelled when the kill switch engages, or when the context passed to
+// WatchKillSwitch is done. While the kill switch is engaged, the returned context is already cancelled, and a new
+// context is returned once it disengages again, so a worker loop can call it before each run.
+func (killSwitch *KillSwitch) Context() context.Context {
+	killSwitch.mu.Lock()
+	defer killSwitch.mu.Unlock()
+	return killSwitch.ctx
+}
+
+// Stop stops watching the flag and cancels the current context of the kill switch.
+func (killSwitch *KillSwitch) Stop() {
+	killSwitch.client.killSwitches.remove(killSwitch)
+	killSwitch.mu.Lock()
+	defer killSwitch.mu.Unlock()
+	killSwitch.stopped = true
+	if killSwitch.remove != nil {
+		killSwitch.remove()
+		killSwitch.remove = nil
+	}
+	killSwitch.cancel()
+}
+
+// unwatch stops watching the changes of the configuration, keeping the state of the kill switch.
+func (killSwitch *KillSwitch) unwatch() {
+	killSwitch.mu.Lock()
+	defer killSwitch.mu.Unlock()
+	if killSwitch.remove != nil {
+		killSwitch.remove()
+		killSwitch.remove = nil
+	}
+}
+
+// killSwitches holds the watching kill switches of a client, which stop watching when the client is closed.
+type killSwitches struct {
+	watching map[*KillSwitch]struct{}
+	closed   bool
+	sync.Mutex
+}
+
+// add registers the kill switch, it returns false if the client is already closed.
+func (switches *killSwitches) add(killSwitch *KillSwitch) bool {
+	switches.Lock()
+	defer switches.Unlock()
+	if switches.closed {
+		return false
+	}
+	if switches.watching == nil {
+		switches.watching = map[*KillSwitch]struct{}{}
+	}
+	switches.watching[killSwitch] = struct{}{}
+	return true
+}
+
+func (switches *killSwitches) remove(killSwitch *KillSwitch) {
+	switches.Lock()
+	defer switches.Unlock()
+	delete(switches.watching, killSwitch)
+}
+
+// close stops the watching of the registered kill switches.
+func (switches *killSwitches) close() {
+	switches.Lock()
+	watching := switches.watching
+	switches.watching, switches.closed = nil, true
+	switches.Unlock()
+	for killSwitch := range watching {
+		killSwitch.unwatch()
+	}
+}
@@ -0,0 +1,99 @@
+package configcat
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestClient_WatchKillSwitch(t *testing.T) {
+	fetcher, client := getKillSwitchTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "stop", "false")})
+	client.Refresh()
+
+	killSwitch := client.WatchKillSwitch(context.Background(), "stop", nil, true)
+	defer killSwitch.Stop()
+	ctx := killSwitch.Context()
+	if killSwitch.Engaged() || ctx.Err() != nil {
+		t.Fatal("Expecting the kill switch to be disengaged")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "stop", "true")})
+	client.Refresh()
+	if !killSwitch.Engaged() || !killSwitch.Value() || ctx.Err() != context.Canceled {
+		t.Fatal("Expecting the kill switch to engage and cancel the context")
+	}
+	if killSwitch.Context().Err() == nil {
+		t.Error("Expecting a cancelled context while the kill switch is engaged")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "other": { "v": true } }`})
+	client.Refresh()
+	if !killSwitch.Engaged() {
+		t.Error("Expecting the state to be kept when the flag is removed")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "stop", "false")})
+	client.Refresh()
+	if killSwitch.Engaged() || killSwitch.Context().Err() != nil {
+		t.Error("Expecting a new context after the kill switch disengaged")
+	}
+
+	killSwitch.Stop()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "stop", "true")})
+	client.Refresh()
+	if killSwitch.Engaged() || killSwitch.Context().Err() != context.Canceled {
+		t.Error("Expecting the stopped kill switch to ignore the changes and cancel its context")
+	}
+}
+
+func TestClient_WatchKillSwitch_FalseTrigger(t *testing.T) {
+	fetcher, client := getKillSwitchTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "enabled", "false")})
+	client.Refresh()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	killSwitch := client.WatchKillSwitch(ctx, "enabled", nil, false)
+	defer killSwitch.Stop()
+	if !killSwitch.Engaged() || killSwitch.Context().Err() == nil {
+		t.Error("Expecting the kill switch to be engaged by the disabled flag")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "enabled", "true")})
+	client.Refresh()
+	if killSwitch.Engaged() || killSwitch.Context().Err() != nil {
+		t.Error("Expecting the kill switch to disengage")
+	}
+	cancel()
+	if killSwitch.Context().Err() == nil {
+		t.Error("Expecting the context to follow the parent")
+	}
+}
+
+// getKillSwitchTestClients is like getTestClients, but the kill switches are updated on the calling goroutine.
+func getKillSwitchTestClients() (*fakeConfigProvider, *Client) {
+	fetcher := newFakeConfigProvider()
+	return fetcher, newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Executor: inlineExecutor}, fetcher)
+}
+
+func TestClient_WatchKillSwitch_Close(t *testing.T) {
+	fetcher, client := getKillSwitchTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "stop", "false")})
+	client.Refresh()
+	listeners := len(client.store.listeners)
+
+	killSwitch := client.WatchKillSwitch(context.Background(), "stop", nil, true)
+	if len(client.store.listeners) != listeners+1 {
+		t.Fatal("Expecting the kill switch to watch the changes")
+	}
+	client.Close()
+	if len(client.store.listeners) != listeners {
+		t.Error("Expecting the listener of the kill switch to be removed by Close")
+	}
+	if killSwitch.Engaged() || killSwitch.Context().Err() != nil {
+		t.Error("Expecting the kill switch to keep its state")
+	}
+	killSwitch.Stop()
+}
@@ -0,0 +1,115 @@
+package configcat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fieldLogger is implemented by the built-in loggers writing the labels as separate fields.
+type fieldLogger interface {
+	withLabels(labels map[string]string) Logger
+}
+
+// newLabelLogger returns a Logger attaching the labels of the client to every message. The labels are added as
+// fields to the logrus and the built-in structured loggers, and as a "[name=value ...]" prefix of the messages
+// to the other loggers.
+func newLabelLogger(logger Logger, labels map[string]string) Logger {
+	if len(labels) == 0 {
+		return logger
+	}
+
+	if structured, ok := logger.(fieldLogger); ok {
+		return structured.withLabels(labels)
+	}
+
+	if logrusLogger, ok := logger.(*logrus.Logger); ok {
+		fields := make(logrus.Fields, len(labels))
+		for name, value := range labels {
+			fields[name] = value
+		}
+		return &labelEntry{Entry: logrusLogger.WithFields(fields)}
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + labels[name]
+	}
+	return &prefixLogger{Logger: logger, prefix: "[" + strings.Join(pairs, " ") + "] "}
+}
+
+// labelEntry is a logrus entry with the label fields which can tell the level of its logger.
+type labelEntry struct {
+	*logrus.Entry
+}
+
+// IsLevelEnabled tells whether the logger of the entry logs the given level.
+func (entry *labelEntry) IsLevelEnabled(level logrus.Level) bool {
+	return entry.Logger.IsLevelEnabled(level)
+}
+
+// prefixLogger is a Logger decorator prefixing every message.
+type prefixLogger struct {
+	Logger
+	prefix string
+}
+
+// IsLevelEnabled tells whether the wrapped logger logs the given level.
+func (logger *prefixLogger) IsLevelEnabled(level logrus.Level) bool {
+	return isLogLevelEnabled(logger.Logger, LogLevel(level))
+}
+
+func (logger *prefixLogger) Debugf(format string, args ...interface{}) {
+	logger.Logger.Debugf("%s"+format, append([]interface{}{logger.prefix}, args...)...)
+}
+
+func (logger *prefixLogger) Infof(format string, args ...interface{}) {
+	logger.Logger.Infof("%s"+format, append([]interface{}{logger.prefix}, args...)...)
+}
+
+func (logger *prefixLogger) Warnf(format string, args ...interface{}) {
+	logger.Logger.Warnf("%s"+format, append([]interface{}{logger.prefix}, args...)...)
+}
+
+func (logger *prefixLogger) Errorf(format string, args ...interface{}) {
+	logger.Logger.Errorf("%s"+format, append([]interface{}{logger.prefix}, args...)...)
+}
+
+func (logger *prefixLogger) Debug(args ...interface{}) {
+	logger.Logger.Debug(logger.prefix + fmt.Sprint(args...))
+}
+
+func (logger *prefixLogger) Info(args ...interface{}) {
+	logger.Logger.Info(logger.prefix + fmt.Sprint(args...))
+}
+
+func (logger *prefixLogger) Warn(args ...interface{}) {
+	logger.Logger.Warn(logger.prefix + fmt.Sprint(args...))
+}
+
+func (logger *prefixLogger) Error(args ...interface{}) {
+	logger.Logger.Error(logger.prefix + fmt.Sprint(args...))
+}
+
+func (logger *prefixLogger) Debugln(args ...interface{}) {
+	logger.Logger.Debugln(logger.prefix + strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *prefixLogger) Infoln(args ...interface{}) {
+	logger.Logger.Infoln(logger.prefix + strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *prefixLogger) Warnln(args ...interface{}) {
+	logger.Logger.Warnln(logger.prefix + strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *prefixLogger) Errorln(args ...interface{}) {
+	logger.Logger.Errorln(logger.prefix + strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
@@ -0,0 +1,68 @@
+package configcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLabelLogger_Prefix(t *testing.T) {
+	recorder := &recordingLogger{Logger: DefaultLogger(LogLevelError)}
+	logger := newLabelLogger(recorder, map[string]string{"service": "api", "env": "prod"})
+
+	logger.Warn("Fetch failed.")
+	logger.Errorln("Fetch", "failed.")
+	if len(recorder.messages) != 1 || recorder.messages[0] != "[env=prod service=api] Fetch failed." {
+		t.Errorf("Unexpected messages: %q", recorder.messages)
+	}
+	recorder.messages = nil
+	formatted := newLabelLogger(&warnfRecorder{recorder}, map[string]string{"rollout": "50%d"})
+	formatted.Warnf("Fetch failed: %s.", "timeout")
+	if len(recorder.messages) != 1 || recorder.messages[0] != "[rollout=50%d] Fetch failed: timeout." {
+		t.Errorf("Expecting the percent sign of the label to be kept, got %q", recorder.messages)
+	}
+	if newLabelLogger(recorder, nil) != Logger(recorder) {
+		t.Error("Expecting the logger to be kept without labels")
+	}
+}
+
+func TestLabelLogger_Logrus(t *testing.T) {
+	var output bytes.Buffer
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&output)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{})
+	logrusLogger.SetLevel(logrus.WarnLevel)
+	logger := newLabelLogger(logrusLogger, map[string]string{"service": "api"})
+
+	logger.Warnf("Fetch %s.", "failed")
+	var entry map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["service"] != "api" || entry["msg"] != "Fetch failed." {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if isLogLevelEnabled(logger, LogLevelInfo) || !isLogLevelEnabled(logger, LogLevelWarn) {
+		t.Error("Expecting the level of the logrus logger")
+	}
+}
+
+func TestClient_Labels(t *testing.T) {
+	var impression Impression
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:   ManualPoll(),
+		Labels: map[string]string{"region": "eu"},
+		Hooks:  Hooks{OnFlagEvaluated: func(evaluated Impression) { impression = evaluated }},
+	}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "key": { "v": true, "t": 0 } }`})
+	client.Refresh()
+
+	client.GetValue("key", false)
+	if impression.Labels["region"] != "eu" || client.Status().Labels["region"] != "eu" {
+		t.Errorf("Expecting the labels in the impression and the status, got %v, %v", impression.Labels, client.Status().Labels)
+	}
+}
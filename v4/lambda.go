@@ -0,0 +1,68 @@
+package configcat
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LambdaFetchBudget is the deadline of the config downloads of the LambdaConfig preset, limiting the time
+// a cold start can spend on waiting for the ConfigCat CDN.
+const LambdaFetchBudget = time.Second
+
+// LambdaConfig returns a ClientConfig preset for AWS Lambda and similar execution environments which freeze the
+// process between the invocations: the configuration is cached in a file under the temp directory (/tmp on
+// Lambda) so the execution environment reuses it, there is no background polling, and the downloads are limited
+// to LambdaFetchBudget. The configuration is refreshed by RefreshIfOlderThan, see WrapLambdaHandler.
+// The returned config can be adjusted before it's passed to NewCustomClient.
+func LambdaConfig(apiKey string) ClientConfig {
+	hash := sha256.Sum256([]byte(apiKey))
+	return ClientConfig{
+		Mode:                    ManualPoll(),
+		Cache:                   NewFileCache(filepath.Join(os.TempDir(), "configcat-"+hex.EncodeToString(hash[:8])+".json")),
+		NoBackgroundGoroutines:  true,
+		FetchTimeout:            LambdaFetchBudget,
+		MaxWaitTimeForSyncCalls: LambdaFetchBudget,
+	}
+}
+
+// RefreshIfOlderThan refreshes the configuration when it wasn't confirmed to be up to date by a fetch within
+// maxAge, and blocks until the refresh is completed or the context is done. It returns nil without fetching
+// when the configuration is recent enough.
+func (client *Client) RefreshIfOlderThan(ctx context.Context, maxAge time.Duration) error {
+	lastFetch := client.statusRecorder.status().LastSuccessfulFetchTime
+	if !lastFetch.IsZero() && client.clock.Now().Sub(lastFetch) < maxAge {
+		return nil
+	}
+
+	return client.RefreshWithContext(ctx)
+}
+
+// LambdaHandler is the handler of a Lambda function invocation, it's compatible with the lambda.Handler
+// interface of github.com/aws/aws-lambda-go.
+type LambdaHandler interface {
+	Invoke(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// LambdaHandlerFunc is an adapter to use ordinary functions as LambdaHandler.
+type LambdaHandlerFunc func(ctx context.Context, payload []byte) ([]byte, error)
+
+// Invoke calls the function.
+func (handler LambdaHandlerFunc) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return handler(ctx, payload)
+}
+
+// WrapLambdaHandler returns a LambdaHandler which refreshes the configuration of the client with
+// RefreshIfOlderThan at the start of each invocation before passing it to the handler. A failed refresh
+// doesn't fail the invocation, the cached configuration is served instead.
+func WrapLambdaHandler(client *Client, maxAge time.Duration, handler LambdaHandler) LambdaHandler {
+	return LambdaHandlerFunc(func(ctx context.Context, payload []byte) ([]byte, error) {
+		if err := client.RefreshIfOlderThan(ctx, maxAge); err != nil {
+			logEvent(client.logger, LogLevelWarn, LogEventFetch, logFields{}, "Refreshing the configuration at the start of the invocation failed: %s.", err.Error())
+		}
+		return handler.Invoke(ctx, payload)
+	})
+}
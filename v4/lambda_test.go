@@ -0,0 +1,60 @@
+package configcat
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWrapLambdaHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	provider := &countingConfigProvider{body: fmt.Sprintf(jsonFormat, "key", "true")}
+	config := LambdaConfig("fakeKey")
+	config.Cache = NewFileCache(filepath.Join(dir, "config.json"))
+	config.ConfigProvider = provider
+	client := NewCustomClient("fakeKey", config)
+	defer client.Close()
+
+	handler := WrapLambdaHandler(client, time.Hour, LambdaHandlerFunc(func(ctx context.Context, payload []byte) ([]byte, error) {
+		return []byte(fmt.Sprint(client.GetValue("key", false))), nil
+	}))
+	for i := 0; i < 3; i++ {
+		if result, err := handler.Invoke(context.Background(), nil); string(result) != "true" || err != nil {
+			t.Errorf("Unexpected result: %s, %v", result, err)
+		}
+	}
+	if count := atomic.LoadInt32(&provider.count); count != 1 {
+		t.Errorf("Expecting a single fetch within the max age, got %d", count)
+	}
+	if cached, _ := config.Cache.Get(); cached != provider.body {
+		t.Errorf("Expecting the configuration in the file cache, got %s", cached)
+	}
+
+	if err := client.RefreshIfOlderThan(context.Background(), 0); err != nil || atomic.LoadInt32(&provider.count) != 2 {
+		t.Errorf("Expecting a refresh of the outdated configuration, got %v", err)
+	}
+}
+
+func TestLambdaConfig(t *testing.T) {
+	config := LambdaConfig("fakeKey")
+	if !config.NoBackgroundGoroutines || config.FetchTimeout != LambdaFetchBudget {
+		t.Errorf("Unexpected preset: %+v", config)
+	}
+	cache, ok := config.Cache.(*fileConfigCache)
+	if !ok || filepath.Dir(cache.path) != filepath.Clean(os.TempDir()) {
+		t.Errorf("Expecting a file cache in the temp directory, got %v", config.Cache)
+	}
+	if other := LambdaConfig("otherKey").Cache.(*fileConfigCache); other.path == cache.path {
+		t.Error("Expecting separate cache files for the api keys")
+	}
+}
@@ -1,6 +1,7 @@
 package configcat
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -8,13 +9,22 @@ import (
 // lazyLoadingPolicy describes a refreshPolicy which uses an expiring cache to maintain the internally stored configuration.
 type lazyLoadingPolicy struct {
 	configRefresher
-	cacheInterval   time.Duration
+	cacheInterval time.Duration
+	// guards currentInterval, lastRefreshTime and fetching, which are read by the concurrent evaluations
+	mutex sync.RWMutex
+	// the cache interval of the last fetched configuration, derived from the cache headers when it's enabled
+	currentInterval time.Duration
+	cacheHeaders    bool
+	minInterval     time.Duration
+	maxInterval     time.Duration
 	isFetching      uint32
 	initialized     uint32
 	useAsyncRefresh bool
+	prefetchRatio   float64
 	lastRefreshTime time.Time
 	fetching        *asyncResult
 	init            *async
+	clock           Clock
 }
 
 // lazyLoadConfig describes the configuration for auto polling.
@@ -24,6 +34,14 @@ type lazyLoadConfig struct {
 	// If you use the asynchronous refresh then when a request is being made on the cache while it's expired,
 	// the previous value will be returned immediately until the fetching of the new configuration is completed
 	useAsyncRefresh bool
+	// If it's true then the cache interval is derived from the Cache-Control and Age headers of the
+	// CDN response, limited to the [minInterval, maxInterval] range.
+	cacheHeaders bool
+	minInterval  time.Duration
+	maxInterval  time.Duration
+	// The part of the cache interval after which a read starts refreshing the configuration in the background,
+	// while the cached one is still served. If it's 0 then the configuration is only refreshed once it's expired.
+	prefetchRatio float64
 }
 
 func (config lazyLoadConfig) getModeIdentifier() string {
@@ -39,71 +57,160 @@ func LazyLoad(cacheInterval time.Duration, useAsyncRefresh bool) RefreshMode {
 	return lazyLoadConfig{cacheInterval: cacheInterval, useAsyncRefresh: useAsyncRefresh}
 }
 
+// LazyLoadWithCacheHeaders creates a lazy loading refresh mode whose cache interval is derived from the
+// Cache-Control and Age headers of the CDN response, limited to the [minInterval, maxInterval] range.
+// The cacheInterval is used when the response has no caching headers.
+func LazyLoadWithCacheHeaders(cacheInterval time.Duration, minInterval time.Duration, maxInterval time.Duration, useAsyncRefresh bool) RefreshMode {
+	return lazyLoadConfig{
+		cacheInterval:   cacheInterval,
+		useAsyncRefresh: useAsyncRefresh,
+		cacheHeaders:    true,
+		minInterval:     minInterval,
+		maxInterval:     maxInterval,
+	}
+}
+
+// LazyLoadWithPrefetch creates a lazy loading refresh mode which starts refreshing the configuration in the
+// background when it's read after the given part of the cache interval (e.g. 0.8 for 80%), so the reads rarely
+// wait for the synchronous refresh of the expired configuration. The prefetchRatio must be between 0 and 1.
+func LazyLoadWithPrefetch(cacheInterval time.Duration, prefetchRatio float64) RefreshMode {
+	return lazyLoadConfig{cacheInterval: cacheInterval, prefetchRatio: prefetchRatio}
+}
+
 // newLazyLoadingPolicy initializes a new lazyLoadingPolicy.
 func newLazyLoadingPolicy(
 	configFetcher configProvider,
 	store *configStore,
 	logger Logger,
+	clock Clock,
 	config lazyLoadConfig) *lazyLoadingPolicy {
 	return &lazyLoadingPolicy{configRefresher: configRefresher{configFetcher: configFetcher, store: store, logger: logger},
 		cacheInterval:   config.cacheInterval,
+		currentInterval: config.cacheInterval,
+		cacheHeaders:    config.cacheHeaders,
+		minInterval:     config.minInterval,
+		maxInterval:     config.maxInterval,
 		isFetching:      no,
 		initialized:     no,
 		useAsyncRefresh: config.useAsyncRefresh,
+		prefetchRatio:   config.prefetchRatio,
 		lastRefreshTime: time.Time{},
-		init:            newAsync()}
+		init:            newAsync(),
+		clock:           clock}
 }
 
 // getConfigurationAsync reads the current configuration value.
 func (policy *lazyLoadingPolicy) getConfigurationAsync() *asyncResult {
-	if time.Since(policy.lastRefreshTime) > policy.cacheInterval {
+	if age, interval := policy.age(); age > interval {
 		initialized := policy.init.isCompleted()
 
 		if initialized && !atomic.CompareAndSwapUint32(&policy.isFetching, no, yes) {
-			if policy.useAsyncRefresh {
-				return policy.readCache()
+			if fetching := policy.currentFetch(); !policy.useAsyncRefresh && fetching != nil {
+				return fetching
 			}
-			return policy.fetching
+			return policy.readCache()
 		}
 
-		policy.logger.Debugln("Cache expired, refreshing.")
+		logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Cache expired, refreshing.")
 		if initialized {
-			policy.fetching = policy.fetch()
+			fetching := policy.startFetch()
 			if policy.useAsyncRefresh {
 				return policy.readCache()
 			}
-			return policy.fetching
+			return fetching
 		}
 
 		if atomic.CompareAndSwapUint32(&policy.isFetching, no, yes) {
-			policy.fetching = policy.fetch()
+			policy.startFetch()
 		}
 		return policy.init.apply(func() interface{} {
 			return policy.store.get()
 		})
 	}
 
+	policy.prefetch()
 	return policy.readCache()
 }
 
+// cachedConfiguration reads the current configuration value while it's not expired.
+func (policy *lazyLoadingPolicy) cachedConfiguration() (string, bool) {
+	if !policy.init.isCompleted() {
+		return "", false
+	}
+	if age, interval := policy.age(); age > interval {
+		return "", false
+	}
+
+	policy.prefetch()
+	return policy.store.get(), true
+}
+
+// prefetch starts refreshing the not yet expired configuration once it's older than the prefetch part of the
+// cache interval, unless a fetch is already in progress.
+func (policy *lazyLoadingPolicy) prefetch() {
+	if policy.prefetchRatio <= 0 || !policy.init.isCompleted() {
+		return
+	}
+
+	age, interval := policy.age()
+	if age < time.Duration(float64(interval)*policy.prefetchRatio) {
+		return
+	}
+
+	if atomic.CompareAndSwapUint32(&policy.isFetching, no, yes) {
+		logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Cache is about to expire, prefetching.")
+		policy.startFetch()
+	}
+}
+
+// age returns the time elapsed since the last refresh and the cache interval of the current configuration.
+func (policy *lazyLoadingPolicy) age() (time.Duration, time.Duration) {
+	policy.mutex.RLock()
+	defer policy.mutex.RUnlock()
+	return policy.clock.Now().Sub(policy.lastRefreshTime), policy.currentInterval
+}
+
+// startFetch starts the fetch of the caller which set isFetching and returns its result.
+func (policy *lazyLoadingPolicy) startFetch() *asyncResult {
+	fetching := policy.fetch()
+	policy.mutex.Lock()
+	policy.fetching = fetching
+	policy.mutex.Unlock()
+	return fetching
+}
+
+// currentFetch returns the result of the last started fetch, or nil if there is none yet.
+func (policy *lazyLoadingPolicy) currentFetch() *asyncResult {
+	policy.mutex.RLock()
+	defer policy.mutex.RUnlock()
+	return policy.fetching
+}
+
 // close shuts down the policy.
 func (policy *lazyLoadingPolicy) close() {
 }
 
 func (policy *lazyLoadingPolicy) fetch() *asyncResult {
-	return policy.configFetcher.getConfigurationAsync().applyThen(func(result interface{}) interface{} {
+	return policy.configFetcher.getConfigurationAsync().applyThenWithError(func(result interface{}, err error) (interface{}, error) {
 		defer atomic.StoreUint32(&policy.isFetching, no)
 
-		response := result.(fetchResponse)
+		if err != nil {
+			logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Refreshing the expired configuration failed: %s.", err.Error())
+		}
+
+		response := asFetchResponse(result)
 		cached := policy.store.get()
 		fetched := response.isFetched()
 
 		if fetched && response.body != cached {
-			policy.store.set(response.body)
+			policy.store.setFetched(response)
 		}
 
 		if !response.isFailed() {
-			policy.lastRefreshTime = time.Now()
+			policy.mutex.Lock()
+			policy.currentInterval = policy.intervalOf(response)
+			policy.lastRefreshTime = policy.clock.Now()
+			policy.mutex.Unlock()
 		}
 
 		if atomic.CompareAndSwapUint32(&policy.initialized, no, yes) {
@@ -111,14 +218,31 @@ func (policy *lazyLoadingPolicy) fetch() *asyncResult {
 		}
 
 		if fetched {
-			return response.body
+			return response.body, nil
 		}
 
-		return cached
-	})
+		return cached, err
+	}).hold()
+}
+
+// intervalOf returns the cache interval of the configuration received with the given response.
+func (policy *lazyLoadingPolicy) intervalOf(response fetchResponse) time.Duration {
+	if !policy.cacheHeaders || !response.hasMaxAge {
+		return policy.cacheInterval
+	}
+
+	interval := response.maxAge
+	if interval < policy.minInterval {
+		interval = policy.minInterval
+	}
+	if policy.maxInterval > 0 && interval > policy.maxInterval {
+		interval = policy.maxInterval
+	}
+
+	return interval
 }
 
 func (policy *lazyLoadingPolicy) readCache() *asyncResult {
-	policy.logger.Debugln("Reading from cache.")
+	logEvent(policy.logger, LogLevelDebug, LogEventCache, logFields{}, "Reading from cache.")
 	return asCompletedAsyncResult(policy.store.get())
 }
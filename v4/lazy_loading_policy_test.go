@@ -1,6 +1,10 @@
 package configcat
 
 import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -14,7 +18,8 @@ func TestLazyLoadingPolicy_GetConfigurationAsync_DoNotUseAsync(t *testing.T) {
 		fetcher,
 		newConfigStore(logger, newInMemoryConfigCache()),
 		logger,
-		lazyLoadConfig{time.Second * 2, false})
+		systemClock{},
+		lazyLoadConfig{cacheInterval: time.Second * 2, useAsyncRefresh: false})
 	config := policy.getConfigurationAsync().get().(string)
 
 	if config != "test" {
@@ -45,7 +50,8 @@ func TestLazyLoadingPolicy_GetConfigurationAsync_Fail(t *testing.T) {
 		fetcher,
 		newConfigStore(logger, newInMemoryConfigCache()),
 		logger,
-		lazyLoadConfig{time.Second * 2, false})
+		systemClock{},
+		lazyLoadConfig{cacheInterval: time.Second * 2, useAsyncRefresh: false})
 	config := policy.getConfigurationAsync().get().(string)
 
 	if config != "" {
@@ -53,6 +59,30 @@ func TestLazyLoadingPolicy_GetConfigurationAsync_Fail(t *testing.T) {
 	}
 }
 
+func TestLazyLoadingPolicy_GetConfigurationAsync_FailWithCache(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "test"})
+	logger := DefaultLogger(LogLevelFatal)
+	clock := &manualClock{now: time.Unix(1000, 0)}
+	policy := newLazyLoadingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		clock,
+		lazyLoadConfig{cacheInterval: time.Second * 2, useAsyncRefresh: false})
+	if config, err := policy.getConfigurationAsync().getWithError(); config != "test" || err != nil {
+		t.Fatalf("Expecting test as result, got %v, %v", config, err)
+	}
+
+	fetchErr := errors.New("fake fetch error")
+	fetcher.SetError(fetchErr)
+	clock.now = clock.now.Add(time.Second * 3)
+	config, err := policy.getConfigurationAsync().getWithError()
+	if config != "test" || err != fetchErr {
+		t.Errorf("Expecting the cached config with the fetch error, got %v, %v", config, err)
+	}
+}
+
 func TestLazyLoadingPolicy_GetConfigurationAsync_UseAsync(t *testing.T) {
 	fetcher := newFakeConfigProvider()
 
@@ -62,7 +92,8 @@ func TestLazyLoadingPolicy_GetConfigurationAsync_UseAsync(t *testing.T) {
 		fetcher,
 		newConfigStore(logger, newInMemoryConfigCache()),
 		logger,
-		lazyLoadConfig{time.Second * 2, true})
+		systemClock{},
+		lazyLoadConfig{cacheInterval: time.Second * 2, useAsyncRefresh: true})
 	config := policy.getConfigurationAsync().get().(string)
 
 	if config != "test" {
@@ -85,3 +116,103 @@ func TestLazyLoadingPolicy_GetConfigurationAsync_UseAsync(t *testing.T) {
 		t.Error("Expecting test2 as result")
 	}
 }
+
+func TestLazyLoadingPolicy_CacheHeaders(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	logger := DefaultLogger(LogLevelWarn)
+	policy := newLazyLoadingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		systemClock{},
+		LazyLoadWithCacheHeaders(time.Minute, time.Second*10, time.Minute*5, false).(lazyLoadConfig))
+
+	tests := []struct {
+		cacheControl string
+		age          string
+		expected     time.Duration
+	}{
+		{"", "", time.Minute},
+		{"public, max-age=120", "", time.Minute * 2},
+		{"max-age=120", "30", time.Second * 90},
+		{"max-age=3600", "", time.Minute * 5},
+		{"max-age=1", "", time.Second * 10},
+		{"no-cache", "", time.Second * 10},
+	}
+	for _, test := range tests {
+		header := http.Header{}
+		header.Set("Cache-Control", test.cacheControl)
+		header.Set("Age", test.age)
+		fetcher.SetResponse(fetchResponse{status: Fetched, body: "{}"}.withCacheHeaders(header))
+		policy.lastRefreshTime = time.Time{}
+		policy.getConfigurationAsync().get()
+		if policy.currentInterval != test.expected {
+			t.Errorf("Expecting %v for %q (age %q), got %v", test.expected, test.cacheControl, test.age, policy.currentInterval)
+		}
+	}
+}
+
+func TestLazyLoadingPolicy_Prefetch(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	logger := DefaultLogger(LogLevelWarn)
+	clock := &manualClock{now: time.Unix(0, 0)}
+	policy := newLazyLoadingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		clock,
+		LazyLoadWithPrefetch(time.Minute*10, 0.8).(lazyLoadConfig))
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "test"})
+	if config := policy.getConfigurationAsync().get(); config != "test" {
+		t.Fatalf("Unexpected config: %v", config)
+	}
+
+	fetcher.SetResponseWithDelay(fetchResponse{status: Fetched, body: "test2"}, time.Millisecond*100)
+	clock.now = clock.now.Add(time.Minute * 7)
+	if config, ok := policy.cachedConfiguration(); config != "test" || !ok || atomic.LoadUint32(&policy.isFetching) == yes {
+		t.Errorf("Expecting the cached config without prefetching, got %v", config)
+	}
+
+	clock.now = clock.now.Add(time.Minute * 2)
+	if config, ok := policy.cachedConfiguration(); config != "test" || !ok {
+		t.Errorf("Expecting the cached config while prefetching, got %v", config)
+	}
+	if _, err := policy.currentFetch().getWithError(); err != nil {
+		t.Fatalf("Unexpected prefetch error: %v", err)
+	}
+	if config, ok := policy.cachedConfiguration(); config != "test2" || !ok {
+		t.Errorf("Expecting the prefetched config before the expiry, got %v", config)
+	}
+}
+
+func TestLazyLoadingPolicy_ConcurrentReads(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	logger := DefaultLogger(LogLevelWarn)
+	policy := newLazyLoadingPolicy(
+		fetcher,
+		newConfigStore(logger, newInMemoryConfigCache()),
+		logger,
+		systemClock{},
+		lazyLoadConfig{cacheInterval: time.Millisecond, cacheHeaders: true, maxInterval: time.Millisecond, prefetchRatio: 0.5})
+
+	header := http.Header{}
+	header.Set("Cache-Control", "max-age=0")
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "test"}.withCacheHeaders(header))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if config := policy.getConfigurationAsync().get(); config != "test" {
+					t.Errorf("Unexpected config: %v", config)
+					return
+				}
+				policy.cachedConfiguration()
+			}
+		}()
+	}
+	wg.Wait()
+}
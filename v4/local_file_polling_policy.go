@@ -0,0 +1,93 @@
+package configcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// localFilePollConfig describes the configuration for polling a local config file.
+type localFilePollConfig struct {
+	// The path of the config JSON file.
+	path string
+	// The interval of checking the file for modifications.
+	pollInterval time.Duration
+	// The configuration change listener.
+	changeListener func()
+}
+
+func (config localFilePollConfig) getModeIdentifier() string {
+	return "f"
+}
+
+func (config localFilePollConfig) accept(visitor pollingModeVisitor) refreshPolicy {
+	return visitor.visitLocalFilePoll(config)
+}
+
+// LocalFilePoll creates a refresh mode which reads the configuration from the JSON file at the given path
+// instead of the ConfigCat CDN, and re-reads it when its modification time or size changes.
+func LocalFilePoll(path string, interval time.Duration) RefreshMode {
+	return localFilePollConfig{path: path, pollInterval: interval}
+}
+
+// LocalFilePollWithChangeListener creates a local file polling refresh mode with change listener callback.
+func LocalFilePollWithChangeListener(path string, interval time.Duration, changeListener func()) RefreshMode {
+	return localFilePollConfig{path: path, pollInterval: interval, changeListener: changeListener}
+}
+
+// localFileConfigProvider is a configProvider which reads the configuration from a local file.
+type localFileConfigProvider struct {
+	path    string
+	logger  Logger
+	modTime time.Time
+	size    int64
+	sync.Mutex
+}
+
+func newLocalFileConfigProvider(path string, logger Logger) *localFileConfigProvider {
+	return &localFileConfigProvider{path: path, logger: logger}
+}
+
+// getConfigurationAsync reads the file if it was modified since the last read.
+func (provider *localFileConfigProvider) getConfigurationAsync() *asyncResult {
+	result := newAsyncResult()
+	response, err := provider.read()
+	result.completeWith(response, err)
+	return result
+}
+
+func (provider *localFileConfigProvider) read() (fetchResponse, error) {
+	provider.Lock()
+	defer provider.Unlock()
+
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		logEvent(provider.logger, LogLevelError, LogEventFetch, logFields{status: Failure.String()}, "Reading the config file failed: %s", err.Error())
+		return fetchResponse{status: Failure}, err
+	}
+
+	if info.ModTime().Equal(provider.modTime) && info.Size() == provider.size {
+		logEvent(provider.logger, LogLevelDebug, LogEventFetch, logFields{status: NotModified.String()}, "Config file not modified.")
+		return fetchResponse{status: NotModified}, nil
+	}
+
+	body, err := ioutil.ReadFile(provider.path)
+	if err != nil {
+		logEvent(provider.logger, LogLevelError, LogEventFetch, logFields{status: Failure.String()}, "Reading the config file failed: %s", err.Error())
+		return fetchResponse{status: Failure}, err
+	}
+
+	if !json.Valid(body) {
+		err = fmt.Errorf("config file %s is not a valid JSON", provider.path)
+		provider.logger.Errorln(err.Error())
+		return fetchResponse{status: Failure}, err
+	}
+
+	provider.modTime = info.ModTime()
+	provider.size = info.Size()
+	logEvent(provider.logger, LogLevelDebug, LogEventFetch, logFields{status: Fetched.String()}, "Config file read.")
+	return fetchResponse{status: Fetched, body: string(body)}, nil
+}
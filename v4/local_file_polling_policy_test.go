@@ -0,0 +1,57 @@
+package configcat
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClient_LocalFilePoll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{ "key": { "v": "first" } }`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan struct{}, 1)
+	client := NewCustomClient("local", ClientConfig{Mode: LocalFilePollWithChangeListener(path, time.Millisecond*50, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})})
+	defer client.Close()
+
+	if value := client.GetValue("key", ""); value != "first" {
+		t.Fatalf("Expecting first, got %v", value)
+	}
+	<-changed
+
+	if err := ioutil.WriteFile(path, []byte(`{ "key": { "v": "second value" } }`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second * 2):
+		t.Fatal("Expecting the change to be detected")
+	}
+
+	if value := client.GetValue("key", ""); value != "second value" {
+		t.Errorf("Expecting second value, got %v", value)
+	}
+}
+
+func TestLocalFileConfigProvider_Failures(t *testing.T) {
+	provider := newLocalFileConfigProvider("/nonexistent/config.json", DefaultLogger(LogLevelError))
+	if _, err := provider.getConfigurationAsync().getWithError(); err == nil {
+		t.Error("Expecting error for a missing file")
+	}
+}
@@ -1,6 +1,9 @@
 package configcat
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -41,3 +44,64 @@ func DefaultLogger(level LogLevel) Logger {
 	logger.SetLevel(logrus.Level(level))
 	return logger
 }
+
+// levelLogger is implemented by the loggers which can tell whether a level is logged, e.g. *logrus.Logger.
+type levelLogger interface {
+	IsLevelEnabled(level logrus.Level) bool
+}
+
+// isLogLevelEnabled returns true if the messages of the given level are logged by the logger, so the hot paths
+// can skip building the arguments of the filtered messages. Loggers not telling their level are assumed to log.
+func isLogLevelEnabled(logger Logger, level LogLevel) bool {
+	if leveled, ok := logger.(levelLogger); ok {
+		return leveled.IsLevelEnabled(logrus.Level(level))
+	}
+
+	return true
+}
+
+// logFields are the structured fields of a log event, the empty ones are left out of the entries.
+type logFields struct {
+	// the key of the evaluated setting
+	key string
+	// the outcome of the fetch, see fetchStatus
+	status string
+	// the ETag of the fetched config
+	eTag string
+	// the duration of the fetch
+	duration time.Duration
+}
+
+// eventLogger is implemented by the structured loggers, which write the event and the fields of a message
+// as separate fields of the entry.
+type eventLogger interface {
+	logEvent(level LogLevel, event string, fields logFields, message string)
+}
+
+// logEvent logs the message of the fetch, evaluation or cache operation together with its event (one of
+// the LogEvent constants) and fields. The loggers which aren't structured receive the message only.
+func logEvent(logger Logger, level LogLevel, event string, fields logFields, format string, args ...interface{}) {
+	events, ok := logger.(eventLogger)
+	if !ok {
+		logf(logger, level, format, args...)
+		return
+	}
+
+	if isLogLevelEnabled(logger, level) {
+		events.logEvent(level, event, fields, fmt.Sprintf(format, args...))
+	}
+}
+
+// logf logs the formatted message at the given level.
+func logf(logger Logger, level LogLevel, format string, args ...interface{}) {
+	switch level {
+	case LogLevelDebug, LogLevelTrace:
+		logger.Debugf(format, args...)
+	case LogLevelInfo:
+		logger.Infof(format, args...)
+	case LogLevelWarn:
+		logger.Warnf(format, args...)
+	default:
+		logger.Errorf(format, args...)
+	}
+}
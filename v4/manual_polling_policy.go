@@ -35,6 +35,11 @@ func (policy *manualPollingPolicy) getConfigurationAsync() *asyncResult {
 	return asCompletedAsyncResult(policy.store.get())
 }
 
+// cachedConfiguration reads the current configuration value, which is always available.
+func (policy *manualPollingPolicy) cachedConfiguration() (string, bool) {
+	return policy.store.get(), true
+}
+
 // close shuts down the policy.
 func (policy *manualPollingPolicy) close() {
 }
@@ -0,0 +1,156 @@
+package configcat
+
+import (
+	"container/list"
+	"sync"
+)
+
+// MemoizationBudget limits the total size of the memoization caches of a Client: the results of Warmup,
+// the GetAllValues results (see AllValuesCacheSize) and the values decoded by GetJSONValue. When a limit is
+// exceeded, the least recently used entries of any of the caches are evicted first, and they are computed
+// again on their next use. The sizes are estimated from the keys and the values of the entries, they don't
+// include the configuration itself. Zero limits are unlimited.
+type MemoizationBudget struct {
+	// The maximum number of the memoized entries.
+	MaxEntries int
+	// The maximum estimated size of the memoized entries in bytes.
+	MaxBytes int64
+}
+
+// MemoizationStatus describes the memoization caches of a Client, see MemoizationBudget.
+type MemoizationStatus struct {
+	// The number of the evaluations memoized by Warmup.
+	WarmedEntries int
+	// The number of the cached GetAllValues results.
+	AllValuesEntries int
+	// The number of the values decoded by GetJSONValue.
+	JSONValueEntries int
+	// The estimated size of all the memoized entries in bytes.
+	EstimatedBytes int64
+	// The number of the entries evicted because the MemoizationBudget was exceeded.
+	Evictions uint64
+}
+
+// memoBudget tracks the entries of the memoization caches of a client in a shared LRU list, and evicts the least
+// recently used ones when the limits are exceeded. The caches add their entries while holding their own lock, and
+// release the evicted entries after unlocking it, so a cache is never locked by the budget.
+type memoBudget struct {
+	maxEntries int
+	maxBytes   int64
+	order      *list.List
+	bytes      int64
+	evictions  uint64
+	sync.Mutex
+}
+
+// memoEntry is an entry of a memoization cache tracked by the budget.
+type memoEntry struct {
+	owner memoOwner
+	key   interface{}
+	size  int64
+	// the element of the entry in the LRU list, nil once it's removed
+	element *list.Element
+}
+
+// memoOwner is a memoization cache whose entries are tracked by the budget.
+type memoOwner interface {
+	// evict removes the entry evicted by the budget, unless it was replaced in the meantime.
+	evict(entry *memoEntry)
+}
+
+func newMemoBudget(config MemoizationBudget) *memoBudget {
+	return &memoBudget{maxEntries: config.MaxEntries, maxBytes: config.MaxBytes, order: list.New()}
+}
+
+// limited returns true if the budget has a limit, otherwise the order of the entries isn't maintained.
+func (budget *memoBudget) limited() bool {
+	return budget.maxEntries > 0 || budget.maxBytes > 0
+}
+
+// add tracks a new entry of the owner and returns it together with the entries evicted to make room for it,
+// which must be passed to release once the owner unlocked. The new entry itself is never evicted.
+func (budget *memoBudget) add(owner memoOwner, key interface{}, size int64) (entry *memoEntry, evicted []*memoEntry) {
+	budget.Lock()
+	defer budget.Unlock()
+
+	entry = &memoEntry{owner: owner, key: key, size: size}
+	entry.element = budget.order.PushFront(entry)
+	budget.bytes += size
+	for budget.order.Len() > 1 && budget.exceeded() {
+		oldest := budget.order.Back().Value.(*memoEntry)
+		budget.removeLocked(oldest)
+		budget.evictions++
+		evicted = append(evicted, oldest)
+	}
+	return entry, evicted
+}
+
+func (budget *memoBudget) exceeded() bool {
+	return (budget.maxEntries > 0 && budget.order.Len() > budget.maxEntries) ||
+		(budget.maxBytes > 0 && budget.bytes > budget.maxBytes)
+}
+
+// touch marks the entry as the most recently used one.
+func (budget *memoBudget) touch(entry *memoEntry) {
+	if !budget.limited() {
+		return
+	}
+
+	budget.Lock()
+	defer budget.Unlock()
+	if entry.element != nil {
+		budget.order.MoveToFront(entry.element)
+	}
+}
+
+// remove stops tracking the entries dropped by their owner.
+func (budget *memoBudget) remove(entries ...*memoEntry) {
+	budget.Lock()
+	defer budget.Unlock()
+	for _, entry := range entries {
+		budget.removeLocked(entry)
+	}
+}
+
+func (budget *memoBudget) removeLocked(entry *memoEntry) {
+	if entry.element != nil {
+		budget.order.Remove(entry.element)
+		budget.bytes -= entry.size
+		entry.element = nil
+	}
+}
+
+// release removes the evicted entries from their owners.
+func release(evicted []*memoEntry) {
+	for _, entry := range evicted {
+		entry.owner.evict(entry)
+	}
+}
+
+// usage returns the estimated size of the tracked entries and the number of the evictions.
+func (budget *memoBudget) usage() (bytes int64, evictions uint64) {
+	budget.Lock()
+	defer budget.Unlock()
+	return budget.bytes, budget.evictions
+}
+
+// memoStatus returns the sizes of the memoization caches of the client.
+func (client *Client) memoStatus() MemoizationStatus {
+	status := MemoizationStatus{WarmedEntries: client.warmed.len(), JSONValueEntries: client.jsonValues.len()}
+	if client.allValues != nil {
+		status.AllValuesEntries = client.allValues.len()
+	}
+	status.EstimatedBytes, status.Evictions = client.memoBudget.usage()
+	return status
+}
+
+// The estimated overhead of a memoized entry, the map and list bookkeeping included.
+const memoEntryOverhead = 128
+
+// estimateValueSize estimates the size of an evaluated setting value.
+func estimateValueSize(value interface{}) int64 {
+	if text, ok := value.(string); ok {
+		return int64(16 + len(text))
+	}
+	return 16
+}
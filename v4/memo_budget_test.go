@@ -0,0 +1,72 @@
+package configcat
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClient_MemoizationBudget(t *testing.T) {
+	body := `{ "a": { "v": "a" }, "b": { "v": "b" }, "c": { "v": "c" }, "d": { "v": "d" }, "json": { "v": "{\"x\":1}" } }`
+	fetcher := newFakeConfigProvider()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: body})
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), AllValuesCacheSize: 10,
+		MemoizationBudget: MemoizationBudget{MaxEntries: 3}}, fetcher)
+	defer client.Close()
+	client.Refresh()
+
+	if err := client.Warmup(context.Background(), []string{"a", "b", "c", "d"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if status := client.Status().Memoization; status.WarmedEntries != 3 || status.Evictions != 1 {
+		t.Errorf("Expecting the warmed entries to be capped, got %+v", status)
+	}
+	if _, ok := client.warmed.get(client.warmed.config, warmedKey{userKey: userCacheKey(nil), key: "a"}); ok {
+		t.Error("Expecting the least recently used entry to be evicted")
+	}
+
+	if _, err := client.GetAllValues(nil); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]int
+	if err := client.GetJSONValue("json", &decoded, nil); err != nil || decoded["x"] != 1 {
+		t.Fatalf("Unexpected result: %v %v", decoded, err)
+	}
+	status := client.Status().Memoization
+	if status.WarmedEntries+status.AllValuesEntries+status.JSONValueEntries != 3 ||
+		status.AllValuesEntries != 1 || status.JSONValueEntries != 1 || status.Evictions != 3 || status.EstimatedBytes <= 0 {
+		t.Errorf("Expecting the budget to be shared by the caches, got %+v", status)
+	}
+	if value := client.GetValue("b", ""); value != "b" {
+		t.Errorf("Expecting the evicted entries to be evaluated again, got %v", value)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "a": { "v": "changed" } }`})
+	client.Refresh()
+	client.GetAllValues(nil)
+	if status := client.Status().Memoization; status.AllValuesEntries != 1 {
+		t.Errorf("Unexpected status after the change: %+v", status)
+	}
+}
+
+func TestMemoBudget_MaxBytes(t *testing.T) {
+	budget := newMemoBudget(MemoizationBudget{MaxBytes: 250})
+	cache := newJsonValueCache(budget)
+	config, stringType := &parsedConfig{}, reflect.TypeOf("")
+	for _, text := range []string{`"aaaaaaaaaa"`, `"bbbbbbbbbb"`, `"cccccccccc"`} {
+		if _, err := cache.decode(config, text, stringType); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bytes, evictions := budget.usage(); cache.len() != 1 || evictions != 2 || bytes > 250 {
+		t.Errorf("Expecting the entries over the byte limit to be evicted, got %d entries, %d bytes", cache.len(), bytes)
+	}
+
+	unlimited := newJsonValueCache(newMemoBudget(MemoizationBudget{}))
+	for _, text := range []string{`"a"`, `"b"`, `"c"`} {
+		unlimited.decode(config, text, stringType)
+	}
+	if unlimited.len() != 3 {
+		t.Errorf("Expecting no eviction without limits, got %d entries", unlimited.len())
+	}
+}
@@ -0,0 +1,96 @@
+package configcat
+
+// MultiClientSource describes an environment a MultiClient evaluates the settings from.
+type MultiClientSource struct {
+	// The name of the source reported in EvaluationDetails.Source, e.g. "org" or "team".
+	Name string
+	// The SDK key of the environment.
+	ApiKey string
+	// Optional cache of the environment's configuration. The sources can't share a cache.
+	Cache ConfigCache
+}
+
+// MultiClient evaluates the settings of multiple environments as if they were one configuration.
+// When more environments contain a setting, the first source in the order of the constructor arguments wins.
+type MultiClient struct {
+	sources []MultiClientSource
+	clients []*Client
+}
+
+// NewMultiClient initializes a MultiClient over the given sources in precedence order. The clients of the sources
+// share the given configuration, except the Cache which is taken from the sources.
+func NewMultiClient(config ClientConfig, sources ...MultiClientSource) *MultiClient {
+	if len(sources) == 0 {
+		panic("at least one source is required")
+	}
+
+	multiClient := &MultiClient{sources: sources}
+	for _, source := range sources {
+		sourceConfig := config
+		sourceConfig.Cache = source.Cache
+		multiClient.clients = append(multiClient.clients, NewCustomClient(source.ApiKey, sourceConfig))
+	}
+
+	return multiClient
+}
+
+// GetValue returns a value synchronously as interface{} from the first source containing the given key.
+func (multiClient *MultiClient) GetValue(key string, defaultValue interface{}) interface{} {
+	return multiClient.GetValueDetails(key, defaultValue, nil).Value
+}
+
+// GetValueForUser returns a value synchronously as interface{} from the first source containing the given key.
+// Optional user argument can be passed to identify the caller.
+func (multiClient *MultiClient) GetValueForUser(key string, defaultValue interface{}, user *User) interface{} {
+	return multiClient.GetValueDetails(key, defaultValue, user).Value
+}
+
+// GetValueDetails returns the value identified by the given key together with the details of the evaluation,
+// including the name of the source it was evaluated from.
+func (multiClient *MultiClient) GetValueDetails(key string, defaultValue interface{}, user *User) EvaluationDetails {
+	for i, client := range multiClient.clients {
+		if client.KeyExists(key) {
+			details := client.GetValueDetails(key, defaultValue, user)
+			details.Source = multiClient.sources[i].Name
+			return details
+		}
+	}
+
+	// none of the sources contains the key, the first one reports the missing key
+	return multiClient.clients[0].GetValueDetails(key, defaultValue, user)
+}
+
+// GetAllKeys retrieves the setting keys of all the sources, each key listed once.
+func (multiClient *MultiClient) GetAllKeys() ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, client := range multiClient.clients {
+		clientKeys, err := client.GetAllKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range clientKeys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// Refresh initiates a force refresh synchronously on the configuration of every source.
+func (multiClient *MultiClient) Refresh() {
+	for _, client := range multiClient.clients {
+		client.Refresh()
+	}
+}
+
+// Close shuts down the clients of the sources.
+func (multiClient *MultiClient) Close() {
+	for _, client := range multiClient.clients {
+		client.Close()
+	}
+}
@@ -0,0 +1,36 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestMultiClient_Precedence(t *testing.T) {
+	orgFetcher, org := getTestClients()
+	teamFetcher, team := getTestClients()
+	multiClient := &MultiClient{sources: []MultiClientSource{{Name: "team"}, {Name: "org"}}, clients: []*Client{team, org}}
+	defer multiClient.Close()
+
+	orgFetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "shared": { "v": "org", "i": "o1" }, "orgOnly": { "v": 1 } }`})
+	teamFetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "shared": { "v": "team", "i": "t1" } }`})
+	multiClient.Refresh()
+
+	details := multiClient.GetValueDetails("shared", "", nil)
+	if details.Value != "team" || details.Source != "team" || details.VariationId != "t1" || details.IsDefaultValue {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+
+	details = multiClient.GetValueDetails("orgOnly", 0.0, nil)
+	if details.Value != 1.0 || details.Source != "org" {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+
+	details = multiClient.GetValueDetails("missing", "default", nil)
+	if details.Value != "default" || !details.IsDefaultValue || details.Error == nil {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+
+	keys, err := multiClient.GetAllKeys()
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+}
@@ -0,0 +1,82 @@
+package configcat
+
+import (
+	"context"
+	"net/http"
+)
+
+// objectStorageProvider is a ConfigProvider which downloads the config JSON from an object storage url.
+type objectStorageProvider struct {
+	getUrl func(ctx context.Context) (string, error)
+	client *http.Client
+	// the maximum size of the downloaded config JSON, the MaxConfigSize of the client using the provider
+	maxConfigSize int64
+}
+
+// NewObjectStorageProvider creates a ConfigProvider which downloads the config JSON from the given url,
+// e.g. an S3 or GCS object mirrored from ConfigCat. The object's ETag is used to skip unchanged downloads.
+// If the http client is nil then http.DefaultClient is used.
+func NewObjectStorageProvider(url string, client *http.Client) ConfigProvider {
+	return NewSignedObjectStorageProvider(func(ctx context.Context) (string, error) {
+		return url, nil
+	}, client)
+}
+
+// NewSignedObjectStorageProvider is like NewObjectStorageProvider, but the url is requested before every download
+// from the sign function, so expiring signed urls (e.g. S3 presigned or GCS signed urls) can be renewed.
+func NewSignedObjectStorageProvider(sign func(ctx context.Context) (string, error), client *http.Client) ConfigProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &objectStorageProvider{getUrl: sign, client: client, maxConfigSize: defaultConfig().MaxConfigSize}
+}
+
+// withMaxConfigSize returns a copy of the provider limiting the downloads to the given size, so the provider
+// shared by multiple clients keeps the limit of each.
+func (provider *objectStorageProvider) withMaxConfigSize(maxConfigSize int64) ConfigProvider {
+	limited := *provider
+	limited.maxConfigSize = maxConfigSize
+	return &limited
+}
+
+// GetConfig downloads the config JSON from the object storage.
+func (provider *objectStorageProvider) GetConfig(ctx context.Context, eTag string) (ConfigResponse, error) {
+	url, err := provider.getUrl(ctx)
+	if err != nil {
+		return ConfigResponse{}, err
+	}
+
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return ConfigResponse{}, err
+	}
+
+	request = request.WithContext(ctx)
+	request.Header.Add("Accept-Encoding", "gzip")
+	if eTag != "" {
+		request.Header.Add("If-None-Match", eTag)
+	}
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return ConfigResponse{}, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified {
+		return ConfigResponse{ETag: eTag, NotModified: true}, nil
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return ConfigResponse{}, &StatusError{StatusCode: response.StatusCode}
+	}
+
+	body, err := readBody(response, provider.maxConfigSize)
+	if err != nil {
+		return ConfigResponse{}, err
+	}
+
+	return ConfigResponse{Body: string(body), ETag: response.Header.Get("Etag")}, nil
+}
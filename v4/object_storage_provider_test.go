@@ -0,0 +1,79 @@
+package configcat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestObjectStorageProvider(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{ "key": { "v": "bucket" } }`))
+	}))
+	defer server.Close()
+
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), ConfigProvider: NewObjectStorageProvider(server.URL+"/config.json", nil)})
+	defer client.Close()
+
+	if err := client.RefreshWithContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if value := client.GetValue("key", ""); value != "bucket" {
+		t.Errorf("Expecting bucket, got %v", value)
+	}
+
+	if err := client.RefreshWithContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := client.Status(); status.ETag != `"v1"` || status.LastFetchStatus != "not modified" || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestObjectStorageProvider_Failures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	provider := NewObjectStorageProvider(server.URL, nil)
+	if _, err := provider.GetConfig(context.Background(), ""); err == nil {
+		t.Error("Expecting error for forbidden response")
+	}
+
+	signErr := errors.New("sign failed")
+	provider = NewSignedObjectStorageProvider(func(ctx context.Context) (string, error) {
+		return "", signErr
+	}, nil)
+	if _, err := provider.GetConfig(context.Background(), ""); err != signErr {
+		t.Errorf("Expecting the sign error, got %v", err)
+	}
+}
+
+func TestObjectStorageProvider_MaxConfigSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{ "key": { "v": "bucket" } }`))
+	}))
+	defer server.Close()
+
+	provider := NewObjectStorageProvider(server.URL, nil)
+	adapter := newCustomConfigProvider(provider, ClientConfig{MaxConfigSize: 10, Logger: DefaultLogger(LogLevelFatal)})
+	if _, err := adapter.provider.GetConfig(context.Background(), ""); err == nil {
+		t.Error("Expecting the download to exceed the MaxConfigSize of the client")
+	}
+	if _, err := provider.GetConfig(context.Background(), ""); err != nil {
+		t.Errorf("Expecting the provider to keep the default limit, got %v", err)
+	}
+}
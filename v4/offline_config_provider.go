@@ -0,0 +1,17 @@
+package configcat
+
+// offlineConfigProvider is a configProvider used in offline mode, it never downloads
+// the configuration, so the client is served from the cache.
+type offlineConfigProvider struct {
+	logger Logger
+}
+
+func newOfflineConfigProvider(logger Logger) *offlineConfigProvider {
+	return &offlineConfigProvider{logger: logger}
+}
+
+// getConfigurationAsync reports that the cached configuration is served.
+func (provider *offlineConfigProvider) getConfigurationAsync() *asyncResult {
+	provider.logger.Debugln("Client is in offline mode, skipping the config fetch.")
+	return asCompletedAsyncResult(fetchResponse{status: ServedFromCache})
+}
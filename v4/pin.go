@@ -0,0 +1,129 @@
+package configcat
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// pinningPolicy is a refreshPolicy which serves the pinned configuration instead of the current one while
+// the client is pinned. The refreshes aren't affected, they keep updating the store in the background.
+type pinningPolicy struct {
+	refreshPolicy
+	// the pinned configuration, a nil *pinnedConfig when the client isn't pinned
+	pinned atomic.Value
+	// serializes the compare and swap of pinned, atomic.Value has no CompareAndSwap before Go 1.17
+	pinMutex sync.Mutex
+}
+
+// pinnedConfig is the pinned config JSON with the name of its config source.
+type pinnedConfig struct {
+	json   string
+	source string
+}
+
+func newPinningPolicy(policy refreshPolicy) *pinningPolicy {
+	pinning := &pinningPolicy{refreshPolicy: policy}
+	pinning.pinned.Store((*pinnedConfig)(nil))
+	return pinning
+}
+
+// pinnedConfiguration returns the pinned config JSON, or false if the client isn't pinned.
+func (policy *pinningPolicy) pinnedConfiguration() (string, bool) {
+	if pinned := policy.pinned.Load().(*pinnedConfig); pinned != nil {
+		return pinned.json, true
+	}
+
+	return "", false
+}
+
+// pinnedSource returns the name of the config source of the given configuration if it's the pinned one,
+// otherwise false.
+func (policy *pinningPolicy) pinnedSource(json string) (string, bool) {
+	if pinned := policy.pinned.Load().(*pinnedConfig); pinned != nil && pinned.json == json {
+		return pinned.source, true
+	}
+
+	return "", false
+}
+
+// pin pins the config JSON unless an other one is pinned already, and returns true if it was pinned.
+func (policy *pinningPolicy) pin(json string, source string) bool {
+	policy.pinMutex.Lock()
+	defer policy.pinMutex.Unlock()
+
+	if _, ok := policy.pinnedConfiguration(); ok {
+		return false
+	}
+	policy.pinned.Store(&pinnedConfig{json: json, source: source})
+	return true
+}
+
+// unpin releases the pinned config JSON.
+func (policy *pinningPolicy) unpin() {
+	policy.pinMutex.Lock()
+	defer policy.pinMutex.Unlock()
+	policy.pinned.Store((*pinnedConfig)(nil))
+}
+
+// getConfigurationAsync returns the pinned configuration while the client is pinned.
+func (policy *pinningPolicy) getConfigurationAsync() *asyncResult {
+	if json, ok := policy.pinnedConfiguration(); ok {
+		return asCompletedAsyncResult(json)
+	}
+
+	return policy.refreshPolicy.getConfigurationAsync()
+}
+
+// cachedConfiguration returns the pinned configuration while the client is pinned.
+func (policy *pinningPolicy) cachedConfiguration() (string, bool) {
+	if json, ok := policy.pinnedConfiguration(); ok {
+		return json, true
+	}
+
+	return policy.refreshPolicy.cachedConfiguration()
+}
+
+// Pin freezes the configuration currently served by the client, e.g. for the duration of a long-running batch
+// which must see consistent values. While the client is pinned, the refreshes continue in the background and
+// update the cache, but the evaluations keep serving the pinned configuration until Unpin is called.
+// Pinning an already pinned client keeps the configuration pinned first. It returns an error if there is no
+// configuration to pin yet.
+func (client *Client) Pin() error {
+	if _, ok := client.pinning.pinnedConfiguration(); ok {
+		return nil
+	}
+
+	json, err := client.getConfiguration()
+	if err != nil {
+		return err
+	}
+	if len(json) == 0 {
+		return errors.New("there is no configuration to pin")
+	}
+	client.pinning.pin(json, client.store.sourceOf(json))
+	return nil
+}
+
+// Unpin releases the configuration pinned by Pin, the evaluations serve the current configuration again.
+func (client *Client) Unpin() {
+	client.pinning.unpin()
+}
+
+// servedConfiguration returns the pinned configuration while the client is pinned, otherwise the stored one.
+// It's the fallback of the evaluations when the refresh policy fails to provide the configuration.
+func (client *Client) servedConfiguration() string {
+	if json, ok := client.pinning.pinnedConfiguration(); ok {
+		return json
+	}
+	return client.store.get()
+}
+
+// configSource returns the name of the config source the given configuration came from, see ClientConfig.Sources.
+// The source of the pinned configuration is kept while the client is pinned, even if the store was updated since.
+func (client *Client) configSource(json string) string {
+	if source, ok := client.pinning.pinnedSource(json); ok {
+		return source
+	}
+	return client.store.sourceOf(json)
+}
@@ -0,0 +1,80 @@
+package configcat
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestClient_Pin(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+
+	if err := client.Pin(); err == nil {
+		t.Error("Expecting an error without a configuration to pin")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", `"pinned"`)})
+	client.Refresh()
+	if err := client.Pin(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !client.Status().Pinned {
+		t.Error("Expecting the status to report the pin")
+	}
+
+	updated := fmt.Sprintf(jsonFormat, "key", `"updated"`)
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: updated})
+	client.Refresh()
+	if value := client.GetValue("key", ""); value != "pinned" {
+		t.Errorf("Expecting the pinned value, got %v", value)
+	}
+	if err := client.Pin(); err != nil || client.GetValue("key", "") != "pinned" {
+		t.Error("Expecting the first pinned configuration to be kept")
+	}
+	if current := client.store.get(); current != updated {
+		t.Errorf("Expecting the refresh to update the store, got %s", current)
+	}
+
+	client.Unpin()
+	if value := client.GetValue("key", ""); value != "updated" {
+		t.Errorf("Expecting the current value after unpinning, got %v", value)
+	}
+	if client.Status().Pinned {
+		t.Error("Expecting the status to report no pin")
+	}
+}
+
+func TestClient_PinConcurrent(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", `"first"`)})
+	client.Refresh()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i == 0 {
+				fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", `"second"`)})
+				client.Refresh()
+			}
+			_ = client.Pin()
+		}(i)
+	}
+	wg.Wait()
+
+	pinned, _ := client.pinning.pinnedConfiguration()
+	if value := client.GetValue("key", ""); value != client.parseJson(pinned, "key", "", nil) {
+		t.Errorf("Expecting the value of the pinned configuration, got %v", value)
+	}
+	if served := client.servedConfiguration(); served != pinned {
+		t.Errorf("Expecting the pinned configuration to be served, got %s", served)
+	}
+
+	client.Unpin()
+	if served := client.servedConfiguration(); served != client.store.get() {
+		t.Errorf("Expecting the stored configuration after unpinning, got %s", served)
+	}
+}
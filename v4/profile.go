@@ -0,0 +1,72 @@
+package configcat
+
+import (
+	"time"
+)
+
+// Profile is a named preset of the ClientConfig options for a common deployment, see ProfileConfig.
+type Profile int
+
+const (
+	// ProfileServerHighThroughput is for long running servers evaluating many flags per second.
+	// The configuration is polled every minute in the background, so the evaluations never wait for a fetch,
+	// at the price of serving a changed flag up to a minute late. The polls of the instances started together
+	// are shifted by random offsets so they don't hit the CDN in lockstep, the GetAllValues results of 1024
+	// users are cached, and the repeated warnings are logged at most once a minute.
+	ProfileServerHighThroughput Profile = 1
+	// ProfileMobileBackend is for the backends of mobile apps, whose traffic comes in bursts with idle periods.
+	// The configuration is only fetched while the backend is used: it's cached for 5 minutes and refreshed in
+	// the background when 80% of that time has passed, so the requests rarely wait for a fetch. The downloads
+	// are limited to 5 seconds and the circuit breaker stops fetching for 30 seconds after 3 failed fetches,
+	// the cached configuration is served meanwhile.
+	ProfileMobileBackend Profile = 2
+	// ProfileBatchJob is for short lived jobs which need the same configuration for their whole run.
+	// Nothing is fetched in the background: the job calls Refresh at its start, which is retried with
+	// exponential backoff up to 5 times, and waits up to 30 seconds for the configuration. The evaluations
+	// don't start goroutines, so the job can exit as soon as it's done.
+	ProfileBatchJob Profile = 3
+)
+
+// String returns the name of the profile.
+func (profile Profile) String() string {
+	switch profile {
+	case ProfileServerHighThroughput:
+		return "server high throughput"
+	case ProfileMobileBackend:
+		return "mobile backend"
+	case ProfileBatchJob:
+		return "batch job"
+	}
+
+	return "unknown"
+}
+
+// ProfileConfig returns the ClientConfig preset of the profile, the documentation of the profiles describes
+// their trade-offs. The options not set by the profile keep their defaults, and the returned config can be
+// adjusted before it's passed to NewCustomClient. An unknown profile returns the default config.
+func ProfileConfig(profile Profile) ClientConfig {
+	switch profile {
+	case ProfileServerHighThroughput:
+		return ClientConfig{
+			Mode:               AutoPoll(time.Minute),
+			PollPhaseOffset:    true,
+			AllValuesCacheSize: 1024,
+			LogDedupWindow:     time.Minute,
+		}
+	case ProfileMobileBackend:
+		return ClientConfig{
+			Mode:           LazyLoadWithPrefetch(5*time.Minute, 0.8),
+			FetchTimeout:   5 * time.Second,
+			CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 3, CoolDown: 30 * time.Second},
+		}
+	case ProfileBatchJob:
+		return ClientConfig{
+			Mode:                    ManualPoll(),
+			RetryPolicy:             ExponentialRetry(time.Second, 10*time.Second, 5),
+			MaxWaitTimeForSyncCalls: 30 * time.Second,
+			NoBackgroundGoroutines:  true,
+		}
+	}
+
+	return ClientConfig{}
+}
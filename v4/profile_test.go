@@ -0,0 +1,43 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProfileConfig(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		name    string
+		mode    string
+	}{
+		{ProfileServerHighThroughput, "server high throughput", "auto"},
+		{ProfileMobileBackend, "mobile backend", "lazy"},
+		{ProfileBatchJob, "batch job", "manual"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &countingConfigProvider{body: fmt.Sprintf(jsonFormat, "key", "true")}
+			config := ProfileConfig(test.profile)
+			config.ConfigProvider = provider
+			client := NewCustomClient("fakeKey", config)
+			defer client.Close()
+			client.Refresh()
+
+			if value := client.GetValue("key", false); value != true {
+				t.Errorf("Expecting the fetched value, got %v", value)
+			}
+			if mode := client.Status().Mode; mode != test.mode {
+				t.Errorf("Expecting %s mode, got %s", test.mode, mode)
+			}
+			if name := test.profile.String(); name != test.name {
+				t.Errorf("Expecting name %q, got %q", test.name, name)
+			}
+		})
+	}
+
+	if config := ProfileConfig(Profile(0)); config.Mode != nil || Profile(0).String() != "unknown" {
+		t.Error("Expecting the default config for an unknown profile")
+	}
+}
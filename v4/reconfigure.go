@@ -0,0 +1,147 @@
+package configcat
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Reconfiguration describes the options of the Client which can be changed at runtime by Reconfigure.
+// The zero values leave the options unchanged.
+type Reconfiguration struct {
+	// The logger replacing the current one. The labels of the client are kept.
+	Logger Logger
+
+	// The base URL of the ConfigCat CDN or the proxy replacing the current one, e.g. after a relay rollout.
+	// It's used from the next fetch on, the fetch in progress completes with the previous URL.
+	// It can't be changed when the configuration is provided by a ConfigProvider, a local file or
+	// the client is offline.
+	BaseUrl string
+
+	// The cache replacing the current one. The current configuration is written into the new cache
+	// before the handover, so it's served without interruption. The state of the circuit breaker is
+	// persisted into the new cache from then on, if it implements CircuitStateCache.
+	Cache ConfigCache
+}
+
+// Reconfigure changes the given options of the client while it's running, without dropping the current
+// configuration. Nothing is changed when an error is returned.
+func (client *Client) Reconfigure(reconfiguration Reconfiguration) error {
+	var fetcher *configFetcher
+	if len(reconfiguration.BaseUrl) > 0 {
+		var ok bool
+		if fetcher, ok = client.provider.(*configFetcher); !ok {
+			return errors.New("the base URL can't be changed, the configuration isn't downloaded from ConfigCat")
+		}
+	}
+
+	if reconfiguration.Cache != nil {
+		if err := client.store.setCache(reconfiguration.Cache); err != nil {
+			return err
+		}
+		if client.circuitBreaker != nil {
+			client.circuitBreaker.setStateCache(reconfiguration.Cache)
+		}
+		client.logger.Infoln("The cache of the client was replaced.")
+	}
+
+	if fetcher != nil {
+		fetcher.setBaseUrl(reconfiguration.BaseUrl)
+		client.logger.Infof("The base URL of the client was changed to %s.", reconfiguration.BaseUrl)
+	}
+
+	if reconfiguration.Logger != nil {
+		client.baseLogger.set(newLabelLogger(reconfiguration.Logger, client.labels))
+		client.logger.Infoln("The logger of the client was replaced.")
+	}
+
+	return nil
+}
+
+// swappableLogger is a Logger decorator whose underlying logger can be replaced while it's used.
+// All the components of a client log through the same swappableLogger, so Reconfigure can replace the logger
+// of all of them at once.
+type swappableLogger struct {
+	logger atomic.Value
+}
+
+// loggerHolder wraps the Logger in a concrete type, as an atomic.Value requires the same type for all its values.
+type loggerHolder struct {
+	Logger
+}
+
+func newSwappableLogger(logger Logger) *swappableLogger {
+	swappable := &swappableLogger{}
+	swappable.set(logger)
+	return swappable
+}
+
+func (logger *swappableLogger) set(replacement Logger) {
+	logger.logger.Store(loggerHolder{replacement})
+}
+
+func (logger *swappableLogger) get() Logger {
+	return logger.logger.Load().(loggerHolder).Logger
+}
+
+// IsLevelEnabled tells whether the current logger logs the given level.
+func (logger *swappableLogger) IsLevelEnabled(level logrus.Level) bool {
+	return isLogLevelEnabled(logger.get(), LogLevel(level))
+}
+
+func (logger *swappableLogger) Debugf(format string, args ...interface{}) {
+	logger.get().Debugf(format, args...)
+}
+
+func (logger *swappableLogger) Infof(format string, args ...interface{}) {
+	logger.get().Infof(format, args...)
+}
+
+func (logger *swappableLogger) Warnf(format string, args ...interface{}) {
+	logger.get().Warnf(format, args...)
+}
+
+func (logger *swappableLogger) Errorf(format string, args ...interface{}) {
+	logger.get().Errorf(format, args...)
+}
+
+func (logger *swappableLogger) Debug(args ...interface{}) {
+	logger.get().Debug(args...)
+}
+
+func (logger *swappableLogger) Info(args ...interface{}) {
+	logger.get().Info(args...)
+}
+
+func (logger *swappableLogger) Warn(args ...interface{}) {
+	logger.get().Warn(args...)
+}
+
+func (logger *swappableLogger) Error(args ...interface{}) {
+	logger.get().Error(args...)
+}
+
+func (logger *swappableLogger) Debugln(args ...interface{}) {
+	logger.get().Debugln(args...)
+}
+
+func (logger *swappableLogger) Infoln(args ...interface{}) {
+	logger.get().Infoln(args...)
+}
+
+func (logger *swappableLogger) Warnln(args ...interface{}) {
+	logger.get().Warnln(args...)
+}
+
+func (logger *swappableLogger) Errorln(args ...interface{}) {
+	logger.get().Errorln(args...)
+}
+
+func (logger *swappableLogger) logEvent(level LogLevel, event string, fields logFields, message string) {
+	if events, ok := logger.get().(eventLogger); ok {
+		events.logEvent(level, event, fields, message)
+		return
+	}
+	logf(logger.get(), level, "%s", message)
+}
@@ -0,0 +1,216 @@
+package configcat
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type errorCapturingLogger struct {
+	Logger
+	errors []string
+	sync.Mutex
+}
+
+func (logger *errorCapturingLogger) Errorf(format string, args ...interface{}) {
+	logger.Lock()
+	defer logger.Unlock()
+	logger.errors = append(logger.errors, fmt.Sprintf(format, args...))
+}
+
+func (logger *errorCapturingLogger) count() int {
+	logger.Lock()
+	defer logger.Unlock()
+	return len(logger.errors)
+}
+
+func TestClient_Reconfigure_Logger(t *testing.T) {
+	first := &errorCapturingLogger{Logger: DefaultLogger(LogLevelError)}
+	second := &errorCapturingLogger{Logger: DefaultLogger(LogLevelError)}
+	fetcher := newFakeConfigProvider()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "flag": { "v": true, "t": 0 } }`})
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Logger: first, Labels: map[string]string{"env": "test"}}, fetcher)
+	defer client.Close()
+	client.Refresh()
+
+	_, _ = client.GetStringValue("flag", "", nil)
+	if err := client.Reconfigure(Reconfiguration{Logger: second}); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.GetStringValue("flag", "", nil)
+
+	if first.count() != 1 || second.count() != 1 {
+		t.Fatalf("Expecting one error per logger, got %v and %v", first.errors, second.errors)
+	}
+	if second.errors[0][:len("[env=test] ")] != "[env=test] " {
+		t.Errorf("Expecting the labels to be kept, got %q", second.errors[0])
+	}
+}
+
+func TestClient_Reconfigure_BaseUrl(t *testing.T) {
+	serve := func(value string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{ "key": { "v": "%s", "t": 1 } }`, value)
+		}))
+	}
+	cdn := serve("cdn")
+	defer cdn.Close()
+	relay := serve("relay")
+	defer relay.Close()
+
+	tests := []struct {
+		name   string
+		config ClientConfig
+	}{
+		{"plain", ClientConfig{}},
+		{"retry policy", ClientConfig{RetryPolicy: ExponentialRetry(time.Millisecond, 0, 2)}},
+		{"circuit breaker", ClientConfig{CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 3}}},
+		{"acceptance", ClientConfig{AcceptConfig: func(*Evaluator) error { return nil }}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := test.config
+			config.Mode, config.BaseUrl = ManualPoll(), cdn.URL
+			client := NewCustomClient("fakeKey", config)
+			defer client.Close()
+			client.Refresh()
+			if value := client.GetValue("key", ""); value != "cdn" {
+				t.Fatalf("Expecting the value of the CDN, got %v", value)
+			}
+
+			if err := client.Reconfigure(Reconfiguration{BaseUrl: relay.URL}); err != nil {
+				t.Fatal(err)
+			}
+			if value := client.GetValue("key", ""); value != "cdn" {
+				t.Errorf("Expecting the current config to be kept until the next fetch, got %v", value)
+			}
+			client.Refresh()
+			if value := client.GetValue("key", ""); value != "relay" {
+				t.Errorf("Expecting the value of the relay, got %v", value)
+			}
+		})
+	}
+}
+
+func TestClient_Reconfigure_BaseUrlOfCustomProvider(t *testing.T) {
+	_, client := getTestClients()
+	defer client.Close()
+
+	if err := client.Reconfigure(Reconfiguration{BaseUrl: "https://relay.example.com", Logger: DefaultLogger(LogLevelError)}); err == nil {
+		t.Error("Expecting error for a client without a config fetcher")
+	}
+	if _, ok := client.baseLogger.get().(*swappableLogger); ok {
+		t.Error("Expecting nothing to be changed")
+	}
+}
+
+func TestClient_Reconfigure_Cache(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", `"value"`)})
+	client.Refresh()
+
+	if err := client.Reconfigure(Reconfiguration{Cache: failingCache{}}); err == nil {
+		t.Error("Expecting error when the new cache can't be written")
+	}
+	if status := client.Status(); status.CacheSource != "memory" {
+		t.Errorf("Expecting the previous cache to be kept, got %v", status.CacheSource)
+	}
+
+	cache := &inMemoryConfigCache{}
+	if err := client.Reconfigure(Reconfiguration{Cache: cache}); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := cache.Get(); value != fmt.Sprintf(jsonFormat, "key", `"value"`) {
+		t.Errorf("Expecting the current config to be handed over, got %q", value)
+	}
+	if value := client.GetValue("key", ""); value != "value" {
+		t.Errorf("Expecting the config to be served from the new cache, got %v", value)
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", `"value2"`)})
+	client.Refresh()
+	if value, _ := cache.Get(); value != fmt.Sprintf(jsonFormat, "key", `"value2"`) {
+		t.Errorf("Expecting the new cache to be written, got %q", value)
+	}
+}
+
+// blockingSetCache is a cache whose writes wait until the release channel is closed.
+type blockingSetCache struct {
+	inMemoryConfigCache
+	release chan struct{}
+}
+
+func (cache *blockingSetCache) Set(value string) error {
+	<-cache.release
+	return cache.inMemoryConfigCache.Set(value)
+}
+
+func TestClient_Reconfigure_CacheReadsDuringHandover(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", `"value"`)})
+	client.Refresh()
+
+	cache := &blockingSetCache{release: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Reconfigure(Reconfiguration{Cache: cache})
+	}()
+
+	read := make(chan interface{}, 1)
+	go func() {
+		read <- client.GetValue("key", "")
+	}()
+	select {
+	case value := <-read:
+		if value != "value" {
+			t.Errorf("Expecting the current value during the handover, got %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expecting the reads not to wait for the write of the new cache")
+	}
+
+	close(cache.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_Reconfigure_CircuitStateCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fetcher := newFakeConfigProvider()
+	fetcher.SetError(errors.New("network error"))
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), Cache: NewFileCache(filepath.Join(dir, "old.json")),
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute}, Logger: DefaultLogger(LogLevelFatal)}, fetcher)
+	defer client.Close()
+	client.Refresh()
+
+	path := filepath.Join(dir, "new.json")
+	if err := client.Reconfigure(Reconfiguration{Cache: NewFileCache(path)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".circuit"); err != nil {
+		t.Errorf("Expecting the open circuit to be persisted into the new cache, got %v", err)
+	}
+
+	if err := client.Reconfigure(Reconfiguration{Cache: &inMemoryConfigCache{}}); err != nil {
+		t.Fatal(err)
+	}
+	if state := client.Status().CircuitState; state != CircuitOpen {
+		t.Errorf("Expecting the circuit to stay open, got %v", state)
+	}
+}
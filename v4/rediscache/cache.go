@@ -0,0 +1,123 @@
+// Package rediscache contains a Redis backed ConfigCache, so the configuration downloaded by one
+// instance of a service is shared with the other instances. It's a separate module to keep the
+// Redis client out of the dependencies of the SDK.
+package rediscache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+	"github.com/go-redis/redis/v8"
+)
+
+// Config describes the configuration of a Redis cache.
+type Config struct {
+	// The Redis client, e.g. redis.NewClient(&redis.Options{Addr: "localhost:6379"}).
+	Client redis.UniversalClient
+	// The prefix of the Redis key the configuration is stored at. The default is "configcat:".
+	// Services using different SDK keys with the same Redis should use different prefixes.
+	KeyPrefix string
+	// The expiration of the stored configuration. If it's 0 then the configuration doesn't expire.
+	TTL time.Duration
+	// The timeout of the Redis commands. The default is 1 second.
+	Timeout time.Duration
+}
+
+// Cache is a configcat.ConfigCache which stores the configuration in Redis. The last configuration read or written
+// is kept in memory as well, and it's served while Redis is unavailable.
+type Cache struct {
+	client redis.UniversalClient
+	key    string
+	// the key of the state of the circuit breaker
+	circuitKey string
+	ttl        time.Duration
+	timeout    time.Duration
+	value      string
+	sync.RWMutex
+}
+
+var _ configcat.ConfigCache = (*Cache)(nil)
+var _ configcat.CircuitStateCache = (*Cache)(nil)
+
+// New creates a Redis cache.
+func New(config Config) *Cache {
+	if len(config.KeyPrefix) == 0 {
+		config.KeyPrefix = "configcat:"
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = time.Second
+	}
+
+	return &Cache{client: config.Client,
+		key:        config.KeyPrefix + "config",
+		circuitKey: config.KeyPrefix + "circuit",
+		ttl:        config.TTL,
+		timeout:    config.Timeout}
+}
+
+// Get reads the configuration from Redis. The configuration kept in memory is returned when Redis fails.
+func (cache *Cache) Get() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cache.timeout)
+	defer cancel()
+
+	value, err := cache.client.Get(ctx, cache.key).Result()
+	if err == redis.Nil {
+		return cache.memoryValue(), nil
+	}
+
+	if err != nil {
+		if memoryValue := cache.memoryValue(); len(memoryValue) > 0 {
+			return memoryValue, nil
+		}
+		return "", err
+	}
+
+	cache.Lock()
+	cache.value = value
+	cache.Unlock()
+	return value, nil
+}
+
+// Set writes the configuration into Redis and into the memory.
+func (cache *Cache) Set(value string) error {
+	cache.Lock()
+	cache.value = value
+	cache.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cache.timeout)
+	defer cancel()
+	return cache.client.Set(ctx, cache.key, value, cache.ttl).Err()
+}
+
+// GetCircuitState reads the state of the circuit breaker of the clients from Redis, so the instances started during
+// an outage of the CDN respect the cool-down of the circuit opened by the others.
+func (cache *Cache) GetCircuitState() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cache.timeout)
+	defer cancel()
+
+	state, err := cache.client.Get(ctx, cache.circuitKey).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return state, err
+}
+
+// SetCircuitState writes the state of the circuit breaker into Redis, the empty state deletes it.
+func (cache *Cache) SetCircuitState(state string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cache.timeout)
+	defer cancel()
+
+	if len(state) == 0 {
+		return cache.client.Del(ctx, cache.circuitKey).Err()
+	}
+	return cache.client.Set(ctx, cache.circuitKey, state, cache.ttl).Err()
+}
+
+func (cache *Cache) memoryValue() string {
+	cache.RLock()
+	defer cache.RUnlock()
+	return cache.value
+}
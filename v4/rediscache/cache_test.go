@@ -0,0 +1,94 @@
+package rediscache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestCache(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cache := New(Config{Client: redis.NewClient(&redis.Options{Addr: server.Addr()}), KeyPrefix: "test:", TTL: time.Minute})
+	if value, err := cache.Get(); err != nil || value != "" {
+		t.Errorf("Expecting empty cache, got %v, %v", value, err)
+	}
+
+	if err := cache.Set("config"); err != nil {
+		t.Fatal(err)
+	}
+
+	if stored, _ := server.Get("test:config"); stored != "config" {
+		t.Errorf("Expecting the config in Redis, got %s", stored)
+	}
+
+	if ttl := server.TTL("test:config"); ttl != time.Minute {
+		t.Errorf("Expecting 1 minute TTL, got %v", ttl)
+	}
+
+	_ = server.Set("test:config", "shared")
+	if value, err := cache.Get(); err != nil || value != "shared" {
+		t.Errorf("Expecting the shared config, got %v, %v", value, err)
+	}
+}
+
+func TestCache_RedisOutage(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := New(Config{Client: redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1}), Timeout: time.Millisecond * 200})
+	if err := cache.Set("config"); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	if value, err := cache.Get(); err != nil || value != "config" {
+		t.Errorf("Expecting the config from memory, got %v, %v", value, err)
+	}
+
+	if err := cache.Set("new config"); err == nil {
+		t.Error("Expecting error while Redis is down")
+	}
+
+	if value, _ := cache.Get(); value != "new config" {
+		t.Errorf("Expecting the new config from memory, got %v", value)
+	}
+}
+
+func TestCache_CircuitState(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cache := New(Config{Client: redis.NewClient(&redis.Options{Addr: server.Addr()}), KeyPrefix: "test:"})
+	if state, err := cache.GetCircuitState(); err != nil || state != "" {
+		t.Errorf("Expecting no circuit state, got %v, %v", state, err)
+	}
+
+	if err := cache.SetCircuitState("open"); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := server.Get("test:circuit"); stored != "open" {
+		t.Errorf("Expecting the circuit state in Redis, got %s", stored)
+	}
+	if state, err := cache.GetCircuitState(); err != nil || state != "open" {
+		t.Errorf("Expecting the stored circuit state, got %v, %v", state, err)
+	}
+
+	if err := cache.SetCircuitState(""); err != nil {
+		t.Fatal(err)
+	}
+	if server.Exists("test:circuit") {
+		t.Error("Expecting the circuit state to be deleted")
+	}
+}
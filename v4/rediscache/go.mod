@@ -0,0 +1,24 @@
+module github.com/configcat/go-sdk/v4/rediscache
+
+go 1.17
+
+// the module is developed together with the SDK in the same repository
+replace github.com/configcat/go-sdk/v4 => ../
+
+require (
+	github.com/alicebob/miniredis/v2 v2.23.0
+	github.com/configcat/go-sdk/v4 v4.0.0-00010101000000-000000000000
+	github.com/go-redis/redis/v8 v8.11.5
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/blang/semver v3.5.1+incompatible // indirect
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/sirupsen/logrus v1.4.2 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
+	golang.org/x/text v0.3.8 // indirect
+)
@@ -0,0 +1,40 @@
+package configcat
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRefreshRateLimited is returned when a forced refresh is skipped because the previous one
+// happened less than ClientConfig.MinRefreshInterval ago. The cached configuration is kept.
+var ErrRefreshRateLimited = errors.New("refresh rate limited, the cached configuration is kept")
+
+// refreshLimiter enforces a minimum interval between forced refreshes.
+type refreshLimiter struct {
+	interval    time.Duration
+	lastRefresh time.Time
+	clock       Clock
+	sync.Mutex
+}
+
+func newRefreshLimiter(interval time.Duration, clock Clock) *refreshLimiter {
+	return &refreshLimiter{interval: interval, clock: clock}
+}
+
+// allow returns true and records the refresh if the minimum interval elapsed since the last allowed refresh.
+func (limiter *refreshLimiter) allow() bool {
+	if limiter.interval <= 0 {
+		return true
+	}
+
+	limiter.Lock()
+	defer limiter.Unlock()
+	now := limiter.clock.Now()
+	if !limiter.lastRefresh.IsZero() && now.Sub(limiter.lastRefresh) < limiter.interval {
+		return false
+	}
+
+	limiter.lastRefresh = now
+	return true
+}
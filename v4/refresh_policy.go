@@ -4,8 +4,13 @@ package configcat
 type refreshPolicy interface {
 	// getConfigurationAsync reads the current configuration value.
 	getConfigurationAsync() *asyncResult
+	// cachedConfiguration reads the current configuration value without allocating an async result,
+	// it returns false if the value isn't available without waiting or refreshing.
+	cachedConfiguration() (string, bool)
 	// refreshAsync initiates a force refresh on the cached configuration.
 	refreshAsync() *async
+	// refresh initiates a force refresh on the cached configuration, the result is the fetchResponse or the fetch error.
+	refresh() *asyncResult
 	// close shuts down the policy.
 	close()
 }
@@ -30,10 +35,54 @@ type RefreshMode interface {
 
 // refreshAsync initiates a force refresh on the cached configuration.
 func (refresher *configRefresher) refreshAsync() *async {
-	return refresher.configFetcher.getConfigurationAsync().accept(func(result interface{}) {
-		response := result.(fetchResponse)
-		if result.(fetchResponse).isFetched() {
-			refresher.store.set(response.body)
+	return refresher.refresh().async
+}
+
+// refresh initiates a force refresh on the cached configuration,
+// the result is the fetchResponse completed after the cache is updated, or the error of the failed fetch.
+func (refresher *configRefresher) refresh() *asyncResult {
+	return refresher.configFetcher.getConfigurationAsync().applyThenWithError(func(result interface{}, err error) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		response := asFetchResponse(result)
+		if response.isFetched() {
+			refresher.store.setFetched(response)
 		}
+
+		return response, nil
+	}).hold()
+}
+
+// firstFetchGate is a refreshPolicy which holds back the configuration until the first fetch, started
+// at its creation, completes. It's used when the cache couldn't be read at startup.
+type firstFetchGate struct {
+	refreshPolicy
+	store *configStore
+	first *asyncResult
+}
+
+func newFirstFetchGate(policy refreshPolicy, store *configStore) *firstFetchGate {
+	return &firstFetchGate{refreshPolicy: policy, store: store, first: policy.refresh()}
+}
+
+// getConfigurationAsync reads the current configuration value once the first fetch completed.
+func (gate *firstFetchGate) getConfigurationAsync() *asyncResult {
+	if gate.first.isCompleted() {
+		return gate.refreshPolicy.getConfigurationAsync()
+	}
+
+	return gate.first.applyThen(func(interface{}) interface{} {
+		return gate.store.get()
 	})
 }
+
+// cachedConfiguration reads the current configuration value once the first fetch completed.
+func (gate *firstFetchGate) cachedConfiguration() (string, bool) {
+	if !gate.first.isCompleted() {
+		return "", false
+	}
+
+	return gate.refreshPolicy.cachedConfiguration()
+}
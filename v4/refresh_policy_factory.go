@@ -1,23 +1,57 @@
 package configcat
 
+import (
+	"net/http"
+	"time"
+)
+
 type pollingModeVisitor interface {
 	visitAutoPoll(config autoPollConfig) refreshPolicy
 	visitManualPoll(config manualPollConfig) refreshPolicy
 	visitLazyLoad(config lazyLoadConfig) refreshPolicy
+	visitLocalFilePoll(config localFilePollConfig) refreshPolicy
+	visitStreaming(config streamingConfig) refreshPolicy
 }
 
 type refreshPolicyFactory struct {
 	configFetcher configProvider
 	store         *configStore
 	logger        Logger
+	executor      *callbackExecutor
+	clock         Clock
+	// the retries of the auto polling within a polling interval
+	pollRetryPolicy RetryPolicy
+	// the spreading of the auto polling of simultaneously started instances
+	pollStartJitter time.Duration
+	pollPhaseOffset bool
+	// if it's true then the policies must not start goroutines, the auto polling is replaced by lazy loading
+	inline bool
+	// the client and the default URL of the streaming, and the size limit of its events
+	streamClient *http.Client
+	streamUrl    func() string
+	maxEventSize int64
 }
 
-func newRefreshPolicyFactory(configFetcher configProvider, store *configStore, logger Logger) *refreshPolicyFactory {
-	return &refreshPolicyFactory{configFetcher: configFetcher, store: store, logger: logger}
+func newRefreshPolicyFactory(configFetcher configProvider, store *configStore, logger Logger, executor *callbackExecutor, clock Clock) *refreshPolicyFactory {
+	return &refreshPolicyFactory{configFetcher: configFetcher, store: store, logger: logger, executor: executor, clock: clock}
 }
 
 func (factory *refreshPolicyFactory) visitAutoPoll(config autoPollConfig) refreshPolicy {
-	return newAutoPollingPolicy(factory.configFetcher, factory.store, factory.logger, config)
+	if factory.inline {
+		if config.changeListener != nil {
+			listener := factory.executor.wrap(config.changeListener)
+			factory.store.addListener(func(previous string, current string) {
+				listener()
+			})
+		}
+		return newLazyLoadingPolicy(factory.configFetcher, factory.store, factory.logger, factory.clock,
+			lazyLoadConfig{cacheInterval: config.autoPollInterval})
+	}
+
+	config.changeListener = factory.executor.wrap(config.changeListener)
+	config.retryPolicy = factory.pollRetryPolicy
+	config.startJitter, config.phaseOffset = factory.pollStartJitter, factory.pollPhaseOffset
+	return newAutoPollingPolicy(factory.configFetcher, factory.store, factory.logger, factory.clock, config)
 }
 
 func (factory *refreshPolicyFactory) visitManualPoll(config manualPollConfig) refreshPolicy {
@@ -25,5 +59,28 @@ func (factory *refreshPolicyFactory) visitManualPoll(config manualPollConfig) re
 }
 
 func (factory *refreshPolicyFactory) visitLazyLoad(config lazyLoadConfig) refreshPolicy {
-	return newLazyLoadingPolicy(factory.configFetcher, factory.store, factory.logger, config)
+	if factory.inline {
+		config.useAsyncRefresh = false
+	}
+	return newLazyLoadingPolicy(factory.configFetcher, factory.store, factory.logger, factory.clock, config)
+}
+
+func (factory *refreshPolicyFactory) visitLocalFilePoll(config localFilePollConfig) refreshPolicy {
+	return factory.visitAutoPoll(autoPollConfig{autoPollInterval: config.pollInterval, changeListener: config.changeListener})
+}
+
+func (factory *refreshPolicyFactory) visitStreaming(config streamingConfig) refreshPolicy {
+	if factory.inline {
+		return factory.visitAutoPoll(autoPollConfig{autoPollInterval: config.FallbackPollInterval, changeListener: config.ChangeListener})
+	}
+
+	config.ChangeListener = factory.executor.wrap(config.ChangeListener)
+	url := factory.streamUrl
+	if len(config.Url) > 0 {
+		url = func() string {
+			return config.Url
+		}
+	}
+	return newStreamingPolicy(factory.configFetcher, factory.store, factory.logger, factory.clock,
+		factory.streamClient, url, factory.maxEventSize, config)
 }
@@ -0,0 +1,80 @@
+package configcat
+
+import (
+	"context"
+	"time"
+)
+
+// RefreshStatus classifies the outcome of a config refresh.
+type RefreshStatus int
+
+const (
+	// RefreshFetched indicates that a new configuration was downloaded.
+	RefreshFetched RefreshStatus = 0
+	// RefreshNotModified indicates that the configuration didn't change since the last download.
+	RefreshNotModified RefreshStatus = 1
+	// RefreshServedFromCache indicates that no download was attempted (e.g. in offline mode or because the
+	// refresh was rate limited) and the cached configuration is served.
+	RefreshServedFromCache RefreshStatus = 2
+	// RefreshFailed indicates that the download failed and the cached configuration is served.
+	RefreshFailed RefreshStatus = 3
+)
+
+// String returns the text representation of the refresh status.
+func (status RefreshStatus) String() string {
+	switch status {
+	case RefreshFetched:
+		return "fetched"
+	case RefreshNotModified:
+		return "not modified"
+	case RefreshServedFromCache:
+		return "served from cache"
+	case RefreshFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+// RefreshResult describes the outcome of a config refresh.
+type RefreshResult struct {
+	// The classification of the outcome.
+	Status RefreshStatus
+	// The reason of the failed or skipped refresh.
+	Error error
+	// The time the refresh started.
+	StartTime time.Time
+	// The time the refresh completed.
+	EndTime time.Time
+}
+
+func newRefreshResult(response fetchResponse, err error, start time.Time, end time.Time) RefreshResult {
+	result := RefreshResult{Status: RefreshFailed, Error: err, StartTime: start, EndTime: end}
+	if err != nil {
+		return result
+	}
+
+	switch response.status {
+	case Fetched:
+		result.Status = RefreshFetched
+	case NotModified:
+		result.Status = RefreshNotModified
+	case ServedFromCache:
+		result.Status = RefreshServedFromCache
+	}
+
+	return result
+}
+
+// RefreshWithResult initiates a force refresh on the cached configuration and describes its outcome.
+// It blocks until the refresh completes or the context is done, in which case the result is failed with
+// the error of the context.
+func (client *Client) RefreshWithResult(ctx context.Context) RefreshResult {
+	start := client.clock.Now()
+	value, err := client.refresh().getWithContext(ctx)
+	if err == ErrRefreshRateLimited {
+		return RefreshResult{Status: RefreshServedFromCache, Error: err, StartTime: start, EndTime: client.clock.Now()}
+	}
+
+	return newRefreshResult(asFetchResponse(value), err, start, client.clock.Now())
+}
@@ -0,0 +1,50 @@
+package configcat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClient_RefreshWithResult(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), MinRefreshInterval: time.Millisecond * 50}, fetcher)
+	defer client.Close()
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: "{}"})
+	result := client.RefreshWithResult(context.Background())
+	if result.Status != RefreshFetched || result.Error != nil || result.EndTime.Before(result.StartTime) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if result = client.RefreshWithResult(context.Background()); result.Status != RefreshServedFromCache || result.Error != ErrRefreshRateLimited {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	fetcher.SetResponse(fetchResponse{status: NotModified})
+	if result = client.RefreshWithResult(context.Background()); result.Status != RefreshNotModified {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	fetchErr := errors.New("network error")
+	fetcher.SetError(fetchErr)
+	if result = client.RefreshWithResult(context.Background()); result.Status != RefreshFailed || result.Error != fetchErr {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if last := client.Status().LastRefresh; last.Status != RefreshFailed || last.Error != fetchErr || last.EndTime.IsZero() {
+		t.Errorf("Unexpected status: %+v", last)
+	}
+}
+
+func TestClient_RefreshWithResult_Offline(t *testing.T) {
+	client := NewCustomClient("fakeKey", ClientConfig{Mode: ManualPoll(), Offline: true})
+	defer client.Close()
+
+	if result := client.RefreshWithResult(context.Background()); result.Status != RefreshServedFromCache || result.Error != nil {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
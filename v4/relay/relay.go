@@ -0,0 +1,208 @@
+// Package relay contains an embeddable HTTP server which serves the configuration of one upstream
+// ConfigCat client to the downstream SDK clients of the cluster, so only the relay reaches the ConfigCat CDN.
+//
+// Downstream SDK clients use the relay by setting its address as their base url. Clients interested in
+// changes can subscribe to the server-sent events stream of the relay at the /sse path, e.g. by the
+// configcat.Streaming refresh mode.
+package relay
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+// Config describes the configuration of a Relay.
+type Config struct {
+	// The SDK key served by the relay. If it's empty then every config request is served with the
+	// configuration of the upstream client, otherwise requests for other SDK keys are rejected.
+	SdkKey string
+	// The interval of checking the upstream client for configuration changes. The default is 1 second.
+	WatchInterval time.Duration
+	// The interval of the heartbeats sent on the idle event streams, so the subscribers can detect a broken
+	// stream. The default is 15 seconds.
+	HeartbeatInterval time.Duration
+}
+
+// Relay serves the configuration of an upstream client over HTTP.
+type Relay struct {
+	client            *configcat.Client
+	sdkKey            string
+	watchInterval     time.Duration
+	heartbeatInterval time.Duration
+	json              string
+	eTag              string
+	subscribers       map[chan string]struct{}
+	stop              chan struct{}
+	stopOnce          sync.Once
+	sync.RWMutex
+}
+
+// New creates a Relay serving the configuration of the given client and starts watching it for changes.
+// The Relay doesn't close the client.
+func New(client *configcat.Client, config Config) *Relay {
+	if config.WatchInterval <= 0 {
+		config.WatchInterval = time.Second
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = 15 * time.Second
+	}
+
+	relay := &Relay{client: client,
+		sdkKey:            config.SdkKey,
+		watchInterval:     config.WatchInterval,
+		heartbeatInterval: config.HeartbeatInterval,
+		subscribers:       make(map[chan string]struct{}),
+		stop:              make(chan struct{})}
+	relay.update()
+	go relay.watch()
+	return relay
+}
+
+// Close stops watching the upstream client and ends the open event streams.
+func (relay *Relay) Close() {
+	relay.stopOnce.Do(func() {
+		close(relay.stop)
+	})
+}
+
+// ServeHTTP serves the config JSON at the /configuration-files/<sdk key>/config_v4.json path
+// and the stream of configuration changes at the /sse path.
+func (relay *Relay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path == "/sse" {
+		relay.serveEvents(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/configuration-files/") || !strings.HasSuffix(r.URL.Path, "/config_v4.json") {
+		http.NotFound(w, r)
+		return
+	}
+
+	sdkKey := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/configuration-files/"), "/config_v4.json")
+	if len(relay.sdkKey) > 0 && sdkKey != relay.sdkKey {
+		http.NotFound(w, r)
+		return
+	}
+
+	relay.serveConfig(w, r)
+}
+
+func (relay *Relay) serveConfig(w http.ResponseWriter, r *http.Request) {
+	relay.RLock()
+	json, eTag := relay.json, relay.eTag
+	relay.RUnlock()
+
+	if len(json) == 0 {
+		http.Error(w, "config is not available yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("ETag", eTag)
+	if r.Header.Get("If-None-Match") == eTag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(json))
+}
+
+// serveEvents streams the configuration, then its changes. The ID of an event is the ETag of its configuration,
+// so a subscriber reconnecting with the Last-Event-ID of the current configuration only receives the later changes.
+func (relay *Relay) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events := make(chan string, 1)
+	relay.Lock()
+	relay.subscribers[events] = struct{}{}
+	json := relay.json
+	if len(json) > 0 && r.Header.Get("Last-Event-ID") == relay.eTag {
+		json = ""
+	}
+	relay.Unlock()
+
+	defer func() {
+		relay.Lock()
+		delete(relay.subscribers, events)
+		relay.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(relay.heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		if len(json) > 0 {
+			fmt.Fprintf(w, "id: %s\ndata: %s\n\n", eTagOf(json), strings.Replace(json, "\n", "\ndata: ", -1))
+		}
+		flusher.Flush()
+
+		select {
+		case json = <-events:
+		case <-heartbeat.C:
+			json = ""
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return
+		case <-relay.stop:
+			return
+		}
+	}
+}
+
+// eTagOf returns the ETag of a configuration, which is the ID of its event as well.
+func eTagOf(json string) string {
+	return fmt.Sprintf("\"%x\"", sha1.Sum([]byte(json)))
+}
+
+func (relay *Relay) watch() {
+	ticker := time.NewTicker(relay.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-relay.stop:
+			return
+		case <-ticker.C:
+			relay.update()
+		}
+	}
+}
+
+// update reads the configuration of the upstream client and notifies the subscribers when it changed.
+func (relay *Relay) update() {
+	json := relay.client.Snapshot(nil).ConfigJSON()
+
+	relay.Lock()
+	defer relay.Unlock()
+	if len(json) == 0 || json == relay.json {
+		return
+	}
+
+	relay.json = json
+	relay.eTag = eTagOf(json)
+	for subscriber := range relay.subscribers {
+		// a slow subscriber only needs the latest configuration
+		select {
+		case <-subscriber:
+		default:
+		}
+		subscriber <- json
+	}
+}
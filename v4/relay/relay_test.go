@@ -0,0 +1,189 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/configcat/go-sdk/v4"
+)
+
+type upstreamProvider struct {
+	body string
+	sync.Mutex
+}
+
+func (provider *upstreamProvider) GetConfig(ctx context.Context, eTag string) (configcat.ConfigResponse, error) {
+	provider.Lock()
+	defer provider.Unlock()
+	return configcat.ConfigResponse{Body: provider.body}, nil
+}
+
+func (provider *upstreamProvider) set(body string) {
+	provider.Lock()
+	defer provider.Unlock()
+	provider.body = body
+}
+
+func newUpstream(provider *upstreamProvider) *configcat.Client {
+	return configcat.NewCustomClient("upstream", configcat.ClientConfig{
+		Mode:           configcat.AutoPoll(time.Millisecond * 20),
+		ConfigProvider: provider,
+	})
+}
+
+func TestRelay_ServesConfig(t *testing.T) {
+	provider := &upstreamProvider{body: `{ "key": { "v": "relayed" } }`}
+	upstream := newUpstream(provider)
+	defer upstream.Close()
+	upstream.GetValue("key", "")
+
+	relay := New(upstream, Config{SdkKey: "downstream", WatchInterval: time.Millisecond * 20})
+	defer relay.Close()
+	server := httptest.NewServer(relay)
+	defer server.Close()
+
+	client := configcat.NewCustomClient("downstream", configcat.ClientConfig{Mode: configcat.ManualPoll(), BaseUrl: server.URL})
+	defer client.Close()
+	if err := client.RefreshWithContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if value := client.GetValue("key", ""); value != "relayed" {
+		t.Errorf("Expecting relayed, got %v", value)
+	}
+
+	if err := client.RefreshWithContext(context.Background()); err != nil || client.Status().LastFetchStatus != "not modified" {
+		t.Errorf("Expecting not modified response, got %v", err)
+	}
+
+	other := configcat.NewCustomClient("other", configcat.ClientConfig{Mode: configcat.ManualPoll(), BaseUrl: server.URL})
+	defer other.Close()
+	if err := other.RefreshWithContext(context.Background()); err == nil {
+		t.Error("Expecting error for an unknown SDK key")
+	}
+}
+
+func TestRelay_StreamsChanges(t *testing.T) {
+	provider := &upstreamProvider{body: `{ "key": { "v": "first" } }`}
+	upstream := newUpstream(provider)
+	defer upstream.Close()
+	upstream.GetValue("key", "")
+
+	relay := New(upstream, Config{WatchInterval: time.Millisecond * 20})
+	defer relay.Close()
+	server := httptest.NewServer(relay)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	events := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				events <- strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	expectEvent := func(expected string) {
+		select {
+		case event := <-events:
+			if !strings.Contains(event, expected) {
+				t.Errorf("Expecting %s in event %s", expected, event)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("Expecting event with %s", expected)
+		}
+	}
+
+	expectEvent("first")
+	provider.set(`{ "key": { "v": "second" } }`)
+	expectEvent("second")
+}
+
+func TestRelay_StreamResumesFromLastEventId(t *testing.T) {
+	provider := &upstreamProvider{body: `{ "key": { "v": "first" } }`}
+	upstream := newUpstream(provider)
+	defer upstream.Close()
+	upstream.GetValue("key", "")
+
+	relay := New(upstream, Config{WatchInterval: time.Millisecond * 20, HeartbeatInterval: time.Millisecond * 20})
+	defer relay.Close()
+	server := httptest.NewServer(relay)
+	defer server.Close()
+
+	readLines := func(lastEventId string) []string {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		request, _ := http.NewRequest("GET", server.URL+"/sse", nil)
+		request = request.WithContext(ctx)
+		if len(lastEventId) > 0 {
+			request.Header.Set("Last-Event-ID", lastEventId)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+
+		var lines []string
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		return lines
+	}
+
+	lines := readLines("")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "id: \"") || !strings.Contains(lines[1], "first") {
+		t.Fatalf("Expecting the config event with its ID, got %q", lines)
+	}
+	resumed := readLines(strings.TrimPrefix(lines[0], "id: "))
+	if len(resumed) == 0 || strings.Contains(strings.Join(resumed, "\n"), "data:") {
+		t.Fatalf("Expecting the heartbeats only after resuming from the current config, got %q", resumed)
+	}
+	if resumed[0] != ": heartbeat" {
+		t.Errorf("Expecting heartbeats, got %q", resumed)
+	}
+}
+
+func TestRelay_StreamingClient(t *testing.T) {
+	provider := &upstreamProvider{body: `{ "key": { "v": "first" } }`}
+	upstream := newUpstream(provider)
+	defer upstream.Close()
+	upstream.GetValue("key", "")
+
+	relay := New(upstream, Config{WatchInterval: time.Millisecond * 20})
+	defer relay.Close()
+	server := httptest.NewServer(relay)
+	defer server.Close()
+
+	client := configcat.NewCustomClient("downstream", configcat.ClientConfig{
+		Mode:    configcat.Streaming(configcat.StreamingConfig{}),
+		BaseUrl: server.URL,
+	})
+	defer client.Close()
+	if value := client.GetValue("key", ""); value != "first" {
+		t.Fatalf("Expecting first, got %v", value)
+	}
+
+	provider.set(`{ "key": { "v": "second" } }`)
+	deadline := time.Now().Add(time.Second * 5)
+	for client.GetValue("key", "") != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expecting the change to be streamed to the client")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
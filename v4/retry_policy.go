@@ -0,0 +1,204 @@
+package configcat
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy decides whether and when a failed config fetch is retried.
+type RetryPolicy interface {
+	// NextDelay returns the wait before the next retry of a fetch which failed attempt times in a row
+	// (starting from 1) with the given error, or false if the fetch shouldn't be retried.
+	NextDelay(attempt int, err error) (time.Duration, bool)
+}
+
+// ConstantRetry returns a RetryPolicy retrying the fetches failed with a timeout or a 502, 503 or 504 response
+// at most maxRetries times, waiting delay before each retry.
+func ConstantRetry(delay time.Duration, maxRetries int) RetryPolicy {
+	return constantRetry{delay: delay, maxRetries: maxRetries}
+}
+
+// ExponentialRetry returns a RetryPolicy retrying the fetches failed with a timeout or a 502, 503 or 504 response
+// at most maxRetries times. The first retry waits initial, doubled for each further retry up to max.
+// If max is 0 then the wait isn't limited.
+func ExponentialRetry(initial time.Duration, max time.Duration, maxRetries int) RetryPolicy {
+	return exponentialRetry{initial: initial, max: max, maxRetries: maxRetries}
+}
+
+// DecorrelatedJitterRetry returns a RetryPolicy retrying the fetches failed with a timeout or a 502, 503 or 504
+// response at most maxRetries times. Each retry waits a random duration between base and three times the
+// previous wait, limited to max, so the retries of many clients spread out.
+func DecorrelatedJitterRetry(base time.Duration, max time.Duration, maxRetries int) RetryPolicy {
+	return &decorrelatedJitterRetry{
+		base:       base,
+		max:        max,
+		maxRetries: maxRetries,
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+type constantRetry struct {
+	delay      time.Duration
+	maxRetries int
+}
+
+func (retry constantRetry) NextDelay(attempt int, err error) (time.Duration, bool) {
+	if attempt > retry.maxRetries || !isTransientFetchError(err) {
+		return 0, false
+	}
+	return retry.delay, true
+}
+
+type exponentialRetry struct {
+	initial    time.Duration
+	max        time.Duration
+	maxRetries int
+}
+
+func (retry exponentialRetry) NextDelay(attempt int, err error) (time.Duration, bool) {
+	if attempt > retry.maxRetries || !isTransientFetchError(err) {
+		return 0, false
+	}
+	delay := retry.initial
+	for i := 1; i < attempt && (retry.max <= 0 || delay < retry.max); i++ {
+		delay *= 2
+	}
+	if retry.max > 0 && delay > retry.max {
+		delay = retry.max
+	}
+	return delay, true
+}
+
+// sequencedRetryPolicy is implemented by the policies keeping state between the retries of a fetch, sequence
+// returns the policy of a new retry sequence so the concurrent sequences don't share the state.
+type sequencedRetryPolicy interface {
+	sequence() RetryPolicy
+}
+
+type decorrelatedJitterRetry struct {
+	base       time.Duration
+	max        time.Duration
+	maxRetries int
+	// the wait of the previous retry when the policy is called directly instead of through a sequence
+	previous time.Duration
+	random   *rand.Rand
+	sync.Mutex
+}
+
+func (retry *decorrelatedJitterRetry) NextDelay(attempt int, err error) (time.Duration, bool) {
+	return retry.next(&retry.previous, attempt, err)
+}
+
+func (retry *decorrelatedJitterRetry) sequence() RetryPolicy {
+	return &jitterSequence{retry: retry}
+}
+
+// next returns the wait after the previous one and updates it.
+func (retry *decorrelatedJitterRetry) next(previous *time.Duration, attempt int, err error) (time.Duration, bool) {
+	if attempt > retry.maxRetries || !isTransientFetchError(err) {
+		return 0, false
+	}
+
+	retry.Lock()
+	defer retry.Unlock()
+	if attempt == 1 || *previous < retry.base {
+		*previous = retry.base
+	}
+	delay := retry.base
+	if spread := *previous*3 - retry.base; spread > 0 {
+		delay += time.Duration(retry.random.Int63n(int64(spread)))
+	}
+	if retry.max > 0 && delay > retry.max {
+		delay = retry.max
+	}
+	*previous = delay
+	return delay, true
+}
+
+// jitterSequence is the decorrelatedJitterRetry of a retry sequence, with its own previous wait.
+type jitterSequence struct {
+	retry    *decorrelatedJitterRetry
+	previous time.Duration
+}
+
+func (sequence *jitterSequence) NextDelay(attempt int, err error) (time.Duration, bool) {
+	return sequence.retry.next(&sequence.previous, attempt, err)
+}
+
+// retryingProvider is a configProvider retrying the failed fetches of the wrapped provider by a RetryPolicy.
+type retryingProvider struct {
+	configProvider
+	policy RetryPolicy
+	clock  Clock
+	logger Logger
+	// if it's true then the retries run on the calling goroutine
+	inline bool
+	// the maximum duration of an inline retry sequence, the retries not fitting into it are given up
+	deadline time.Duration
+	// closed when the client is closed, giving up the retries
+	stop     chan struct{}
+	stopOnce sync.Once
+	// the number of the running retry goroutines
+	running int32
+}
+
+func newRetryingProvider(provider configProvider, policy RetryPolicy, clock Clock, logger Logger) *retryingProvider {
+	return &retryingProvider{configProvider: provider, policy: policy, clock: clock, logger: logger, stop: make(chan struct{})}
+}
+
+// getConfigurationAsync fetches through the wrapped provider and retries the failures while the policy allows.
+func (provider *retryingProvider) getConfigurationAsync() *asyncResult {
+	result := newAsyncResult()
+	retry := func() {
+		policy := provider.policy
+		if sequenced, ok := policy.(sequencedRetryPolicy); ok {
+			policy = sequenced.sequence()
+		}
+		start := provider.clock.Now()
+		for attempt := 1; ; attempt++ {
+			response, err := provider.configProvider.getConfigurationAsync().getWithError()
+			if err == nil {
+				result.complete(response)
+				return
+			}
+
+			delay, retry := policy.NextDelay(attempt, err)
+			if retry && provider.inline && provider.deadline > 0 && provider.clock.Now().Sub(start)+delay > provider.deadline {
+				retry = false
+			}
+			if !retry {
+				result.completeWithError(err)
+				return
+			}
+			provider.logger.Debugf("Config fetch failed: %s. Retrying in %v.", err.Error(), delay)
+			timer := provider.clock.NewTimer(delay)
+			select {
+			case <-timer.C():
+			case <-provider.stop:
+				timer.Stop()
+				result.completeWithError(err)
+				return
+			}
+		}
+	}
+
+	if provider.inline {
+		retry()
+	} else {
+		atomic.AddInt32(&provider.running, 1)
+		go func() {
+			defer atomic.AddInt32(&provider.running, -1)
+			retry()
+		}()
+	}
+	return result
+}
+
+// close gives up the retries in progress, it can be called repeatedly.
+func (provider *retryingProvider) close() {
+	provider.stopOnce.Do(func() {
+		close(provider.stop)
+	})
+}
@@ -0,0 +1,141 @@
+package configcat
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	transient := &StatusError{StatusCode: 503}
+
+	constant := ConstantRetry(time.Second, 2)
+	if delay, ok := constant.NextDelay(2, transient); delay != time.Second || !ok {
+		t.Errorf("Unexpected constant retry: %v, %v", delay, ok)
+	}
+	if _, ok := constant.NextDelay(3, transient); ok {
+		t.Error("Expecting no retry after the budget")
+	}
+	if _, ok := constant.NextDelay(1, errors.New("permanent")); ok {
+		t.Error("Expecting no retry of a permanent error")
+	}
+
+	exponential := ExponentialRetry(time.Second, time.Second*5, 10)
+	for attempt, expected := range []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5} {
+		if delay, ok := exponential.NextDelay(attempt+1, transient); delay != expected || !ok {
+			t.Errorf("Expecting %v for attempt %d, got %v, %v", expected, attempt+1, delay, ok)
+		}
+	}
+
+	jitter := DecorrelatedJitterRetry(time.Second, time.Second*10, 10)
+	previous := time.Second
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay, ok := jitter.NextDelay(attempt, transient)
+		if !ok || delay < time.Second || delay > time.Second*10 || delay > previous*3 {
+			t.Fatalf("Unexpected jittered delay %v after %v", delay, previous)
+		}
+		previous = delay
+	}
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	fetcher := &flakyConfigProvider{failures: 2, err: &StatusError{StatusCode: 502}}
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:        ManualPoll(),
+		RetryPolicy: ConstantRetry(time.Millisecond, 3),
+	}, fetcher)
+	defer client.Close()
+
+	if err := client.RefreshWithContext(context.Background()); err != nil {
+		t.Fatalf("Expecting the retried fetch to succeed, got %v", err)
+	}
+	if attempts := atomic.LoadInt32(&fetcher.attempts); attempts != 3 {
+		t.Errorf("Expecting 3 attempts, got %d", attempts)
+	}
+	if client.GetValue("missing", "default") != "default" || client.Status().FetchErrorCount != 0 {
+		t.Errorf("Expecting a single successful fetch, got %+v", client.Status())
+	}
+}
+
+func TestClient_RetryPolicy_PollRetriesIgnored(t *testing.T) {
+	fetcher := &flakyConfigProvider{failures: 100, err: &StatusError{StatusCode: 503}}
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:             AutoPoll(time.Hour),
+		RetryPolicy:      ConstantRetry(time.Millisecond, 2),
+		PollRetries:      2,
+		PollRetryBackoff: time.Millisecond,
+	}, fetcher)
+	defer client.Close()
+
+	deadline := time.Now().Add(time.Second * 5)
+	for atomic.LoadInt32(&fetcher.attempts) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if attempts := atomic.LoadInt32(&fetcher.attempts); attempts != 3 {
+		t.Errorf("Expecting the attempts of the RetryPolicy only, got %d", attempts)
+	}
+}
+
+func TestClient_RetryPolicy_Close(t *testing.T) {
+	fetcher := &flakyConfigProvider{failures: 100, err: &StatusError{StatusCode: 503}}
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:        ManualPoll(),
+		RetryPolicy: ConstantRetry(time.Hour, 10),
+	}, fetcher)
+
+	done := make(chan error)
+	go func() {
+		done <- client.RefreshWithContext(context.Background())
+	}()
+	for atomic.LoadInt32(&fetcher.attempts) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	client.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expecting the failure of the given up retries")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expecting the retries to be given up by Close")
+	}
+	if attempts := atomic.LoadInt32(&fetcher.attempts); attempts != 1 {
+		t.Errorf("Expecting no fetch after Close, got %d attempts", attempts)
+	}
+}
+
+func TestClient_RetryPolicy_InlineDeadline(t *testing.T) {
+	fetcher := &flakyConfigProvider{failures: 100, err: &StatusError{StatusCode: 503}}
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:                   ManualPoll(),
+		RetryPolicy:            ConstantRetry(10*time.Millisecond, 100),
+		HttpTimeout:            50 * time.Millisecond,
+		NoBackgroundGoroutines: true,
+	}, fetcher)
+	defer client.Close()
+
+	if err := client.RefreshWithContext(context.Background()); err == nil {
+		t.Error("Expecting the failure of the given up retries")
+	}
+	if attempts := atomic.LoadInt32(&fetcher.attempts); attempts < 2 || attempts > 6 {
+		t.Errorf("Expecting the retries fitting into the HttpTimeout, got %d attempts", attempts)
+	}
+}
+
+func TestDecorrelatedJitterRetry_Sequences(t *testing.T) {
+	transient := &StatusError{StatusCode: 503}
+	jitter := DecorrelatedJitterRetry(time.Second, time.Hour, 10).(sequencedRetryPolicy)
+	first, second := jitter.sequence(), jitter.sequence()
+
+	previous, _ := second.NextDelay(1, transient)
+	for attempt := 1; attempt <= 10; attempt++ {
+		_, _ = first.NextDelay(attempt, transient)
+	}
+	if delay, _ := second.NextDelay(2, transient); delay > previous*3 {
+		t.Errorf("Expecting the delay to follow the own previous delay %v, got %v", previous, delay)
+	}
+}
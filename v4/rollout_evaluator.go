@@ -2,281 +2,436 @@ package configcat
 
 import (
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blang/semver"
 )
 
 type rolloutEvaluator struct {
-	logger          Logger
-	comparatorTexts []string
+	logger            Logger
+	attributeResolver AttributeResolver
+	bucketer          Bucketer
+	canonicalizer     canonicalizer
+	// if it's true then the targeted settings can't be evaluated without a user
+	strictTargeting bool
+	// if it's true then the duration of the rule walk and the hashing is measured
+	timing bool
+	// the key/attribute pairs a missing attribute warning was logged for
+	warnedMissing map[string]bool
+	warnedMutex   sync.Mutex
+}
+
+// maxMissingAttributeWarnings limits the number of distinct key/attribute pairs a warning is logged for.
+const maxMissingAttributeWarnings = 1000
+
+// comparatorTexts contains the names of the known comparators.
+var comparatorTexts = map[int]string{
+	0:  "IS ONE OF",
+	1:  "IS NOT ONE OF",
+	2:  "CONTAINS",
+	3:  "DOES NOT CONTAIN",
+	4:  "IS ONE OF (SemVer)",
+	5:  "IS NOT ONE OF (SemVer)",
+	6:  "< (SemVer)",
+	7:  "<= (SemVer)",
+	8:  "> (SemVer)",
+	9:  ">= (SemVer)",
+	10: "= (Number)",
+	11: "<> (Number)",
+	12: "< (Number)",
+	13: "<= (Number)",
+	14: "> (Number)",
+	15: ">= (Number)",
+	16: "IS ONE OF (Sensitive)",
+	17: "IS NOT ONE OF (Sensitive)",
+	18: "BEFORE (UTC DateTime)",
+	19: "AFTER (UTC DateTime)",
+	20: "EQUALS (hashed)",
+	21: "NOT EQUALS (hashed)",
+	22: "STARTS WITH ANY OF (hashed)",
+	23: "NOT STARTS WITH ANY OF (hashed)",
+	24: "ENDS WITH ANY OF (hashed)",
+	25: "NOT ENDS WITH ANY OF (hashed)",
+	26: "ARRAY CONTAINS ANY OF (hashed)",
+	27: "ARRAY NOT CONTAINS ANY OF (hashed)",
+	28: "EQUALS",
+	29: "NOT EQUALS",
+	30: "STARTS WITH ANY OF",
+	31: "NOT STARTS WITH ANY OF",
+	32: "ENDS WITH ANY OF",
+	33: "NOT ENDS WITH ANY OF",
+	34: "ARRAY CONTAINS ANY OF",
+	35: "ARRAY NOT CONTAINS ANY OF",
 }
 
 func newRolloutEvaluator(logger Logger) *rolloutEvaluator {
-	return &rolloutEvaluator{logger: logger,
-		comparatorTexts: []string{
-			"IS ONE OF",
-			"IS NOT ONE OF",
-			"CONTAINS",
-			"DOES NOT CONTAIN",
-			"IS ONE OF (SemVer)",
-			"IS NOT ONE OF (SemVer)",
-			"< (SemVer)",
-			"<= (SemVer)",
-			"> (SemVer)",
-			">= (SemVer)",
-			"= (Number)",
-			"<> (Number)",
-			"< (Number)",
-			"<= (Number)",
-			"> (Number)",
-			">= (Number)",
-			"IS ONE OF (Sensitive)",
-			"IS NOT ONE OF (Sensitive)",
-		}}
+	return &rolloutEvaluator{logger: logger, warnedMissing: make(map[string]bool)}
 }
 
-func (evaluator *rolloutEvaluator) evaluate(json interface{}, key string, user *User) interface{} {
+// evaluation is the result of a setting evaluation.
+type evaluation struct {
+	value       interface{}
+	variationId string
+	// the user attributes referenced by the targeting rules but missing from the user
+	missingAttributes []string
+	// true if the value was returned by a targeting rule
+	ruleMatched bool
+	// the duration of the phases of the evaluation, measured when the timing is enabled
+	timing EvaluationTiming
+}
 
-	node, ok := json.(map[string]interface{})
-	if !ok {
-		return nil
+// evaluate returns the value of the setting for the user, together with the variation ID of the value.
+func (evaluator *rolloutEvaluator) evaluate(setting *compiledSetting, key string, user *User) evaluation {
+	if evaluator.timing {
+		return evaluator.timedEvaluate(setting, key, user)
 	}
+	return evaluator.walk(setting, key, user, nil)
+}
 
-	evaluator.logger.Infof("Evaluating GetValue(%s).", key)
-
-	rolloutRules, rolloutOk := node["r"].([]interface{})
-	percentageRules, percentageOk := node["p"].([]interface{})
+// walk evaluates the targeting rules and the percentage options of the setting, adding the duration of the
+// hashing to hashing unless it's nil.
+func (evaluator *rolloutEvaluator) walk(setting *compiledSetting, key string, user *User, hashing *time.Duration) evaluation {
+	logInfo := evaluator.infoEnabled()
+	if logInfo {
+		evaluator.log(LogLevelInfo, key, "Evaluating GetValue(%s).", key)
+	}
 
 	if user == nil {
-		if (rolloutOk && len(rolloutRules) > 0) || (percentageOk && len(percentageRules) > 0) {
-			evaluator.logger.Warnln("Evaluating GetValue(" + key + "). UserObject missing! You should pass a " +
-				"UserObject to GetValueForUser() in order to make targeting work properly. " +
-				"Read more: https://configcat.com/docs/advanced/user-object.")
+		if setting.targeted && isLogLevelEnabled(evaluator.logger, LogLevelWarn) {
+			evaluator.log(LogLevelWarn, key, "Evaluating GetValue(%s). UserObject missing! You should pass a "+
+				"UserObject to GetValueForUser() in order to make targeting work properly. "+
+				"Read more: https://configcat.com/docs/advanced/user-object.", key)
 		}
 
-		result := node["v"]
-		evaluator.logger.Infof("Returning %v.", result)
-		return result
+		if logInfo {
+			evaluator.log(LogLevelInfo, key, "Returning %v.", setting.value)
+		}
+		return evaluation{value: setting.value, variationId: setting.variationId}
 	}
 
-	evaluator.logger.Infof("User object: %v", user)
+	if logInfo {
+		evaluator.log(LogLevelInfo, key, "User object: %v", user)
+	}
 
-	if rolloutOk {
-		for _, r := range rolloutRules {
-			rule, ok := r.(map[string]interface{})
-			if !ok {
-				continue
+	var missing []string
+	for _, rule := range setting.rules {
+		userValue := evaluator.attribute(user, rule)
+		if evaluator.canonicalizer.enabled() {
+			rule = evaluator.canonicalizer.rule(rule)
+			userValue = evaluator.canonicalizer.value(userValue, rule.comparator)
+		}
+		if !rule.hasComparator || len(userValue) == 0 {
+			if rule.hasComparator && len(rule.attribute) > 0 {
+				missing = evaluator.missingAttribute(missing, key, rule.attribute)
 			}
+			evaluator.logNoMatch(key, rule, userValue)
+			continue
+		}
 
-			comparisonAttribute, ok := rule["a"].(string)
-			comparisonValue, ok := rule["c"].(string)
-			comparator, ok := rule["t"].(float64)
-			userValue := user.GetAttribute(comparisonAttribute)
-			value := rule["v"]
+		matched, err := evaluator.timedMatches(rule, userValue, setting.salt, hashing)
+		if err != nil {
+			evaluator.logFormatError(key, rule, userValue, err.Error())
+			continue
+		}
 
-			if !ok || len(userValue) == 0 {
-				evaluator.logNoMatch(comparisonAttribute, userValue, comparator, comparisonValue)
-				continue
-			}
+		if matched {
+			evaluator.logMatch(key, rule, userValue)
+			return evaluation{value: rule.value, variationId: rule.variationId, missingAttributes: missing, ruleMatched: true}
+		}
 
-			switch comparator {
-			//IS ONE OF
-			case 0:
-				separated := strings.Split(comparisonValue, ",")
-				for _, item := range separated {
-					if strings.Contains(strings.TrimSpace(item), userValue) {
-						evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-						return value
-					}
-				}
-			//IS NOT ONE OF
-			case 1:
-				separated := strings.Split(comparisonValue, ",")
-				found := false
-				for _, item := range separated {
-					if strings.Contains(strings.TrimSpace(item), userValue) {
-						found = true
-					}
-				}
+		evaluator.logNoMatch(key, rule, userValue)
+	}
 
-				if !found {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
-			//CONTAINS
-			case 2:
-				if strings.Contains(userValue, comparisonValue) {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
-			//DOES NOT CONTAIN
-			case 3:
-				if !strings.Contains(userValue, comparisonValue) {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
-			//IS ONE OF, IS NOT ONE OF (SemVer)
-			case 4, 5:
-				separated := strings.Split(comparisonValue, ",")
-				userVersion, err := semver.Make(userValue)
-				if err != nil {
-					evaluator.logFormatError(comparisonAttribute, userValue, comparator, comparisonValue, err.Error())
-					continue
-				}
-				matched := false
-				shouldContinue := false
-				for _, item := range separated {
-					cmpItem := strings.TrimSpace(item)
-					if len(cmpItem) == 0 {
-						continue
-					}
-
-					semVer, err := semver.Make(cmpItem)
-					if err != nil {
-						evaluator.logFormatError(comparisonAttribute, userValue, comparator, comparisonValue, err.Error())
-						shouldContinue = true
-						break
-					}
-
-					matched = userVersion.EQ(semVer) || matched
+	if len(setting.percentages) > 0 {
+		scaled := int64(evaluator.timedBucket(key, user.identifier, hashing))
+		for _, option := range setting.percentages {
+			if scaled < option.threshold {
+				if logInfo {
+					evaluator.log(LogLevelInfo, key, "Evaluating %% options. Returning %s", option.value)
 				}
+				return evaluation{value: option.value, variationId: option.variationId, missingAttributes: missing}
+			}
+		}
+	}
 
-				if shouldContinue {
-					continue
-				}
+	if logInfo {
+		evaluator.log(LogLevelInfo, key, "Returning %v.", setting.value)
+	}
+	return evaluation{value: setting.value, variationId: setting.variationId, missingAttributes: missing}
+}
 
-				if (matched && comparator == 4) || (!matched && comparator == 5) {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
-			//LESS THAN, LESS THAN OR EQUALS TO, GREATER THAN, GREATER THAN OR EQUALS TO (SemVer)
-			case 6, 7, 8, 9:
-				userVersion, err := semver.Make(userValue)
-				if err != nil {
-					evaluator.logFormatError(comparisonAttribute, userValue, comparator, comparisonValue, err.Error())
-					continue
-				}
+// matches returns true if the user value satisfies the comparator of the rule, the salt is appended to the
+// user values hashed by the hashed comparators. The error of parsing the attribute or the comparison value is
+// returned, in which case the rule is skipped.
+func (evaluator *rolloutEvaluator) matches(rule *compiledRule, userValue string, salt string) (bool, error) {
+	comparator := rule.comparator
+	switch comparator {
+	//IS ONE OF, IS NOT ONE OF
+	case 0, 1:
+		return containsSubstring(rule, userValue) == (comparator == 0), nil
+	//CONTAINS
+	case 2:
+		return strings.Contains(userValue, rule.comparisonValue), nil
+	//DOES NOT CONTAIN
+	case 3:
+		return !strings.Contains(userValue, rule.comparisonValue), nil
+	//IS ONE OF, IS NOT ONE OF (SemVer)
+	case 4, 5:
+		userVersion, err := semver.Make(userValue)
+		if err != nil {
+			return false, err
+		}
+		if rule.err != nil {
+			return false, rule.err
+		}
 
-				cmpVersion, err := semver.Make(strings.TrimSpace(comparisonValue))
-				if err != nil {
-					evaluator.logFormatError(comparisonAttribute, userValue, comparator, comparisonValue, err.Error())
-					continue
-				}
+		matched := false
+		for _, version := range rule.versions {
+			matched = userVersion.EQ(version) || matched
+		}
+		return matched == (comparator == 4), nil
+	//LESS THAN, LESS THAN OR EQUALS TO, GREATER THAN, GREATER THAN OR EQUALS TO (SemVer)
+	case 6, 7, 8, 9:
+		userVersion, err := semver.Make(userValue)
+		if err != nil {
+			return false, err
+		}
+		if rule.err != nil {
+			return false, rule.err
+		}
 
-				if (comparator == 6 && userVersion.LT(cmpVersion)) ||
-					(comparator == 7 && userVersion.LTE(cmpVersion)) ||
-					(comparator == 8 && userVersion.GT(cmpVersion)) ||
-					(comparator == 9 && userVersion.GTE(cmpVersion)) {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
-			//LESS THAN, LESS THAN OR EQUALS TO, GREATER THAN, GREATER THAN OR EQUALS TO (SemVer)
-			case 10, 11, 12, 13, 14, 15:
-				userDouble, err := strconv.ParseFloat(strings.Replace(userValue, ",", ".", -1), 64)
-				if err != nil {
-					evaluator.logFormatError(comparisonAttribute, userValue, comparator, comparisonValue, err.Error())
-					continue
-				}
+		return (comparator == 6 && userVersion.LT(rule.version)) ||
+			(comparator == 7 && userVersion.LTE(rule.version)) ||
+			(comparator == 8 && userVersion.GT(rule.version)) ||
+			(comparator == 9 && userVersion.GTE(rule.version)), nil
+	//EQUALS, NOT EQUALS, LESS THAN, LESS THAN OR EQUALS TO, GREATER THAN, GREATER THAN OR EQUALS TO (Number)
+	case 10, 11, 12, 13, 14, 15:
+		userDouble, err := strconv.ParseFloat(strings.Replace(userValue, ",", ".", -1), 64)
+		if err != nil {
+			return false, err
+		}
+		if rule.err != nil {
+			return false, rule.err
+		}
 
-				cmpDouble, err := strconv.ParseFloat(strings.Replace(comparisonValue, ",", ".", -1), 64)
-				if err != nil {
-					evaluator.logFormatError(comparisonAttribute, userValue, comparator, comparisonValue, err.Error())
-					continue
-				}
+		return (comparator == 10 && userDouble == rule.number) ||
+			(comparator == 11 && userDouble != rule.number) ||
+			(comparator == 12 && userDouble < rule.number) ||
+			(comparator == 13 && userDouble <= rule.number) ||
+			(comparator == 14 && userDouble > rule.number) ||
+			(comparator == 15 && userDouble >= rule.number), nil
+	//IS ONE OF, IS NOT ONE OF (Sensitive)
+	case 16, 17:
+		return containsHash(rule, hashValue(userValue)) == (comparator == 16), nil
+	//BEFORE, AFTER (UTC DateTime)
+	case 18, 19:
+		userSeconds, err := parseDateTime(userValue)
+		if err != nil {
+			return false, err
+		}
+		if rule.err != nil {
+			return false, rule.err
+		}
 
-				if (comparator == 10 && userDouble == cmpDouble) ||
-					(comparator == 11 && userDouble != cmpDouble) ||
-					(comparator == 12 && userDouble < cmpDouble) ||
-					(comparator == 13 && userDouble <= cmpDouble) ||
-					(comparator == 14 && userDouble > cmpDouble) ||
-					(comparator == 15 && userDouble >= cmpDouble) {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
-			//IS ONE OF (Sensitive)
-			case 16:
-				separated := strings.Split(comparisonValue, ",")
-				sha := sha1.New()
-				sha.Write([]byte(userValue))
-				hash := hex.EncodeToString(sha.Sum(nil))
-				for _, item := range separated {
-					if strings.Contains(strings.TrimSpace(item), hash) {
-						evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-						return value
-					}
-				}
-			//IS NOT ONE OF (Sensitive)
-			case 17:
-				separated := strings.Split(comparisonValue, ",")
-				found := false
-				sha := sha1.New()
-				sha.Write([]byte(userValue))
-				hash := hex.EncodeToString(sha.Sum(nil))
-				for _, item := range separated {
-					if strings.Contains(strings.TrimSpace(item), hash) {
-						found = true
-					}
-				}
+		return (comparator == 18 && userSeconds < rule.number) ||
+			(comparator == 19 && userSeconds > rule.number), nil
+	//EQUALS, NOT EQUALS (hashed and plain)
+	case 20, 21, 28, 29:
+		userItem := userValue
+		if comparator < 28 {
+			userItem = hashSalted(userValue, salt)
+		}
 
-				if !found {
-					evaluator.logMatch(comparisonAttribute, userValue, comparator, comparisonValue, value)
-					return value
-				}
+		return containsSorted(rule.sortedItems, userItem) == (comparator == 20 || comparator == 28), nil
+	//STARTS WITH ANY OF, NOT STARTS WITH ANY OF, ENDS WITH ANY OF, NOT ENDS WITH ANY OF (hashed)
+	case 22, 23, 24, 25:
+		found := false
+		for _, affix := range rule.affixes {
+			if affix.length > len(userValue) {
+				continue
+			}
+
+			part := userValue[:affix.length]
+			if comparator >= 24 {
+				part = userValue[len(userValue)-affix.length:]
 			}
 
-			evaluator.logNoMatch(comparisonAttribute, userValue, comparator, comparisonValue)
+			if hashSalted(part, salt) == affix.hash {
+				found = true
+				break
+			}
 		}
-	}
 
-	if percentageOk && len(percentageRules) > 0 {
-		hashCandidate := key + user.identifier
-		sha := sha1.New()
-		sha.Write([]byte(hashCandidate))
-		hash := hex.EncodeToString(sha.Sum(nil))[:7]
-		num, err := strconv.ParseInt(hash, 16, 64)
-		scaled := num % 100
-		if err == nil {
-			bucket := int64(0)
-			for _, r := range percentageRules {
-				rule, ok := r.(map[string]interface{})
-				if ok {
-					p, ok := rule["p"].(float64)
-					if ok {
-						percentage := int64(p)
-						bucket += percentage
-						if scaled < bucket {
-							result := rule["v"]
-							evaluator.logger.Infof("Evaluating %% options. Returning %s", result)
-							return result
-						}
-					}
-				}
+		return found == (comparator == 22 || comparator == 24), nil
+	//STARTS WITH ANY OF, NOT STARTS WITH ANY OF, ENDS WITH ANY OF, NOT ENDS WITH ANY OF
+	case 30, 31, 32, 33:
+		found := false
+		for _, item := range rule.items {
+			if (comparator <= 31 && strings.HasPrefix(userValue, item)) ||
+				(comparator >= 32 && strings.HasSuffix(userValue, item)) {
+				found = true
+				break
 			}
 		}
+
+		return found == (comparator == 30 || comparator == 32), nil
+	//ARRAY CONTAINS ANY OF, ARRAY NOT CONTAINS ANY OF (hashed and plain)
+	case 26, 27, 34, 35:
+		var userItems []string
+		var err error
+		if comparator < 34 {
+			userItems, err = parseArray(userValue)
+		} else {
+			userItems, err = parseList(userValue)
+		}
+		if err != nil {
+			return false, err
+		}
+
+		found := false
+		for _, userItem := range userItems {
+			if comparator < 34 {
+				userItem = hashSalted(userItem, salt)
+			}
+			if _, ok := rule.itemSet[userItem]; ok {
+				found = true
+				break
+			}
+		}
+
+		return found == (comparator == 26 || comparator == 34), nil
+	}
+
+	if match := lookupComparator(comparator); match != nil {
+		return match(userValue, rule.comparisonValue), nil
+	}
+	return false, nil
+}
+
+func (evaluator *rolloutEvaluator) logMatch(key string, rule *compiledRule, userValue string) {
+	if !evaluator.infoEnabled() {
+		return
+	}
+
+	evaluator.log(LogLevelInfo, key, "Evaluating rule: [%s:%s] [%s] [%s] => match, returning: %v",
+		rule.attribute, userValue, Comparator(rule.comparator), rule.comparisonValue, rule.value)
+}
+
+func (evaluator *rolloutEvaluator) logNoMatch(key string, rule *compiledRule, userValue string) {
+	if !evaluator.infoEnabled() {
+		return
+	}
+
+	evaluator.log(LogLevelInfo, key, "Evaluating rule: [%s:%s] [%s] [%s] => no match",
+		rule.attribute, userValue, Comparator(rule.comparator), rule.comparisonValue)
+}
+
+func (evaluator *rolloutEvaluator) logFormatError(key string, rule *compiledRule, userValue string, error string) {
+	if !evaluator.infoEnabled() {
+		return
+	}
+
+	evaluator.log(LogLevelInfo, key, "Evaluating rule: [%s:%s] [%s] [%s] => SKIP rule. Validation error: %s",
+		rule.attribute, userValue, Comparator(rule.comparator), rule.comparisonValue, error)
+}
+
+// log logs the message about the evaluation of the setting with the key.
+func (evaluator *rolloutEvaluator) log(level LogLevel, key string, format string, args ...interface{}) {
+	logEvent(evaluator.logger, level, LogEventEvaluation, logFields{key: key}, format, args...)
+}
+
+// infoEnabled returns true if the info messages describing the evaluation are logged.
+func (evaluator *rolloutEvaluator) infoEnabled() bool {
+	return isLogLevelEnabled(evaluator.logger, LogLevelInfo)
+}
+
+// parseDateTime converts a date time attribute, given as Unix seconds or in RFC 3339 format, into Unix seconds.
+func parseDateTime(value string) (float64, error) {
+	if seconds, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", -1), 64); err == nil {
+		return seconds, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+
+	return unixSeconds(t), nil
+}
+
+// unixSeconds converts the time into Unix seconds with millisecond precision.
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()/int64(time.Millisecond)) / 1000
+}
+
+// parseList converts a list attribute, given as a JSON array of strings or as comma separated values, into its items.
+func parseList(value string) ([]string, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") {
+		var items []string
+		if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+			return nil, err
+		}
+		return items, nil
 	}
 
-	result := node["v"]
-	evaluator.logger.Infof("Returning %v.", result)
-	return result
+	items := strings.Split(trimmed, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items, nil
 }
 
-func (evaluator *rolloutEvaluator) logMatch(comparisonAttribute string, userValue interface{},
-	comparator float64, comparisonValue string, value interface{}) {
-	evaluator.logger.Infof("Evaluating rule: [%s:%s] [%s] [%s] => match, returning: %v",
-		comparisonAttribute, userValue, evaluator.comparatorTexts[int(comparator)], comparisonValue, value)
+// parseArray converts a list attribute given as a JSON array of strings into its items, any other value is a
+// single item, commas included.
+func parseArray(value string) ([]string, error) {
+	if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "[") {
+		return parseList(trimmed)
+	}
+	return []string{value}, nil
 }
 
-func (evaluator *rolloutEvaluator) logNoMatch(comparisonAttribute string, userValue interface{},
-	comparator float64, comparisonValue string) {
-	evaluator.logger.Infof("Evaluating rule: [%s:%s] [%s] [%s] => no match",
-		comparisonAttribute, userValue, evaluator.comparatorTexts[int(comparator)], comparisonValue)
+// hashValue returns the hex encoded SHA-1 hash of the value, the format of the sensitive comparison values.
+func hashValue(value string) string {
+	sha := sha1.New()
+	sha.Write([]byte(value))
+	return hex.EncodeToString(sha.Sum(nil))
 }
 
-func (evaluator *rolloutEvaluator) logFormatError(comparisonAttribute string, userValue interface{},
-	comparator float64, comparisonValue string, error string) {
-	evaluator.logger.Infof("Evaluating rule: [%s:%s] [%s] [%s] => SKIP rule. Validation error: %s",
-		comparisonAttribute, userValue, evaluator.comparatorTexts[int(comparator)], comparisonValue, error)
+// hashSalted returns the hex encoded SHA-256 hash of the value followed by the salt, the format of the
+// hashed comparison values.
+func hashSalted(value string, salt string) string {
+	sum := sha256.Sum256([]byte(value + salt))
+	return hex.EncodeToString(sum[:])
+}
+
+// missingAttribute records the attribute missing from the user, and logs a warning the first time
+// the attribute is found missing for the setting.
+func (evaluator *rolloutEvaluator) missingAttribute(missing []string, key string, attribute string) []string {
+	for _, recorded := range missing {
+		if recorded == attribute {
+			return missing
+		}
+	}
+
+	pair := key + "\x00" + attribute
+	evaluator.warnedMutex.Lock()
+	warn := !evaluator.warnedMissing[pair] && len(evaluator.warnedMissing) < maxMissingAttributeWarnings
+	if warn {
+		evaluator.warnedMissing[pair] = true
+	}
+	evaluator.warnedMutex.Unlock()
+
+	if warn {
+		evaluator.log(LogLevelWarn, key, "Evaluating GetValue(%s). Targeting rule references a user attribute missing from the User. key=%s attribute=%s",
+			key, key, attribute)
+	}
+
+	return append(missing, attribute)
 }
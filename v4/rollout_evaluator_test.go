@@ -0,0 +1,167 @@
+package configcat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func evaluateRule(t *testing.T, comparator int, comparisonValue string, user *User) interface{} {
+	parser := newParser(DefaultLogger(LogLevelWarn))
+	json := fmt.Sprintf(`{ "p": { "s": "salt" }, "key": { "v": "default", "r": [ { "a": "attr", "t": %d, "c": %q, "v": "matched" } ] } }`, comparator, comparisonValue)
+	value, err := parser.ParseWithUser(json, "key", user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+func TestRolloutEvaluator_NumberComparators(t *testing.T) {
+	tests := []struct {
+		comparator      int
+		comparisonValue string
+		userValue       interface{}
+		expected        string
+	}{
+		{10, "5", 5, "matched"},
+		{10, "5", "5.0", "matched"},
+		{11, "5", 5, "default"},
+		{12, "5", 4.5, "matched"},
+		{13, "5", "5", "matched"},
+		{14, "5", int64(6), "matched"},
+		{15, "5,5", "5,5", "matched"},
+		{14, "5", "not a number", "default"},
+	}
+
+	for _, test := range tests {
+		user := NewUserWithCustomValues("id", "", "", map[string]interface{}{"attr": test.userValue})
+		if value := evaluateRule(t, test.comparator, test.comparisonValue, user); value != test.expected {
+			t.Errorf("Comparator %d, %s, %v: expecting %s, got %v", test.comparator, test.comparisonValue, test.userValue, test.expected, value)
+		}
+	}
+}
+
+func TestRolloutEvaluator_DateComparators(t *testing.T) {
+	signup := time.Date(2020, 6, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+	seconds := signup.Unix()
+	tests := []struct {
+		comparator      int
+		comparisonValue string
+		userValue       interface{}
+		expected        string
+	}{
+		{18, fmt.Sprint(seconds + 1), signup, "matched"},
+		{18, fmt.Sprint(seconds), signup, "default"},
+		{19, fmt.Sprint(seconds), signup, "matched"},
+		{19, fmt.Sprint(seconds), "2020-06-01T12:00:00.5Z", "matched"},
+		{18, fmt.Sprint(seconds), "2020-06-01T11:00:00+00:00", "matched"},
+		{19, fmt.Sprint(seconds), fmt.Sprintf("%d.5", seconds), "matched"},
+		{19, fmt.Sprint(seconds), "yesterday", "default"},
+	}
+
+	for _, test := range tests {
+		user := NewUserWithCustomValues("id", "", "", map[string]interface{}{"attr": test.userValue})
+		if value := evaluateRule(t, test.comparator, test.comparisonValue, user); value != test.expected {
+			t.Errorf("Comparator %d, %s, %v: expecting %s, got %v", test.comparator, test.comparisonValue, test.userValue, test.expected, value)
+		}
+	}
+}
+
+func TestRolloutEvaluator_ArrayComparators(t *testing.T) {
+	tests := []struct {
+		comparator      int
+		comparisonValue string
+		userValue       interface{}
+		expected        string
+	}{
+		{34, "admin, editor", []string{"viewer", "editor"}, "matched"},
+		{34, "admin, editor", []string{"viewer"}, "default"},
+		{34, "admin", `["admin","viewer"]`, "matched"},
+		{34, "admin", "viewer, admin", "matched"},
+		{34, "admin", "administrator", "default"},
+		{35, "admin", []string{"viewer"}, "matched"},
+		{35, "admin", []string{"admin"}, "default"},
+		{34, "admin", `["admin"`, "default"},
+	}
+
+	for _, test := range tests {
+		user := NewUserWithCustomValues("id", "", "", map[string]interface{}{"attr": test.userValue})
+		if value := evaluateRule(t, test.comparator, test.comparisonValue, user); value != test.expected {
+			t.Errorf("Comparator %d, %s, %v: expecting %s, got %v", test.comparator, test.comparisonValue, test.userValue, test.expected, value)
+		}
+	}
+}
+
+func TestRolloutEvaluator_TextComparators(t *testing.T) {
+	email := "jane@example.com"
+	// the salt of the config followed by the key of the setting
+	hash := func(value string) string { return hashSalted(value, "saltkey") }
+	tests := []struct {
+		comparator      int
+		comparisonValue string
+		expected        string
+	}{
+		{20, hash(email), "matched"},
+		{20, hashValue(email), "default"},
+		{20, hashSalted(email, "salt"), "default"},
+		{21, hash(email), "default"},
+		{22, "4_" + hash("jane") + ", 3_" + hash("bob"), "matched"},
+		{23, "4_" + hash("jane"), "default"},
+		{24, "12_" + hash("@example.com"), "matched"},
+		{25, "12_" + hash("@example.com"), "default"},
+		{24, "100_" + hash(email), "default"},
+		{26, hash("jane@example.com"), "matched"},
+		{27, hash("jane@example.com"), "default"},
+		{28, "bob@example.com, jane@example.com", "matched"},
+		{29, "jane@example.com", "default"},
+		{30, "bob, jane", "matched"},
+		{31, "bob", "matched"},
+		{32, "@example.com", "matched"},
+		{33, "@example.com", "default"},
+	}
+
+	for _, test := range tests {
+		user := NewUserWithCustomValues("id", "", "", map[string]interface{}{"attr": email})
+		if value := evaluateRule(t, test.comparator, test.comparisonValue, user); value != test.expected {
+			t.Errorf("Comparator %d, %s: expecting %s, got %v", test.comparator, test.comparisonValue, test.expected, value)
+		}
+	}
+}
+
+func TestRolloutEvaluator_HashedArrayComparators(t *testing.T) {
+	hash := func(value string) string { return hashSalted(value, "saltkey") }
+	tests := []struct {
+		comparator      int
+		comparisonValue string
+		userValue       interface{}
+		expected        string
+	}{
+		{26, hash("admin") + ", " + hash("editor"), []string{"viewer", "editor"}, "matched"},
+		{26, hash("admin"), `["viewer","admin"]`, "matched"},
+		{26, hash("admin"), "viewer, admin", "default"},
+		{26, hash("viewer, admin"), "viewer, admin", "matched"},
+		{27, hash("admin"), "viewer, admin", "matched"},
+		{27, hash("admin"), []string{"admin"}, "default"},
+	}
+
+	for _, test := range tests {
+		user := NewUserWithCustomValues("id", "", "", map[string]interface{}{"attr": test.userValue})
+		if value := evaluateRule(t, test.comparator, test.comparisonValue, user); value != test.expected {
+			t.Errorf("Comparator %d, %s, %v: expecting %s, got %v", test.comparator, test.comparisonValue, test.userValue, test.expected, value)
+		}
+	}
+}
+
+func TestConfigParser_Preferences(t *testing.T) {
+	parser := newParser(DefaultLogger(LogLevelWarn))
+	keys, err := parser.GetAllKeys(`{ "p": { "s": "salt" }, "key": { "v": "value" } }`)
+	if err != nil || len(keys) != 1 || keys[0] != "key" {
+		t.Errorf("Expecting only the setting key, got %v, %v", keys, err)
+	}
+
+	value, err := parser.Parse(`{ "p": { "v": "setting" } }`, "p")
+	if err != nil || value != "setting" {
+		t.Errorf("Expecting the setting named p, got %v, %v", value, err)
+	}
+}
@@ -0,0 +1,87 @@
+package configcat
+
+import (
+	"errors"
+)
+
+// PercentageOption is a percentage option of a setting with the range of the buckets it's served to.
+type PercentageOption struct {
+	// The value of the option.
+	Value interface{}
+	// The variation ID of the option.
+	VariationId string
+	// The percentage of the users the option is served to.
+	Percentage int
+	// The first bucket of the option.
+	From int
+	// The bucket after the last bucket of the option, the option is served to the buckets From <= bucket < To.
+	To int
+}
+
+// RolloutPosition describes the position of a user in the percentage rollout of a setting, e.g. to explain why
+// the user doesn't get the new value yet.
+type RolloutPosition struct {
+	// The key of the setting.
+	Key string
+	// The percentage bucket of the user for the setting (0-99), which doesn't change while the identifier of
+	// the user and the key of the setting are the same.
+	Bucket int
+	// The percentage options of the setting in their order, empty if the setting has none.
+	Options []PercentageOption
+	// The index of the option whose range contains the bucket, -1 if there is none.
+	Selected int
+	// True if a targeting rule matches the user. The targeting rules are evaluated before the percentage options,
+	// so the user gets the value of the rule instead of the selected option.
+	RuleMatched bool
+	// The value evaluated for the user.
+	Value interface{}
+}
+
+// GetRolloutPosition returns the percentage bucket of the user for the setting identified by the given key,
+// together with the bucket ranges of the percentage options of the setting and the evaluated value.
+func (client *Client) GetRolloutPosition(key string, user *User) (*RolloutPosition, error) {
+	if user == nil {
+		return nil, errors.New("the percentage options are evaluated only for users")
+	}
+
+	json, err := client.getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	config, err := client.parser.load(json)
+	if err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+	setting, ok := config.settings[key]
+	if !ok {
+		return nil, &ParseError{"Value not found for key " + key + "."}
+	}
+	result, err := client.variation(json, key, user)
+	if err != nil {
+		return nil, err
+	}
+
+	position := &RolloutPosition{
+		Key:         key,
+		Bucket:      client.parser.evaluator.bucket(key, user.identifier),
+		Options:     make([]PercentageOption, 0, len(setting.percentages)),
+		Selected:    -1,
+		RuleMatched: result.ruleMatched,
+		Value:       result.value,
+	}
+	from := int64(0)
+	for i, option := range setting.percentages {
+		position.Options = append(position.Options, PercentageOption{
+			Value:       option.value,
+			VariationId: option.variationId,
+			Percentage:  int(option.threshold - from),
+			From:        int(from),
+			To:          int(option.threshold),
+		})
+		if position.Selected < 0 && int64(position.Bucket) < option.threshold {
+			position.Selected = i
+		}
+		from = option.threshold
+	}
+	return position, nil
+}
@@ -0,0 +1,56 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestClient_GetRolloutPosition(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"rollout": { "v": "default", "t": 1,
+			"r": [ { "o": 0, "a": "Identifier", "t": 0, "c": "u2", "v": "targeted" } ],
+			"p": [ { "o": 0, "v": "new", "p": 25, "i": "p0" }, { "o": 1, "v": "none", "p": 0, "i": "p1" },
+				{ "o": 2, "v": "old", "p": 75, "i": "p2" } ] },
+		"flag": { "v": true, "t": 0 }
+	}`})
+	client.Refresh()
+
+	// the bucket of "a" is 24, "c" is 79 and "u2" is 6 for the rollout key
+	position, err := client.GetRolloutPosition("rollout", NewUser("c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position.Bucket != 79 || position.Selected != 2 || position.RuleMatched || position.Value != "old" {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+	expected := []PercentageOption{
+		{Value: "new", VariationId: "p0", Percentage: 25, From: 0, To: 25},
+		{Value: "none", VariationId: "p1", Percentage: 0, From: 25, To: 25},
+		{Value: "old", VariationId: "p2", Percentage: 75, From: 25, To: 100},
+	}
+	if len(position.Options) != len(expected) {
+		t.Fatalf("Expecting %v, got %v", expected, position.Options)
+	}
+	for i := range expected {
+		if position.Options[i] != expected[i] {
+			t.Errorf("Expecting %+v, got %+v", expected[i], position.Options[i])
+		}
+	}
+
+	if position, _ := client.GetRolloutPosition("rollout", NewUser("a")); position.Bucket != 24 || position.Selected != 0 || position.Value != "new" {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+	if position, _ := client.GetRolloutPosition("rollout", NewUser("u2")); position.Selected != 0 || !position.RuleMatched || position.Value != "targeted" {
+		t.Errorf("Expecting the targeting rule to win over the selected option, got %+v", position)
+	}
+	if position, _ := client.GetRolloutPosition("flag", NewUser("a")); len(position.Options) != 0 || position.Selected != -1 || position.Value != true {
+		t.Errorf("Expecting no options, got %+v", position)
+	}
+	if _, err := client.GetRolloutPosition("missing", NewUser("a")); err == nil {
+		t.Error("Expecting error for a missing key")
+	}
+	if _, err := client.GetRolloutPosition("rollout", nil); err == nil {
+		t.Error("Expecting error without user")
+	}
+}
@@ -0,0 +1,85 @@
+package configcat
+
+// ShadowConfig describes the shadow evaluation of the flags against a candidate configuration.
+// The candidate is evaluated after each evaluation of the client and the mismatching results
+// are reported, but the client always returns its own result. It's useful to validate a restructured
+// config (e.g. under a different SDK key or in a local file) against the production traffic.
+type ShadowConfig struct {
+	// The client serving the candidate configuration, e.g. created with a different SDK key or with LocalFilePoll.
+	// The shadow evaluation is disabled when it's nil. It's evaluated from its cached configuration, so the
+	// shadow evaluation never blocks, and it isn't closed together with the client.
+	Candidate *Client
+	// Called with each evaluation where the candidate result differs from the primary one.
+	OnMismatch func(mismatch ShadowMismatch)
+}
+
+// ShadowMismatch describes an evaluation where the candidate configuration gave a different result.
+type ShadowMismatch struct {
+	// The key of the evaluated setting.
+	Key string
+	// The user the setting was evaluated for.
+	User *User
+	// The value served by the client, the default value when the primary evaluation failed.
+	PrimaryValue interface{}
+	// The variation ID of the served value.
+	PrimaryVariationId string
+	// The reason of the failed primary evaluation.
+	PrimaryError error
+	// The value evaluated from the candidate configuration, nil when the candidate evaluation failed.
+	CandidateValue interface{}
+	// The variation ID of the candidate value.
+	CandidateVariationId string
+	// The reason of the failed candidate evaluation.
+	CandidateError error
+}
+
+// shadowEvaluator compares the evaluations of the client with the candidate configuration.
+type shadowEvaluator struct {
+	candidate  *Client
+	onMismatch func(mismatch ShadowMismatch)
+	logger     Logger
+}
+
+func newShadowEvaluator(config ShadowConfig, logger Logger) *shadowEvaluator {
+	if config.Candidate == nil || config.OnMismatch == nil {
+		return nil
+	}
+
+	return &shadowEvaluator{candidate: config.Candidate, onMismatch: config.OnMismatch, logger: logger}
+}
+
+// compare evaluates the setting from the candidate configuration and reports a mismatch with the primary details.
+func (shadow *shadowEvaluator) compare(primary EvaluationDetails) {
+	defer func() {
+		// the shadow evaluation must never affect the primary evaluation
+		if r := recover(); r != nil {
+			shadow.logger.Errorf("Shadow evaluation of %s panicked: %v", primary.Key, r)
+		}
+	}()
+
+	var candidateValue interface{}
+	candidateVariationId := ""
+	result, candidateErr := shadow.candidate.parser.parseVariation(shadow.candidate.store.get(), primary.Key, primary.User)
+	if candidateErr == nil {
+		candidateValue, candidateVariationId = result.value, result.variationId
+	}
+
+	if primary.Error != nil && candidateErr != nil {
+		return
+	}
+
+	if primary.Error == nil && candidateErr == nil && primary.Value == candidateValue {
+		return
+	}
+
+	shadow.onMismatch(ShadowMismatch{
+		Key:                  primary.Key,
+		User:                 primary.User,
+		PrimaryValue:         primary.Value,
+		PrimaryVariationId:   primary.VariationId,
+		PrimaryError:         primary.Error,
+		CandidateValue:       candidateValue,
+		CandidateVariationId: candidateVariationId,
+		CandidateError:       candidateErr,
+	})
+}
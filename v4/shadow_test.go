@@ -0,0 +1,49 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestClient_Shadow(t *testing.T) {
+	candidateFetcher := newFakeConfigProvider()
+	candidate := newInternal("candidateKey", ClientConfig{Mode: ManualPoll()}, candidateFetcher)
+	defer candidate.Close()
+	candidateFetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "same": { "v": "a" }, "different": { "v": "candidate" }, "added": { "v": true } }`})
+	candidate.Refresh()
+
+	var mismatches []ShadowMismatch
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode: ManualPoll(),
+		Shadow: ShadowConfig{
+			Candidate:  candidate,
+			OnMismatch: func(mismatch ShadowMismatch) { mismatches = append(mismatches, mismatch) },
+		},
+	}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "same": { "v": "a" }, "different": { "v": "primary" }, "removed": { "v": 1 } }`})
+	client.Refresh()
+
+	if value := client.GetValue("same", ""); value != "a" || len(mismatches) != 0 {
+		t.Errorf("Unexpected value %v or mismatches %+v", value, mismatches)
+	}
+
+	if value := client.GetValue("different", ""); value != "primary" || len(mismatches) != 1 ||
+		mismatches[0].PrimaryValue != "primary" || mismatches[0].CandidateValue != "candidate" {
+		t.Errorf("Unexpected value %v or mismatches %+v", value, mismatches)
+	}
+
+	if value := client.GetValue("removed", 0); value != 1.0 || len(mismatches) != 2 || mismatches[1].CandidateError == nil {
+		t.Errorf("Unexpected value %v or mismatches %+v", value, mismatches)
+	}
+
+	if value := client.GetValue("added", false); value != false || len(mismatches) != 3 ||
+		mismatches[2].PrimaryError == nil || mismatches[2].CandidateValue != true {
+		t.Errorf("Unexpected value %v or mismatches %+v", value, mismatches)
+	}
+
+	client.GetValue("missing", "")
+	if len(mismatches) != 3 {
+		t.Errorf("Expecting no mismatch when both evaluations fail, got %+v", mismatches)
+	}
+}
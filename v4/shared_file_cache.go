@@ -0,0 +1,142 @@
+package configcat
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// SharedFileCacheConfig describes the configuration of a file cache shared by multiple processes.
+type SharedFileCacheConfig struct {
+	// The maximum time a write waits for the lock held by an other process. The default is 5 seconds.
+	LockTimeout time.Duration
+	// The age after which a lock is considered abandoned by a crashed process and removed. The default is 30 seconds.
+	StaleLockAge time.Duration
+	// The source of the time of the lock timeout. If it's nil then the system clock is used. The age of the lock
+	// is measured with the wall time, as it's compared to the modification time of the lock file.
+	Clock Clock
+}
+
+// errLockTimeout is returned when the lock of a shared file cache couldn't be acquired in time.
+var errLockTimeout = errors.New("timed out waiting for the cache file lock")
+
+// sharedFileConfigCache is a file cache whose writes are serialized between processes with a lock file.
+type sharedFileConfigCache struct {
+	*fileConfigCache
+	lockPath     string
+	lockTimeout  time.Duration
+	staleLockAge time.Duration
+	clock        Clock
+}
+
+// NewSharedFileCache creates a ConfigCache which stores the configuration in the file at the given path and is safe
+// to use by multiple processes of the same host. The writes are serialized with a lock file next to the cache file
+// and the content is replaced atomically, so the processes always read a complete configuration.
+func NewSharedFileCache(path string, config SharedFileCacheConfig) ConfigCache {
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = time.Second * 5
+	}
+
+	if config.StaleLockAge <= 0 {
+		config.StaleLockAge = time.Second * 30
+	}
+
+	if config.Clock == nil {
+		config.Clock = systemClock{}
+	}
+
+	return &sharedFileConfigCache{fileConfigCache: &fileConfigCache{path: path},
+		lockPath:     path + ".lock",
+		lockTimeout:  config.LockTimeout,
+		staleLockAge: config.StaleLockAge,
+		clock:        config.Clock}
+}
+
+// Set writes the configuration into the file while holding the lock. The file is left untouched
+// when an other process already wrote the same configuration.
+func (cache *sharedFileConfigCache) Set(value string) error {
+	token, err := cache.lock()
+	if err != nil {
+		return err
+	}
+	defer cache.unlock(token)
+
+	if current, err := cache.fileConfigCache.Get(); err == nil && current == value {
+		return nil
+	}
+
+	return cache.fileConfigCache.Set(value)
+}
+
+// lockTokens makes the tokens of the locks taken by the same process unique.
+var lockTokens uint64
+
+// lock creates the lock file holding a token unique to the caller, waiting for the other processes to
+// release it, and returns the token. A lock older than the stale lock age is taken over, so a crashed
+// process can't block the others forever.
+func (cache *sharedFileConfigCache) lock() (string, error) {
+	token := fmt.Sprintf("%d-%d", os.Getpid(), atomic.AddUint64(&lockTokens, 1))
+	deadline := cache.clock.Now().Add(cache.lockTimeout)
+	for {
+		file, err := os.OpenFile(cache.lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.WriteString(token)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(cache.lockPath)
+				return "", err
+			}
+			return token, nil
+		}
+
+		if !os.IsExist(err) {
+			return "", err
+		}
+
+		if cache.takeOver(token) {
+			continue
+		}
+
+		if cache.clock.Now().After(deadline) {
+			return "", errLockTimeout
+		}
+
+		timer := cache.clock.NewTimer(time.Millisecond * 10)
+		<-timer.C()
+		timer.Stop()
+	}
+}
+
+// takeOver removes the lock file if it's stale and returns true if it was removed. The stale lock is renamed
+// to a name unique to the caller first, so of the processes finding it stale only one removes it. When the
+// renamed file turns out to be an other one than the stale lock, because the lock was released and acquired
+// again in the meantime, the live lock is put back unless a new one was created already.
+func (cache *sharedFileConfigCache) takeOver(token string) bool {
+	stale, err := os.Stat(cache.lockPath)
+	if err != nil || time.Since(stale.ModTime()) <= cache.staleLockAge {
+		return false
+	}
+
+	claimed := cache.lockPath + "." + token
+	if err := os.Rename(cache.lockPath, claimed); err != nil {
+		return false
+	}
+	defer os.Remove(claimed)
+
+	if renamed, err := os.Stat(claimed); err == nil && !os.SameFile(stale, renamed) {
+		_ = os.Link(claimed, cache.lockPath)
+	}
+	return true
+}
+
+// unlock removes the lock file unless it was taken over by an other process.
+func (cache *sharedFileConfigCache) unlock(token string) {
+	if current, err := ioutil.ReadFile(cache.lockPath); err == nil && string(current) == token {
+		_ = os.Remove(cache.lockPath)
+	}
+}
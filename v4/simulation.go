@@ -0,0 +1,68 @@
+package configcat
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Simulation describes the distribution of the values of a setting across a set of users.
+type Simulation struct {
+	// The key of the simulated setting.
+	Key string
+	// The number of the evaluated users.
+	Total int
+	// The values served to the users, ordered by the number of users descending.
+	Variations []SimulatedVariation
+}
+
+// SimulatedVariation describes a value of a setting served to a part of the simulated users.
+type SimulatedVariation struct {
+	// The served value.
+	Value interface{}
+	// The variation ID of the served value.
+	VariationId string
+	// The number of users the value was served to.
+	Count int
+	// The ratio of the users the value was served to, in percents.
+	Percentage float64
+}
+
+// Simulate evaluates the setting identified by the given key for each of the given users against the current
+// configuration and returns the distribution of the served values, e.g. to verify that a 10% rollout hits about
+// 10% of the real user identifiers before enabling it. The simulated evaluations don't invoke the hooks.
+func (client *Client) Simulate(key string, users []User) (*Simulation, error) {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	json, err := client.getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &Simulation{Key: key, Total: len(users)}
+	indexes := make(map[string]int)
+	for i := range users {
+		result, err := client.parser.parseVariation(json, key, &users[i])
+		if err != nil {
+			return nil, err
+		}
+
+		id := result.variationId + "\x00" + fmt.Sprint(result.value)
+		index, ok := indexes[id]
+		if !ok {
+			index = len(simulation.Variations)
+			indexes[id] = index
+			simulation.Variations = append(simulation.Variations, SimulatedVariation{Value: result.value, VariationId: result.variationId})
+		}
+		simulation.Variations[index].Count++
+	}
+
+	for i := range simulation.Variations {
+		simulation.Variations[i].Percentage = float64(simulation.Variations[i].Count) * 100 / float64(simulation.Total)
+	}
+	sort.SliceStable(simulation.Variations, func(i, j int) bool {
+		return simulation.Variations[i].Count > simulation.Variations[j].Count
+	})
+	return simulation, nil
+}
@@ -0,0 +1,41 @@
+package configcat
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestClient_Simulate(t *testing.T) {
+	fetcher, client := getTestClients()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "rollout": { "v": false, "i": "off", "p": [
+		{ "o": 0, "v": true, "p": 10, "i": "on" },
+		{ "o": 1, "v": false, "p": 90, "i": "off" } ] } }`})
+	client.Refresh()
+
+	users := make([]User, 10000)
+	for i := range users {
+		users[i] = *NewUser("user" + strconv.Itoa(i))
+	}
+
+	simulation, err := client.Simulate("rollout", users)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if simulation.Total != 10000 || len(simulation.Variations) != 2 {
+		t.Fatalf("Unexpected simulation: %+v", simulation)
+	}
+
+	off, on := simulation.Variations[0], simulation.Variations[1]
+	if off.VariationId != "off" || off.Value != false || on.VariationId != "on" || on.Value != true {
+		t.Errorf("Unexpected variations: %+v", simulation.Variations)
+	}
+
+	if on.Count+off.Count != 10000 || on.Percentage < 9 || on.Percentage > 11 {
+		t.Errorf("Expecting about 10%% of the users, got %+v", on)
+	}
+
+	if _, err := client.Simulate("missing", users); err == nil {
+		t.Error("Expecting an error for a missing key")
+	}
+}
@@ -0,0 +1,124 @@
+//go:build go1.21
+// +build go1.21
+
+package configcat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slogLogger is a Logger bridging the messages to a *slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a Logger passing the messages to the slog logger, so they are formatted by its handler,
+// e.g. slog.NewJSONHandler. Each record carries the "event" attribute with one of the LogEvent constants, and
+// the ClientConfig.Labels are attached in the "labels" group. The records of the fetches and the evaluations carry
+// the same "key", "status", "etag" and "duration" attributes as the entries of NewJSONLogger, if they have a value.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+// withLabels returns the logger attaching the labels to its records.
+func (logger *slogLogger) withLabels(labels map[string]string) Logger {
+	attrs := make([]any, 0, len(labels))
+	for name, value := range labels {
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return &slogLogger{logger: logger.logger.With(slog.Group("labels", attrs...))}
+}
+
+// slogLevel returns the slog level of a LogLevel.
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug, LogLevelTrace:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	}
+
+	return slog.LevelError
+}
+
+// IsLevelEnabled tells whether the handler of the slog logger handles the given level.
+func (logger *slogLogger) IsLevelEnabled(level logrus.Level) bool {
+	return logger.logger.Enabled(context.Background(), slogLevel(LogLevel(level)))
+}
+
+// logEvent passes the message with its event and fields.
+func (logger *slogLogger) logEvent(level LogLevel, event string, fields logFields, message string) {
+	attrs := []slog.Attr{slog.String("event", event)}
+	if fields.key != "" {
+		attrs = append(attrs, slog.String("key", fields.key))
+	}
+	if fields.status != "" {
+		attrs = append(attrs, slog.String("status", fields.status))
+	}
+	if fields.eTag != "" {
+		attrs = append(attrs, slog.String("etag", fields.eTag))
+	}
+	if fields.duration != 0 {
+		attrs = append(attrs, slog.Duration("duration", fields.duration))
+	}
+	logger.logger.LogAttrs(context.Background(), slogLevel(level), message, attrs...)
+}
+
+func (logger *slogLogger) log(level LogLevel, message string) {
+	logger.logger.LogAttrs(context.Background(), slogLevel(level), message, slog.String("event", LogEventClient))
+}
+
+func (logger *slogLogger) Debugf(format string, args ...interface{}) {
+	logger.log(LogLevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (logger *slogLogger) Infof(format string, args ...interface{}) {
+	logger.log(LogLevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (logger *slogLogger) Warnf(format string, args ...interface{}) {
+	logger.log(LogLevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (logger *slogLogger) Errorf(format string, args ...interface{}) {
+	logger.log(LogLevelError, fmt.Sprintf(format, args...))
+}
+
+func (logger *slogLogger) Debug(args ...interface{}) {
+	logger.log(LogLevelDebug, fmt.Sprint(args...))
+}
+
+func (logger *slogLogger) Info(args ...interface{}) {
+	logger.log(LogLevelInfo, fmt.Sprint(args...))
+}
+
+func (logger *slogLogger) Warn(args ...interface{}) {
+	logger.log(LogLevelWarn, fmt.Sprint(args...))
+}
+
+func (logger *slogLogger) Error(args ...interface{}) {
+	logger.log(LogLevelError, fmt.Sprint(args...))
+}
+
+func (logger *slogLogger) Debugln(args ...interface{}) {
+	logger.log(LogLevelDebug, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *slogLogger) Infoln(args ...interface{}) {
+	logger.log(LogLevelInfo, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *slogLogger) Warnln(args ...interface{}) {
+	logger.log(LogLevelWarn, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (logger *slogLogger) Errorln(args ...interface{}) {
+	logger.log(LogLevelError, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
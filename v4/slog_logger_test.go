@@ -0,0 +1,36 @@
+//go:build go1.21
+// +build go1.21
+
+package configcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var output bytes.Buffer
+	handler := slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelWarn})
+	logger := newLabelLogger(NewSlogLogger(slog.New(handler)), map[string]string{"service": "api"})
+
+	logger.Infof("Polling the latest configuration.")
+	fields := logFields{status: Failure.String(), eTag: "etag1", duration: time.Second}
+	logEvent(logger, LogLevelError, LogEventFetch, fields, "Config fetch failed: %s.", "timeout")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	labels, _ := entry["labels"].(map[string]interface{})
+	if entry["level"] != "ERROR" || entry["msg"] != "Config fetch failed: timeout." || entry["event"] != LogEventFetch ||
+		entry["status"] != "failed" || entry["etag"] != "etag1" || entry["duration"] != float64(time.Second) ||
+		entry["key"] != nil || labels["service"] != "api" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if isLogLevelEnabled(logger, LogLevelInfo) || !isLogLevelEnabled(logger, LogLevelWarn) {
+		t.Error("Expecting the level of the handler to be reported")
+	}
+}
@@ -0,0 +1,51 @@
+package configcat
+
+// Snapshot holds the configuration of a Client at a point in time together with an optional user,
+// so multiple evaluations made through it are consistent with each other.
+type Snapshot struct {
+	client *Client
+	json   string
+	user   *User
+}
+
+// Snapshot captures the current configuration of the client. Optional user argument can be passed to identify the caller.
+func (client *Client) Snapshot(user *User) *Snapshot {
+	json, err := client.getConfiguration()
+	if err != nil {
+		json = client.servedConfiguration()
+	}
+
+	return &Snapshot{client: client, json: json, user: user}
+}
+
+// User returns the user the snapshot evaluates the settings for.
+func (snapshot *Snapshot) User() *User {
+	return snapshot.user
+}
+
+// GetValue returns a value as interface{} from the captured configuration identified by the given key.
+func (snapshot *Snapshot) GetValue(key string, defaultValue interface{}) interface{} {
+	return snapshot.GetValueForUser(key, defaultValue, snapshot.user)
+}
+
+// GetValueForUser returns a value as interface{} from the captured configuration identified by the given key
+// for the given user instead of the user of the snapshot.
+func (snapshot *Snapshot) GetValueForUser(key string, defaultValue interface{}, user *User) interface{} {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	snapshot.client.evaluatedKeys.add(key)
+	defaultValue = snapshot.client.defaults.resolve(key, defaultValue)
+	return snapshot.client.parseJson(snapshot.json, key, defaultValue, user)
+}
+
+// ConfigJSON returns the captured config JSON as it was downloaded.
+func (snapshot *Snapshot) ConfigJSON() string {
+	return snapshot.json
+}
+
+// GetAllKeys retrieves all the setting keys from the captured configuration.
+func (snapshot *Snapshot) GetAllKeys() ([]string, error) {
+	return snapshot.client.parser.GetAllKeys(snapshot.json)
+}
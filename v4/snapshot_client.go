@@ -0,0 +1,19 @@
+package configcat
+
+// NewSnapshotClient creates a read-only Client serving the given config JSON, e.g. a Snapshot.ConfigJSON()
+// captured in production, for offline analysis tools and deterministic integration tests. The client has all
+// the getters, but it never fetches, polls or reads a cache. The refresh mode, the cache, the offline mode,
+// the bootstrap config and the config provider of the given ClientConfig are ignored, the other options
+// (e.g. the logger, the hooks and the attribute resolver) are applied.
+func NewSnapshotClient(config []byte, clientConfig ClientConfig) (*Client, error) {
+	if _, err := newParser(DefaultLogger(LogLevelWarn)).load(string(config)); err != nil {
+		return nil, &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+
+	clientConfig.Mode = ManualPoll()
+	clientConfig.Cache = newInMemoryConfigCache()
+	clientConfig.Offline = true
+	clientConfig.Bootstrap = config
+	clientConfig.ConfigProvider = nil
+	return newClient("snapshot", clientConfig, nil)
+}
@@ -0,0 +1,39 @@
+package configcat
+
+import (
+	"testing"
+)
+
+func TestNewSnapshotClient(t *testing.T) {
+	fetcher, source := getTestClients()
+	defer source.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "flag": { "v": true, "t": 0 }, "count": { "v": 3, "t": 2 } }`})
+	source.Refresh()
+
+	evaluated := 0
+	client, err := NewSnapshotClient([]byte(source.Snapshot(nil).ConfigJSON()), ClientConfig{
+		Hooks: Hooks{OnFlagEvaluated: func(Impression) { evaluated++ }},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if value := client.GetValue("flag", false); value != true {
+		t.Errorf("Expecting the snapshot value, got %v", value)
+	}
+	if value, err := client.GetIntValue("count", 0, nil); value != 3 || err != nil {
+		t.Errorf("Unexpected int value: %v, %v", value, err)
+	}
+	client.Refresh()
+	if keys, _ := client.GetAllKeys(); len(keys) != 2 || evaluated != 2 {
+		t.Errorf("Expecting the snapshot to be kept, got %v after %d evaluations", keys, evaluated)
+	}
+	if status := client.Status(); !status.Offline || status.FetchErrorCount != 0 {
+		t.Errorf("Expecting an offline client, got %+v", status)
+	}
+
+	if _, err := NewSnapshotClient([]byte("not json"), ClientConfig{}); err == nil {
+		t.Error("Expecting an error for an invalid config")
+	}
+}
@@ -0,0 +1,212 @@
+package configcat
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ClientStatus describes the state of a Client, suitable for health check endpoints.
+type ClientStatus struct {
+	// The polling mode of the client: "auto", "lazy", "manual" or "local file".
+	Mode string
+	// The time of the last completed config fetch, zero if there was none.
+	LastFetchTime time.Time
+	// The outcome of the last config fetch: "fetched", "not modified", "served from cache" or "failed",
+	// empty if there was none.
+	LastFetchStatus string
+	// The result of the last refresh, either polling or forced, with its timestamps.
+	LastRefresh RefreshResult
+	// The error of the last config fetch, nil if it succeeded.
+	LastFetchError error
+	// The ETag of the last downloaded configuration.
+	ETag string
+	// The source of the cached configuration: "memory" for the default in-memory cache, "external" for a custom ConfigCache.
+	CacheSource string
+	// True if the client is in offline mode.
+	Offline bool
+	// True if the served configuration is pinned by Client.Pin.
+	Pinned bool
+	// The sizes of the memoization caches, see ClientConfig.MemoizationBudget.
+	Memoization MemoizationStatus
+	// The name of the source the served configuration came from, see ClientConfig.Sources. It's empty when
+	// the sources aren't configured, none of them had a configuration yet or the served configuration was
+	// read from the cache of the client.
+	ConfigSource string
+	// The labels of the client, see ClientConfig.Labels.
+	Labels map[string]string
+	// The time of the last successful config fetch (either fetched or not modified), zero if there was none.
+	LastSuccessfulFetchTime time.Time
+	// True if the configuration is older than ClientConfig.MaxStaleness.
+	Stale bool
+	// The state of the circuit breaker around the config fetches, always closed when it's disabled.
+	CircuitState CircuitState
+	// The number of completed config fetches.
+	FetchCount uint64
+	// The number of failed config fetches.
+	FetchErrorCount uint64
+	// The number of failed evaluations.
+	EvaluationErrorCount uint64
+	// The number of failed reads of the cache.
+	CacheReadErrorCount uint64
+	// The number of failed writes of the cache.
+	CacheWriteErrorCount uint64
+}
+
+// maxRecentFetchErrors is the number of recent fetch errors kept by the statusRecorder.
+const maxRecentFetchErrors = 10
+
+// fetchErrorRecord describes a failed config fetch.
+type fetchErrorRecord struct {
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+// statusRecorder is a configProvider which records the outcome of the fetches of the wrapped provider.
+type statusRecorder struct {
+	configProvider
+	fetchCount           uint64
+	fetchErrorCount      uint64
+	evaluationErrorCount uint64
+	lastFetchTime        time.Time
+	lastFetchStatus      string
+	lastFetchError       error
+	eTag                 string
+	recentErrors         []fetchErrorRecord
+	lastRefresh          RefreshResult
+	lastSuccessTime      time.Time
+	// the time the current configuration was downloaded
+	downloadTime time.Time
+	// the configuration is considered stale when no fetch succeeded for maxStaleness, measured from the creation
+	// of the recorder before the first successful fetch
+	maxStaleness time.Duration
+	created      time.Time
+	stale        bool
+	clock        Clock
+	onStale      func(age time.Duration)
+	// the last fetch being recorded, joined fetches are recorded only once
+	tracked *asyncResult
+	sync.RWMutex
+}
+
+func newStatusRecorder(provider configProvider, maxStaleness time.Duration, clock Clock) *statusRecorder {
+	return &statusRecorder{configProvider: provider, maxStaleness: maxStaleness, created: clock.Now(), clock: clock}
+}
+
+// getConfigurationAsync collects the actual configuration through the wrapped provider.
+func (recorder *statusRecorder) getConfigurationAsync() *asyncResult {
+	result := recorder.configProvider.getConfigurationAsync()
+	recorder.Lock()
+	joined := result == recorder.tracked
+	recorder.tracked = result
+	recorder.Unlock()
+	if joined {
+		return result
+	}
+
+	start := recorder.clock.Now()
+	result.acceptWithError(func(value interface{}, err error) {
+		atomic.AddUint64(&recorder.fetchCount, 1)
+		response := asFetchResponse(value)
+		if err != nil || response.isFailed() {
+			atomic.AddUint64(&recorder.fetchErrorCount, 1)
+		}
+
+		recorder.Lock()
+		staleAge, becameStale := time.Duration(0), false
+		defer func() {
+			recorder.Unlock()
+			if becameStale && recorder.onStale != nil {
+				recorder.onStale(staleAge)
+			}
+		}()
+		recorder.lastFetchTime = recorder.clock.Now()
+		if err == nil && (response.isFetched() || response.isNotModified()) {
+			recorder.lastSuccessTime = recorder.lastFetchTime
+			recorder.stale = false
+		} else if age := recorder.ageAt(recorder.lastFetchTime); !recorder.stale && recorder.isStale(age) {
+			recorder.stale = true
+			staleAge, becameStale = age, true
+		}
+		recorder.lastFetchError = err
+		recorder.lastFetchStatus = response.status.String()
+		recorder.lastRefresh = newRefreshResult(response, err, start, recorder.lastFetchTime)
+		if err != nil {
+			recorder.recentErrors = append(recorder.recentErrors, fetchErrorRecord{Time: recorder.lastFetchTime, Error: err.Error()})
+			if len(recorder.recentErrors) > maxRecentFetchErrors {
+				recorder.recentErrors = recorder.recentErrors[1:]
+			}
+		}
+		if !response.isFailed() && len(response.eTag) > 0 {
+			recorder.eTag = response.eTag
+		}
+		if err == nil && response.isFetched() {
+			recorder.downloadTime = recorder.lastFetchTime
+		}
+	})
+	return result
+}
+
+func (recorder *statusRecorder) evaluationFailed() {
+	atomic.AddUint64(&recorder.evaluationErrorCount, 1)
+}
+
+// ageAt returns the age of the configuration at the given time.
+func (recorder *statusRecorder) ageAt(now time.Time) time.Duration {
+	if recorder.lastSuccessTime.IsZero() {
+		return now.Sub(recorder.created)
+	}
+
+	return now.Sub(recorder.lastSuccessTime)
+}
+
+func (recorder *statusRecorder) isStale(age time.Duration) bool {
+	return recorder.maxStaleness > 0 && age > recorder.maxStaleness
+}
+
+func (recorder *statusRecorder) status() ClientStatus {
+	recorder.RLock()
+	defer recorder.RUnlock()
+	return ClientStatus{
+		LastSuccessfulFetchTime: recorder.lastSuccessTime,
+		Stale:                   recorder.isStale(recorder.ageAt(recorder.clock.Now())),
+		LastFetchTime:           recorder.lastFetchTime,
+		LastFetchStatus:         recorder.lastFetchStatus,
+		LastFetchError:          recorder.lastFetchError,
+		LastRefresh:             recorder.lastRefresh,
+		ETag:                    recorder.eTag,
+		FetchCount:              atomic.LoadUint64(&recorder.fetchCount),
+		FetchErrorCount:         atomic.LoadUint64(&recorder.fetchErrorCount),
+		EvaluationErrorCount:    atomic.LoadUint64(&recorder.evaluationErrorCount),
+	}
+}
+
+// download returns the time the current configuration was downloaded and its ETag.
+func (recorder *statusRecorder) download() (time.Time, string) {
+	recorder.RLock()
+	defer recorder.RUnlock()
+	return recorder.downloadTime, recorder.eTag
+}
+
+// recentFetchErrors returns the most recent fetch errors, the oldest first.
+func (recorder *statusRecorder) recentFetchErrors() []fetchErrorRecord {
+	recorder.RLock()
+	defer recorder.RUnlock()
+	return append([]fetchErrorRecord{}, recorder.recentErrors...)
+}
+
+// modeName returns the name of the refresh mode used in the ClientStatus.
+func modeName(mode RefreshMode) string {
+	switch mode.getModeIdentifier() {
+	case "a":
+		return "auto"
+	case "l":
+		return "lazy"
+	case "m":
+		return "manual"
+	case "f":
+		return "local file"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,380 @@
+package configcat
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// StreamingConfig describes the configuration of the streaming refresh mode, see Streaming.
+type StreamingConfig struct {
+	// The URL of the server-sent events stream notifying the configuration changes, e.g. the /sse path of a
+	// relay (see the relay package). If it's empty then the /sse path of the ClientConfig.BaseUrl is used.
+	Url string
+	// The wait before the first reconnection of a broken stream, doubled for each failed reconnection
+	// up to MaxReconnectBackoff. The waits are randomized by up to the half of their length, so the clients
+	// of a restarted server don't reconnect at once. 500 milliseconds by default.
+	ReconnectBackoff time.Duration
+	// The maximum wait before a reconnection, 30 seconds by default.
+	MaxReconnectBackoff time.Duration
+	// The stream is considered broken and reconnected when neither an event nor a heartbeat arrives within
+	// this duration. 45 seconds by default, a negative value disables the detection.
+	HeartbeatTimeout time.Duration
+	// The number of failed reconnections in a row after which the configuration is polled every
+	// FallbackPollInterval until the stream is reconnected. 5 by default.
+	MaxReconnectFailures int
+	// The interval of the polling while the stream is unavailable, 1 minute by default.
+	FallbackPollInterval time.Duration
+	// Optional callback invoked when the configuration changed.
+	ChangeListener func()
+}
+
+// streamingConfig describes the configuration for streaming.
+type streamingConfig struct {
+	StreamingConfig
+}
+
+func (config streamingConfig) getModeIdentifier() string {
+	return "s"
+}
+
+func (config streamingConfig) accept(visitor pollingModeVisitor) refreshPolicy {
+	return visitor.visitStreaming(config)
+}
+
+// Streaming creates a refresh mode which refreshes the configuration when a server-sent events stream notifies
+// a change, instead of polling it. The configuration is fetched from the BaseUrl like in the other modes, so
+// the notifications only decide when. The stream is reconnected with a backoff when it breaks, resuming
+// from the last event received (by its Last-Event-ID header). While it can't be reconnected, the configuration
+// is polled. With ClientConfig.NoBackgroundGoroutines the configuration is lazy loaded with the
+// FallbackPollInterval instead.
+func Streaming(config StreamingConfig) RefreshMode {
+	if config.ReconnectBackoff <= 0 {
+		config.ReconnectBackoff = 500 * time.Millisecond
+	}
+	if config.MaxReconnectBackoff < config.ReconnectBackoff {
+		config.MaxReconnectBackoff = 30 * time.Second
+		if config.MaxReconnectBackoff < config.ReconnectBackoff {
+			config.MaxReconnectBackoff = config.ReconnectBackoff
+		}
+	}
+	if config.HeartbeatTimeout == 0 {
+		config.HeartbeatTimeout = 45 * time.Second
+	}
+	if config.MaxReconnectFailures <= 0 {
+		config.MaxReconnectFailures = 5
+	}
+	if config.FallbackPollInterval <= 0 {
+		config.FallbackPollInterval = time.Minute
+	}
+
+	return streamingConfig{StreamingConfig: config}
+}
+
+// streamingPolicy describes a refreshPolicy which refreshes the configuration on the events of a server-sent
+// events stream, falling back to polling while the stream is unavailable.
+type streamingPolicy struct {
+	configRefresher
+	config StreamingConfig
+	// the URL of the stream, read at each connection as Reconfigure can change the base URL
+	url          func() string
+	client       *http.Client
+	maxEventSize int64
+	clock        Clock
+	init         *async
+	initialized  uint32
+	ctx          context.Context
+	cancel       context.CancelFunc
+	random       *rand.Rand
+	// the ID of the last event received, sent when reconnecting so the server can skip the events seen
+	lastEventId string
+}
+
+func newStreamingPolicy(
+	configFetcher configProvider,
+	store *configStore,
+	logger Logger,
+	clock Clock,
+	client *http.Client,
+	url func() string,
+	maxEventSize int64,
+	config streamingConfig) *streamingPolicy {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := &streamingPolicy{
+		configRefresher: configRefresher{configFetcher: configFetcher, store: store, logger: logger},
+		config:          config.StreamingConfig,
+		url:             url,
+		client:          client,
+		maxEventSize:    maxEventSize,
+		clock:           clock,
+		init:            newAsync(),
+		ctx:             ctx,
+		cancel:          cancel,
+		random:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	go policy.run()
+	return policy
+}
+
+// getConfigurationAsync reads the current configuration value.
+func (policy *streamingPolicy) getConfigurationAsync() *asyncResult {
+	if policy.init.isCompleted() {
+		return policy.readCache()
+	}
+
+	return policy.init.apply(func() interface{} {
+		return policy.store.get()
+	})
+}
+
+// cachedConfiguration reads the current configuration value once the first fetch completed.
+func (policy *streamingPolicy) cachedConfiguration() (string, bool) {
+	if !policy.init.isCompleted() {
+		return "", false
+	}
+
+	return policy.store.get(), true
+}
+
+// close shuts down the policy, closing the stream.
+func (policy *streamingPolicy) close() {
+	policy.cancel()
+}
+
+// run fetches the configuration, then keeps the stream connected until the policy is closed. After
+// MaxReconnectFailures failed reconnections in a row it polls the configuration between the reconnections,
+// until a reconnected stream delivers an event or a heartbeat.
+func (policy *streamingPolicy) run() {
+	policy.fetch()
+
+	failures := 0
+	var poll Ticker
+	defer func() {
+		if poll != nil {
+			poll.Stop()
+		}
+	}()
+	for {
+		err := policy.stream(func() {
+			failures = 0
+			if poll != nil {
+				poll.Stop()
+				poll = nil
+				logEvent(policy.logger, LogLevelInfo, LogEventFetch, logFields{},
+					"The configuration stream is reconnected, switching back from polling to streaming.")
+			}
+		})
+		if policy.ctx.Err() != nil {
+			logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Streaming stopped.")
+			return
+		}
+
+		failures++
+		delay := policy.backoff(failures)
+		logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{},
+			"The configuration stream failed: %s. Reconnecting in %v.", err.Error(), delay)
+		if failures == policy.config.MaxReconnectFailures && poll == nil {
+			logEvent(policy.logger, LogLevelWarn, LogEventFetch, logFields{}, "The configuration stream failed %d times "+
+				"in a row, polling the configuration every %v until it's reconnected.", failures, policy.config.FallbackPollInterval)
+			poll = policy.clock.NewTicker(policy.config.FallbackPollInterval)
+			policy.fetch()
+		}
+
+		if !policy.wait(delay, poll) {
+			return
+		}
+	}
+}
+
+// backoff returns the randomized wait before the given reconnection.
+func (policy *streamingPolicy) backoff(failures int) time.Duration {
+	delay := policy.config.ReconnectBackoff
+	for i := 1; i < failures && delay < policy.config.MaxReconnectBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.config.MaxReconnectBackoff {
+		delay = policy.config.MaxReconnectBackoff
+	}
+
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + policy.random.Int63n(half+1))
+}
+
+// wait waits for the given duration while polling the configuration on the ticks of poll, unless it's nil.
+// It reports false when the policy was closed in the meantime.
+func (policy *streamingPolicy) wait(d time.Duration, poll Ticker) bool {
+	timer := policy.clock.NewTimer(d)
+	defer timer.Stop()
+	var ticks <-chan time.Time
+	if poll != nil {
+		ticks = poll.C()
+	}
+	for {
+		select {
+		case <-policy.ctx.Done():
+			logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Streaming stopped.")
+			return false
+		case <-timer.C():
+			return true
+		case <-ticks:
+			policy.fetch()
+		}
+	}
+}
+
+// stream connects to the stream and fetches the configuration on its events until the stream breaks,
+// the returned error tells why. The recovered function is called when the first event or heartbeat arrives.
+func (policy *streamingPolicy) stream(recovered func()) error {
+	ctx, cancel := context.WithCancel(policy.ctx)
+	defer cancel()
+
+	request, err := http.NewRequest("GET", policy.url(), nil)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Accept", "text/event-stream")
+	request.Header.Set("Cache-Control", "no-cache")
+	if len(policy.lastEventId) > 0 {
+		request.Header.Set("Last-Event-ID", policy.lastEventId)
+	}
+
+	response, err := policy.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: response.StatusCode}
+	}
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), int(policy.maxEventSize))
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		err := scanner.Err()
+		if err == nil {
+			err = errors.New("the stream was closed by the server")
+		}
+		readErr <- err
+	}()
+
+	heartbeat, timeout := policy.heartbeatTimer()
+	defer func() {
+		if heartbeat != nil {
+			heartbeat.Stop()
+		}
+	}()
+	first := true
+	id, hasData := policy.lastEventId, false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-timeout:
+			return fmt.Errorf("no event or heartbeat received in %v", policy.config.HeartbeatTimeout)
+		case line := <-lines:
+			if heartbeat != nil {
+				heartbeat.Stop()
+			}
+			heartbeat, timeout = policy.heartbeatTimer()
+			if first {
+				first = false
+				recovered()
+			}
+
+			name, value := parseEventField(line)
+			switch name {
+			case "":
+				// a blank line dispatches the event, the comments (the heartbeats) have no data
+				if hasData {
+					hasData = false
+					policy.lastEventId = id
+					policy.fetch()
+				}
+			case "id":
+				id = value
+			case "data":
+				hasData = true
+			}
+		}
+	}
+}
+
+// heartbeatTimer starts the timer of the heartbeat timeout, it returns nils if the detection is disabled.
+func (policy *streamingPolicy) heartbeatTimer() (Timer, <-chan time.Time) {
+	if policy.config.HeartbeatTimeout < 0 {
+		return nil, nil
+	}
+	timer := policy.clock.NewTimer(policy.config.HeartbeatTimeout)
+	return timer, timer.C()
+}
+
+// parseEventField returns the name and the value of a field of a server-sent event, the name of a comment
+// is ":" and the name of a blank line is empty.
+func parseEventField(line string) (string, string) {
+	if len(line) == 0 {
+		return "", ""
+	}
+	if line[0] == ':' {
+		return ":", line[1:]
+	}
+
+	colon := strings.IndexByte(line, ':')
+	if colon < 0 {
+		return line, ""
+	}
+	return line[:colon], strings.TrimPrefix(line[colon+1:], " ")
+}
+
+// fetch fetches the configuration and notifies the change listener when it changed.
+func (policy *streamingPolicy) fetch() {
+	defer func() {
+		if r := recover(); r != nil {
+			err := newPanicError(r)
+			logEvent(policy.logger, LogLevelError, LogEventFetch, logFields{}, "Refreshing the streamed configuration failed. %s.\n%s", err.Error(), err.Stack)
+		}
+
+		if atomic.CompareAndSwapUint32(&policy.initialized, no, yes) {
+			policy.init.complete()
+		}
+	}()
+
+	result, err := policy.configFetcher.getConfigurationAsync().getWithError()
+	if err != nil {
+		logEvent(policy.logger, LogLevelDebug, LogEventFetch, logFields{}, "Refreshing the streamed configuration failed: %s.", err.Error())
+		return
+	}
+
+	response := asFetchResponse(result)
+	if response.isFetched() && policy.store.get() != response.body {
+		policy.store.setFetched(response)
+		if policy.config.ChangeListener != nil {
+			policy.config.ChangeListener()
+		}
+	}
+}
+
+func (policy *streamingPolicy) readCache() *asyncResult {
+	logEvent(policy.logger, LogLevelDebug, LogEventCache, logFields{}, "Reading from cache.")
+	return asCompletedAsyncResult(policy.store.get())
+}
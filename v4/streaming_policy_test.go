@@ -0,0 +1,203 @@
+package configcat
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sseServer serves a config and a server-sent events stream whose events are pushed by the tests.
+type sseServer struct {
+	*httptest.Server
+	body         string
+	status       int
+	events       chan string
+	lastEventIds []string
+	connections  int32
+	fetches      int32
+	sync.Mutex
+}
+
+func newSseServer(body string) *sseServer {
+	server := &sseServer{body: body, status: http.StatusOK, events: make(chan string, 10)}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+	return server
+}
+
+func (server *sseServer) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/sse" {
+		atomic.AddInt32(&server.fetches, 1)
+		server.Lock()
+		body := server.body
+		server.Unlock()
+		_, _ = w.Write([]byte(body))
+		return
+	}
+
+	atomic.AddInt32(&server.connections, 1)
+	server.Lock()
+	server.lastEventIds = append(server.lastEventIds, r.Header.Get("Last-Event-ID"))
+	status := server.status
+	server.Unlock()
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	for {
+		select {
+		case event := <-server.events:
+			if len(event) == 0 {
+				return
+			}
+			_, _ = fmt.Fprint(w, event)
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (server *sseServer) set(body string, status int) {
+	server.Lock()
+	defer server.Unlock()
+	server.body, server.status = body, status
+}
+
+func (server *sseServer) eventIds() []string {
+	server.Lock()
+	defer server.Unlock()
+	return append([]string(nil), server.lastEventIds...)
+}
+
+func waitFor(t *testing.T, condition func() bool, message string) {
+	deadline := time.Now().Add(time.Second * 5)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+}
+
+func TestStreamingPolicy_RefreshesOnEvents(t *testing.T) {
+	server := newSseServer(fmt.Sprintf(jsonFormat, "key", `"first"`))
+	defer server.Close()
+	changes := make(chan struct{}, 10)
+	client := NewCustomClient("fakeKey", ClientConfig{
+		BaseUrl: server.URL,
+		Mode: Streaming(StreamingConfig{ChangeListener: func() {
+			changes <- struct{}{}
+		}}),
+	})
+	defer client.Close()
+
+	if value := client.GetValue("key", ""); value != "first" {
+		t.Fatalf("Expecting the fetched value, got %v", value)
+	}
+	server.set(fmt.Sprintf(jsonFormat, "key", `"second"`), http.StatusOK)
+	server.events <- "id: 1\ndata: changed\n\n"
+
+	waitFor(t, func() bool { return client.GetValue("key", "") == "second" }, "Expecting the value to be refreshed by the event")
+	if len(changes) != 2 {
+		t.Errorf("Expecting the change listener to be called twice, got %d", len(changes))
+	}
+}
+
+func TestStreamingPolicy_ReconnectsWithLastEventId(t *testing.T) {
+	server := newSseServer(fmt.Sprintf(jsonFormat, "key", `"first"`))
+	defer server.Close()
+	client := NewCustomClient("fakeKey", ClientConfig{
+		BaseUrl: server.URL,
+		Mode:    Streaming(StreamingConfig{ReconnectBackoff: time.Millisecond}),
+	})
+	defer client.Close()
+
+	server.events <- ": heartbeat\n\nid: \"e1\"\ndata: changed\n\n"
+	server.events <- ""
+
+	waitFor(t, func() bool { return len(server.eventIds()) >= 2 }, "Expecting the stream to be reconnected")
+	if ids := server.eventIds(); ids[0] != "" || ids[1] != `"e1"` {
+		t.Errorf("Expecting the reconnection to resume from the last event, got %q", ids)
+	}
+}
+
+func TestStreamingPolicy_HeartbeatTimeout(t *testing.T) {
+	server := newSseServer(fmt.Sprintf(jsonFormat, "key", `"first"`))
+	defer server.Close()
+	client := NewCustomClient("fakeKey", ClientConfig{
+		BaseUrl: server.URL,
+		Mode:    Streaming(StreamingConfig{ReconnectBackoff: time.Millisecond, HeartbeatTimeout: time.Millisecond * 50}),
+	})
+	defer client.Close()
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&server.connections) >= 2 },
+		"Expecting the silent stream to be reconnected")
+}
+
+func TestStreamingPolicy_FallbackToPolling(t *testing.T) {
+	server := newSseServer(fmt.Sprintf(jsonFormat, "key", `"first"`))
+	defer server.Close()
+	server.set(fmt.Sprintf(jsonFormat, "key", `"first"`), http.StatusServiceUnavailable)
+	client := NewCustomClient("fakeKey", ClientConfig{
+		BaseUrl: server.URL,
+		Mode: Streaming(StreamingConfig{
+			ReconnectBackoff:     time.Millisecond * 20,
+			MaxReconnectBackoff:  time.Millisecond * 20,
+			MaxReconnectFailures: 2,
+			FallbackPollInterval: time.Millisecond * 5,
+		}),
+	})
+	defer client.Close()
+
+	server.set(fmt.Sprintf(jsonFormat, "key", `"polled"`), http.StatusServiceUnavailable)
+	waitFor(t, func() bool { return client.GetValue("key", "") == "polled" }, "Expecting the configuration to be polled")
+
+	server.set(fmt.Sprintf(jsonFormat, "key", `"polled"`), http.StatusOK)
+	server.events <- ": heartbeat\n\n"
+	waitFor(t, func() bool { return len(server.events) == 0 }, "Expecting the stream to be reconnected")
+	time.Sleep(time.Millisecond * 20)
+	fetches := atomic.LoadInt32(&server.fetches)
+	time.Sleep(time.Millisecond * 50)
+	if polled := atomic.LoadInt32(&server.fetches) - fetches; polled != 0 {
+		t.Errorf("Expecting the polling to stop once the stream recovered, got %d fetches", polled)
+	}
+}
+
+func TestStreamingPolicy_Inline(t *testing.T) {
+	server := newSseServer(fmt.Sprintf(jsonFormat, "key", `"first"`))
+	defer server.Close()
+	client := NewCustomClient("fakeKey", ClientConfig{
+		BaseUrl:                server.URL,
+		Mode:                   Streaming(StreamingConfig{}),
+		NoBackgroundGoroutines: true,
+	})
+	defer client.Close()
+
+	if value := client.GetValue("key", ""); value != "first" || atomic.LoadInt32(&server.connections) != 0 {
+		t.Errorf("Expecting the configuration to be lazy loaded without a stream, got %v", value)
+	}
+}
+
+func TestParseEventField(t *testing.T) {
+	tests := map[string][2]string{
+		"":            {"", ""},
+		": heartbeat": {":", " heartbeat"},
+		"id: 1":       {"id", "1"},
+		"data:{}":     {"data", "{}"},
+		"data: a: b":  {"data", "a: b"},
+		"retry":       {"retry", ""},
+	}
+	for line, expected := range tests {
+		if name, value := parseEventField(line); name != expected[0] || value != expected[1] {
+			t.Errorf("%q: expecting %q, got %q %q", line, expected, name, value)
+		}
+	}
+}
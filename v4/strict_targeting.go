@@ -0,0 +1,24 @@
+package configcat
+
+import (
+	"errors"
+)
+
+// ErrUserRequired is matched by errors.Is for every UserRequiredError.
+var ErrUserRequired = errors.New("user required")
+
+// UserRequiredError is returned in StrictTargeting mode when a setting with targeting rules or percentage options
+// is evaluated without a user. The default value is returned instead of the non-targeted value of the setting.
+type UserRequiredError struct {
+	// The key of the setting.
+	Key string
+}
+
+func (err *UserRequiredError) Error() string {
+	return "setting " + err.Key + " has targeting rules or percentage options, it can't be evaluated without a user"
+}
+
+// Is reports whether the target is ErrUserRequired.
+func (err *UserRequiredError) Is(target error) bool {
+	return target == ErrUserRequired
+}
@@ -0,0 +1,42 @@
+package configcat
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClient_StrictTargeting(t *testing.T) {
+	body := `{
+		"targeted": { "v": "fallthrough", "t": 1, "r": [ { "o": 0, "a": "Identifier", "t": 0, "c": "id", "v": "matched" } ] },
+		"rollout": { "v": "fallthrough", "t": 1, "p": [ { "o": 0, "v": "a", "p": 100 } ] },
+		"plain": { "v": "plain", "t": 1 }
+	}`
+
+	for _, strict := range []bool{false, true} {
+		var output bytes.Buffer
+		fetcher := newFakeConfigProvider()
+		fetcher.SetResponse(fetchResponse{status: Fetched, body: body})
+		client := newInternal("fakeKey", ClientConfig{Mode: ManualPoll(), StrictTargeting: strict,
+			Logger: NewJSONLogger(&output, LogLevelWarn)}, fetcher)
+		client.Refresh()
+
+		for _, key := range []string{"targeted", "rollout"} {
+			value, err := client.GetStringValue(key, "default", nil)
+			if strict && (value != "default" || !errors.Is(err, ErrUserRequired)) {
+				t.Errorf("%s: expecting the default value and ErrUserRequired in strict mode, got %v %v", key, value, err)
+			}
+			if !strict && (value != "fallthrough" || err != nil || !strings.Contains(output.String(), "UserObject missing")) {
+				t.Errorf("%s: expecting the non-targeted value with a warning, got %v %v", key, value, err)
+			}
+		}
+		if value, err := client.GetStringValue("plain", "default", nil); value != "plain" || err != nil {
+			t.Errorf("Expecting the settings without targeting to be evaluated, got %v %v", value, err)
+		}
+		if value, err := client.GetStringValue("targeted", "default", NewUser("id")); value != "matched" || err != nil {
+			t.Errorf("Expecting the targeting with a user, got %v %v", value, err)
+		}
+		client.Close()
+	}
+}
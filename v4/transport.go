@@ -0,0 +1,212 @@
+package configcat
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// newTransport creates the http.RoundTripper used by the config fetcher from the client configuration.
+// The connection level options can only be applied when the configured transport is an *http.Transport.
+func newTransport(config ClientConfig) http.RoundTripper {
+	transport := config.Transport
+	if httpTransport, ok := transport.(*http.Transport); ok {
+		httpTransport = httpTransport.Clone()
+		if config.ConnectTimeout > 0 || config.Dialer != (DialerConfig{}) {
+			httpTransport.DialContext = newDialContext(config.Dialer, config.ConnectTimeout)
+		}
+		if config.ConnectTimeout > 0 {
+			httpTransport.TLSHandshakeTimeout = config.ConnectTimeout
+		}
+
+		if config.Proxy != nil {
+			httpTransport.Proxy = config.Proxy
+		}
+
+		if config.TLSConfig != nil {
+			httpTransport.TLSClientConfig = config.TLSConfig
+		}
+
+		applyConnectionConfig(httpTransport, config.Connection)
+		transport = httpTransport
+	} else if config.Proxy != nil || config.TLSConfig != nil || config.Dialer != (DialerConfig{}) || config.Connection != (ConnectionConfig{}) {
+		config.Logger.Warnln("The Proxy, TLSConfig, Dialer and Connection options are ignored because the configured Transport is not an *http.Transport.")
+	}
+
+	if config.HttpTimeout > 0 {
+		transport = &requestTimeoutTransport{transport: transport, timeout: config.HttpTimeout}
+	}
+
+	return transport
+}
+
+// IPFamily restricts the IP addresses used to connect to the ConfigCat CDN.
+type IPFamily int
+
+const (
+	// IPAny connects over both IPv4 and IPv6, racing them by Happy Eyeballs.
+	IPAny IPFamily = 0
+	// IPv4Only connects over IPv4 only.
+	IPv4Only IPFamily = 1
+	// IPv6Only connects over IPv6 only.
+	IPv6Only IPFamily = 2
+)
+
+// DialerConfig describes how the connections to the ConfigCat CDN are established. It's applied when the
+// configured transport is an *http.Transport, the dial timeout is ClientConfig.ConnectTimeout.
+type DialerConfig struct {
+	// The address ("host:port") of the DNS server used to resolve the ConfigCat CDN, e.g. for split-horizon DNS.
+	// If it's empty then the resolver of the system is used.
+	Resolver string
+	// Restricts the connections to IPv4 or IPv6 addresses.
+	Family IPFamily
+	// The wait before falling back to the other IP family when the first one doesn't connect (Happy Eyeballs),
+	// 300 milliseconds if it's 0. A negative value disables the fallback.
+	FallbackDelay time.Duration
+}
+
+// newDialContext creates the dial function of the transport from the dialer options.
+func newDialContext(config DialerConfig, timeout time.Duration) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, FallbackDelay: config.FallbackDelay}
+	if len(config.Resolver) > 0 {
+		resolverDialer := &net.Dialer{Timeout: timeout}
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				return resolverDialer.DialContext(ctx, network, config.Resolver)
+			},
+		}
+	}
+
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		if network == "tcp" {
+			switch config.Family {
+			case IPv4Only:
+				network = "tcp4"
+			case IPv6Only:
+				network = "tcp6"
+			}
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+// ConnectionConfig describes the reuse of the connections to the ConfigCat CDN, cutting the TCP and TLS
+// handshakes of the polls. It's applied when the configured transport is an *http.Transport.
+type ConnectionConfig struct {
+	// If it's true then the connections use HTTP/1.1 only. Otherwise HTTP/2 is negotiated when the server
+	// supports it, even with a custom TLSConfig or Dialer, so the polls share a single connection.
+	DisableHTTP2 bool
+	// The maximum number of idle connections kept per host, 2 if it's 0 (the default of the transport).
+	MaxIdleConnsPerHost int
+	// How long an idle connection is kept open, 90 seconds if it's 0 (the default of the transport). Set it
+	// above the polling interval to reuse the connection across the polls.
+	IdleConnTimeout time.Duration
+	// The number of TLS sessions cached for resumption, which skips the full handshake when a connection has to
+	// be reopened. It's 64 if it's 0, a negative value disables the resumption.
+	TLSSessionCacheSize int
+}
+
+// applyConnectionConfig configures the connection reuse of the transport.
+func applyConnectionConfig(transport *http.Transport, config ConnectionConfig) {
+	if config.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	} else {
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+
+	if config.TLSSessionCacheSize >= 0 {
+		var tlsConfig *tls.Config
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		} else {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ClientSessionCache == nil {
+			tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(config.TLSSessionCacheSize)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+}
+
+// NewTLSConfig creates a TLS configuration which trusts the certificates of the given PEM encoded CA bundle
+// in addition to the system roots. When both the certFile and keyFile arguments are set, the client certificate
+// is presented to the server for mutual TLS. Any of the arguments can be left empty.
+func NewTLSConfig(caFile string, certFile string, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if len(caFile) > 0 {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + caFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(certFile) > 0 || len(keyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// requestTimeoutTransport limits the duration of each individual http request, including reading the response body.
+type requestTimeoutTransport struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+}
+
+func (t *requestTimeoutTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), t.timeout)
+	response, err := t.transport.RoundTrip(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+// cancelOnCloseBody releases the request context when the response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelOnCloseBody) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
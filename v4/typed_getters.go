@@ -0,0 +1,115 @@
+package configcat
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTypeMismatch is matched by errors.Is for every TypeMismatchError.
+var ErrTypeMismatch = errors.New("setting type mismatch")
+
+// TypeMismatchError is returned by the typed getters (e.g. GetStringValue) when the type of the setting
+// declared in the configuration differs from the requested type.
+type TypeMismatchError struct {
+	// The key of the setting.
+	Key string
+	// The type of the setting declared in the configuration.
+	SettingType SettingType
+	// The type requested by the getter.
+	RequestedType SettingType
+}
+
+func (err *TypeMismatchError) Error() string {
+	return fmt.Sprintf("setting %s is declared as %s but %s was requested", err.Key, err.SettingType, err.RequestedType)
+}
+
+// Is reports whether the target is ErrTypeMismatch.
+func (err *TypeMismatchError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+// GetBoolValue returns the value of a bool setting identified by the given key. Optional user argument can be
+// passed to identify the caller. The default value is returned with a *TypeMismatchError when the setting isn't a bool.
+func (client *Client) GetBoolValue(key string, defaultValue bool, user *User) (bool, error) {
+	value, err := client.getTypedValue(key, BoolSetting, defaultValue, user)
+	return value.(bool), err
+}
+
+// GetStringValue returns the value of a string setting identified by the given key. Optional user argument can be
+// passed to identify the caller. The default value is returned with a *TypeMismatchError when the setting isn't a string.
+func (client *Client) GetStringValue(key string, defaultValue string, user *User) (string, error) {
+	value, err := client.getTypedValue(key, StringSetting, defaultValue, user)
+	return value.(string), err
+}
+
+// GetIntValue returns the value of a whole number setting identified by the given key. Optional user argument can be
+// passed to identify the caller. The default value is returned with a *TypeMismatchError when the setting isn't a whole number.
+func (client *Client) GetIntValue(key string, defaultValue int, user *User) (int, error) {
+	value, err := client.getTypedValue(key, IntSetting, defaultValue, user)
+	return value.(int), err
+}
+
+// GetFloatValue returns the value of a number setting identified by the given key. Optional user argument can be
+// passed to identify the caller. The default value is returned with a *TypeMismatchError when the setting isn't a number.
+func (client *Client) GetFloatValue(key string, defaultValue float64, user *User) (float64, error) {
+	value, err := client.getTypedValue(key, FloatSetting, defaultValue, user)
+	return value.(float64), err
+}
+
+// getTypedValue evaluates the setting and converts its value to the requested type,
+// the returned value has the type of the default value.
+func (client *Client) getTypedValue(key string, requested SettingType, defaultValue interface{}, user *User) (interface{}, error) {
+	if len(key) == 0 {
+		panic("key cannot be empty")
+	}
+
+	client.evaluatedKeys.add(key)
+	defaultValue = client.defaults.resolveTyped(key, defaultValue)
+	json, err := client.getConfiguration()
+	if err != nil {
+		client.reportError(err)
+		json = client.servedConfiguration()
+	}
+
+	if metadata, err := client.parser.GetKeyMetadata(json, key); err == nil && !isAssignableSetting(metadata.Type, requested) {
+		return defaultValue, client.typeMismatch(key, metadata.Type, requested, defaultValue)
+	}
+
+	details := client.evaluateDetails(json, key, defaultValue, user)
+	if details.Error != nil {
+		return defaultValue, details.Error
+	}
+
+	switch value := details.Value.(type) {
+	case bool:
+		if requested == BoolSetting {
+			return value, nil
+		}
+	case string:
+		if requested == StringSetting {
+			return value, nil
+		}
+	case float64:
+		if requested == FloatSetting {
+			return value, nil
+		}
+		if requested == IntSetting && value == float64(int(value)) {
+			return int(value), nil
+		}
+	}
+
+	return defaultValue, client.typeMismatch(key, settingTypeOf(map[string]interface{}{"v": details.Value}), requested, defaultValue)
+}
+
+func (client *Client) typeMismatch(key string, settingType SettingType, requested SettingType, defaultValue interface{}) error {
+	err := &TypeMismatchError{Key: key, SettingType: settingType, RequestedType: requested}
+	logEvent(client.logger, LogLevelError, LogEventEvaluation, logFields{key: key}, "Evaluating %s failed. Returning defaultValue: [%v]. %s.", key, defaultValue, err.Error())
+	client.statusRecorder.evaluationFailed()
+	client.reportError(err)
+	return err
+}
+
+// isAssignableSetting reports whether the value of a setting of the declared type can be returned as the requested type.
+func isAssignableSetting(declared SettingType, requested SettingType) bool {
+	return declared == requested || declared == UnknownSetting || (declared == IntSetting && requested == FloatSetting)
+}
@@ -0,0 +1,51 @@
+package configcat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClient_TypedGetters(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	var reported error
+	client.onError = func(err error) { reported = err }
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"flag": { "v": true, "t": 0 },
+		"text": { "v": "value", "t": 1 },
+		"count": { "v": 42, "t": 2 },
+		"ratio": { "v": 0.5, "t": 3 },
+		"untyped": { "v": 1.5 }
+	}`})
+	client.Refresh()
+
+	if value, err := client.GetBoolValue("flag", false, nil); value != true || err != nil {
+		t.Errorf("Unexpected bool result: %v, %v", value, err)
+	}
+	if value, err := client.GetStringValue("text", "", nil); value != "value" || err != nil {
+		t.Errorf("Unexpected string result: %v, %v", value, err)
+	}
+	if value, err := client.GetIntValue("count", 0, nil); value != 42 || err != nil {
+		t.Errorf("Unexpected int result: %v, %v", value, err)
+	}
+	if value, err := client.GetFloatValue("count", 0, nil); value != 42 || err != nil {
+		t.Errorf("Unexpected float result: %v, %v", value, err)
+	}
+
+	value, err := client.GetStringValue("flag", "default", nil)
+	var mismatch *TypeMismatchError
+	if value != "default" || !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &mismatch) || reported != err {
+		t.Fatalf("Expecting type mismatch, got %v, %v", value, err)
+	}
+	if mismatch.Key != "flag" || mismatch.SettingType != BoolSetting || mismatch.RequestedType != StringSetting {
+		t.Errorf("Unexpected mismatch: %+v", mismatch)
+	}
+
+	if value, err := client.GetIntValue("untyped", 7, nil); value != 7 || !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expecting type mismatch, got %v, %v", value, err)
+	}
+
+	if value, err := client.GetBoolValue("missing", true, nil); value != true || err == nil || errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expecting missing key error, got %v, %v", value, err)
+	}
+}
@@ -1,6 +1,13 @@
 package configcat
 
-import "strings"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // User is an object containing attributes to properly identify a given user for rollout evaluation.
 type User struct {
@@ -39,6 +46,48 @@ func NewUserWithAdditionalAttributes(identifier string, email string, country st
 	return user
 }
 
+// NewUserWithCustomValues creates a new user object like NewUserWithAdditionalAttributes, but the custom attributes
+// can be numbers, time.Time and []string values as well. Numbers are stored in decimal notation, times as Unix seconds
+// with millisecond precision and lists as JSON arrays, the formats the comparators of the targeting rules expect.
+func NewUserWithCustomValues(identifier string, email string, country string, custom map[string]interface{}) *User {
+	attributes := make(map[string]string, len(custom))
+	for k, v := range custom {
+		attributes[k] = formatAttribute(v)
+	}
+
+	return NewUserWithAdditionalAttributes(identifier, email, country, attributes)
+}
+
+func formatAttribute(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string, []interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	case time.Time:
+		return strconv.FormatFloat(unixSeconds(v), 'f', -1, 64)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	}
+
+	return fmt.Sprint(value)
+}
+
 // GetAttribute retrieves a user attribute identified by a key.
 func (user *User) GetAttribute(key string) string {
 	val := user.attributes[strings.ToLower(key)]
@@ -48,3 +97,79 @@ func (user *User) GetAttribute(key string) string {
 
 	return ""
 }
+
+// NewUserFromMap creates a new user object from a map of attributes, e.g. decoded from JSON. The "identifier" entry is
+// mandatory, "email" and "country" are the standard attributes and the other entries are custom attributes. The values
+// can be strings, numbers, time.Time and lists, formatted like by NewUserWithCustomValues.
+func NewUserFromMap(attributes map[string]interface{}) (*User, error) {
+	var identifier, email, country string
+	custom := make(map[string]interface{}, len(attributes))
+	for name, value := range attributes {
+		if value == nil {
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "identifier":
+			identifier = formatAttribute(value)
+		case "email":
+			email = formatAttribute(value)
+		case "country":
+			country = formatAttribute(value)
+		default:
+			custom[name] = value
+		}
+	}
+
+	if len(identifier) == 0 {
+		return nil, errors.New("user identifier is missing")
+	}
+	return NewUserWithCustomValues(identifier, email, country, custom), nil
+}
+
+// userJson is the JSON representation of a User.
+type userJson struct {
+	Identifier string                 `json:"identifier"`
+	Email      string                 `json:"email,omitempty"`
+	Country    string                 `json:"country,omitempty"`
+	Custom     map[string]interface{} `json:"custom,omitempty"`
+}
+
+// MarshalJSON encodes the user as a JSON object with the identifier, email, country and custom attributes.
+func (user *User) MarshalJSON() ([]byte, error) {
+	encoded := userJson{Identifier: user.identifier}
+	for name, value := range user.attributes {
+		switch name {
+		case "identifier":
+		case "email":
+			encoded.Email = value
+		case "country":
+			encoded.Country = value
+		default:
+			if encoded.Custom == nil {
+				encoded.Custom = map[string]interface{}{}
+			}
+			encoded.Custom[name] = value
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes a user encoded by MarshalJSON, the custom attributes can have mixed value types.
+func (user *User) UnmarshalJSON(data []byte) error {
+	var decoded userJson
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	attributes := make(map[string]interface{}, len(decoded.Custom)+3)
+	for name, value := range decoded.Custom {
+		attributes[name] = value
+	}
+	attributes["identifier"], attributes["email"], attributes["country"] = decoded.Identifier, decoded.Email, decoded.Country
+	decodedUser, err := NewUserFromMap(attributes)
+	if err != nil {
+		return err
+	}
+	*user = *decodedUser
+	return nil
+}
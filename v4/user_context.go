@@ -0,0 +1,55 @@
+package configcat
+
+import (
+	"context"
+)
+
+type userContextKey struct{}
+
+// WithUser returns a copy of the given context carrying the user, e.g. set once per request by a middleware
+// and used by the getters of the Client ending with FromContext.
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the user attached to the context by WithUser, or the user of the Snapshot attached
+// by NewContext, or nil if there is none.
+func UserFromContext(ctx context.Context) *User {
+	if user, ok := ctx.Value(userContextKey{}).(*User); ok {
+		return user
+	}
+	if snapshot := FromContext(ctx); snapshot != nil {
+		return snapshot.user
+	}
+	return nil
+}
+
+// GetValueFromContext is like GetValueWithContext, but the user is resolved from the context by UserFromContext.
+func (client *Client) GetValueFromContext(ctx context.Context, key string, defaultValue interface{}) (interface{}, error) {
+	return client.GetValueWithContext(ctx, key, defaultValue, UserFromContext(ctx))
+}
+
+// GetValueDetailsFromContext is like GetValueDetails, but the user is resolved from the context by UserFromContext.
+func (client *Client) GetValueDetailsFromContext(ctx context.Context, key string, defaultValue interface{}) EvaluationDetails {
+	return client.GetValueDetails(key, defaultValue, UserFromContext(ctx))
+}
+
+// GetBoolValueFromContext is like GetBoolValue, but the user is resolved from the context by UserFromContext.
+func (client *Client) GetBoolValueFromContext(ctx context.Context, key string, defaultValue bool) (bool, error) {
+	return client.GetBoolValue(key, defaultValue, UserFromContext(ctx))
+}
+
+// GetStringValueFromContext is like GetStringValue, but the user is resolved from the context by UserFromContext.
+func (client *Client) GetStringValueFromContext(ctx context.Context, key string, defaultValue string) (string, error) {
+	return client.GetStringValue(key, defaultValue, UserFromContext(ctx))
+}
+
+// GetIntValueFromContext is like GetIntValue, but the user is resolved from the context by UserFromContext.
+func (client *Client) GetIntValueFromContext(ctx context.Context, key string, defaultValue int) (int, error) {
+	return client.GetIntValue(key, defaultValue, UserFromContext(ctx))
+}
+
+// GetFloatValueFromContext is like GetFloatValue, but the user is resolved from the context by UserFromContext.
+func (client *Client) GetFloatValueFromContext(ctx context.Context, key string, defaultValue float64) (float64, error) {
+	return client.GetFloatValue(key, defaultValue, UserFromContext(ctx))
+}
@@ -0,0 +1,50 @@
+package configcat
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUserFromContext(t *testing.T) {
+	_, client := getTestClients()
+	defer client.Close()
+	user := NewUser("user-id")
+	snapshotUser := NewUser("snapshot-user")
+
+	if UserFromContext(context.Background()) != nil {
+		t.Error("Expecting no user")
+	}
+	if UserFromContext(WithUser(context.Background(), user)) != user {
+		t.Error("Expecting the attached user")
+	}
+	if UserFromContext(NewContext(context.Background(), client.Snapshot(snapshotUser))) != snapshotUser {
+		t.Error("Expecting the user of the snapshot")
+	}
+	if UserFromContext(WithUser(NewContext(context.Background(), client.Snapshot(snapshotUser)), user)) != user {
+		t.Error("Expecting the attached user to win over the snapshot")
+	}
+}
+
+func TestClient_GettersFromContext(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"flag": { "v": false, "t": 0, "r": [ { "o": 0, "a": "Identifier", "t": 0, "c": "user-id", "v": true } ] },
+		"text": { "v": "a", "t": 1, "r": [ { "o": 0, "a": "Identifier", "t": 0, "c": "user-id", "v": "b" } ] }
+	}`})
+	client.Refresh()
+	ctx := WithUser(context.Background(), NewUser("user-id"))
+
+	if value, err := client.GetValueFromContext(ctx, "flag", false); value != true || err != nil {
+		t.Errorf("Unexpected value: %v, %v", value, err)
+	}
+	if details := client.GetValueDetailsFromContext(ctx, "text", ""); details.Value != "b" || details.User == nil {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+	if value, err := client.GetBoolValueFromContext(ctx, "flag", false); value != true || err != nil {
+		t.Errorf("Unexpected bool value: %v, %v", value, err)
+	}
+	if value, err := client.GetStringValueFromContext(context.Background(), "text", ""); value != "a" || err != nil {
+		t.Errorf("Expecting the value without user, got %v, %v", value, err)
+	}
+}
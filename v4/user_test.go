@@ -0,0 +1,59 @@
+package configcat
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUser_JSONRoundTrip(t *testing.T) {
+	user := NewUserWithAdditionalAttributes("id", "a@example.com", "HU", map[string]string{"Plan": "pro"})
+	encoded, err := json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"identifier":"id","email":"a@example.com","country":"HU","custom":{"plan":"pro"}}` {
+		t.Errorf("Unexpected encoding: %s", encoded)
+	}
+
+	var decoded User
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, user) {
+		t.Errorf("Expecting %+v, got %+v", user, decoded)
+	}
+}
+
+func TestUser_UnmarshalJSON_MixedTypes(t *testing.T) {
+	var user User
+	err := json.Unmarshal([]byte(`{"identifier":"id","custom":{"age":42,"ratio":0.5,"beta":true,"tags":["a","b"]}}`), &user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"age": "42", "ratio": "0.5", "beta": "true", "tags": `["a","b"]`}
+	for name, value := range expected {
+		if user.GetAttribute(name) != value {
+			t.Errorf("Expecting %s to be %q, got %q", name, value, user.GetAttribute(name))
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"email":"a@example.com"}`), &user); err == nil {
+		t.Error("Expecting an error without identifier")
+	}
+}
+
+func TestNewUserFromMap(t *testing.T) {
+	user, err := NewUserFromMap(map[string]interface{}{"Identifier": 1234, "Email": "a@example.com", "Country": nil, "Score": 9.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.identifier != "1234" || user.GetAttribute("email") != "a@example.com" ||
+		user.GetAttribute("country") != "" || user.GetAttribute("score") != "9.5" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	if _, err := NewUserFromMap(map[string]interface{}{"email": "a@example.com"}); err == nil {
+		t.Error("Expecting an error without identifier")
+	}
+}
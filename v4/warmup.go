@@ -0,0 +1,171 @@
+package configcat
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Warmup blocks until the configuration is available or the context is done, then pre-evaluates the given
+// keys for the user and memoizes the results, so the latency-sensitive request paths don't pay the parsing
+// and the first evaluation costs. If keys is empty then all the settings are warmed up. The memoized results
+// are used by the evaluations of the same keys for a user with the same attributes, until the configuration
+// changes. The results aren't memoized when ClientConfig.AttributeResolver is set, as the resolved attributes
+// may change between the evaluations, only the parsing is warmed up then. The returned error lists the keys
+// which failed to evaluate, the others are warmed up regardless.
+func (client *Client) Warmup(ctx context.Context, keys []string, user *User) error {
+	json, ok := client.refreshPolicy.cachedConfiguration()
+	if !ok {
+		result, err := client.refreshPolicy.getConfigurationAsync().getWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		json, _ = result.(string)
+	}
+
+	config, err := client.parser.load(json)
+	if err != nil {
+		return &ParseError{"JSON parsing failed. " + err.Error() + "."}
+	}
+
+	if len(keys) == 0 {
+		keys = make([]string, 0, len(config.settings))
+		for key := range config.settings {
+			keys = append(keys, key)
+		}
+	}
+
+	memoize := client.parser.evaluator.attributeResolver == nil
+	userKey := userCacheKey(user)
+	var failed []string
+	for _, key := range keys {
+		result, err := client.parser.parseVariation(json, key, user)
+		if err != nil {
+			failed = append(failed, key)
+			continue
+		}
+		if memoize {
+			client.warmed.put(config, warmedKey{userKey: userKey, key: key}, result)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("warmup failed for the keys: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// variation evaluates the key for the user, reusing the result memoized by Warmup when there is one.
+func (client *Client) variation(json string, key string, user *User) (evaluation, error) {
+	if client.warmed.empty() {
+		return client.parser.parseVariation(json, key, user)
+	}
+
+	// the user isn't hashed unless the memoized evaluations belong to the current configuration
+	if config, err := client.parser.load(json); err == nil && client.warmed.holds(config) {
+		if result, ok := client.warmed.get(config, warmedKey{userKey: userCacheKey(user), key: key}); ok {
+			result.timing = EvaluationTiming{}
+			return result, nil
+		}
+	}
+	return client.parser.parseVariation(json, key, user)
+}
+
+type warmedKey struct {
+	userKey [sha256.Size]byte
+	key     string
+}
+
+// warmedEvaluations holds the evaluations memoized by Warmup for a configuration, it's emptied when the
+// configuration changes.
+type warmedEvaluations struct {
+	budget  *memoBudget
+	config  *parsedConfig
+	entries map[warmedKey]warmedEntry
+	// the number of the entries, so the evaluations don't lock while nothing is warmed up
+	count int32
+	// the *parsedConfig of the entries, so the evaluations don't lock after the configuration changes
+	current atomic.Value
+	sync.RWMutex
+}
+
+type warmedEntry struct {
+	result evaluation
+	memo   *memoEntry
+}
+
+func newWarmedEvaluations(budget *memoBudget) *warmedEvaluations {
+	return &warmedEvaluations{budget: budget, entries: map[warmedKey]warmedEntry{}}
+}
+
+func (warmed *warmedEvaluations) len() int {
+	return int(atomic.LoadInt32(&warmed.count))
+}
+
+func (warmed *warmedEvaluations) empty() bool {
+	return atomic.LoadInt32(&warmed.count) == 0
+}
+
+// holds returns true if there are entries memoized for the configuration.
+func (warmed *warmedEvaluations) holds(config *parsedConfig) bool {
+	current, _ := warmed.current.Load().(*parsedConfig)
+	return current == config && !warmed.empty()
+}
+
+func (warmed *warmedEvaluations) get(config *parsedConfig, key warmedKey) (evaluation, bool) {
+	warmed.RLock()
+	defer warmed.RUnlock()
+
+	if warmed.config != config {
+		return evaluation{}, false
+	}
+	entry, ok := warmed.entries[key]
+	if ok {
+		warmed.budget.touch(entry.memo)
+	}
+	return entry.result, ok
+}
+
+func (warmed *warmedEvaluations) put(config *parsedConfig, key warmedKey, result evaluation) {
+	var evicted []*memoEntry
+	defer func() {
+		release(evicted)
+	}()
+	warmed.Lock()
+	defer warmed.Unlock()
+
+	if warmed.config != config {
+		for _, entry := range warmed.entries {
+			warmed.budget.remove(entry.memo)
+		}
+		warmed.config = config
+		warmed.entries = map[warmedKey]warmedEntry{}
+		warmed.current.Store(config)
+	}
+	if previous, ok := warmed.entries[key]; ok {
+		warmed.budget.remove(previous.memo)
+	}
+	size := int64(memoEntryOverhead+len(key.key)+len(result.variationId)) + estimateValueSize(result.value)
+	for _, attribute := range result.missingAttributes {
+		size += int64(16 + len(attribute))
+	}
+	entry := warmedEntry{result: result}
+	entry.memo, evicted = warmed.budget.add(warmed, key, size)
+	warmed.entries[key] = entry
+	atomic.StoreInt32(&warmed.count, int32(len(warmed.entries)))
+}
+
+// evict removes the entry evicted by the memoization budget.
+func (warmed *warmedEvaluations) evict(memo *memoEntry) {
+	warmed.Lock()
+	defer warmed.Unlock()
+
+	key := memo.key.(warmedKey)
+	if entry, ok := warmed.entries[key]; ok && entry.memo == memo {
+		delete(warmed.entries, key)
+		atomic.StoreInt32(&warmed.count, int32(len(warmed.entries)))
+	}
+}
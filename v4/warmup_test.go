@@ -0,0 +1,115 @@
+package configcat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestClient_Warmup(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"flag": { "v": false, "t": 0, "r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": true } ] },
+		"text": { "v": "value", "t": 1 }
+	}`})
+	client.Refresh()
+
+	user := NewUserWithAdditionalAttributes("id", "a@example.com", "", nil)
+	if err := client.Warmup(context.Background(), []string{"flag", "text"}, user); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config, _ := client.parser.load(client.store.get())
+	if _, ok := client.warmed.get(config, warmedKey{userKey: userCacheKey(user), key: "flag"}); !ok {
+		t.Error("Expecting the warmed up evaluation to be memoized")
+	}
+
+	sameUser := NewUserWithAdditionalAttributes("id", "a@example.com", "", nil)
+	if value, err := client.GetBoolValue("flag", false, sameUser); !value || err != nil {
+		t.Errorf("Unexpected warmed up value: %v, %v", value, err)
+	}
+	if value, _ := client.GetBoolValue("flag", false, NewUserWithAdditionalAttributes("id", "a@test.com", "", nil)); value {
+		t.Error("Expecting the other users to be evaluated")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "flag", "false")})
+	client.Refresh()
+	if value, _ := client.GetBoolValue("flag", true, sameUser); value {
+		t.Error("Expecting the memoized evaluations to be dropped when the configuration changes")
+	}
+}
+
+func TestClient_Warmup_AllKeys(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{ "a": { "v": 1, "t": 2 }, "b": { "v": 2, "t": 2 } }`})
+	client.Refresh()
+
+	if err := client.Warmup(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(client.warmed.entries) != 2 {
+		t.Errorf("Expecting all the keys to be warmed up, got %v", client.warmed.entries)
+	}
+}
+
+func TestClient_Warmup_MissingKey(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+
+	err := client.Warmup(context.Background(), []string{"key", "missing"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expecting the missing key to be reported, got %v", err)
+	}
+	if client.warmed.empty() {
+		t.Error("Expecting the existing key to be warmed up regardless")
+	}
+}
+
+func TestClient_Warmup_ConfigChanged(t *testing.T) {
+	fetcher, client := getTestClients()
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "true")})
+	client.Refresh()
+
+	if err := client.Warmup(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config, _ := client.parser.load(client.store.get())
+	if !client.warmed.holds(config) {
+		t.Error("Expecting the evaluations of the current configuration to be memoized")
+	}
+
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: fmt.Sprintf(jsonFormat, "key", "false")})
+	client.Refresh()
+	config, _ = client.parser.load(client.store.get())
+	if client.warmed.holds(config) {
+		t.Error("Expecting the memoized evaluations to be skipped after the configuration changes")
+	}
+	if value, _ := client.GetBoolValue("key", true, nil); value {
+		t.Error("Expecting the new configuration to be evaluated")
+	}
+}
+
+func TestClient_Warmup_AttributeResolver(t *testing.T) {
+	fetcher := newFakeConfigProvider()
+	client := newInternal("fakeKey", ClientConfig{
+		Mode:              ManualPoll(),
+		AttributeResolver: func(user *User, attribute string) (string, bool) { return "a@example.com", true },
+	}, fetcher)
+	defer client.Close()
+	fetcher.SetResponse(fetchResponse{status: Fetched, body: `{
+		"flag": { "v": false, "t": 0, "r": [ { "o": 0, "a": "Email", "t": 2, "c": "@example.com", "v": true } ] }
+	}`})
+	client.Refresh()
+
+	if err := client.Warmup(context.Background(), nil, NewUser("id")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !client.warmed.empty() {
+		t.Errorf("Expecting no memoized evaluations with an attribute resolver, got %v", client.warmed.entries)
+	}
+}